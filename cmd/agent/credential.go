@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/rancher/rancher/pkg/rkenodeconfigclient"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	credentialRetryInterval = time.Minute
+	// credentialFile keeps the credential across restarts of the agents running outside of the cluster, their
+	// container is restarted in place
+	credentialFile = "credential"
+	// credentialSecretPrefix names the secrets keeping the credential across restarts of the agent pods
+	credentialSecretPrefix = "cattle-agent-credential"
+	credentialSecretKey    = "credential"
+)
+
+type credentialResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// tunnelCredential holds the token the agent authenticates with. The registration token is exchanged for a
+// short-lived credential which is renewed before it expires and kept across restarts by its store. The agent exits when
+// its credential is rejected, it was revoked and the registration token has to be exchanged again.
+type tunnelCredential struct {
+	sync.Mutex
+	url               string
+	registrationToken string
+	params            string
+	token             string
+	expiresAt         time.Time
+	client            *http.Client
+	store             credentialStore
+}
+
+func newTunnelCredential(host, registrationToken, params string, store credentialStore) *tunnelCredential {
+	c := &tunnelCredential{
+		url:               fmt.Sprintf("https://%s/v3/connect/credential", host),
+		registrationToken: registrationToken,
+		params:            params,
+		token:             registrationToken,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		store: store,
+	}
+	if cred, err := store.load(); err != nil {
+		logrus.Warnf("Failed to load agent credential: %v", err)
+	} else if cred != nil && time.Now().Before(cred.ExpiresAt) {
+		c.token = cred.Token
+		c.expiresAt = cred.ExpiresAt
+	}
+	return c
+}
+
+// headers returns the headers authenticating the agent with its current token
+func (c *tunnelCredential) headers() http.Header {
+	c.Lock()
+	defer c.Unlock()
+
+	return http.Header{
+		Token:                      {c.token},
+		rkenodeconfigclient.Params: {c.params},
+	}
+}
+
+// rotate renews the credential when two thirds of its lifetime have passed, until the context is done or the server
+// does not issue credentials
+func (c *tunnelCredential) rotate(ctx context.Context) {
+	for {
+		supported, err := c.renew()
+		if !supported {
+			logrus.Infof("Server does not issue agent credentials, using the registration token")
+			return
+		}
+
+		wait := credentialRetryInterval
+		if err != nil {
+			logrus.Errorf("Failed to renew agent credential: %v", err)
+		} else {
+			c.Lock()
+			wait = time.Until(c.expiresAt) * 2 / 3
+			c.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// renew exchanges the current token for a new credential
+func (c *tunnelCredential) renew() (bool, error) {
+	c.Lock()
+	token := c.token
+	c.Unlock()
+
+	resp, status, err := c.exchange(token)
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	if (status == http.StatusUnauthorized || status == http.StatusForbidden) && token != c.registrationToken {
+		if err := c.store.remove(); err != nil {
+			logrus.Warnf("Failed to remove agent credential: %v", err)
+		}
+		logrus.Fatalf("Agent credential was rejected, restarting to exchange the registration token: %v", err)
+	}
+	if status == http.StatusForbidden {
+		return true, fmt.Errorf("the registration token was already exchanged, the agent credentials of the cluster have to be revoked to exchange it again: %v", err)
+	}
+	if err != nil {
+		return true, err
+	}
+
+	if err := c.store.store(resp); err != nil {
+		logrus.Warnf("Failed to store agent credential: %v", err)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.token = resp.Token
+	c.expiresAt = resp.ExpiresAt
+	logrus.Infof("Renewed agent credential, expires at %v", resp.ExpiresAt)
+	return true, nil
+}
+
+func (c *tunnelCredential) exchange(token string) (*credentialResponse, int, error) {
+	req, err := http.NewRequest(http.MethodPost, c.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set(Token, token)
+	req.Header.Set(rkenodeconfigclient.Params, c.params)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, resp.StatusCode, fmt.Errorf("invalid response %d: %s", resp.StatusCode, string(body))
+	}
+
+	cred := &credentialResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(cred); err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if cred.Token == "" {
+		return nil, resp.StatusCode, fmt.Errorf("empty agent credential")
+	}
+	return cred, resp.StatusCode, nil
+}
+
+// credentialStore keeps the credential of the agent across its restarts, the registration token can only be exchanged
+// once
+type credentialStore interface {
+	load() (*credentialResponse, error)
+	store(cred *credentialResponse) error
+	remove() error
+}
+
+// newCredentialStore returns a store keeping the credential in a secret of the cluster when the agent runs in a pod, a
+// new pod replaces it on restart, and in a file otherwise
+func newCredentialStore() credentialStore {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return fileCredentialStore(credentialFile)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		logrus.Warnf("Failed to create client to store agent credential, using file %s: %v", credentialFile, err)
+		return fileCredentialStore(credentialFile)
+	}
+	return &secretCredentialStore{
+		secrets: client.CoreV1().Secrets(namespace.System),
+		name:    credentialSecretName(isCluster(), os.Getenv("CATTLE_NODE_NAME")),
+	}
+}
+
+// credentialSecretName returns the secret of the cluster agent, or of the node agent of a node
+func credentialSecretName(cluster bool, nodeName string) string {
+	if cluster {
+		return credentialSecretPrefix
+	}
+	hash := sha256.Sum256([]byte(nodeName))
+	return credentialSecretPrefix + "-" + hex.EncodeToString(hash[:])[:10]
+}
+
+type secretCredentialStore struct {
+	secrets typedcorev1.SecretInterface
+	name    string
+}
+
+func (s *secretCredentialStore) load() (*credentialResponse, error) {
+	secret, err := s.secrets.Get(context.Background(), s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	cred := &credentialResponse{}
+	return cred, json.Unmarshal(secret.Data[credentialSecretKey], cred)
+}
+
+func (s *secretCredentialStore) store(cred *credentialResponse) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+
+	secret, err := s.secrets.Get(context.Background(), s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = s.secrets.Create(context.Background(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: s.name,
+			},
+			Data: map[string][]byte{
+				credentialSecretKey: data,
+			},
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	secret = secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[credentialSecretKey] = data
+	_, err = s.secrets.Update(context.Background(), secret, metav1.UpdateOptions{})
+	return err
+}
+
+func (s *secretCredentialStore) remove() error {
+	err := s.secrets.Delete(context.Background(), s.name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+type fileCredentialStore string
+
+func (f fileCredentialStore) load() (*credentialResponse, error) {
+	data, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	cred := &credentialResponse{}
+	return cred, json.Unmarshal(data, cred)
+}
+
+func (f fileCredentialStore) store(cred *credentialResponse) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(string(f), data, 0600)
+}
+
+func (f fileCredentialStore) remove() error {
+	err := os.Remove(string(f))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
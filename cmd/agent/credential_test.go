@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeCredentialServer exchanges the registration token once and the issued credentials any number of times
+type fakeCredentialServer struct {
+	sync.Mutex
+	exchanged []string
+	issued    int
+}
+
+func (f *fakeCredentialServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	token := req.Header.Get(Token)
+	if token == "registration" {
+		for _, exchanged := range f.exchanged {
+			if exchanged == token {
+				rw.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+	}
+	f.exchanged = append(f.exchanged, token)
+	f.issued++
+	json.NewEncoder(rw).Encode(credentialResponse{
+		Token:     fmt.Sprintf("credential-%d", f.issued),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+}
+
+func TestCredentialRestart(t *testing.T) {
+	fakeServer := &fakeCredentialServer{}
+	server := httptest.NewTLSServer(fakeServer)
+	defer server.Close()
+
+	store := &secretCredentialStore{
+		secrets: fake.NewSimpleClientset().CoreV1().Secrets(namespace.System),
+		name:    credentialSecretName(true, ""),
+	}
+	start := func() *tunnelCredential {
+		c := newTunnelCredential(strings.TrimPrefix(server.URL, "https://"), "registration", "params", store)
+		c.client = server.Client()
+		return c
+	}
+
+	c := start()
+	assert.Equal(t, "registration", c.headers()[Token][0])
+	supported, err := c.renew()
+	assert.True(t, supported)
+	assert.Nil(t, err)
+	assert.Equal(t, "credential-1", c.headers()[Token][0])
+
+	// the restarted agent renews the stored credential instead of exchanging the registration token again
+	c = start()
+	assert.Equal(t, "credential-1", c.headers()[Token][0])
+	supported, err = c.renew()
+	assert.True(t, supported)
+	assert.Nil(t, err)
+	assert.Equal(t, "credential-2", c.headers()[Token][0])
+	assert.Equal(t, []string{"registration", "credential-1"}, fakeServer.exchanged)
+
+	// without the stored credential the registration token is rejected
+	assert.Nil(t, store.remove())
+	c = start()
+	_, err = c.renew()
+	assert.NotNil(t, err)
+	assert.Equal(t, "registration", c.headers()[Token][0])
+}
+
+func TestCredentialSecretName(t *testing.T) {
+	assert.Equal(t, "cattle-agent-credential", credentialSecretName(true, "node1"))
+	assert.NotEqual(t, credentialSecretName(false, "node1"), credentialSecretName(false, "node2"))
+	assert.True(t, strings.HasPrefix(credentialSecretName(false, "node1"), "cattle-agent-credential-"))
+}
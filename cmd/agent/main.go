@@ -173,16 +173,13 @@ func run() error {
 		return err
 	}
 
-	headers := map[string][]string{
-		Token:                      {token},
-		rkenodeconfigclient.Params: {base64.StdEncoding.EncodeToString(bytes)},
-	}
-
 	serverURL, err := url.Parse(server)
 	if err != nil {
 		return err
 	}
 
+	credential := newTunnelCredential(serverURL.Host, token, base64.StdEncoding.EncodeToString(bytes), newCredentialStore())
+
 	// Check if secure connection can be made successfully
 	var httpClient = &http.Client{
 		Timeout: time.Second * 5,
@@ -280,7 +277,7 @@ func run() error {
 	onConnect := func(ctx context.Context, _ *remotedialer.Session) error {
 		connected()
 		connectConfig := fmt.Sprintf("https://%s/v3/connect/config", serverURL.Host)
-		interval, err := rkenodeconfigclient.ConfigClient(ctx, connectConfig, headers, writeCertsOnly)
+		interval, err := rkenodeconfigclient.ConfigClient(ctx, connectConfig, credential.headers(), writeCertsOnly)
 		if err != nil {
 			return err
 		}
@@ -303,7 +300,7 @@ func run() error {
 			for {
 				select {
 				case <-time.After(tt):
-					receivedInterval, err := rkenodeconfigclient.ConfigClient(ctx, connectConfig, headers, writeCertsOnly)
+					receivedInterval, err := rkenodeconfigclient.ConfigClient(ctx, connectConfig, credential.headers(), writeCertsOnly)
 					if err != nil {
 						logrus.Errorf("failed to check plan: %v", err)
 					} else if receivedInterval != 0 && receivedInterval != interval {
@@ -326,13 +323,16 @@ func run() error {
 		}()
 	}
 
+	// the credential is needed to connect when the server requires it
+	go credential.rotate(topContext)
+
 	for {
 		wsURL := fmt.Sprintf("wss://%s/v3/connect", serverURL.Host)
 		if !isConnect() {
 			wsURL += "/register"
 		}
-		logrus.Infof("Connecting to %s with token %s", wsURL, token)
-		remotedialer.ClientConnect(context.Background(), wsURL, http.Header(headers), nil, func(proto, address string) bool {
+		logrus.Infof("Connecting to %s", wsURL)
+		remotedialer.ClientConnect(context.Background(), wsURL, credential.headers(), nil, func(proto, address string) bool {
 			switch proto {
 			case "tcp":
 				return true
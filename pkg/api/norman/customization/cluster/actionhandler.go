@@ -5,12 +5,12 @@ import (
 
 	"github.com/rancher/norman/httperror"
 	"github.com/rancher/norman/types"
-	gaccess "github.com/rancher/rancher/pkg/api/norman/customization/globalnamespaceaccess"
 	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/catalog/manager"
 	mgmtclient "github.com/rancher/rancher/pkg/client/generated/management/v3"
 	"github.com/rancher/rancher/pkg/clustermanager"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	gaccess "github.com/rancher/rancher/pkg/globalnamespaceaccess"
 	"github.com/rancher/rancher/pkg/user"
 	v1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -58,6 +58,13 @@ func (a ActionHandler) ClusterActionHandler(actionName string, action *types.Act
 		return canCreateTemplates
 	}
 
+	if apiContext.ID == "" {
+		if actionName == v32.ClusterActionGenerateKubeconfig {
+			return a.GenerateClustersKubeconfigActionHandler(actionName, action, apiContext)
+		}
+		return httperror.NewAPIError(httperror.NotFound, "not found")
+	}
+
 	switch actionName {
 	case v32.ClusterActionGenerateKubeconfig:
 		return a.GenerateKubeconfigActionHandler(actionName, action, apiContext)
@@ -92,6 +99,11 @@ func (a ActionHandler) ClusterActionHandler(actionName string, action *types.Act
 			return httperror.NewAPIError(httperror.PermissionDenied, "can not restore etcd backup")
 		}
 		return a.RestoreFromEtcdBackupHandler(actionName, action, apiContext)
+	case v32.ClusterActionResume:
+		if !canUpdateCluster() {
+			return httperror.NewAPIError(httperror.PermissionDenied, "can not resume cluster create")
+		}
+		return a.ResumeHandler(actionName, action, apiContext)
 	case v32.ClusterActionRotateCertificates:
 		if !canUpdateCluster() {
 			return httperror.NewAPIError(httperror.PermissionDenied, "can not rotate certificates")
@@ -1,14 +1,24 @@
 package cluster
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/rancher/norman/api/access"
+	"github.com/rancher/norman/httperror"
 	"github.com/rancher/norman/types"
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/auth/tokens"
 	mgmtclient "github.com/rancher/rancher/pkg/client/generated/management/v3"
 	"github.com/rancher/rancher/pkg/kubeconfig"
 	"github.com/rancher/rancher/pkg/settings"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 func (a ActionHandler) GenerateKubeconfigActionHandler(actionName string, action *types.Action, apiContext *types.APIContext) error {
@@ -17,18 +27,30 @@ func (a ActionHandler) GenerateKubeconfigActionHandler(actionName string, action
 		return err
 	}
 
+	input, err := readGenerateKubeconfigInput(apiContext)
+	if err != nil {
+		return err
+	}
+
 	var (
 		cfg   string
 		token string
-		err   error
 	)
 
 	endpointEnabled := cluster.LocalClusterAuthEndpoint != nil && cluster.LocalClusterAuthEndpoint.Enabled
+	// clusters limiting the ttl of their kubeconfig tokens get tokens scoped to them
+	clusterTTL, err := tokens.ClusterKubeconfigTokenTTL(cluster.ID)
+	if err != nil {
+		return err
+	}
 
-	generateToken := strings.EqualFold(settings.KubeconfigGenerateToken.Get(), "true")
+	generateToken, err := kubeconfigGenerateToken(input.AuthMode)
+	if err != nil {
+		return err
+	}
 	if generateToken {
-		// generate token and place it in kubeconfig, token doesn't expire
-		if endpointEnabled {
+		// generate token and place it in kubeconfig, token doesn't expire unless the cluster limits its ttl
+		if endpointEnabled || clusterTTL > 0 {
 			token, err = a.getClusterToken(cluster.ID, apiContext)
 		} else {
 			token, err = a.getToken(apiContext)
@@ -38,7 +60,9 @@ func (a ActionHandler) GenerateKubeconfigActionHandler(actionName string, action
 		}
 	}
 
-	if endpointEnabled {
+	if input.AuthMode == v32.KubeconfigAuthModeExec {
+		cfg, err = kubeconfig.ForExecCredential(&cluster, apiContext.ID, apiContext.Request.Host)
+	} else if endpointEnabled {
 		cfg, err = kubeconfig.ForClusterTokenBased(&cluster, apiContext.ID, apiContext.Request.Host, token)
 	} else {
 		cfg, err = kubeconfig.ForTokenBased(cluster.Name, apiContext.ID, apiContext.Request.Host, token)
@@ -54,3 +78,95 @@ func (a ActionHandler) GenerateKubeconfigActionHandler(actionName string, action
 	apiContext.WriteResponse(http.StatusOK, data)
 	return nil
 }
+
+// GenerateClustersKubeconfigActionHandler generates a kubeconfig with the contexts of all the clusters the user can
+// access matching the cluster selector, with a single token scoped to these clusters
+func (a ActionHandler) GenerateClustersKubeconfigActionHandler(actionName string, action *types.Action, apiContext *types.APIContext) error {
+	input, err := readGenerateKubeconfigInput(apiContext)
+	if err != nil {
+		return err
+	}
+
+	selector, err := labels.Parse(input.ClusterSelector)
+	if err != nil {
+		return httperror.NewAPIError(httperror.InvalidFormat, fmt.Sprintf("invalid clusterSelector: %v", err))
+	}
+
+	var clusters []mgmtclient.Cluster
+	if err := access.List(apiContext, apiContext.Version, mgmtclient.ClusterType, &types.QueryOptions{}, &clusters); err != nil {
+		return err
+	}
+
+	var (
+		matching   []mgmtclient.Cluster
+		clusterIDs []string
+	)
+	for _, cluster := range clusters {
+		if !selector.Matches(labels.Set(cluster.Labels)) {
+			continue
+		}
+		matching = append(matching, cluster)
+		clusterIDs = append(clusterIDs, cluster.ID)
+	}
+	if len(matching) == 0 {
+		return httperror.NewAPIError(httperror.NotFound, "no accessible cluster matches the clusterSelector")
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].Name < matching[j].Name
+	})
+	sort.Strings(clusterIDs)
+
+	generateToken, err := kubeconfigGenerateToken(input.AuthMode)
+	if err != nil {
+		return err
+	}
+
+	token := ""
+	if generateToken {
+		// one token per cluster selector, so that kubeconfigs of different selectors don't revoke each other
+		userName := a.UserMgr.GetUser(apiContext)
+		hash := sha256.Sum256([]byte(selector.String()))
+		tokenName := fmt.Sprintf("kubeconfig-%s.clusters-%s", userName, hex.EncodeToString(hash[:])[:10])
+		token, err = a.UserMgr.EnsureClustersToken(clusterIDs, tokenName, "Kubeconfig token", "kubeconfig", userName)
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg, err := kubeconfig.ForClusters(matching, apiContext.Request.Host, token)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"config": cfg,
+		"type":   "generateKubeconfigOutput",
+	}
+	apiContext.WriteResponse(http.StatusOK, data)
+	return nil
+}
+
+func readGenerateKubeconfigInput(apiContext *types.APIContext) (*mgmtclient.GenerateKubeConfigInput, error) {
+	input := &mgmtclient.GenerateKubeConfigInput{}
+	if err := json.NewDecoder(apiContext.Request.Body).Decode(input); err != nil && err != io.EOF {
+		return nil, httperror.NewAPIError(httperror.InvalidBodyContent, "failed to parse generateKubeconfig input: "+err.Error())
+	}
+	return input, nil
+}
+
+// kubeconfigGenerateToken returns whether a token is placed in the kubeconfig for the auth mode
+func kubeconfigGenerateToken(authMode string) (bool, error) {
+	generateToken := strings.EqualFold(settings.KubeconfigGenerateToken.Get(), "true")
+	switch authMode {
+	case "":
+		return generateToken, nil
+	case v32.KubeconfigAuthModeExec:
+		return false, nil
+	case v32.KubeconfigAuthModeToken:
+		if !generateToken {
+			return false, httperror.NewAPIError(httperror.ActionNotAvailable, "kubeconfig with token can't be generated, kubeconfig-generate-token is disabled")
+		}
+		return true, nil
+	}
+	return false, httperror.NewAPIError(httperror.InvalidOption, "invalid authMode "+authMode)
+}
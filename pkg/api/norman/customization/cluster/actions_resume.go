@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rancher/norman/api/access"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	v3 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	mgmtv3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResumeHandler retries a failed create of a cluster right away. The provisioner continues the create from the last
+// phase checkpointed by kontainer-engine instead of waiting out the backoff of the failure.
+func (a ActionHandler) ResumeHandler(actionName string, action *types.Action, apiContext *types.APIContext) error {
+	var mgmtCluster mgmtv3.Cluster
+	if err := access.ByID(apiContext, apiContext.Version, apiContext.Type, apiContext.ID, &mgmtCluster); err != nil {
+		return errors.Wrapf(err, "failed to get cluster by ID %s", apiContext.ID)
+	}
+
+	cluster, err := a.ClusterClient.Get(apiContext.ID, v1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get cluster by ID %s", apiContext.ID)
+	}
+	if !v3.ClusterConditionProvisioned.IsFalse(cluster) {
+		return httperror.NewAPIError(httperror.InvalidAction, "cluster has not failed to provision")
+	}
+
+	cluster.Status.FailedSpec = nil
+	v3.ClusterConditionProvisioned.Unknown(cluster)
+	v3.ClusterConditionProvisioned.Message(cluster, "Resuming cluster create")
+	if _, err := a.ClusterClient.Update(cluster); err != nil {
+		return errors.Wrapf(err, "unable to update cluster %s", cluster.Name)
+	}
+
+	apiContext.WriteResponse(http.StatusOK, nil)
+	return nil
+}
@@ -108,6 +108,10 @@ func (f *Formatter) Formatter(request *types.APIContext, resource *types.RawReso
 		resource.AddAction(request, v32.ClusterActionViewMonitoring)
 	}
 
+	if resumeEnabled(f.clusterLister, resource.ID) && canUserUpdateCluster(request, resource) {
+		resource.AddAction(request, v32.ClusterActionResume)
+	}
+
 	if gkeConfig, ok := resource.Values["googleKubernetesEngineConfig"]; ok {
 		configMap, ok := gkeConfig.(map[string]interface{})
 		if !ok {
@@ -178,6 +182,15 @@ func rotateEncryptionKeyEnabled(clusterLister v3.ClusterLister, clusterName stri
 	return v32.ClusterConditionUpdated.IsTrue(cluster)
 }
 
+// resumeEnabled returns true if the cluster failed to provision, its create can then be resumed.
+func resumeEnabled(clusterLister v3.ClusterLister, clusterName string) bool {
+	cluster, err := clusterLister.Get("", clusterName)
+	if err != nil {
+		return false
+	}
+	return v32.ClusterConditionProvisioned.IsFalse(cluster)
+}
+
 func setTrueIfNil(configMap map[string]interface{}, fieldName string) {
 	if configMap[fieldName] == nil {
 		configMap[fieldName] = true
@@ -202,6 +215,7 @@ func hideClusterTemplateAnswers(answerMap map[string]interface{}, clusterSpecPwd
 
 func (f *Formatter) CollectionFormatter(request *types.APIContext, collection *types.GenericCollection) {
 	collection.AddAction(request, "createFromTemplate")
+	collection.AddAction(request, v32.ClusterActionGenerateKubeconfig)
 }
 
 func gatherClusterSpecPwdFields(schemas *types.Schemas, schema *types.Schema) map[string]interface{} {
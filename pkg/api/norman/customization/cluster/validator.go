@@ -12,12 +12,12 @@ import (
 	"github.com/rancher/norman/httperror"
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/types/convert"
-	gaccess "github.com/rancher/rancher/pkg/api/norman/customization/globalnamespaceaccess"
 	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
 	mgmtclient "github.com/rancher/rancher/pkg/client/generated/management/v3"
 	"github.com/rancher/rancher/pkg/controllers/management/k3sbasedupgrade"
 	"github.com/rancher/rancher/pkg/controllers/managementuser/cis"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	gaccess "github.com/rancher/rancher/pkg/globalnamespaceaccess"
 	"github.com/rancher/rancher/pkg/kontainer-engine/service"
 	"github.com/rancher/rancher/pkg/namespace"
 	mgmtSchema "github.com/rancher/rancher/pkg/schemas/management.cattle.io/v3"
@@ -55,6 +55,10 @@ func (v *Validator) Validator(request *types.APIContext, schema *types.Schema, d
 		return err
 	}
 
+	if err := v.validateConnectionMode(request, &clusterSpec); err != nil {
+		return err
+	}
+
 	if err := v.validateLocalClusterAuthEndpoint(request, &clusterSpec); err != nil {
 		return err
 	}
@@ -147,6 +151,43 @@ func validateScheduledClusterScan(spec *mgmtclient.Cluster) error {
 	return nil
 }
 
+// validateConnectionMode only allows admins to force the way rancher connects to a cluster, as the direct and proxy
+// modes bypass the agent tunnel
+func (v *Validator) validateConnectionMode(request *types.APIContext, spec *v32.ClusterSpec) error {
+	current := ""
+	if request.ID != "" {
+		cluster, err := v.ClusterLister.Get("", request.ID)
+		if err != nil {
+			return err
+		}
+		current = cluster.Spec.ConnectionMode
+	}
+	if connectionModeOrAuto(spec.ConnectionMode) == connectionModeOrAuto(current) {
+		return nil
+	}
+
+	ma := gaccess.MemberAccess{
+		Users:     v.Users,
+		GrLister:  v.GrLister,
+		GrbLister: v.GrbLister,
+	}
+	isAdmin, err := ma.IsAdmin(request.Request.Header.Get(gaccess.ImpersonateUserHeader))
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return httperror.NewFieldAPIError(httperror.PermissionDenied, "connectionMode", "only admins can set the connection mode of a cluster")
+	}
+	return nil
+}
+
+func connectionModeOrAuto(mode string) string {
+	if mode == "" {
+		return v32.ClusterConnectionModeAuto
+	}
+	return mode
+}
+
 func (v *Validator) validateLocalClusterAuthEndpoint(request *types.APIContext, spec *v32.ClusterSpec) error {
 	if !spec.LocalClusterAuthEndpoint.Enabled {
 		return nil
@@ -2,10 +2,20 @@ package cluster
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/rancher/norman/types"
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
 	mgmtclient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	mgmtfakes "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3/fakes"
+	gaccess "github.com/rancher/rancher/pkg/globalnamespaceaccess"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const clusterSpecJSON = `
@@ -191,3 +201,86 @@ func TestClusterTemplateValidateScheduledClusterScan(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestValidateConnectionMode(t *testing.T) {
+	users := map[string]string{
+		"admin": "admin",
+		"owner": "user",
+	}
+	v := &Validator{
+		ClusterLister: &mgmtfakes.ClusterListerMock{
+			GetFunc: func(namespace, name string) (*v32.Cluster, error) {
+				cluster := &v32.Cluster{}
+				cluster.Name = name
+				if name == "c-direct" {
+					cluster.Spec.ConnectionMode = v32.ClusterConnectionModeDirect
+				}
+				return cluster, nil
+			},
+		},
+		Users: &mgmtfakes.UserInterfaceMock{
+			ControllerFunc: func() v3.UserController {
+				return &mgmtfakes.UserControllerMock{
+					ListerFunc: func() v3.UserLister {
+						return &mgmtfakes.UserListerMock{
+							GetFunc: func(namespace, name string) (*v32.User, error) {
+								user := &v32.User{}
+								user.Name = name
+								return user, nil
+							},
+						}
+					},
+				}
+			},
+		},
+		GrbLister: &mgmtfakes.GlobalRoleBindingListerMock{
+			ListFunc: func(namespace string, selector labels.Selector) ([]*v32.GlobalRoleBinding, error) {
+				var grbs []*v32.GlobalRoleBinding
+				for user, role := range users {
+					grbs = append(grbs, &v32.GlobalRoleBinding{UserName: user, GlobalRoleName: role})
+				}
+				return grbs, nil
+			},
+		},
+		GrLister: &mgmtfakes.GlobalRoleListerMock{
+			GetFunc: func(namespace, name string) (*v32.GlobalRole, error) {
+				role := &v32.GlobalRole{}
+				if name == "admin" {
+					role.Rules = []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}
+				}
+				return role, nil
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		cluster string
+		mode    string
+		allowed bool
+	}{
+		{name: "create in auto mode", user: "owner", mode: "", allowed: true},
+		{name: "create in direct mode", user: "owner", mode: v32.ClusterConnectionModeDirect},
+		{name: "admin creates in direct mode", user: "admin", mode: v32.ClusterConnectionModeDirect, allowed: true},
+		{name: "unchanged mode", user: "owner", cluster: "c-direct", mode: v32.ClusterConnectionModeDirect, allowed: true},
+		{name: "auto mode unset", user: "owner", cluster: "c-auto", mode: v32.ClusterConnectionModeAuto, allowed: true},
+		{name: "changed mode", user: "owner", cluster: "c-auto", mode: v32.ClusterConnectionModeProxy},
+		{name: "reset mode", user: "owner", cluster: "c-direct", mode: ""},
+		{name: "admin changes mode", user: "admin", cluster: "c-direct", mode: v32.ClusterConnectionModeTunnel, allowed: true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/v3/clusters", nil)
+		req.Header.Set(gaccess.ImpersonateUserHeader, tt.user)
+		request := &types.APIContext{
+			ID:      tt.cluster,
+			Request: req,
+		}
+		err := v.validateConnectionMode(request, &v32.ClusterSpec{ConnectionMode: tt.mode})
+		if tt.allowed {
+			assert.Nil(t, err, tt.name)
+		} else {
+			assert.NotNil(t, err, tt.name)
+		}
+	}
+}
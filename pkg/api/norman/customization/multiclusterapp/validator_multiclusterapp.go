@@ -8,18 +8,21 @@ import (
 	"strings"
 	"time"
 
+	v33 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
 	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
 
 	"github.com/rancher/norman/api/access"
+	"github.com/rancher/norman/httperror"
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/types/convert"
 	"github.com/rancher/norman/types/set"
 	"github.com/rancher/norman/types/values"
-	gaccess "github.com/rancher/rancher/pkg/api/norman/customization/globalnamespaceaccess"
 	"github.com/rancher/rancher/pkg/catalog/manager"
 	client "github.com/rancher/rancher/pkg/client/generated/management/v3"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	pv3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	gaccess "github.com/rancher/rancher/pkg/globalnamespaceaccess"
+	"github.com/rancher/rancher/pkg/project"
 	"github.com/rancher/rancher/pkg/ref"
 	managementschema "github.com/rancher/rancher/pkg/schemas/management.cattle.io/v3"
 	"github.com/sirupsen/logrus"
@@ -114,6 +117,14 @@ func (w Wrapper) Validator(request *types.APIContext, schema *types.Schema, data
 		for _, t := range targets {
 			targetProjects = append(targetProjects, convert.ToString(t[client.TargetFieldProjectID]))
 		}
+		selectorProjects, err := w.selectorProjects(data)
+		if err != nil {
+			return err
+		}
+		targetProjects = append(targetProjects, selectorProjects...)
+		if err := validateTargets(len(targets), data); err != nil {
+			return err
+		}
 		roleTemplates := convert.ToStringSlice(data[client.MultiClusterAppFieldRoles])
 		return ma.EnsureRoleInTargets(targetProjects, roleTemplates, callerID)
 	}
@@ -148,6 +159,29 @@ func (w Wrapper) Validator(request *types.APIContext, schema *types.Schema, data
 		return err
 	}
 
+	if _, ok := data[client.MultiClusterAppFieldTargetSelectors]; ok {
+		explicitTargets := 0
+		for _, t := range mcapp.Spec.Targets {
+			if t.Selector == "" {
+				explicitTargets++
+			}
+		}
+		if err := validateTargets(explicitTargets, data); err != nil {
+			return err
+		}
+	}
+
+	// changing the target selectors changes the targets, so the caller needs all roles in the newly selected projects
+	if data[client.MultiClusterAppFieldTargetSelectors] != nil {
+		selectorProjects, err := w.selectorProjects(data)
+		if err != nil {
+			return err
+		}
+		if err := ma.EnsureRoleInTargets(selectorProjects, mcapp.Spec.Roles, callerID); err != nil {
+			return err
+		}
+	}
+
 	// check whether roles are being edited, if yes then only owner should be allowed to, and should have this role in all projects
 	roles := convert.ToStringSlice(data[client.MultiClusterAppFieldRoles])
 	newRoles := make(map[string]bool)
@@ -205,3 +239,26 @@ func (w Wrapper) Validator(request *types.APIContext, schema *types.Schema, data
 	}
 	return ma.RemoveRolesFromTargets(targetProjects, rolesToRemove, mcapp.Name, false)
 }
+
+// validateTargets requires a multiclusterapp to have explicit targets or target selectors, targets are not required
+// by the schema as selectors can add all of them
+func validateTargets(explicitTargets int, data map[string]interface{}) error {
+	selectors, _ := values.GetSlice(data, client.MultiClusterAppFieldTargetSelectors)
+	if explicitTargets == 0 && len(selectors) == 0 {
+		return httperror.NewAPIError(httperror.MissingRequired, "targets or targetSelectors are required")
+	}
+	return nil
+}
+
+// selectorProjects returns the projects currently matched by the target selectors in data
+func (w Wrapper) selectorProjects(data map[string]interface{}) ([]string, error) {
+	var selectors []v33.TargetSelector
+	if err := convert.ToObj(data[client.MultiClusterAppFieldTargetSelectors], &selectors); err != nil {
+		return nil, httperror.WrapAPIError(err, httperror.InvalidBodyContent, "invalid targetSelectors")
+	}
+	_, projects, err := project.SelectTargets(w.ClusterLister, w.ProjectLister, selectors)
+	if err != nil {
+		return nil, httperror.WrapAPIError(err, httperror.InvalidBodyContent, err.Error())
+	}
+	return projects, nil
+}
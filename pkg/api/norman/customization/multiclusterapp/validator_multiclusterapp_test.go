@@ -0,0 +1,53 @@
+package multiclusterapp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTargets(t *testing.T) {
+	selectors := []interface{}{
+		map[string]interface{}{"name": "prod"},
+	}
+	tests := []struct {
+		name            string
+		explicitTargets int
+		data            map[string]interface{}
+		valid           bool
+	}{
+		{
+			name:            "targets",
+			explicitTargets: 1,
+			data:            map[string]interface{}{},
+			valid:           true,
+		},
+		{
+			name:  "selectors only",
+			data:  map[string]interface{}{"targetSelectors": selectors},
+			valid: true,
+		},
+		{
+			name:            "targets and selectors",
+			explicitTargets: 2,
+			data:            map[string]interface{}{"targetSelectors": selectors},
+			valid:           true,
+		},
+		{
+			name: "neither",
+			data: map[string]interface{}{},
+		},
+		{
+			name: "empty selectors",
+			data: map[string]interface{}{"targetSelectors": []interface{}{}},
+		},
+		{
+			name: "null selectors",
+			data: map[string]interface{}{"targetSelectors": nil},
+		},
+	}
+	for _, tt := range tests {
+		err := validateTargets(tt.explicitTargets, tt.data)
+		assert.Equal(t, tt.valid, err == nil, tt.name)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/rancher/norman/api/access"
 	"github.com/rancher/norman/httperror"
 	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
 	client "github.com/rancher/rancher/pkg/client/generated/project/v3"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/pipeline/providers"
@@ -20,6 +21,7 @@ import (
 	"github.com/rancher/rancher/pkg/pipeline/utils"
 	"github.com/rancher/rancher/pkg/rbac"
 	"github.com/rancher/rancher/pkg/ref"
+	"github.com/robfig/cron"
 	"gopkg.in/yaml.v2"
 	"k8s.io/apimachinery/pkg/labels"
 )
@@ -53,6 +55,17 @@ func Formatter(apiContext *types.APIContext, resource *types.RawResource) {
 	resource.Links[linkBranches] = apiContext.URLBuilder.Link(linkBranches, resource)
 }
 
+// Validator checks the schedules of the cron triggers of a pipeline
+func Validator(request *types.APIContext, schema *types.Schema, data map[string]interface{}) error {
+	for _, trigger := range convert.ToMapSlice(data[client.PipelineFieldCronTriggers]) {
+		schedule := convert.ToString(trigger[client.CronTriggerFieldSchedule])
+		if _, err := cron.ParseStandard(schedule); err != nil {
+			return httperror.NewFieldAPIError(httperror.InvalidFormat, client.CronTriggerFieldSchedule, fmt.Sprintf("error parsing cron schedule %q: %v", schedule, err))
+		}
+	}
+	return nil
+}
+
 func (h *Handler) LinkHandler(apiContext *types.APIContext, next types.RequestHandler) error {
 	if apiContext.Link == linkYaml {
 		if apiContext.Method == http.MethodPut {
@@ -396,6 +409,9 @@ func (h *Handler) updatePipelineConfigYaml(apiContext *types.APIContext) error {
 	if err := yaml.Unmarshal(content, config); err != nil {
 		return err
 	}
+	if err := utils.ValidPipelineConfig(*utils.ConfigWithCloneStage(config)); err != nil {
+		return httperror.NewAPIError(httperror.InvalidBodyContent, err.Error())
+	}
 
 	//use current user's auth to do the push
 	userName := apiContext.Request.Header.Get("Impersonate-User")
@@ -96,6 +96,12 @@ func (h *ExecutionHandler) handleLog(apiContext *types.APIContext) error {
 			c.WriteControl(websocket.CloseMessage, []byte{}, time.Now().Add(writeWait))
 			return nil
 		}
+		//the log is masked as a whole by the engine, a secret still being written is held back with its line until the
+		//line is complete so that it is not sent before it can be masked
+		ended := execution.Status.Stages[stage].Steps[step].Ended != ""
+		if !ended {
+			log = completeLines(log)
+		}
 		newLog := getNewLog(prevLog, log)
 		prevLog = log
 		if newLog != "" {
@@ -104,7 +110,7 @@ func (h *ExecutionHandler) handleLog(apiContext *types.APIContext) error {
 				return nil
 			}
 		}
-		if execution.Status.Stages[stage].Steps[step].Ended != "" {
+		if ended {
 			c.WriteControl(websocket.CloseMessage, []byte{}, time.Now().Add(writeWait))
 			return nil
 		}
@@ -127,6 +133,11 @@ func writeData(c *websocket.Conn, buf []byte) error {
 	return nil
 }
 
+// completeLines drops the trailing line of a log that is not terminated yet
+func completeLines(log string) string {
+	return log[:strings.LastIndex(log, "\n")+1]
+}
+
 func getNewLog(prevLog string, currLog string) string {
 	if len(prevLog) < longLogThreshold {
 		return strings.TrimPrefix(currLog, prevLog)
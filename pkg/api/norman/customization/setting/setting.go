@@ -13,6 +13,7 @@ import (
 	"github.com/rancher/rancher/pkg/auth/providerrefresh"
 	"github.com/rancher/rancher/pkg/auth/tokens"
 	v3client "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/rancher/rancher/pkg/clusterrouter/ratelimits"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/settings"
 )
@@ -81,6 +82,10 @@ func Validator(request *types.APIContext, schema *types.Schema, data map[string]
 		_, err = providerrefresh.ParseMaxAge(newValueString)
 	case "auth-user-info-resync-cron":
 		_, err = providerrefresh.ParseCron(newValueString)
+	case "k8s-proxy-rate-limits":
+		_, err = ratelimits.Parse(newValueString)
+	case "kubeconfig-cluster-token-ttl-minutes":
+		_, err = tokens.ParseClusterTokenTTLs(newValueString)
 	case "kubeconfig-token-ttl-minutes":
 		generateToken := strings.EqualFold(settings.KubeconfigGenerateToken.Get(), "true")
 		if generateToken {
@@ -624,6 +624,7 @@ func Pipeline(schemas *types.Schemas, management *config.ScaledContext, clusterM
 	}
 	schema := schemas.Schema(&projectschema.Version, projectclient.PipelineType)
 	schema.Formatter = pipeline.Formatter
+	schema.Validator = pipeline.Validator
 	schema.ActionHandler = pipelineHandler.ActionHandler
 	schema.LinkHandler = pipelineHandler.LinkHandler
 
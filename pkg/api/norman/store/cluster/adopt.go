@@ -0,0 +1,62 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/values"
+	managementv3 "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/rancher/rancher/pkg/controllers/management/clusterprovisioner"
+	"github.com/rancher/rancher/pkg/controllers/management/rbac"
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/rancher/rancher/pkg/ref"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// getAdoptSecret returns the adopt secret named by a cluster being created, nil if the cluster is not adopted. The
+// secret must have been created by the user creating the cluster and not be claimed for another cluster yet.
+func (r *Store) getAdoptSecret(apiContext *types.APIContext, data map[string]interface{}) (*corev1.Secret, error) {
+	annotations, _ := values.GetValue(data, managementv3.ClusterFieldAnnotations)
+	secretRef := convert.ToString(convert.ToMapInterface(annotations)[clusterprovisioner.AdoptAnnotation])
+	if secretRef == "" {
+		return nil, nil
+	}
+
+	secretNamespace, name := ref.Parse(secretRef)
+	if secretNamespace != "" && secretNamespace != namespace.GlobalNamespace {
+		return nil, httperror.NewAPIError(httperror.InvalidBodyContent,
+			fmt.Sprintf("adopt secret %s must be in namespace %s", secretRef, namespace.GlobalNamespace))
+	}
+	secret, err := r.SecretLister.Get(namespace.GlobalNamespace, name)
+	if apierrors.IsNotFound(err) {
+		return nil, httperror.NewAPIError(httperror.InvalidBodyContent, fmt.Sprintf("adopt secret %s not found", secretRef))
+	} else if err != nil {
+		return nil, err
+	}
+
+	creatorID := apiContext.Request.Header.Get("Impersonate-User")
+	if creatorID == "" || secret.Annotations[rbac.CreatorIDAnn] != creatorID {
+		return nil, httperror.NewAPIError(httperror.PermissionDenied,
+			fmt.Sprintf("adopt secret %s was not created by %s", secretRef, creatorID))
+	}
+	if len(secret.OwnerReferences) > 0 {
+		return nil, httperror.NewAPIError(httperror.InvalidBodyContent, fmt.Sprintf("adopt secret %s is already in use", secretRef))
+	}
+	return secret, nil
+}
+
+// claimAdoptSecret makes the created cluster the owner of its adopt secret, only claimed secrets are adopted by the
+// provisioner and the secret is removed with the cluster
+func (r *Store) claimAdoptSecret(secret *corev1.Secret, cluster map[string]interface{}) error {
+	secret = secret.DeepCopy()
+	secret.OwnerReferences = append(secret.OwnerReferences, clusterprovisioner.AdoptSecretOwnerReference(
+		convert.ToString(cluster["id"]), k8stypes.UID(convert.ToString(cluster["uuid"]))))
+	if _, err := r.Secrets.Update(secret); err != nil {
+		return fmt.Errorf("failed to claim adopt secret %s: %v", ref.Ref(secret), err)
+	}
+	return nil
+}
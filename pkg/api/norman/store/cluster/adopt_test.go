@@ -0,0 +1,134 @@
+package cluster
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher/norman/types"
+	"github.com/rancher/rancher/pkg/controllers/management/clusterprovisioner"
+	"github.com/rancher/rancher/pkg/controllers/management/rbac"
+	"github.com/rancher/rancher/pkg/generated/norman/core/v1/fakes"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGetAdoptSecret(t *testing.T) {
+	secrets := map[string]*corev1.Secret{
+		"archive": {
+			ObjectMeta: metav1.ObjectMeta{Name: "archive", Namespace: "cattle-global-data", Annotations: map[string]string{rbac.CreatorIDAnn: "u-1"}},
+		},
+		"claimed": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "claimed",
+				Namespace:       "cattle-global-data",
+				Annotations:     map[string]string{rbac.CreatorIDAnn: "u-1"},
+				OwnerReferences: []metav1.OwnerReference{clusterprovisioner.AdoptSecretOwnerReference("c-1", "uid-1")},
+			},
+		},
+	}
+	r := &Store{
+		SecretLister: &fakes.SecretListerMock{
+			GetFunc: func(namespace string, name string) (*corev1.Secret, error) {
+				if secret, ok := secrets[name]; ok && namespace == "cattle-global-data" {
+					return secret, nil
+				}
+				return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		user      string
+		secretRef string
+		secret    string
+		errored   bool
+	}{
+		{
+			name: "not adopted",
+			user: "u-1",
+		},
+		{
+			name:      "created by the user",
+			user:      "u-1",
+			secretRef: "archive",
+			secret:    "archive",
+		},
+		{
+			name:      "created by the user in the global data namespace",
+			user:      "u-1",
+			secretRef: "cattle-global-data:archive",
+			secret:    "archive",
+		},
+		{
+			name:      "created by another user",
+			user:      "u-2",
+			secretRef: "archive",
+			errored:   true,
+		},
+		{
+			name:      "other namespace",
+			user:      "u-1",
+			secretRef: "p-1:archive",
+			errored:   true,
+		},
+		{
+			name:      "not found",
+			user:      "u-1",
+			secretRef: "missing",
+			errored:   true,
+		},
+		{
+			name:      "claimed by another cluster",
+			user:      "u-1",
+			secretRef: "claimed",
+			errored:   true,
+		},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/v3/clusters", nil)
+		req.Header.Set("Impersonate-User", tt.user)
+		data := map[string]interface{}{}
+		if tt.secretRef != "" {
+			data["annotations"] = map[string]interface{}{clusterprovisioner.AdoptAnnotation: tt.secretRef}
+		}
+
+		secret, err := r.getAdoptSecret(&types.APIContext{Request: req}, data)
+		if tt.errored {
+			assert.NotNil(t, err, tt.name)
+			continue
+		}
+		assert.Nil(t, err, tt.name)
+		if tt.secret == "" {
+			assert.Nil(t, secret, tt.name)
+		} else if assert.NotNil(t, secret, tt.name) {
+			assert.Equal(t, tt.secret, secret.Name, tt.name)
+		}
+	}
+}
+
+func TestClaimAdoptSecret(t *testing.T) {
+	var updated *corev1.Secret
+	r := &Store{
+		Secrets: &fakes.SecretInterfaceMock{
+			UpdateFunc: func(in1 *corev1.Secret) (*corev1.Secret, error) {
+				updated = in1
+				return in1, nil
+			},
+		},
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "archive", Namespace: "cattle-global-data"}}
+
+	assert.Nil(t, r.claimAdoptSecret(secret, map[string]interface{}{"id": "c-1", "uuid": "uid-1"}))
+	assert.Empty(t, secret.OwnerReferences, "the cached secret is not modified")
+	if assert.NotNil(t, updated) {
+		cluster := &v3.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c-1", UID: "uid-1"}}
+		assert.True(t, clusterprovisioner.IsAdoptSecretOf(updated, cluster))
+		cluster.UID = "uid-2"
+		assert.False(t, clusterprovisioner.IsAdoptSecretOf(updated, cluster))
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/rancher/rancher/pkg/controllers/management/clusterstatus"
 	"github.com/rancher/rancher/pkg/controllers/management/etcdbackup"
 	"github.com/rancher/rancher/pkg/controllers/management/rkeworkerupgrader"
+	corev1 "github.com/rancher/rancher/pkg/generated/norman/core/v1"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/namespace"
 	nodehelper "github.com/rancher/rancher/pkg/node"
@@ -61,6 +62,8 @@ type Store struct {
 	ClusterTemplateRevisionLister v3.ClusterTemplateRevisionLister
 	NodeLister                    v3.NodeLister
 	ClusterLister                 v3.ClusterLister
+	SecretLister                  corev1.SecretLister
+	Secrets                       corev1.SecretInterface
 	DialerFactory                 dialer.Factory
 }
 
@@ -133,6 +136,8 @@ func GetClusterStore(schema *types.Schema, mgmt *config.ScaledContext, clusterMa
 		ClusterTemplateRevisionLister: mgmt.Management.ClusterTemplateRevisions("").Controller().Lister(),
 		ClusterLister:                 mgmt.Management.Clusters("").Controller().Lister(),
 		NodeLister:                    mgmt.Management.Nodes("").Controller().Lister(),
+		SecretLister:                  mgmt.Core.Secrets("").Controller().Lister(),
+		Secrets:                       mgmt.Core.Secrets(""),
 		DialerFactory:                 mgmt.Dialer,
 	}
 	schema.Store = s
@@ -239,7 +244,16 @@ func (r *Store) Create(apiContext *types.APIContext, schema *types.Schema, data
 		return nil, err
 	}
 
-	return r.Store.Create(apiContext, schema, data)
+	adoptSecret, err := r.getAdoptSecret(apiContext, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Store.Create(apiContext, schema, data)
+	if err != nil || adoptSecret == nil {
+		return result, err
+	}
+	return result, r.claimAdoptSecret(adoptSecret, result)
 }
 
 func transposeNameFields(data map[string]interface{}, clusterConfigSchema *types.Schema) map[string]interface{} {
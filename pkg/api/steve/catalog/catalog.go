@@ -36,7 +36,11 @@ func addSchemas(server *steve.Server, ops *operation, index http.Handler) {
 	server.BaseSchemas.MustImportAndCustomize(types2.ChartUpgrade{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(types2.ChartInstallAction{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(types2.ChartInstall{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(types2.ChartRollbackAction{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(types2.ChartActionOutput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(types2.ChartValuesError{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(types2.ChartValidationOutput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(types2.ChartHistory{}, nil)
 
 	operationTemplate := schema2.Template{
 		Group: catalog.GroupName,
@@ -58,12 +62,20 @@ func addSchemas(server *steve.Server, ops *operation, index http.Handler) {
 		Customize: func(apiSchema *types.APISchema) {
 			apiSchema.ActionHandlers = map[string]http.Handler{
 				"uninstall": ops,
+				"rollback":  ops,
 			}
 			apiSchema.ResourceActions = map[string]schemas3.Action{
 				"uninstall": {
 					Input:  "chartUninstallAction",
 					Output: "chartActionOutput",
 				},
+				"rollback": {
+					Input:  "chartRollbackAction",
+					Output: "chartActionOutput",
+				},
+			}
+			apiSchema.LinkHandlers = map[string]http.Handler{
+				"history": ops,
 			}
 		},
 	}
@@ -72,8 +84,9 @@ func addSchemas(server *steve.Server, ops *operation, index http.Handler) {
 		Kind:  "Repo",
 		Customize: func(apiSchema *types.APISchema) {
 			apiSchema.ActionHandlers = map[string]http.Handler{
-				"install": ops,
-				"upgrade": ops,
+				"install":  ops,
+				"upgrade":  ops,
+				"validate": ops,
 			}
 			apiSchema.ResourceActions = map[string]schemas3.Action{
 				"install": {
@@ -84,6 +97,10 @@ func addSchemas(server *steve.Server, ops *operation, index http.Handler) {
 					Input:  "chartUpgradeAction",
 					Output: "chartActionOutput",
 				},
+				"validate": {
+					Input:  "chartInstallAction",
+					Output: "chartValidationOutput",
+				},
 			}
 			apiSchema.LinkHandlers = map[string]http.Handler{
 				"index": index,
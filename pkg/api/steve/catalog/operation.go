@@ -1,6 +1,7 @@
 package catalog
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/rancher/apiserver/pkg/types"
@@ -53,12 +54,18 @@ func (o *operation) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		op, err = o.ops.Upgrade(apiRequest.Context(), user, ns, name, req.Body, o.imageOverride)
 	case "uninstall":
 		op, err = o.ops.Uninstall(apiRequest.Context(), user, ns, name, req.Body, o.imageOverride)
+	case "rollback":
+		op, err = o.ops.Rollback(apiRequest.Context(), user, ns, name, req.Body, o.imageOverride)
+	case "validate":
+		err = o.serveValidate(apiRequest, ns, name, req.Body)
 	}
 
 	switch apiRequest.Link {
 	case "logs":
 		err = o.ops.Log(apiRequest.Response, apiRequest.Request,
 			apiRequest.Namespace, apiRequest.Name)
+	case "history":
+		err = o.serveHistory(apiRequest)
 	}
 
 	if err != nil {
@@ -79,6 +86,32 @@ func (o *operation) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	})
 }
 
+func (o *operation) serveHistory(apiRequest *types.APIRequest) error {
+	history, err := o.ops.History(apiRequest.Context(), apiRequest.Namespace, apiRequest.Name)
+	if err != nil {
+		return err
+	}
+
+	apiRequest.WriteResponse(http.StatusOK, types.APIObject{
+		Type:   "chartHistory",
+		Object: history,
+	})
+	return nil
+}
+
+func (o *operation) serveValidate(apiRequest *types.APIRequest, namespace, name string, body io.Reader) error {
+	output, err := o.ops.Validate(namespace, name, body)
+	if err != nil {
+		return err
+	}
+
+	apiRequest.WriteResponse(http.StatusOK, types.APIObject{
+		Type:   "chartValidationOutput",
+		Object: output,
+	})
+	return nil
+}
+
 func (o *operation) OnAdd(gvk schema2.GroupVersionKind, key string, obj runtime.Object) error {
 	return o.ops.Impersonator.PurgeOldRoles(gvk, key, obj)
 }
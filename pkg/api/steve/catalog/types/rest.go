@@ -30,6 +30,7 @@ type ChartInfo struct {
 	APPReadme string                `json:"appReadme,omitempty"`
 	Values    v3.MapStringInterface `json:"values,omitempty"`
 	Questions v3.MapStringInterface `json:"questions,omitempty"`
+	Schema    v3.MapStringInterface `json:"schema,omitempty"`
 	Chart     v3.MapStringInterface `json:"chart,omitempty"`
 }
 
@@ -65,7 +66,47 @@ type ChartUpgrade struct {
 	Annotations map[string]string     `json:"annotations,omitempty"`
 }
 
+type ChartValuesError struct {
+	ChartName string `json:"chartName,omitempty"`
+	Field     string `json:"field,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+type ChartValidationOutput struct {
+	Valid  bool               `json:"valid"`
+	Errors []ChartValuesError `json:"errors,omitempty"`
+}
+
 type ChartActionOutput struct {
 	OperationName      string `json:"operationName,omitempty"`
 	OperationNamespace string `json:"operationNamespace,omitempty"`
 }
+
+type ChartRollbackAction struct {
+	Revision      int              `json:"revision,omitempty"`
+	Timeout       *metav1.Duration `json:"timeout,omitempty"`
+	Wait          bool             `json:"wait,omitempty"`
+	DisableHooks  bool             `json:"noHooks,omitempty"`
+	Force         bool             `json:"force,omitempty"`
+	Recreate      bool             `json:"recreatePods,omitempty"`
+	CleanupOnFail bool             `json:"cleanupOnFail,omitempty"`
+	MaxHistory    int              `json:"historyMax,omitempty"`
+}
+
+type ChartRevision struct {
+	Revision     int          `json:"revision,omitempty"`
+	ChartName    string       `json:"chartName,omitempty"`
+	ChartVersion string       `json:"chartVersion,omitempty"`
+	AppVersion   string       `json:"appVersion,omitempty"`
+	Status       string       `json:"status,omitempty"`
+	Description  string       `json:"description,omitempty"`
+	Updated      *metav1.Time `json:"updated,omitempty"`
+	ValuesDigest string       `json:"valuesDigest,omitempty"`
+}
+
+type ChartHistory struct {
+	ReleaseName      string          `json:"releaseName,omitempty"`
+	ReleaseNamespace string          `json:"releaseNamespace,omitempty"`
+	Revisions        []ChartRevision `json:"revisions,omitempty"`
+}
@@ -7,16 +7,20 @@ import (
 	"net/http"
 
 	"github.com/pborman/uuid"
+	"github.com/rancher/apiserver/pkg/apierror"
 	"github.com/rancher/apiserver/pkg/types"
 	"github.com/rancher/steve/pkg/attributes"
 	steveschema "github.com/rancher/steve/pkg/schema"
 	"github.com/rancher/steve/pkg/stores/proxy"
 	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
 	"github.com/rancher/wrangler/pkg/yaml"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
 )
 
 type Apply struct {
@@ -35,13 +39,32 @@ func (a *Apply) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if input.Prune && len(input.PruneLabels) == 0 {
+		apiContext.WriteError(apierror.NewAPIError(validation.InvalidBodyContent, "pruneLabels are required to prune"))
+		return
+	}
+
 	objs, err := yaml.ToObjects(bytes.NewBufferString(input.YAML))
 	if err != nil {
 		apiContext.WriteError(err)
 		return
 	}
 
-	apply, err := a.createApply(apiContext)
+	var resolved []appliedObject
+	if input.DryRun || input.Prune {
+		resolved, err = a.resolveObjects(apiContext, objs, input.DefaultNamespace)
+		if err != nil {
+			apiContext.WriteError(err)
+			return
+		}
+	}
+
+	if input.DryRun {
+		a.dryRun(apiContext, resolved, input)
+		return
+	}
+
+	apply, err := a.createApply(apiContext, a.clientFactory(apiContext))
 	if err != nil {
 		apiContext.WriteError(err)
 		return
@@ -52,6 +75,13 @@ func (a *Apply) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if input.Prune {
+		if err := a.prune(apiContext, resolved, input.PruneLabels, false); err != nil {
+			apiContext.WriteError(err)
+			return
+		}
+	}
+
 	var result types.APIObjectList
 
 	for _, obj := range objs {
@@ -61,6 +91,68 @@ func (a *Apply) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	apiContext.WriteResponseList(http.StatusOK, result)
 }
 
+// dryRun writes the changes the apply would make, including the objects it would prune
+func (a *Apply) dryRun(apiContext *types.APIRequest, objs []appliedObject, input ApplyInput) {
+	desiredSet, err := a.createApply(apiContext, takeoverClientFactory(a.clientFactory(apiContext), objs))
+	if err != nil {
+		apiContext.WriteError(err)
+		return
+	}
+
+	dynamicClient, err := a.cg.DynamicClient(apiContext)
+	if err != nil {
+		apiContext.WriteError(err)
+		return
+	}
+
+	results, err := dryRun(apiContext.Context(), desiredSet.WithDefaultNamespace(input.DefaultNamespace), dynamicClient, objs)
+	if err != nil {
+		apiContext.WriteError(err)
+		return
+	}
+	if input.Prune {
+		pruned, err := prune(apiContext.Context(), dynamicClient, objs, input.PruneLabels, true)
+		if err != nil {
+			apiContext.WriteError(err)
+			return
+		}
+		results = append(results, pruned...)
+	}
+
+	var result types.APIObjectList
+	for _, r := range results {
+		id := r.Name
+		if r.Namespace != "" {
+			id = r.Namespace + "/" + id
+		}
+		result.Objects = append(result.Objects, types.APIObject{
+			Type:   "applyResult",
+			ID:     id,
+			Object: r,
+		})
+	}
+
+	apiContext.WriteResponseList(http.StatusOK, result)
+}
+
+func (a *Apply) prune(apiContext *types.APIRequest, objs []appliedObject, pruneLabels map[string]string, dryRun bool) error {
+	dynamicClient, err := a.cg.DynamicClient(apiContext)
+	if err != nil {
+		return err
+	}
+	_, err = prune(apiContext.Context(), dynamicClient, objs, pruneLabels, dryRun)
+	return err
+}
+
+func (a *Apply) resolveObjects(apiContext *types.APIRequest, objs []runtime.Object, defaultNamespace string) ([]appliedObject, error) {
+	client, err := a.cg.K8sInterface(apiContext)
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client.Discovery()))
+	return resolveObjects(mapper, objs, defaultNamespace)
+}
+
 func (a *Apply) toAPIObject(apiContext *types.APIRequest, obj runtime.Object, defaultNamespace string) types.APIObject {
 	if defaultNamespace == "" {
 		defaultNamespace = "default"
@@ -103,19 +195,23 @@ func (a *Apply) toAPIObject(apiContext *types.APIRequest, obj runtime.Object, de
 	return result
 }
 
-func (a *Apply) createApply(apiContext *types.APIRequest) (apply.Apply, error) {
-	client, err := a.cg.K8sInterface(apiContext)
-	if err != nil {
-		return nil, err
-	}
-
-	apply := apply.New(client.Discovery(), func(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+func (a *Apply) clientFactory(apiContext *types.APIRequest) apply.ClientFactory {
+	return func(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
 		dynamicClient, err := a.cg.DynamicClient(apiContext)
 		if err != nil {
 			return nil, err
 		}
 		return dynamicClient.Resource(gvr), nil
-	})
+	}
+}
+
+func (a *Apply) createApply(apiContext *types.APIRequest, clientFactory apply.ClientFactory) (apply.Apply, error) {
+	client, err := a.cg.K8sInterface(apiContext)
+	if err != nil {
+		return nil, err
+	}
+
+	apply := apply.New(client.Discovery(), clientFactory)
 
 	return apply.
 		WithDynamicLookup().
@@ -36,6 +36,7 @@ func Register(ctx context.Context, server *steve.Server) error {
 	})
 	server.BaseSchemas.MustImportAndCustomize(&ApplyInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(&ApplyOutput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(&ApplyResult{}, nil)
 
 	server.SchemaFactory.AddTemplate(schema2.Template{
 		Group: "management.cattle.io",
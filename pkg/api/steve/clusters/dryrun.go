@@ -0,0 +1,368 @@
+package clusters
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/objectset"
+	"github.com/rancher/wrangler/pkg/patch"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	ApplyStatusCreate    = "create"
+	ApplyStatusUpdate    = "update"
+	ApplyStatusUnchanged = "unchanged"
+	ApplyStatusDelete    = "delete"
+)
+
+// ignoredDiffFields are maintained by the server and are not compared
+var ignoredDiffFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"status"},
+}
+
+// appliedObject is an object of the YAML with the resource it is applied to
+type appliedObject struct {
+	obj      *unstructured.Unstructured
+	resource schema.GroupVersionResource
+}
+
+func (o appliedObject) key() string {
+	return o.resource.String() + "/" + o.obj.GetNamespace() + "/" + o.obj.GetName()
+}
+
+func (o appliedObject) client(dynamicClient dynamic.Interface) dynamic.ResourceInterface {
+	if o.obj.GetNamespace() == "" {
+		return dynamicClient.Resource(o.resource)
+	}
+	return dynamicClient.Resource(o.resource).Namespace(o.obj.GetNamespace())
+}
+
+func (o appliedObject) result(status string, diff []FieldDiff) ApplyResult {
+	return ApplyResult{
+		APIVersion: o.obj.GetAPIVersion(),
+		Kind:       o.obj.GetKind(),
+		Namespace:  o.obj.GetNamespace(),
+		Name:       o.obj.GetName(),
+		Status:     status,
+		Diff:       diff,
+	}
+}
+
+// resolveObjects maps the objects of the YAML to their resources and sets the default namespace of the namespaced ones
+func resolveObjects(mapper meta.RESTMapper, objs []runtime.Object, defaultNamespace string) ([]appliedObject, error) {
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+
+	var result []appliedObject
+	for _, obj := range objs {
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		u := &unstructured.Unstructured{Object: data}
+		gvk := u.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if u.GetNamespace() == "" {
+				u.SetNamespace(defaultNamespace)
+			}
+		} else {
+			u.SetNamespace("")
+		}
+		result = append(result, appliedObject{
+			obj:      u,
+			resource: mapping.Resource,
+		})
+	}
+	return result, nil
+}
+
+// dryRun returns the changes the apply would make to the objects. The plan of a dry run of the apply decides which
+// objects it creates or patches, their creation or patch is then run as a server-side dry run so that the cluster
+// validates and defaults them. The desired set must list the existing objects of the YAML, see takeoverClientFactory.
+func dryRun(ctx context.Context, desiredSet apply.Apply, dynamicClient dynamic.Interface, objs []appliedObject) ([]ApplyResult, error) {
+	var runtimeObjs []runtime.Object
+	for _, o := range objs {
+		runtimeObjs = append(runtimeObjs, o.obj)
+	}
+
+	plan, err := desiredSet.DryRun(runtimeObjs...)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]*unstructured.Unstructured{}
+	for _, obj := range plan.Objects {
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		u := &unstructured.Unstructured{Object: data}
+		existing[objectKey(u)] = u
+	}
+
+	var results []ApplyResult
+	for _, o := range objs {
+		gvk := o.obj.GroupVersionKind()
+		key := objectset.ObjectKey{
+			Namespace: o.obj.GetNamespace(),
+			Name:      o.obj.GetName(),
+		}
+		client := o.client(dynamicClient)
+
+		if containsKey(plan.Create[gvk], key) {
+			if _, err := client.Create(ctx, o.obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+				return nil, err
+			}
+			results = append(results, o.result(ApplyStatusCreate, nil))
+			continue
+		}
+
+		oldObj, ok := existing[objectKey(o.obj)]
+		patchData, patched := plan.Update[gvk][key]
+		if !ok || !patched {
+			results = append(results, o.result(ApplyStatusUnchanged, nil))
+			continue
+		}
+
+		patchType, _, err := patch.GetMergeStyle(gvk)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := client.Patch(ctx, o.obj.GetName(), patchType, []byte(patchData), metav1.PatchOptions{
+			DryRun: []string{metav1.DryRunAll},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		oldData, newData := runtime.DeepCopyJSON(oldObj.Object), runtime.DeepCopyJSON(updated.Object)
+		removeSetMetadata(oldData)
+		removeSetMetadata(newData)
+		if diff := diffObjects(oldData, newData); len(diff) == 0 {
+			results = append(results, o.result(ApplyStatusUnchanged, nil))
+		} else {
+			results = append(results, o.result(ApplyStatusUpdate, diff))
+		}
+	}
+	return results, nil
+}
+
+// removeSetMetadata removes the labels and annotations recording the apply set of an object, a new set is used on
+// every apply so they always change
+func removeSetMetadata(obj map[string]interface{}) {
+	for _, field := range []string{"labels", "annotations"} {
+		values, ok, _ := unstructured.NestedMap(obj, "metadata", field)
+		if !ok {
+			continue
+		}
+		for key := range values {
+			if strings.HasPrefix(key, apply.LabelPrefix) {
+				delete(values, key)
+			}
+		}
+		if len(values) == 0 {
+			unstructured.RemoveNestedField(obj, "metadata", field)
+		} else {
+			unstructured.SetNestedMap(obj, values, "metadata", field)
+		}
+	}
+}
+
+// takeoverClientFactory returns clients listing the existing objects of the YAML as the objects of the apply set. The
+// set of an apply is new, it takes over these objects when their creation fails, its dry run has to compare them
+// instead of planning their creation.
+func takeoverClientFactory(clientFactory apply.ClientFactory, objs []appliedObject) apply.ClientFactory {
+	return func(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+		client, err := clientFactory(gvr)
+		if err != nil {
+			return nil, err
+		}
+		return &takeoverClient{
+			NamespaceableResourceInterface: client,
+			resource:                       gvr,
+			objs:                           objs,
+		}, nil
+	}
+}
+
+type takeoverClient struct {
+	dynamic.NamespaceableResourceInterface
+	resource schema.GroupVersionResource
+	objs     []appliedObject
+}
+
+// List lists the namespaces of the applied objects of the resource, once each, and returns the applied ones
+func (c *takeoverClient) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	names := map[string]map[string]bool{}
+	for _, o := range c.objs {
+		if o.resource != c.resource || o.obj.GetName() == "" {
+			continue
+		}
+		if names[o.obj.GetNamespace()] == nil {
+			names[o.obj.GetNamespace()] = map[string]bool{}
+		}
+		names[o.obj.GetNamespace()][o.obj.GetName()] = true
+	}
+
+	result := &unstructured.UnstructuredList{}
+	for namespace, applied := range names {
+		var client dynamic.ResourceInterface = c.NamespaceableResourceInterface
+		if namespace != "" {
+			client = c.Namespace(namespace)
+		}
+		list, err := client.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			if applied[item.GetName()] {
+				result.Items = append(result.Items, item)
+			}
+		}
+	}
+	return result, nil
+}
+
+func containsKey(keys []objectset.ObjectKey, key objectset.ObjectKey) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func objectKey(obj *unstructured.Unstructured) string {
+	return obj.GroupVersionKind().String() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// prune deletes the objects matching the prune labels which are not applied, in the resources and namespaces of the
+// applied objects
+func prune(ctx context.Context, dynamicClient dynamic.Interface, objs []appliedObject, pruneLabels map[string]string, dryRun bool) ([]ApplyResult, error) {
+	applied := map[string]bool{}
+	scopes := map[string]appliedObject{}
+	for _, o := range objs {
+		applied[o.key()] = true
+		scopes[o.resource.String()+"/"+o.obj.GetNamespace()] = o
+	}
+
+	var scopeKeys []string
+	for key := range scopes {
+		scopeKeys = append(scopeKeys, key)
+	}
+	sort.Strings(scopeKeys)
+
+	selector := labels.SelectorFromSet(pruneLabels).String()
+	deleteOptions := metav1.DeleteOptions{}
+	if dryRun {
+		deleteOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	var results []ApplyResult
+	for _, key := range scopeKeys {
+		scope := scopes[key]
+		client := scope.client(dynamicClient)
+		list, err := client.List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range list.Items {
+			o := appliedObject{
+				obj:      &list.Items[i],
+				resource: scope.resource,
+			}
+			if applied[o.key()] || o.obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			if err := client.Delete(ctx, o.obj.GetName(), deleteOptions); err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			results = append(results, o.result(ApplyStatusDelete, nil))
+		}
+	}
+	return results, nil
+}
+
+// diffObjects returns the fields that differ between two objects, ignoring the fields maintained by the server
+func diffObjects(oldObj, newObj map[string]interface{}) []FieldDiff {
+	oldObj, newObj = runtime.DeepCopyJSON(oldObj), runtime.DeepCopyJSON(newObj)
+	for _, fields := range ignoredDiffFields {
+		unstructured.RemoveNestedField(oldObj, fields...)
+		unstructured.RemoveNestedField(newObj, fields...)
+	}
+
+	var diff []FieldDiff
+	diffValues("", oldObj, newObj, &diff)
+	return diff
+}
+
+func diffValues(path string, oldValue, newValue interface{}, diff *[]FieldDiff) {
+	switch oldTyped := oldValue.(type) {
+	case map[string]interface{}:
+		if newTyped, ok := newValue.(map[string]interface{}); ok {
+			diffMaps(path, oldTyped, newTyped, diff)
+			return
+		}
+	case []interface{}:
+		if newTyped, ok := newValue.([]interface{}); ok && len(oldTyped) == len(newTyped) {
+			for i := range oldTyped {
+				diffValues(fmt.Sprintf("%s[%d]", path, i), oldTyped[i], newTyped[i], diff)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*diff = append(*diff, FieldDiff{
+			Path: path,
+			Old:  oldValue,
+			New:  newValue,
+		})
+	}
+}
+
+func diffMaps(path string, oldMap, newMap map[string]interface{}, diff *[]FieldDiff) {
+	keys := map[string]bool{}
+	for key := range oldMap {
+		keys[key] = true
+	}
+	for key := range newMap {
+		keys[key] = true
+	}
+
+	var sorted []string
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		diffValues(fieldPath, oldMap[key], newMap[key], diff)
+	}
+}
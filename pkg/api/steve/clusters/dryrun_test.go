@@ -0,0 +1,186 @@
+package clusters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/patch"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var configMapGVK = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+func configMap(namespace, name string, labels map[string]string, data map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"data": data,
+	}}
+	u.SetGroupVersionKind(configMapGVK)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetLabels(labels)
+	return u
+}
+
+func TestDiffObjects(t *testing.T) {
+	oldObj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "a",
+			"resourceVersion": "1",
+			"labels":          map[string]interface{}{"app": "a"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"ports":    []interface{}{map[string]interface{}{"port": int64(80)}},
+		},
+		"status": map[string]interface{}{"ready": true},
+	}
+	newObj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "a",
+			"resourceVersion": "2",
+			"labels":          map[string]interface{}{"app": "a", "tier": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"ports":    []interface{}{map[string]interface{}{"port": int64(8080)}},
+		},
+	}
+
+	assert.Equal(t, []FieldDiff{
+		{Path: "metadata.labels.tier", New: "web"},
+		{Path: "spec.ports[0].port", Old: int64(80), New: int64(8080)},
+		{Path: "spec.replicas", Old: int64(1), New: int64(2)},
+	}, diffObjects(oldObj, newObj))
+	assert.Empty(t, diffObjects(oldObj, oldObj))
+}
+
+func TestResolveObjects(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(configMapGVK, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+
+	ns := &unstructured.Unstructured{}
+	ns.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"})
+	ns.SetName("ns1")
+	ns.SetNamespace("bogus")
+
+	objs, err := resolveObjects(mapper, []runtime.Object{configMap("", "a", nil, nil), ns}, "ns1")
+	assert.Nil(t, err)
+	assert.Equal(t, "ns1", objs[0].obj.GetNamespace())
+	assert.Equal(t, "configmaps", objs[0].resource.Resource)
+	assert.Equal(t, "", objs[1].obj.GetNamespace())
+
+	objs, err = resolveObjects(mapper, []runtime.Object{configMap("", "a", nil, nil)}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "default", objs[0].obj.GetNamespace())
+}
+
+func TestPrune(t *testing.T) {
+	pruneLabels := map[string]string{"app": "a"}
+	resource := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{resource: "ConfigMapList"},
+		configMap("ns1", "applied", pruneLabels, nil),
+		configMap("ns1", "stale", pruneLabels, nil),
+		configMap("ns1", "unlabeled", nil, nil),
+		configMap("ns2", "other-namespace", pruneLabels, nil),
+	)
+	objs := []appliedObject{{
+		obj:      configMap("ns1", "applied", pruneLabels, nil),
+		resource: resource,
+	}}
+
+	results, err := prune(context.Background(), client, objs, pruneLabels, false)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "stale", results[0].Name)
+	assert.Equal(t, ApplyStatusDelete, results[0].Status)
+
+	list, err := client.Resource(resource).Namespace("").List(context.Background(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	assert.ElementsMatch(t, []string{"applied", "unlabeled", "other-namespace"}, names)
+}
+
+func TestDryRun(t *testing.T) {
+	resource := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	stored := map[string]*unstructured.Unstructured{
+		"same":    configMap("ns1", "same", nil, map[string]interface{}{"a": "1"}),
+		"changed": configMap("ns1", "changed", nil, map[string]interface{}{"a": "1"}),
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{resource: "ConfigMapList"},
+		stored["same"], stored["changed"],
+	)
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+	}}}}
+
+	var objs []appliedObject
+	for _, obj := range []*unstructured.Unstructured{
+		configMap("ns1", "same", nil, map[string]interface{}{"a": "1"}),
+		configMap("ns1", "changed", nil, map[string]interface{}{"a": "2"}),
+		configMap("ns1", "new", nil, map[string]interface{}{"a": "1"}),
+	} {
+		objs = append(objs, appliedObject{obj: obj, resource: resource})
+	}
+
+	// the cluster creates and patches the objects in its dry run without storing them
+	var dryRuns []string
+	client.PrependReactor("create", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		dryRuns = append(dryRuns, "create")
+		return true, action.(k8stesting.CreateAction).GetObject(), nil
+	})
+	client.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(k8stesting.PatchAction)
+		dryRuns = append(dryRuns, "patch "+patchAction.GetName())
+		original, err := json.Marshal(stored[patchAction.GetName()])
+		if err != nil {
+			return true, nil, err
+		}
+		patched, err := patch.Apply(original, patchAction.GetPatch())
+		if err != nil {
+			return true, nil, err
+		}
+		updated := &unstructured.Unstructured{}
+		return true, updated, updated.UnmarshalJSON(patched)
+	})
+
+	clientFactory := takeoverClientFactory(func(gvr schema.GroupVersionResource) (dynamic.NamespaceableResourceInterface, error) {
+		return client.Resource(gvr), nil
+	}, objs)
+	desiredSet := apply.New(discovery, clientFactory).WithDynamicLookup().WithSetID("test")
+
+	results, err := dryRun(context.Background(), desiredSet, client, objs)
+	assert.Nil(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, ApplyStatusUnchanged, results[0].Status)
+	assert.Equal(t, ApplyStatusUpdate, results[1].Status)
+	assert.Equal(t, []FieldDiff{{Path: "data.a", Old: "1", New: "2"}}, results[1].Diff)
+	assert.Equal(t, ApplyStatusCreate, results[2].Status)
+	assert.Equal(t, []string{"patch same", "patch changed", "create"}, dryRuns)
+
+	// the errors of the server-side dry run fail the preview
+	client.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "changed", errors.New("denied by webhook"))
+	})
+	_, err = dryRun(context.Background(), desiredSet, client, objs)
+	assert.True(t, apierrors.IsForbidden(err))
+}
@@ -7,8 +7,30 @@ import (
 type ApplyInput struct {
 	DefaultNamespace string `json:"defaultNamespace,omitempty"`
 	YAML             string `json:"yaml,omitempty"`
+	// DryRun previews the changes of the apply with a server-side dry run instead of applying them
+	DryRun bool `json:"dryRun,omitempty"`
+	// Prune deletes the objects matching PruneLabels, of the kinds and namespaces of the YAML, which are not in the YAML
+	Prune       bool              `json:"prune,omitempty"`
+	PruneLabels map[string]string `json:"pruneLabels,omitempty"`
 }
 
 type ApplyOutput struct {
 	Resources []runtime.Object `json:"resources,omitempty"`
 }
+
+// ApplyResult is the change an apply makes to an object
+type ApplyResult struct {
+	APIVersion string      `json:"apiVersion,omitempty"`
+	Kind       string      `json:"kind,omitempty"`
+	Namespace  string      `json:"namespace,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	Status     string      `json:"status,omitempty"`
+	Diff       []FieldDiff `json:"diff,omitempty"`
+}
+
+// FieldDiff is the change of a field of an object, a missing old or new value means the field is added or removed
+type FieldDiff struct {
+	Path string      `json:"path,omitempty"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
@@ -8,11 +8,13 @@ import (
 	"time"
 
 	gmux "github.com/gorilla/mux"
+	"github.com/rancher/rancher/pkg/api/steve/shellrecording"
 	v3 "github.com/rancher/rancher/pkg/generated/controllers/management.cattle.io/v3"
 	managementv3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/settings"
 	"github.com/rancher/steve/pkg/auth"
 	"github.com/rancher/steve/pkg/proxy"
+	corecontrollers "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
 	authzv1 "k8s.io/api/authorization/v1"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
@@ -47,6 +49,8 @@ func NewProxyMiddleware(sar v1.SubjectAccessReviewInterface,
 	dialerFactory ClusterDialerFactory,
 	clusters v3.ClusterCache,
 	localSupport bool,
+	shellRecording bool,
+	secrets corecontrollers.SecretCache,
 	localCluster http.Handler) (func(http.Handler) http.Handler, error) {
 	cfg := authorizerfactory.DelegatingAuthorizerConfig{
 		SubjectAccessReviewClient: sar,
@@ -64,9 +68,16 @@ func NewProxyMiddleware(sar v1.SubjectAccessReviewInterface,
 
 	mux := gmux.NewRouter()
 	mux.UseEncodedPath()
-	mux.Path("/v1/management.cattle.io.clusters/{clusterID}").Queries("link", "shell").HandlerFunc(routeToShellProxy("link", "shell", localSupport, localCluster, mux, proxyHandler))
+	shell := routeToShellProxy("link", "shell", localSupport, localCluster, mux, proxyHandler)
+	if shellRecording {
+		recorder := shellrecording.New(authorizer, secrets)
+		recorder.RegisterRoutes(mux)
+		shell = recorder.Record(shell)
+	}
+
+	mux.Path("/v1/management.cattle.io.clusters/{clusterID}").Queries("link", "shell").HandlerFunc(shell)
 	mux.Path("/v1/management.cattle.io.clusters/{clusterID}").Queries("action", "apply").HandlerFunc(routeToShellProxy("action", "apply", localSupport, localCluster, mux, proxyHandler))
-	mux.Path("/v3/clusters/{clusterID}").Queries("shell", "true").HandlerFunc(routeToShellProxy("link", "shell", localSupport, localCluster, mux, proxyHandler))
+	mux.Path("/v3/clusters/{clusterID}").Queries("shell", "true").HandlerFunc(shell)
 	mux.Path("/{prefix:k8s/clusters/[^/]+}{suffix:/v1.*}").MatcherFunc(proxyHandler.MatchNonLegacy("/k8s/clusters/")).Handler(proxyHandler)
 
 	return func(handler http.Handler) http.Handler {
@@ -77,7 +88,7 @@ func NewProxyMiddleware(sar v1.SubjectAccessReviewInterface,
 	}, nil
 }
 
-func routeToShellProxy(key, value string, localSupport bool, localCluster http.Handler, mux *gmux.Router, proxyHandler *Handler) func(rw http.ResponseWriter, r *http.Request) {
+func routeToShellProxy(key, value string, localSupport bool, localCluster http.Handler, mux *gmux.Router, proxyHandler *Handler) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		vars := gmux.Vars(r)
 		cluster := vars["clusterID"]
@@ -0,0 +1,269 @@
+package shellrecording
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	gmux "github.com/gorilla/mux"
+	"github.com/rancher/rancher/pkg/auth/audit"
+	managementv3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/settings"
+	corecontrollers "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+const (
+	// RecordQueryParam opts a shell session in for recording
+	RecordQueryParam = "record"
+
+	castContentType = "application/x-asciicast"
+)
+
+// Recorder records the shell sessions of the clusters and serves the API listing and replaying the recordings. The
+// recordings are kept in the store selected by the shell-recording-store setting, which all the rancher servers share
+// so that each of them lists and replays the recordings of the others.
+type Recorder struct {
+	authorizer authorizer.Authorizer
+	store      func() (Store, error)
+}
+
+func New(authorizer authorizer.Authorizer, secrets corecontrollers.SecretCache) *Recorder {
+	return &Recorder{
+		authorizer: authorizer,
+		store: func() (Store, error) {
+			switch settings.ShellRecordingStore.Get() {
+			case "s3":
+				return NewS3Store(secrets)
+			case "", "file":
+				return NewFileStore(settings.ShellRecordingPath.Get()), nil
+			default:
+				return nil, fmt.Errorf("unknown shell recording store %q", settings.ShellRecordingStore.Get())
+			}
+		},
+	}
+}
+
+// Enforced returns whether the shell sessions of the cluster must be recorded
+func Enforced(clusterID string) bool {
+	for _, cluster := range strings.Split(settings.ShellRecordingClusters.Get(), ",") {
+		cluster = strings.TrimSpace(cluster)
+		if cluster == "*" || (cluster != "" && cluster == clusterID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record wraps the shell handler of a cluster to record the sessions that opt in or that are enforced for the
+// cluster. Sessions enforced to be recorded are refused when the recording can't be stored, and ended when it can no
+// longer be written.
+func (r *Recorder) Record(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		clusterID := gmux.Vars(req)["clusterID"]
+		enforced := Enforced(clusterID)
+
+		q := req.URL.Query()
+		record := enforced || q.Get(RecordQueryParam) == "true"
+		if _, ok := q[RecordQueryParam]; ok {
+			q.Del(RecordQueryParam)
+			req.URL.RawQuery = q.Encode()
+		}
+
+		u, ok := request.UserFrom(req.Context())
+		if !record || !ok || !httpstream.IsUpgradeRequest(req) {
+			next(rw, req)
+			return
+		}
+
+		recording := &Recording{
+			ID:      newID(),
+			Cluster: clusterID,
+			User:    u.GetName(),
+			Started: time.Now().UTC(),
+		}
+		if principals := u.GetExtra()[audit.ExtraPrincipalID]; len(principals) > 0 {
+			recording.Principal = principals[0]
+		}
+		if providers := u.GetExtra()[audit.ExtraAuthProvider]; len(providers) > 0 {
+			recording.AuthProvider = providers[0]
+		}
+
+		out, err := r.create(recording)
+		if err != nil {
+			logrus.Errorf("Failed to create shell session recording for cluster %s: %v", clusterID, err)
+			if enforced {
+				http.Error(rw, "shell sessions of cluster "+clusterID+" must be recorded but the recording failed", http.StatusInternalServerError)
+				return
+			}
+			next(rw, req)
+			return
+		}
+
+		s := newSession(out, fmt.Sprintf("%s on cluster %s", recording.User, clusterID), func() string {
+			if protocol := rw.Header().Get(protocolHeader); protocol != "" {
+				return protocol
+			}
+			return req.Header.Get(protocolHeader)
+		})
+		s.enforced = enforced
+		defer func() {
+			if err := s.Close(); err != nil {
+				logrus.Errorf("Failed to complete shell session recording %s: %v", recording.ID, err)
+			}
+		}()
+
+		next(&responseWriter{ResponseWriter: rw, session: s}, req)
+	}
+}
+
+func (r *Recorder) create(recording *Recording) (io.WriteCloser, error) {
+	store, err := r.store()
+	if err != nil {
+		return nil, err
+	}
+	return store.Create(recording)
+}
+
+// RegisterRoutes registers the API listing the recordings and replaying their asciicast
+func (r *Recorder) RegisterRoutes(mux *gmux.Router) {
+	mux.Path("/v1/shellrecordings").Methods(http.MethodGet).HandlerFunc(r.list)
+	mux.Path("/v1/shellrecordings/{id}").Methods(http.MethodGet).HandlerFunc(r.get)
+	mux.Path("/v1/shellrecordings/{id}/cast").Methods(http.MethodGet).HandlerFunc(r.replay)
+}
+
+func (r *Recorder) list(rw http.ResponseWriter, req *http.Request) {
+	u, ok := request.UserFrom(req.Context())
+	if !ok {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	store, err := r.store()
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	recordings, err := store.List()
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the recordings are listed for the clusters the user can list the recordings of
+	allowed := map[string]bool{}
+	cluster, userName := req.URL.Query().Get("cluster"), req.URL.Query().Get("user")
+	data := []*Recording{}
+	for _, recording := range recordings {
+		if (cluster != "" && recording.Cluster != cluster) || (userName != "" && recording.User != userName) {
+			continue
+		}
+		access, ok := allowed[recording.Cluster]
+		if !ok {
+			access = r.canAccess(req, u, "list", recording.Cluster, "")
+			allowed[recording.Cluster] = access
+		}
+		if access {
+			data = append(data, recording)
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(map[string]interface{}{
+		"type": "collection",
+		"data": data,
+	})
+}
+
+func (r *Recorder) get(rw http.ResponseWriter, req *http.Request) {
+	_, recording, ok := r.authorized(rw, req)
+	if !ok {
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(recording)
+}
+
+func (r *Recorder) replay(rw http.ResponseWriter, req *http.Request) {
+	store, recording, ok := r.authorized(rw, req)
+	if !ok {
+		return
+	}
+	cast, err := store.Open(recording.ID)
+	if err != nil {
+		storeError(rw, err)
+		return
+	}
+	defer cast.Close()
+
+	rw.Header().Set("Content-Type", castContentType)
+	io.Copy(rw, cast)
+}
+
+// authorized returns the recording of the request when the user can get the recordings of its cluster
+func (r *Recorder) authorized(rw http.ResponseWriter, req *http.Request) (Store, *Recording, bool) {
+	u, ok := request.UserFrom(req.Context())
+	if !ok {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	store, err := r.store()
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	recording, err := store.Get(gmux.Vars(req)["id"])
+	if err != nil {
+		storeError(rw, err)
+		return nil, nil, false
+	}
+
+	if !r.canAccess(req, u, "get", recording.Cluster, recording.ID) {
+		// the recordings of the clusters the user can't access are not disclosed
+		rw.WriteHeader(http.StatusNotFound)
+		return nil, nil, false
+	}
+	return store, recording, true
+}
+
+// canAccess checks the access to the recordings of a cluster, which are granted in the namespace of the cluster by
+// its role templates or in all the namespaces by the global roles
+func (r *Recorder) canAccess(req *http.Request, u user.Info, verb, clusterID, name string) bool {
+	resp, _, err := r.authorizer.Authorize(req.Context(), authorizer.AttributesRecord{
+		ResourceRequest: true,
+		User:            u,
+		Verb:            verb,
+		APIGroup:        managementv3.GroupName,
+		APIVersion:      managementv3.Version,
+		Resource:        "shellrecordings",
+		Namespace:       clusterID,
+		Name:            name,
+	})
+	return err == nil && resp == authorizer.DecisionAllow
+}
+
+func storeError(rw http.ResponseWriter, err error) {
+	if os.IsNotExist(err) {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	http.Error(rw, err.Error(), http.StatusBadRequest)
+}
+
+func newID() string {
+	random := make([]byte, 4)
+	rand.Read(random)
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(random)
+}
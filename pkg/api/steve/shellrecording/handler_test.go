@@ -0,0 +1,92 @@
+package shellrecording
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	gmux "github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// clusterAuthorizer allows the access to the shell recordings of the clusters in its list
+type clusterAuthorizer []string
+
+func (c clusterAuthorizer) Authorize(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+	if a.GetResource() != "shellrecordings" {
+		return authorizer.DecisionDeny, "", nil
+	}
+	for _, cluster := range c {
+		if a.GetNamespace() == cluster {
+			return authorizer.DecisionAllow, "", nil
+		}
+	}
+	return authorizer.DecisionDeny, "", nil
+}
+
+func TestRecordingAccess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shell-recordings-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := NewFileStore(dir)
+	for _, recording := range []*Recording{
+		{ID: "allowed", Cluster: "c-allowed", User: "u-1", Started: time.Now()},
+		{ID: "denied", Cluster: "c-denied", User: "u-1", Started: time.Now()},
+	} {
+		out, err := store.Create(recording)
+		assert.Nil(t, err)
+		_, err = out.Write([]byte("cast\n"))
+		assert.Nil(t, err)
+		assert.Nil(t, out.Close())
+	}
+
+	recorder := &Recorder{
+		authorizer: clusterAuthorizer{"c-allowed"},
+		store: func() (Store, error) {
+			return store, nil
+		},
+	}
+	mux := gmux.NewRouter()
+	recorder.RegisterRoutes(mux)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: "u-1"}))
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+		return rw
+	}
+
+	recordings := list(t, serve("/v1/shellrecordings"))
+	if assert.Len(t, recordings, 1) {
+		assert.Equal(t, "allowed", recordings[0].ID)
+	}
+	assert.Empty(t, list(t, serve("/v1/shellrecordings?cluster=c-denied")))
+
+	assert.Equal(t, http.StatusOK, serve("/v1/shellrecordings/allowed").Code)
+	rw := serve("/v1/shellrecordings/allowed/cast")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "cast\n", rw.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, serve("/v1/shellrecordings/denied").Code)
+	assert.Equal(t, http.StatusNotFound, serve("/v1/shellrecordings/denied/cast").Code)
+	assert.Equal(t, http.StatusNotFound, serve("/v1/shellrecordings/missing").Code)
+}
+
+func list(t *testing.T, rw *httptest.ResponseRecorder) []*Recording {
+	assert.Equal(t, http.StatusOK, rw.Code)
+	collection := struct {
+		Data []*Recording `json:"data"`
+	}{}
+	assert.Nil(t, json.NewDecoder(rw.Body).Decode(&collection))
+	return collection.Data
+}
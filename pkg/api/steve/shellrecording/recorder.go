@@ -0,0 +1,472 @@
+package shellrecording
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Shell sessions are recorded from the websocket between the browser and the shell pod, the frames of the kubernetes
+// exec channel protocol are decoded to record the terminal output, input and resizes in the asciicast v2 format.
+
+const (
+	stdinChannel  = 0
+	stdoutChannel = 1
+	stderrChannel = 2
+	resizeChannel = 4
+
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+
+	maxFrameSize    = 1 << 24
+	maxHeaderSize   = 1 << 16
+	defaultWidth    = 80
+	defaultHeight   = 24
+	protocolHeader  = "Sec-WebSocket-Protocol"
+	base64Protocols = "base64."
+)
+
+type castHeader struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Title     string `json:"title,omitempty"`
+}
+
+type terminalSize struct {
+	Width  int
+	Height int
+}
+
+// session writes the asciicast of a shell session
+type session struct {
+	sync.Mutex
+	out      io.WriteCloser
+	title    string
+	start    time.Time
+	started  bool
+	protocol string
+	// fallbackProtocol resolves the channel protocol when the upgrade response was not seen
+	fallbackProtocol func() string
+	// pending holds the incomplete utf-8 sequences of the input and output streams
+	pending map[string][]byte
+	err     error
+	// enforced sessions are ended when the recording fails
+	enforced bool
+}
+
+func newSession(out io.WriteCloser, title string, fallbackProtocol func() string) *session {
+	return &session{
+		out:              out,
+		title:            title,
+		start:            time.Now(),
+		fallbackProtocol: fallbackProtocol,
+		pending:          map[string][]byte{},
+	}
+}
+
+func (s *session) setProtocol(protocol string) {
+	s.Lock()
+	defer s.Unlock()
+	if protocol != "" {
+		s.protocol = protocol
+	}
+}
+
+func (s *session) base64() bool {
+	s.Lock()
+	protocol := s.protocol
+	s.Unlock()
+	if protocol == "" && s.fallbackProtocol != nil {
+		protocol = s.fallbackProtocol()
+	}
+	return strings.Contains(protocol, base64Protocols)
+}
+
+// channelData decodes a message of the kubernetes channel protocol
+func (s *session) channelData(opcode byte, payload []byte) (byte, []byte, bool) {
+	if len(payload) == 0 || (opcode != opText && opcode != opBinary) {
+		return 0, nil, false
+	}
+	if !s.base64() {
+		return payload[0], payload[1:], true
+	}
+	if payload[0] < '0' || payload[0] > '9' {
+		return 0, nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(string(payload[1:]))
+	if err != nil {
+		return 0, nil, false
+	}
+	return payload[0] - '0', data, true
+}
+
+// input records a message sent by the browser to the shell
+func (s *session) input(opcode byte, payload []byte) {
+	channel, data, ok := s.channelData(opcode, payload)
+	if !ok {
+		return
+	}
+	switch channel {
+	case stdinChannel:
+		s.event("i", data)
+	case resizeChannel:
+		size := terminalSize{}
+		if err := json.Unmarshal(data, &size); err != nil || size.Width <= 0 || size.Height <= 0 {
+			return
+		}
+		s.resize(size)
+	}
+}
+
+// output records a message sent by the shell to the browser
+func (s *session) output(opcode byte, payload []byte) {
+	channel, data, ok := s.channelData(opcode, payload)
+	if !ok {
+		return
+	}
+	if channel == stdoutChannel || channel == stderrChannel {
+		s.event("o", data)
+	}
+}
+
+func (s *session) resize(size terminalSize) {
+	s.Lock()
+	defer s.Unlock()
+	if !s.started {
+		// the first resize sets the size of the terminal in the header
+		s.writeHeader(size)
+		return
+	}
+	s.write("r", fmt.Sprintf("%dx%d", size.Width, size.Height))
+}
+
+func (s *session) event(code string, data []byte) {
+	s.Lock()
+	defer s.Unlock()
+	if !s.started {
+		s.writeHeader(terminalSize{Width: defaultWidth, Height: defaultHeight})
+	}
+	data, s.pending[code] = splitIncomplete(append(s.pending[code], data...))
+	if len(data) > 0 {
+		s.write(code, string(data))
+	}
+}
+
+func (s *session) writeHeader(size terminalSize) {
+	s.started = true
+	header, err := json.Marshal(castHeader{
+		Version:   2,
+		Width:     size.Width,
+		Height:    size.Height,
+		Timestamp: s.start.Unix(),
+		Title:     s.title,
+	})
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.writeLine(header)
+}
+
+func (s *session) write(code, data string) {
+	elapsed := math.Round(time.Since(s.start).Seconds()*1e6) / 1e6
+	event, err := json.Marshal([]interface{}{elapsed, code, data})
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.writeLine(event)
+}
+
+func (s *session) writeLine(line []byte) {
+	if s.err != nil {
+		return
+	}
+	if _, err := s.out.Write(append(line, '\n')); err != nil {
+		s.err = err
+		logrus.Errorf("Failed to write shell session recording: %v", err)
+	}
+}
+
+// failed returns the error that ends an enforced session whose recording failed
+func (s *session) failed() error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.enforced || s.err == nil {
+		return nil
+	}
+	return fmt.Errorf("shell session recording failed: %v", s.err)
+}
+
+// Close completes the recording
+func (s *session) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.started {
+		s.writeHeader(terminalSize{Width: defaultWidth, Height: defaultHeight})
+	}
+	for code, data := range s.pending {
+		if len(data) > 0 {
+			s.write(code, string(data))
+		}
+	}
+	s.pending = map[string][]byte{}
+	return s.out.Close()
+}
+
+// splitIncomplete splits the trailing incomplete utf-8 sequence from the data
+func splitIncomplete(data []byte) ([]byte, []byte) {
+	for i := 1; i <= utf8.UTFMax-1 && i <= len(data); i++ {
+		b := data[len(data)-i]
+		if b < utf8.RuneSelf {
+			break
+		}
+		if utf8.RuneStart(b) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i], append([]byte(nil), data[len(data)-i:]...)
+			}
+			break
+		}
+	}
+	return data, nil
+}
+
+// frameParser decodes the websocket messages of one direction of a connection from the bytes it transfers
+type frameParser struct {
+	buf       []byte
+	message   []byte
+	opcode    byte
+	failed    bool
+	onMessage func(opcode byte, payload []byte)
+	// onResponse receives the upgrade response preceding the frames, when it is seen on the connection
+	onResponse     func(resp *http.Response)
+	responseParsed bool
+}
+
+func (f *frameParser) write(p []byte) {
+	if f.failed {
+		return
+	}
+	f.buf = append(f.buf, p...)
+
+	if !f.responseParsed && f.onResponse != nil {
+		if !f.parseResponse() {
+			return
+		}
+	}
+
+	for !f.failed {
+		n := f.next()
+		if n == 0 {
+			break
+		}
+		f.buf = f.buf[n:]
+	}
+	f.buf = append([]byte(nil), f.buf...)
+}
+
+// parseResponse consumes the http response that precedes the frames, it returns false while the response is incomplete
+func (f *frameParser) parseResponse() bool {
+	const prefix = "HTTP/"
+	if len(f.buf) < len(prefix) {
+		if !bytes.HasPrefix([]byte(prefix), f.buf) {
+			f.responseParsed = true
+			return true
+		}
+		return false
+	}
+	if !bytes.HasPrefix(f.buf, []byte(prefix)) {
+		f.responseParsed = true
+		return true
+	}
+
+	end := bytes.Index(f.buf, []byte("\r\n\r\n"))
+	if end < 0 {
+		if len(f.buf) > maxHeaderSize {
+			f.failed = true
+		}
+		return false
+	}
+	end += 4
+	f.responseParsed = true
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(f.buf[:end])), nil)
+	if err == nil {
+		f.onResponse(resp)
+	}
+	f.buf = f.buf[end:]
+	return true
+}
+
+// next decodes the frame at the start of the buffer and returns its length, or 0 if the frame is incomplete
+func (f *frameParser) next() int {
+	if len(f.buf) < 2 {
+		return 0
+	}
+	fin := f.buf[0]&0x80 != 0
+	opcode := f.buf[0] & 0x0f
+	masked := f.buf[1]&0x80 != 0
+	length := uint64(f.buf[1] & 0x7f)
+
+	offset := 2
+	switch length {
+	case 126:
+		if len(f.buf) < 4 {
+			return 0
+		}
+		length = uint64(binary.BigEndian.Uint16(f.buf[2:4]))
+		offset = 4
+	case 127:
+		if len(f.buf) < 10 {
+			return 0
+		}
+		length = binary.BigEndian.Uint64(f.buf[2:10])
+		offset = 10
+	}
+	if length > maxFrameSize {
+		logrus.Warnf("Shell session recording stopped on a websocket frame of %d bytes", length)
+		f.failed = true
+		return 0
+	}
+
+	var mask []byte
+	if masked {
+		if len(f.buf) < offset+4 {
+			return 0
+		}
+		mask = f.buf[offset : offset+4]
+		offset += 4
+	}
+	total := offset + int(length)
+	if len(f.buf) < total {
+		return 0
+	}
+
+	payload := append([]byte(nil), f.buf[offset:total]...)
+	for i := range payload {
+		if mask != nil {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	switch {
+	case opcode >= opClose:
+		// control frames can be interleaved with the fragments of a message
+	case opcode == opContinuation:
+		f.message = append(f.message, payload...)
+	default:
+		f.opcode = opcode
+		f.message = payload
+	}
+	if fin && opcode < opClose {
+		f.onMessage(f.opcode, f.message)
+		f.message = nil
+	}
+	return total
+}
+
+// recordingConn records the websocket messages read from and written to the hijacked connection of the browser
+type recordingConn struct {
+	net.Conn
+	buffered []byte
+	session  *session
+	input    *frameParser
+	output   *frameParser
+}
+
+func newRecordingConn(conn net.Conn, buffered []byte, s *session) *recordingConn {
+	return &recordingConn{
+		Conn:     conn,
+		buffered: buffered,
+		session:  s,
+		input: &frameParser{
+			onMessage: s.input,
+		},
+		output: &frameParser{
+			onMessage: s.output,
+			onResponse: func(resp *http.Response) {
+				s.setProtocol(resp.Header.Get(protocolHeader))
+			},
+		},
+	}
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	if err := c.session.failed(); err != nil {
+		c.Conn.Close()
+		return 0, err
+	}
+	var (
+		n   int
+		err error
+	)
+	if len(c.buffered) > 0 {
+		n = copy(p, c.buffered)
+		c.buffered = c.buffered[n:]
+	} else {
+		n, err = c.Conn.Read(p)
+	}
+	if n > 0 {
+		c.input.write(p[:n])
+	}
+	return n, err
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	if err := c.session.failed(); err != nil {
+		c.Conn.Close()
+		return 0, err
+	}
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.output.write(p[:n])
+	}
+	return n, err
+}
+
+// responseWriter hands out a recording connection when the shell proxy hijacks the connection of the browser
+type responseWriter struct {
+	http.ResponseWriter
+	session *session
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buffered []byte
+	if n := brw.Reader.Buffered(); n > 0 {
+		peeked, _ := brw.Reader.Peek(n)
+		buffered = append(buffered, peeked...)
+	}
+	recording := newRecordingConn(conn, buffered, w.session)
+	return recording, bufio.NewReadWriter(bufio.NewReader(recording), bufio.NewWriter(recording)), nil
+}
+
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,208 @@
+package shellrecording
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nopCloser struct {
+	strings.Builder
+}
+
+func (n *nopCloser) Close() error {
+	return nil
+}
+
+func frame(fin bool, opcode byte, payload []byte, mask []byte) []byte {
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	var data []byte
+	switch {
+	case len(payload) < 126:
+		data = []byte{b0, byte(len(payload))}
+	default:
+		data = []byte{b0, 126, byte(len(payload) >> 8), byte(len(payload))}
+	}
+	if mask == nil {
+		return append(data, payload...)
+	}
+	data[1] |= 0x80
+	data = append(data, mask...)
+	for i, b := range payload {
+		data = append(data, b^mask[i%4])
+	}
+	return data
+}
+
+func events(t *testing.T, cast string) []interface{} {
+	lines := strings.Split(strings.TrimSpace(cast), "\n")
+	header := castHeader{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, 2, header.Version)
+
+	var result []interface{}
+	for _, line := range lines[1:] {
+		var event []interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &event))
+		result = append(result, event[1:]...)
+	}
+	return result
+}
+
+func TestFrameParser(t *testing.T) {
+	var messages []string
+	f := &frameParser{
+		onMessage: func(opcode byte, payload []byte) {
+			messages = append(messages, string(payload))
+		},
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	data := frame(true, opText, []byte("hello"), mask)
+	data = append(data, frame(false, opBinary, []byte("frag"), nil)...)
+	data = append(data, frame(true, 0x9, []byte("ping"), nil)...)
+	data = append(data, frame(true, opContinuation, []byte("mented"), nil)...)
+	data = append(data, frame(true, opText, []byte(strings.Repeat("x", 300)), mask)...)
+
+	// the frames are split across writes at every byte
+	for i := range data {
+		f.write(data[i : i+1])
+	}
+	assert.Equal(t, []string{"hello", "fragmented", strings.Repeat("x", 300)}, messages)
+}
+
+func TestFrameParserResponse(t *testing.T) {
+	var (
+		protocol string
+		messages []string
+	)
+	f := &frameParser{
+		onMessage: func(opcode byte, payload []byte) {
+			messages = append(messages, string(payload))
+		},
+		onResponse: func(resp *http.Response) {
+			protocol = resp.Header.Get(protocolHeader)
+		},
+	}
+
+	f.write([]byte("HTTP/1.1 101 Switching Protocols\r\nSec-WebSocket-Protocol: base64.channel.k8s.io\r\n"))
+	f.write(append([]byte("\r\n"), frame(true, opText, []byte("1aGk="), nil)...))
+	assert.Equal(t, "base64.channel.k8s.io", protocol)
+	assert.Equal(t, []string{"1aGk="}, messages)
+}
+
+func TestSessionBase64(t *testing.T) {
+	out := &nopCloser{}
+	s := newSession(out, "user on cluster c1", func() string {
+		return "base64.channel.k8s.io"
+	})
+	encode := func(channel string, data string) []byte {
+		return []byte(channel + base64.StdEncoding.EncodeToString([]byte(data)))
+	}
+
+	s.input(opText, encode("4", `{"Width":120,"Height":40}`))
+	s.input(opText, encode("0", "ls\r"))
+	// a rune split across messages is recorded once complete
+	s.output(opText, encode("1", "caf\xc3"))
+	s.output(opText, encode("1", "\xa9\r\n"))
+	s.output(opText, encode("3", `{"status":"Success"}`))
+	s.input(opText, encode("4", `{"Width":100,"Height":30}`))
+	assert.Nil(t, s.Close())
+
+	header := castHeader{}
+	assert.Nil(t, json.Unmarshal([]byte(strings.SplitN(out.String(), "\n", 2)[0]), &header))
+	assert.Equal(t, 120, header.Width)
+	assert.Equal(t, 40, header.Height)
+	assert.Equal(t, "user on cluster c1", header.Title)
+	assert.Equal(t, []interface{}{"i", "ls\r", "o", "caf", "o", "é\r\n", "r", "100x30"}, events(t, out.String()))
+}
+
+func TestRecordingConn(t *testing.T) {
+	out := &nopCloser{}
+	s := newSession(out, "", nil)
+	client, server := net.Pipe()
+	conn := newRecordingConn(server, frame(true, opBinary, []byte("\x00ls\r"), []byte{5, 6, 7, 8}), s)
+
+	// the bytes buffered before the connection was hijacked are read first
+	data := make([]byte, 64)
+	_, err := conn.Read(data)
+	assert.Nil(t, err)
+	go ioutil.ReadAll(client)
+
+	w := bufio.NewWriter(conn)
+	w.WriteString("HTTP/1.1 101 Switching Protocols\r\nSec-WebSocket-Protocol: channel.k8s.io\r\n\r\n")
+	w.Write(frame(true, opBinary, []byte("\x01file\r\n"), nil))
+	assert.Nil(t, w.Flush())
+	client.Close()
+	assert.Nil(t, s.Close())
+
+	assert.Equal(t, []interface{}{"i", "ls\r", "o", "file\r\n"}, events(t, out.String()))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func (failingWriter) Close() error {
+	return nil
+}
+
+func TestEnforcedRecordingFailure(t *testing.T) {
+	s := newSession(failingWriter{}, "", nil)
+	s.enforced = true
+	client, server := net.Pipe()
+	defer client.Close()
+	conn := newRecordingConn(server, nil, s)
+	go ioutil.ReadAll(client)
+
+	_, err := conn.Write(frame(true, opBinary, []byte("\x01file\r\n"), nil))
+	assert.Nil(t, err)
+	_, err = conn.Read(make([]byte, 64))
+	assert.EqualError(t, err, "shell session recording failed: disk full")
+	_, err = server.Write([]byte("x"))
+	assert.NotNil(t, err)
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shellrecordings")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store := NewFileStore(dir)
+
+	recording := &Recording{ID: newID(), Cluster: "c1", User: "u1"}
+	w, err := store.Create(recording)
+	assert.Nil(t, err)
+	w.Write([]byte("cast\n"))
+	assert.Nil(t, w.Close())
+
+	recordings, err := store.List()
+	assert.Nil(t, err)
+	assert.Len(t, recordings, 1)
+	assert.Equal(t, int64(5), recordings[0].Size)
+	assert.NotNil(t, recordings[0].Ended)
+
+	cast, err := store.Open(recording.ID)
+	assert.Nil(t, err)
+	data, _ := ioutil.ReadAll(cast)
+	cast.Close()
+	assert.Equal(t, "cast\n", string(data))
+
+	_, err = store.Get("../" + recording.ID)
+	assert.NotNil(t, err)
+	_, err = store.Create(&Recording{ID: "../escape"})
+	assert.NotNil(t, err)
+}
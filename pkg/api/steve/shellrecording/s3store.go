@@ -0,0 +1,326 @@
+package shellrecording
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	minio "github.com/minio/minio-go"
+	"github.com/minio/minio-go/pkg/credentials"
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/rancher/rancher/pkg/settings"
+	corecontrollers "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+)
+
+// The s3 store keeps the recordings in a bucket shared by the rancher servers. The asciicast of a session is uploaded
+// in chunks while the session runs, so that a restart of the server loses at most the last flush interval of the
+// recording, and it is replayed by concatenating the chunks. Its metadata is uploaded when the session starts so that
+// the sessions in progress are listed, and updated with each chunk.
+
+const (
+	s3Prefix          = "shell-recordings/"
+	s3AccessKeyKey    = "accessKey"
+	s3SecretKeyKey    = "secretKey"
+	s3DefaultEndpoint = "s3.amazonaws.com"
+	s3NoSuchKey       = "NoSuchKey"
+	s3ChunkSize       = 5 << 20
+	s3FlushInterval   = 10 * time.Second
+)
+
+// NewS3Store returns a store keeping the recordings in the bucket configured by the shell-recording-s3 settings. The
+// credentials are read from the secret named by shell-recording-s3-credentials in the system namespace, IAM roles are
+// used when it is not set.
+func NewS3Store(secrets corecontrollers.SecretCache) (Store, error) {
+	bucket := settings.ShellRecordingS3Bucket.Get()
+	if bucket == "" {
+		return nil, fmt.Errorf("setting %s is required to store shell recordings in s3", settings.ShellRecordingS3Bucket.Name)
+	}
+
+	endpoint := settings.ShellRecordingS3Endpoint.Get()
+	creds := credentials.NewIAM("")
+	if secretName := settings.ShellRecordingS3Credentials.Get(); secretName != "" {
+		secret, err := secrets.Get(namespace.System, secretName)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewStatic(string(secret.Data[s3AccessKeyKey]), string(secret.Data[s3SecretKeyKey]), "", credentials.SignatureDefault)
+	}
+	if endpoint == "" {
+		endpoint = s3DefaultEndpoint
+	}
+
+	client, err := minio.NewWithOptions(endpoint, &minio.Options{
+		Creds:        creds,
+		Region:       settings.ShellRecordingS3Region.Get(),
+		Secure:       true,
+		BucketLookup: minio.BucketLookupAuto,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s3Store{
+		client: client,
+		bucket: bucket,
+	}, nil
+}
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+func (s *s3Store) object(id, ext string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", fmt.Errorf("invalid recording id %q", id)
+	}
+	return s3Prefix + id + ext, nil
+}
+
+func (s *s3Store) Create(recording *Recording) (io.WriteCloser, error) {
+	if _, err := s.object(recording.ID, castExtension); err != nil {
+		return nil, err
+	}
+	if err := s.writeMetadata(recording); err != nil {
+		return nil, err
+	}
+	w := &s3Writer{
+		store:     s,
+		recording: recording,
+		done:      make(chan struct{}),
+	}
+	go w.flushPeriodically()
+	return w, nil
+}
+
+// chunk returns the name of a chunk of the asciicast of a recording, the chunks are named so that their names sort in
+// their order
+func (s *s3Store) chunk(id string, index int) (string, error) {
+	name, err := s.object(id, castExtension)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%08d", name, index), nil
+}
+
+func (s *s3Store) writeMetadata(recording *Recording) error {
+	name, err := s.object(recording.ID, metadataExtension)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(recording)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(s.bucket, name, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/json",
+	})
+	return err
+}
+
+func (s *s3Store) List() ([]*Recording, error) {
+	done := make(chan struct{})
+	defer close(done)
+
+	var recordings []*Recording
+	for object := range s.client.ListObjects(s.bucket, s3Prefix, false, done) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		if !strings.HasSuffix(object.Key, metadataExtension) {
+			continue
+		}
+		recording, err := s.Get(strings.TrimSuffix(strings.TrimPrefix(object.Key, s3Prefix), metadataExtension))
+		if err != nil {
+			continue
+		}
+		recordings = append(recordings, recording)
+	}
+
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].Started.After(recordings[j].Started)
+	})
+	return recordings, nil
+}
+
+func (s *s3Store) Get(id string) (*Recording, error) {
+	metadata, err := s.open(id, metadataExtension)
+	if err != nil {
+		return nil, err
+	}
+	defer metadata.Close()
+	recording := &Recording{}
+	return recording, json.NewDecoder(metadata).Decode(recording)
+}
+
+func (s *s3Store) Open(id string) (io.ReadCloser, error) {
+	prefix, err := s.chunk(id, 0)
+	if err != nil {
+		return nil, err
+	}
+	prefix = prefix[:strings.LastIndex(prefix, "/")+1]
+
+	done := make(chan struct{})
+	defer close(done)
+
+	var chunks []string
+	for object := range s.client.ListObjects(s.bucket, prefix, true, done) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		chunks = append(chunks, object.Key)
+	}
+	if len(chunks) == 0 {
+		return nil, os.ErrNotExist
+	}
+	sort.Strings(chunks)
+	return &chunkReader{
+		store:  s,
+		chunks: chunks,
+	}, nil
+}
+
+func (s *s3Store) open(id, ext string) (io.ReadCloser, error) {
+	name, err := s.object(id, ext)
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(s.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, notExist(err)
+	}
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, notExist(err)
+	}
+	return object, nil
+}
+
+// notExist converts the missing objects to the errors of missing files
+func notExist(err error) error {
+	if minio.ToErrorResponse(err).Code == s3NoSuchKey {
+		return os.ErrNotExist
+	}
+	return err
+}
+
+// chunkReader reads the chunks of an asciicast one after the other
+type chunkReader struct {
+	store   *s3Store
+	chunks  []string
+	current *minio.Object
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+			object, err := r.store.client.GetObject(r.store.bucket, r.chunks[0], minio.GetObjectOptions{})
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.chunks = object, r.chunks[1:]
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
+
+type s3Writer struct {
+	sync.Mutex
+	store     *s3Store
+	recording *Recording
+	buf       bytes.Buffer
+	chunks    int
+	// err is the failure of the last upload, it is returned by the following writes to end the enforced sessions
+	err  error
+	done chan struct{}
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, _ := w.buf.Write(p)
+	w.recording.Size += int64(n)
+	if w.buf.Len() >= s3ChunkSize {
+		w.err = w.flush()
+	}
+	return n, w.err
+}
+
+func (w *s3Writer) flushPeriodically() {
+	ticker := time.NewTicker(s3FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.Lock()
+			if w.err == nil {
+				w.err = w.flush()
+			}
+			w.Unlock()
+		}
+	}
+}
+
+// flush uploads the buffered asciicast as the next chunk and the size of the recording uploaded so far
+func (w *s3Writer) flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	name, err := w.store.chunk(w.recording.ID, w.chunks)
+	if err != nil {
+		return err
+	}
+	if _, err := w.store.client.PutObject(w.store.bucket, name, bytes.NewReader(w.buf.Bytes()), int64(w.buf.Len()), minio.PutObjectOptions{
+		ContentType: castContentType,
+	}); err != nil {
+		return err
+	}
+	w.chunks++
+	w.buf.Reset()
+	return w.store.writeMetadata(w.recording)
+}
+
+func (w *s3Writer) Close() error {
+	close(w.done)
+	w.Lock()
+	defer w.Unlock()
+
+	if w.err == nil {
+		w.err = w.flush()
+	}
+	if w.err != nil {
+		return w.err
+	}
+	ended := time.Now().UTC()
+	w.recording.Ended = &ended
+	return w.store.writeMetadata(w.recording)
+}
@@ -0,0 +1,172 @@
+package shellrecording
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	castExtension     = ".cast"
+	metadataExtension = ".json"
+)
+
+var validID = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// Recording is the metadata of a recorded shell session
+type Recording struct {
+	ID           string     `json:"id"`
+	Cluster      string     `json:"cluster"`
+	User         string     `json:"user"`
+	Principal    string     `json:"principal,omitempty"`
+	AuthProvider string     `json:"authProvider,omitempty"`
+	Started      time.Time  `json:"started"`
+	Ended        *time.Time `json:"ended,omitempty"`
+	Size         int64      `json:"size"`
+}
+
+// Store persists the recordings of shell sessions
+type Store interface {
+	// Create stores a new recording, its asciicast is written to the returned writer which completes the recording
+	// when closed
+	Create(recording *Recording) (io.WriteCloser, error)
+	List() ([]*Recording, error)
+	Get(id string) (*Recording, error)
+	// Open returns the asciicast of a recording
+	Open(id string) (io.ReadCloser, error)
+}
+
+// NewFileStore returns a store keeping each recording as an asciicast file and a metadata file in the directory
+func NewFileStore(dir string) Store {
+	return &fileStore{
+		dir: dir,
+	}
+}
+
+type fileStore struct {
+	dir string
+}
+
+func (f *fileStore) path(id, ext string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", fmt.Errorf("invalid recording id %q", id)
+	}
+	return filepath.Join(f.dir, id+ext), nil
+}
+
+func (f *fileStore) Create(recording *Recording) (io.WriteCloser, error) {
+	castPath, err := f.path(recording.ID, castExtension)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := f.writeMetadata(recording); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(castPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileWriter{
+		store:     f,
+		file:      file,
+		recording: recording,
+	}, nil
+}
+
+func (f *fileStore) writeMetadata(recording *Recording) error {
+	metadataPath, err := f.path(recording.ID, metadataExtension)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(recording)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(metadataPath, data, 0600)
+}
+
+func (f *fileStore) List() ([]*Recording, error) {
+	files, err := ioutil.ReadDir(f.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var recordings []*Recording
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), metadataExtension) {
+			continue
+		}
+		recording, err := f.Get(strings.TrimSuffix(file.Name(), metadataExtension))
+		if err != nil {
+			continue
+		}
+		recordings = append(recordings, recording)
+	}
+
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].Started.After(recordings[j].Started)
+	})
+	return recordings, nil
+}
+
+func (f *fileStore) Get(id string) (*Recording, error) {
+	metadataPath, err := f.path(id, metadataExtension)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+	recording := &Recording{}
+	return recording, json.Unmarshal(data, recording)
+}
+
+func (f *fileStore) Open(id string) (io.ReadCloser, error) {
+	castPath, err := f.path(id, castExtension)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(castPath)
+}
+
+type fileWriter struct {
+	sync.Mutex
+	store     *fileStore
+	file      *os.File
+	recording *Recording
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	n, err := w.file.Write(p)
+	w.recording.Size += int64(n)
+	return n, err
+}
+
+func (w *fileWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	err := w.file.Close()
+	ended := time.Now().UTC()
+	w.recording.Ended = &ended
+	if metaErr := w.store.writeMetadata(w.recording); err == nil {
+		err = metaErr
+	}
+	return err
+}
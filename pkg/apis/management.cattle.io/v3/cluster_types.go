@@ -33,6 +33,7 @@ const (
 	ClusterActionDisableMonitoring     = "disableMonitoring"
 	ClusterActionBackupEtcd            = "backupEtcd"
 	ClusterActionRestoreFromEtcdBackup = "restoreFromEtcdBackup"
+	ClusterActionResume                = "resume"
 	ClusterActionRotateCertificates    = "rotateCertificates"
 	ClusterActionRotateEncryptionKey   = "rotateEncryptionKey"
 	ClusterActionRunSecurityScan       = "runSecurityScan"
@@ -80,6 +81,14 @@ const (
 	ClusterDriverEKS      = "EKS"
 	ClusterDriverGKE      = "GKE"
 	ClusterDriverRancherD = "rancherd"
+
+	ClusterConnectionModeAuto   = "auto"
+	ClusterConnectionModeTunnel = "tunnel"
+	ClusterConnectionModeDirect = "direct"
+	ClusterConnectionModeProxy  = "proxy"
+
+	KubeconfigAuthModeToken = "token"
+	KubeconfigAuthModeExec  = "exec"
 )
 
 // +genclient
@@ -135,6 +144,7 @@ type ClusterSpec struct {
 	ClusterTemplateAnswers              Answer                      `json:"answers,omitempty"`
 	ClusterTemplateQuestions            []Question                  `json:"questions,omitempty" norman:"nocreate,noupdate"`
 	FleetWorkspaceName                  string                      `json:"fleetWorkspaceName,omitempty"`
+	ConnectionMode                      string                      `json:"connectionMode,omitempty" norman:"type=enum,options=auto|tunnel|direct|proxy"`
 }
 
 type ImportedConfig struct {
@@ -157,6 +167,7 @@ type ClusterStatus struct {
 	APIEndpoint                          string                      `json:"apiEndpoint,omitempty"`
 	ServiceAccountToken                  string                      `json:"serviceAccountToken,omitempty"`
 	CACert                               string                      `json:"caCert,omitempty"`
+	LastConnectionMode                   string                      `json:"lastConnectionMode,omitempty" norman:"nocreate,noupdate"`
 	Capacity                             v1.ResourceList             `json:"capacity,omitempty"`
 	Allocatable                          v1.ResourceList             `json:"allocatable,omitempty"`
 	AppliedSpec                          ClusterSpec                 `json:"appliedSpec,omitempty"`
@@ -259,6 +270,11 @@ type ClusterRegistrationTokenStatus struct {
 	Token              string `json:"token"`
 }
 
+type GenerateKubeConfigInput struct {
+	AuthMode        string `json:"authMode,omitempty" norman:"type=enum,options=token|exec"`
+	ClusterSelector string `json:"clusterSelector,omitempty"`
+}
+
 type GenerateKubeConfigOutput struct {
 	Config string `json:"config"`
 }
@@ -27,6 +27,17 @@ type KontainerDriverStatus struct {
 	ExecutablePath string      `json:"executablePath"`
 	Conditions     []Condition `json:"conditions"`
 	DisplayName    string      `json:"displayName"`
+	// Health of the driver process, reported by the rancher servers running it
+	Health *KontainerDriverHealth `json:"health,omitempty"`
+}
+
+// KontainerDriverHealth is the latest health of the driver process of the leader, State is one of healthy, unhealthy, restarting
+// or failed. It is only reported when it changes, LastChecked is the time of the check that reported the change.
+type KontainerDriverHealth struct {
+	State       string `json:"state,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Restarts    int64  `json:"restarts,omitempty"`
+	LastChecked string `json:"lastChecked,omitempty"`
 }
 
 type KontainerDriverSpec struct {
@@ -36,8 +47,23 @@ type KontainerDriverSpec struct {
 	Active           bool     `json:"active"`
 	UIURL            string   `json:"uiUrl"`
 	WhitelistDomains []string `json:"whitelistDomains,omitempty"`
+	// Timeouts bound the phases of creating a cluster with the driver
+	Timeouts *KontainerDriverTimeouts `json:"timeouts,omitempty"`
+}
+
+// KontainerDriverTimeouts are in seconds, zero uses the default timeout of the phase
+type KontainerDriverTimeouts struct {
+	CreateSeconds    int64 `json:"createSeconds,omitempty" norman:"min=0"`
+	PostCheckSeconds int64 `json:"postCheckSeconds,omitempty" norman:"min=0"`
 }
 
+const (
+	KontainerDriverHealthy    = "healthy"
+	KontainerDriverUnhealthy  = "unhealthy"
+	KontainerDriverRestarting = "restarting"
+	KontainerDriverFailed     = "failed"
+)
+
 var (
 	KontainerDriverConditionDownloaded condition.Cond = "Downloaded"
 	KontainerDriverConditionInstalled  condition.Cond = "Installed"
@@ -12,6 +12,10 @@ import (
 var (
 	MultiClusterAppConditionInstalled condition.Cond = "Installed"
 	MultiClusterAppConditionDeployed  condition.Cond = "Deployed"
+
+	// TargetConditionDrifted is set on the status of a target whose app no longer matches the current revision of the
+	// multi cluster app
+	TargetConditionDrifted condition.Cond = "Drifted"
 )
 
 // +genclient
@@ -31,21 +35,44 @@ type MultiClusterApp struct {
 }
 
 type MultiClusterAppSpec struct {
-	TemplateVersionName  string          `json:"templateVersionName,omitempty" norman:"type=reference[templateVersion],required"`
-	Answers              []Answer        `json:"answers,omitempty"`
-	Wait                 bool            `json:"wait,omitempty"`
-	Timeout              int             `json:"timeout,omitempty" norman:"min=1,default=300"`
-	Targets              []Target        `json:"targets,omitempty" norman:"required,noupdate"`
-	Members              []Member        `json:"members,omitempty"`
-	Roles                []string        `json:"roles,omitempty" norman:"type=array[reference[roleTemplate]],required"`
-	RevisionHistoryLimit int             `json:"revisionHistoryLimit,omitempty" norman:"default=10"`
-	UpgradeStrategy      UpgradeStrategy `json:"upgradeStrategy,omitempty"`
+	TemplateVersionName  string           `json:"templateVersionName,omitempty" norman:"type=reference[templateVersion],required"`
+	Answers              []Answer         `json:"answers,omitempty"`
+	Wait                 bool             `json:"wait,omitempty"`
+	Timeout              int              `json:"timeout,omitempty" norman:"min=1,default=300"`
+	Targets              []Target         `json:"targets,omitempty" norman:"noupdate"`
+	TargetSelectors      []TargetSelector `json:"targetSelectors,omitempty"`
+	Members              []Member         `json:"members,omitempty"`
+	Roles                []string         `json:"roles,omitempty" norman:"type=array[reference[roleTemplate]],required"`
+	RevisionHistoryLimit int              `json:"revisionHistoryLimit,omitempty" norman:"default=10"`
+	UpgradeStrategy      UpgradeStrategy  `json:"upgradeStrategy,omitempty"`
+	// RevertDrift resets target apps that were changed outside of the multi cluster app to the current revision
+	RevertDrift bool `json:"revertDrift,omitempty"`
 }
 
 type MultiClusterAppStatus struct {
 	Conditions   []v3.AppCondition `json:"conditions,omitempty"`
 	RevisionName string            `json:"revisionName,omitempty" norman:"type=reference[multiClusterAppRevision],required"`
 	HelmVersion  string            `json:"helmVersion,omitempty" norman:"nocreate,noupdate"`
+	// TargetStatuses reports whether the apps of the targets drifted from the current revision
+	TargetStatuses []TargetStatus `json:"targetStatuses,omitempty" norman:"nocreate,noupdate"`
+	// AbortedRollout records the last aborted rolling update while the spec is the one it was aborted for
+	AbortedRollout *AbortedRollout `json:"abortedRollout,omitempty" norman:"nocreate,noupdate"`
+}
+
+// AbortedRollout is a rolling update that was aborted. The spec is left as is, the template version and answers it
+// was aborted for are not rolled out again until the spec changes.
+type AbortedRollout struct {
+	// SpecDigest identifies the template version and answers of the aborted rollout
+	SpecDigest string `json:"specDigest,omitempty"`
+	// RollbackRevisionName is the revision the apps are rolled back to, empty if the rollout is only halted
+	RollbackRevisionName string `json:"rollbackRevisionName,omitempty" norman:"type=reference[multiClusterAppRevision]"`
+}
+
+// TargetStatus is the observed state of the app of a target
+type TargetStatus struct {
+	ProjectName string            `json:"projectName,omitempty" norman:"type=reference[project]"`
+	AppName     string            `json:"appName,omitempty" norman:"type=reference[v3/projects/schemas/app]"`
+	Conditions  []v3.AppCondition `json:"conditions,omitempty"`
 }
 
 type Target struct {
@@ -53,6 +80,23 @@ type Target struct {
 	AppName     string `json:"appName,omitempty" norman:"type=reference[v3/projects/schemas/app]"`
 	State       string `json:"state,omitempty"`
 	Healthstate string `json:"healthState,omitempty"`
+	// Priority orders targets during a rolling update when the strategy orders by priority, lower values are upgraded first
+	Priority int `json:"priority,omitempty"`
+	// Selector is the name of the target selector that added this target, empty for targets added explicitly
+	Selector string `json:"selector,omitempty" norman:"nocreate,noupdate"`
+}
+
+// TargetSelector adds every project matching ProjectSelector in the clusters matching ClusterSelector as a target.
+// Targets are added and removed as clusters and projects come and go or their labels change.
+type TargetSelector struct {
+	Name string `json:"name,omitempty" norman:"required"`
+	// ClusterSelector selects the clusters to deploy to, all clusters are selected if empty
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+	// ProjectSelector selects the projects of the matching clusters to deploy to, the Default project
+	// of each cluster is selected if empty
+	ProjectSelector *metav1.LabelSelector `json:"projectSelector,omitempty"`
+	// Values are answers that override the global answers for all projects selected by this selector
+	Values map[string]string `json:"values,omitempty"`
 }
 
 func (t *Target) ObjClusterName() string {
@@ -87,6 +131,14 @@ type UpgradeStrategy struct {
 type RollingUpdate struct {
 	BatchSize int `json:"batchSize,omitempty"`
 	Interval  int `json:"interval,omitempty"`
+	// OrderBy is either "priority" to order targets by their priority, or a cluster label key whose values
+	// are used to order the targets. Targets are upgraded in the order they are listed if empty.
+	OrderBy string `json:"orderBy,omitempty"`
+	// BatchTimeout is the number of seconds the apps of a batch have to become installed and deployed
+	// before the rollout is aborted. Zero waits forever.
+	BatchTimeout int `json:"batchTimeout,omitempty"`
+	// RollbackOnFailure rolls the apps back to the previous revision when the rollout is aborted, the spec is kept
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
 }
 
 // +genclient
@@ -27,6 +27,7 @@ import (
 	types "github.com/rancher/rke/types"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	version "k8s.io/apimachinery/pkg/version"
 )
@@ -65,6 +66,22 @@ func (in *ADFSProvider) DeepCopy() *ADFSProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AbortedRollout) DeepCopyInto(out *AbortedRollout) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AbortedRollout.
+func (in *AbortedRollout) DeepCopy() *AbortedRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(AbortedRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Action) DeepCopyInto(out *Action) {
 	*out = *in
@@ -3664,6 +3681,22 @@ func (in *GKEStatus) DeepCopy() *GKEStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenerateKubeConfigInput) DeepCopyInto(out *GenerateKubeConfigInput) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenerateKubeConfigInput.
+func (in *GenerateKubeConfigInput) DeepCopy() *GenerateKubeConfigInput {
+	if in == nil {
+		return nil
+	}
+	out := new(GenerateKubeConfigInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GenerateKubeConfigOutput) DeepCopyInto(out *GenerateKubeConfigOutput) {
 	*out = *in
@@ -4695,6 +4728,22 @@ func (in *KontainerDriver) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KontainerDriverHealth) DeepCopyInto(out *KontainerDriverHealth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KontainerDriverHealth.
+func (in *KontainerDriverHealth) DeepCopy() *KontainerDriverHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(KontainerDriverHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KontainerDriverList) DeepCopyInto(out *KontainerDriverList) {
 	*out = *in
@@ -4736,6 +4785,11 @@ func (in *KontainerDriverSpec) DeepCopyInto(out *KontainerDriverSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(KontainerDriverTimeouts)
+		**out = **in
+	}
 	return
 }
 
@@ -4757,6 +4811,11 @@ func (in *KontainerDriverStatus) DeepCopyInto(out *KontainerDriverStatus) {
 		*out = make([]Condition, len(*in))
 		copy(*out, *in)
 	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(KontainerDriverHealth)
+		**out = **in
+	}
 	return
 }
 
@@ -4770,6 +4829,22 @@ func (in *KontainerDriverStatus) DeepCopy() *KontainerDriverStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KontainerDriverTimeouts) DeepCopyInto(out *KontainerDriverTimeouts) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KontainerDriverTimeouts.
+func (in *KontainerDriverTimeouts) DeepCopy() *KontainerDriverTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(KontainerDriverTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LdapConfig) DeepCopyInto(out *LdapConfig) {
 	*out = *in
@@ -5587,6 +5662,13 @@ func (in *MultiClusterAppSpec) DeepCopyInto(out *MultiClusterAppSpec) {
 		*out = make([]Target, len(*in))
 		copy(*out, *in)
 	}
+	if in.TargetSelectors != nil {
+		in, out := &in.TargetSelectors, &out.TargetSelectors
+		*out = make([]TargetSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Members != nil {
 		in, out := &in.Members, &out.Members
 		*out = make([]Member, len(*in))
@@ -5619,6 +5701,18 @@ func (in *MultiClusterAppStatus) DeepCopyInto(out *MultiClusterAppStatus) {
 		*out = make([]projectcattleiov3.AppCondition, len(*in))
 		copy(*out, *in)
 	}
+	if in.TargetStatuses != nil {
+		in, out := &in.TargetStatuses, &out.TargetStatuses
+		*out = make([]TargetStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AbortedRollout != nil {
+		in, out := &in.AbortedRollout, &out.AbortedRollout
+		*out = new(AbortedRollout)
+		**out = **in
+	}
 	return
 }
 
@@ -9042,6 +9136,60 @@ func (in *Target) DeepCopy() *Target {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetStatus) DeepCopyInto(out *TargetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]projectcattleiov3.AppCondition, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetStatus.
+func (in *TargetStatus) DeepCopy() *TargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetSelector) DeepCopyInto(out *TargetSelector) {
+	*out = *in
+	if in.ClusterSelector != nil {
+		in, out := &in.ClusterSelector, &out.ClusterSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProjectSelector != nil {
+		in, out := &in.ProjectSelector, &out.ProjectSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetSelector.
+func (in *TargetSelector) DeepCopy() *TargetSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetEvent) DeepCopyInto(out *TargetEvent) {
 	*out = *in
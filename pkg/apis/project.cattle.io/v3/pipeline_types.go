@@ -29,7 +29,7 @@ type SourceCodeProvider struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	ProjectName string `json:"projectName" norman:"type=reference[project]"`
-	Type        string `json:"type" norman:"options=github|gitlab|bitbucketcloud|bitbucketserver"`
+	Type        string `json:"type" norman:"options=github|gitlab|bitbucketcloud|bitbucketserver|gitea|git"`
 }
 
 func (s *SourceCodeProvider) ObjClusterName() string {
@@ -65,6 +65,14 @@ type BitbucketServerProvider struct {
 	OauthProvider `json:",inline"`
 }
 
+type GiteaProvider struct {
+	OauthProvider `json:",inline"`
+}
+
+type GitProvider struct {
+	SourceCodeProvider `json:",inline"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -75,7 +83,7 @@ type SourceCodeProviderConfig struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	ProjectName string `json:"projectName" norman:"required,type=reference[project]"`
-	Type        string `json:"type" norman:"noupdate,options=github|gitlab|bitbucketcloud|bitbucketserver"`
+	Type        string `json:"type" norman:"noupdate,options=github|gitlab|bitbucketcloud|bitbucketserver|gitea|git"`
 	Enabled     bool   `json:"enabled,omitempty"`
 }
 
@@ -140,6 +148,35 @@ type BitbucketServerPipelineConfig struct {
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+type GiteaPipelineConfig struct {
+	SourceCodeProviderConfig `json:",inline" mapstructure:",squash"`
+
+	Hostname     string `json:"hostname,omitempty" norman:"noupdate"`
+	TLS          bool   `json:"tls,omitempty" norman:"notnullable,default=true" norman:"noupdate"`
+	ClientID     string `json:"clientId,omitempty" norman:"noupdate"`
+	ClientSecret string `json:"clientSecret,omitempty" norman:"noupdate,type=password"`
+	RedirectURL  string `json:"redirectUrl,omitempty" norman:"noupdate"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GitPipelineConfig configures repositories on git servers without an API. They are cloned with a deploy key and
+// polled for changes instead of sending webhooks.
+type GitPipelineConfig struct {
+	SourceCodeProviderConfig `json:",inline" mapstructure:",squash"`
+
+	Repositories []string `json:"repositories,omitempty"`
+	PublicKey    string   `json:"publicKey,omitempty"`
+	PrivateKey   string   `json:"privateKey,omitempty" norman:"type=password"`
+	// KnownHosts are the ssh host keys of the git servers in known_hosts format, servers are only trusted if their
+	// key is listed
+	KnownHosts string `json:"knownHosts,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 type Pipeline struct {
 	types.Namespaced
 
@@ -237,6 +274,10 @@ type PipelineStatus struct {
 	WebHookID            string                `json:"webhookId,omitempty" yaml:"webhookId,omitempty"`
 	Token                string                `json:"token,omitempty" yaml:"token,omitempty" norman:"writeOnly,noupdate"`
 	SourceCodeCredential *SourceCodeCredential `json:"sourceCodeCredential,omitempty" yaml:"sourceCodeCredential,omitempty"`
+	// LastCronCheck is the time the cron triggers of the pipeline were last evaluated at
+	LastCronCheck string `json:"lastCronCheck,omitempty" yaml:"lastCronCheck,omitempty"`
+	// CronTriggersHash identifies the cron triggers that were evaluated at LastCronCheck
+	CronTriggersHash string `json:"cronTriggersHash,omitempty" yaml:"cronTriggersHash,omitempty"`
 }
 
 type PipelineSpec struct {
@@ -249,6 +290,15 @@ type PipelineSpec struct {
 
 	RepositoryURL            string `json:"repositoryUrl,omitempty" yaml:"repositoryUrl,omitempty"`
 	SourceCodeCredentialName string `json:"sourceCodeCredentialName,omitempty" yaml:"sourceCodeCredentialName,omitempty" norman:"type=reference[sourceCodeCredential],noupdate"`
+
+	CronTriggers []CronTrigger `json:"cronTriggers,omitempty" yaml:"cronTriggers,omitempty"`
+}
+
+// CronTrigger runs the pipeline on a branch on a schedule, the executions it creates have the cron event
+type CronTrigger struct {
+	Schedule string            `json:"schedule,omitempty" yaml:"schedule,omitempty" norman:"required"`
+	Branch   string            `json:"branch,omitempty" yaml:"branch,omitempty" norman:"required"`
+	Env      map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
 }
 
 func (p *PipelineSpec) ObjClusterName() string {
@@ -315,11 +365,32 @@ type Step struct {
 	MemoryRequest string            `json:"memoryRequest,omitempty" yaml:"memoryRequest,omitempty"`
 	MemoryLimit   string            `json:"memoryLimit,omitempty" yaml:"memoryLimit,omitempty"`
 	When          *Constraints      `json:"when,omitempty" yaml:"when,omitempty"`
+
+	Cache     *CacheConfig     `json:"cache,omitempty" yaml:"cache,omitempty"`
+	Artifacts *ArtifactsConfig `json:"artifacts,omitempty" yaml:"artifacts,omitempty"`
+
+	// Matrix runs the step once for every combination of the values, each instance gets the values as
+	// environment variables. Executions hold the expanded instances instead of the matrix step.
+	Matrix map[string][]string `json:"matrix,omitempty" yaml:"matrix,omitempty"`
+}
+
+// CacheConfig restores the paths saved under the key before the step runs and saves them again after it succeeds.
+// The key may refer to environment variables of the step, e.g. go-${CICD_GIT_BRANCH}.
+type CacheConfig struct {
+	Key   string   `json:"key,omitempty" yaml:"key,omitempty" norman:"required"`
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty" norman:"required"`
+}
+
+// ArtifactsConfig saves the paths after the step succeeds and restores them before the steps of all later stages.
+type ArtifactsConfig struct {
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty" norman:"required"`
 }
 
 type Constraints struct {
 	Branch *Constraint `json:"branch,omitempty" yaml:"branch,omitempty"`
 	Event  *Constraint `json:"event,omitempty" yaml:"event,omitempty"`
+	// Matrix constrains the matrix values of the instances of a step, instances not matching are left out
+	Matrix map[string]Constraint `json:"matrix,omitempty" yaml:"matrix,omitempty"`
 }
 
 type Constraint struct {
@@ -386,6 +457,8 @@ type PipelineExecutionSpec struct {
 	Author          string         `json:"author,omitempty"`
 	AvatarURL       string         `json:"avatarUrl,omitempty"`
 	Email           string         `json:"email,omitempty"`
+	// Env holds extra environment variables of the steps, set by the cron trigger that created the execution
+	Env map[string]string `json:"env,omitempty"`
 }
 
 func (p *PipelineExecutionSpec) ObjClusterName() string {
@@ -412,14 +485,15 @@ type StageStatus struct {
 }
 
 type StepStatus struct {
-	State   string `json:"state,omitempty"`
-	Started string `json:"started,omitempty"`
-	Ended   string `json:"ended,omitempty"`
+	State   string            `json:"state,omitempty"`
+	Started string            `json:"started,omitempty"`
+	Ended   string            `json:"ended,omitempty"`
+	Matrix  map[string]string `json:"matrix,omitempty"`
 }
 
 type SourceCodeCredentialSpec struct {
 	ProjectName    string `json:"projectName" norman:"type=reference[project]"`
-	SourceCodeType string `json:"sourceCodeType,omitempty" norman:"required,options=github|gitlab|bitbucketcloud|bitbucketserver"`
+	SourceCodeType string `json:"sourceCodeType,omitempty" norman:"required,options=github|gitlab|bitbucketcloud|bitbucketserver|gitea|git"`
 	UserName       string `json:"userName" norman:"required,type=reference[user]"`
 	DisplayName    string `json:"displayName,omitempty" norman:"required"`
 	AvatarURL      string `json:"avatarUrl,omitempty"`
@@ -445,7 +519,7 @@ type SourceCodeCredentialStatus struct {
 
 type SourceCodeRepositorySpec struct {
 	ProjectName              string   `json:"projectName" norman:"type=reference[project]"`
-	SourceCodeType           string   `json:"sourceCodeType,omitempty" norman:"required,options=github|gitlab|bitbucketcloud|bitbucketserver"`
+	SourceCodeType           string   `json:"sourceCodeType,omitempty" norman:"required,options=github|gitlab|bitbucketcloud|bitbucketserver|gitea|git"`
 	UserName                 string   `json:"userName" norman:"required,type=reference[user]"`
 	SourceCodeCredentialName string   `json:"sourceCodeCredentialName,omitempty" norman:"required,type=reference[sourceCodeCredential]"`
 	URL                      string   `json:"url,omitempty"`
@@ -476,7 +550,7 @@ type RunPipelineInput struct {
 
 type AuthAppInput struct {
 	InheritGlobal  bool   `json:"inheritGlobal,omitempty"`
-	SourceCodeType string `json:"sourceCodeType,omitempty" norman:"type=string,required,options=github|gitlab|bitbucketcloud|bitbucketserver"`
+	SourceCodeType string `json:"sourceCodeType,omitempty" norman:"type=string,required,options=github|gitlab|bitbucketcloud|bitbucketserver|gitea|git"`
 	RedirectURL    string `json:"redirectUrl,omitempty" norman:"type=string"`
 	TLS            bool   `json:"tls,omitempty"`
 	Host           string `json:"host,omitempty"`
@@ -486,7 +560,7 @@ type AuthAppInput struct {
 }
 
 type AuthUserInput struct {
-	SourceCodeType string `json:"sourceCodeType,omitempty" norman:"type=string,required,options=github|gitlab|bitbucketcloud|bitbucketserver"`
+	SourceCodeType string `json:"sourceCodeType,omitempty" norman:"type=string,required,options=github|gitlab|bitbucketcloud|bitbucketserver|gitea|git"`
 	RedirectURL    string `json:"redirectUrl,omitempty" norman:"type=string"`
 	Code           string `json:"code,omitempty" norman:"type=string,required"`
 }
@@ -528,6 +602,15 @@ type BitbucketCloudApplyInput struct {
 	OauthApplyInput
 }
 
+type GiteaApplyInput struct {
+	OauthApplyInput
+}
+
+type GitApplyInput struct {
+	Repositories []string `json:"repositories,omitempty"`
+	KnownHosts   string   `json:"knownHosts,omitempty"`
+}
+
 type BitbucketServerApplyInput struct {
 	OAuthToken    string `json:"oauthToken,omitempty"`
 	OAuthVerifier string `json:"oauthVerifier,omitempty"`
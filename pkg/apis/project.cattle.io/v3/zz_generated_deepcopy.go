@@ -337,6 +337,27 @@ func (in *ApplyYamlConfig) DeepCopy() *ApplyYamlConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactsConfig) DeepCopyInto(out *ArtifactsConfig) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactsConfig.
+func (in *ArtifactsConfig) DeepCopy() *ArtifactsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthAppInput) DeepCopyInto(out *AuthAppInput) {
 	*out = *in
@@ -578,6 +599,27 @@ func (in *BitbucketServerRequestLoginOutput) DeepCopy() *BitbucketServerRequestL
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheConfig) DeepCopyInto(out *CacheConfig) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheConfig.
+func (in *CacheConfig) DeepCopy() *CacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Certificate) DeepCopyInto(out *Certificate) {
 	*out = *in
@@ -682,6 +724,13 @@ func (in *Constraints) DeepCopyInto(out *Constraints) {
 		*out = new(Constraint)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = make(map[string]Constraint, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -695,6 +744,29 @@ func (in *Constraints) DeepCopy() *Constraints {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronTrigger) DeepCopyInto(out *CronTrigger) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronTrigger.
+func (in *CronTrigger) DeepCopy() *CronTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(CronTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentRollbackInput) DeepCopyInto(out *DeploymentRollbackInput) {
 	*out = *in
@@ -794,6 +866,133 @@ func (in *EnvFrom) DeepCopy() *EnvFrom {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitApplyInput) DeepCopyInto(out *GitApplyInput) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitApplyInput.
+func (in *GitApplyInput) DeepCopy() *GitApplyInput {
+	if in == nil {
+		return nil
+	}
+	out := new(GitApplyInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitPipelineConfig) DeepCopyInto(out *GitPipelineConfig) {
+	*out = *in
+	in.SourceCodeProviderConfig.DeepCopyInto(&out.SourceCodeProviderConfig)
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitPipelineConfig.
+func (in *GitPipelineConfig) DeepCopy() *GitPipelineConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GitPipelineConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitPipelineConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitProvider) DeepCopyInto(out *GitProvider) {
+	*out = *in
+	in.SourceCodeProvider.DeepCopyInto(&out.SourceCodeProvider)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProvider.
+func (in *GitProvider) DeepCopy() *GitProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(GitProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GiteaApplyInput) DeepCopyInto(out *GiteaApplyInput) {
+	*out = *in
+	out.OauthApplyInput = in.OauthApplyInput
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GiteaApplyInput.
+func (in *GiteaApplyInput) DeepCopy() *GiteaApplyInput {
+	if in == nil {
+		return nil
+	}
+	out := new(GiteaApplyInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GiteaPipelineConfig) DeepCopyInto(out *GiteaPipelineConfig) {
+	*out = *in
+	in.SourceCodeProviderConfig.DeepCopyInto(&out.SourceCodeProviderConfig)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GiteaPipelineConfig.
+func (in *GiteaPipelineConfig) DeepCopy() *GiteaPipelineConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GiteaPipelineConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GiteaPipelineConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GiteaProvider) DeepCopyInto(out *GiteaProvider) {
+	*out = *in
+	in.OauthProvider.DeepCopyInto(&out.OauthProvider)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GiteaProvider.
+func (in *GiteaProvider) DeepCopy() *GiteaProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(GiteaProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubApplyInput) DeepCopyInto(out *GithubApplyInput) {
 	*out = *in
@@ -1271,7 +1470,7 @@ func (in *Pipeline) DeepCopyInto(out *Pipeline) {
 	out.Namespaced = in.Namespaced
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -1409,6 +1608,13 @@ func (in *PipelineExecutionList) DeepCopyObject() runtime.Object {
 func (in *PipelineExecutionSpec) DeepCopyInto(out *PipelineExecutionSpec) {
 	*out = *in
 	in.PipelineConfig.DeepCopyInto(&out.PipelineConfig)
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -1572,6 +1778,13 @@ func (in *PipelineSettingList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 	*out = *in
+	if in.CronTriggers != nil {
+		in, out := &in.CronTriggers, &out.CronTriggers
+		*out = make([]CronTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -2272,7 +2485,9 @@ func (in *StageStatus) DeepCopyInto(out *StageStatus) {
 	if in.Steps != nil {
 		in, out := &in.Steps, &out.Steps
 		*out = make([]StepStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -2337,6 +2552,31 @@ func (in *Step) DeepCopyInto(out *Step) {
 		*out = new(Constraints)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(CacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = new(ArtifactsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -2353,6 +2593,13 @@ func (in *Step) DeepCopy() *Step {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepStatus) DeepCopyInto(out *StepStatus) {
 	*out = *in
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
 	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/endpoints/request"
 )
 
@@ -18,6 +19,15 @@ const (
 	contentTypeJSON = "application/json"
 )
 
+// Attributes of the user of a request, forwarded as extra impersonation attributes to the clusters the request is
+// proxied to
+const (
+	ExtraAuditID      = "rancher-audit-id"
+	ExtraTokenName    = "rancher-token-name"
+	ExtraAuthProvider = "rancher-auth-provider"
+	ExtraPrincipalID  = "rancher-principal-id"
+)
+
 const (
 	levelNull = iota
 	levelMetadata
@@ -49,13 +59,18 @@ type log struct {
 	RequestTimestamp  string       `json:"requestTimestamp,omitempty"`
 	ResponseTimestamp string       `json:"responseTimestamp,omitempty"`
 	ResponseCode      int          `json:"responseCode,omitempty"`
+	ClusterID         string       `json:"clusterID,omitempty"`
+	Resource          *Resource    `json:"resource,omitempty"`
 	RequestHeader     http.Header  `json:"requestHeader,omitempty"`
 	ResponseHeader    http.Header  `json:"responseHeader,omitempty"`
 	RequestBody       []byte       `json:"requestBody,omitempty"`
 	ResponseBody      []byte       `json:"responseBody,omitempty"`
 }
 
-var userKey struct{}
+var (
+	userKey struct{}
+	logKey  = struct{ name string }{"auditLog"}
+)
 
 type User struct {
 	Name  string   `json:"name,omitempty"`
@@ -64,21 +79,65 @@ type User struct {
 	RequestUser string `json:"requestUser,omitempty"`
 	// RequestGroups is the --as-group list
 	RequestGroups []string `json:"requestGroups,omitempty"`
+	// TokenName is the token the user authenticated with
+	TokenName string `json:"tokenName,omitempty"`
+	// AuthProvider is the provider the user authenticated with
+	AuthProvider string `json:"authProvider,omitempty"`
+}
+
+// Resource is the kubernetes resource targeted by a request proxied to a cluster
+type Resource struct {
+	Verb        string `json:"verb,omitempty"`
+	APIGroup    string `json:"apiGroup,omitempty"`
+	APIVersion  string `json:"apiVersion,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
 }
 
 func getUserInfo(req *http.Request) *User {
 	user, _ := request.UserFrom(req.Context())
 	return &User{
-		Name:  user.GetName(),
-		Group: user.GetGroups(),
+		Name:         user.GetName(),
+		Group:        user.GetGroups(),
+		TokenName:    firstExtra(user, ExtraTokenName),
+		AuthProvider: firstExtra(user, ExtraAuthProvider),
 	}
 }
 
+func firstExtra(user user.Info, key string) string {
+	if user == nil {
+		return ""
+	}
+	if values := user.GetExtra()[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
 func FromContext(ctx context.Context) (*User, bool) {
 	u, ok := ctx.Value(userKey).(*User)
 	return u, ok
 }
 
+// IDFromContext returns the audit ID of the request
+func IDFromContext(ctx context.Context) (string, bool) {
+	l, ok := ctx.Value(logKey).(*log)
+	if !ok {
+		return "", false
+	}
+	return string(l.AuditID), true
+}
+
+// SetProxyTarget records the cluster and the resource targeted by a request proxied to a cluster in its audit log
+func SetProxyTarget(ctx context.Context, clusterID string, resource *Resource) {
+	if l, ok := ctx.Value(logKey).(*log); ok {
+		l.ClusterID = clusterID
+		l.Resource = resource
+	}
+}
+
 func newAuditLog(writer *LogWriter, req *http.Request) (*auditLog, error) {
 	auditLog := &auditLog{
 		writer: writer,
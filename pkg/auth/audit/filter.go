@@ -35,15 +35,16 @@ func (h auditHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	user := getUserInfo(req)
 
-	context := context.WithValue(req.Context(), userKey, user)
-	req = req.WithContext(context)
-
 	auditLog, err := newAuditLog(h.auditWriter, req)
 	if err != nil {
 		util.ReturnHTTPError(rw, req, 500, err.Error())
 		return
 	}
 
+	ctx := context.WithValue(req.Context(), userKey, user)
+	ctx = context.WithValue(ctx, logKey, auditLog.log)
+	req = req.WithContext(ctx)
+
 	wr := &wrapWriter{ResponseWriter: rw, auditWriter: h.auditWriter, statusCode: http.StatusOK}
 	h.next.ServeHTTP(wr, req)
 
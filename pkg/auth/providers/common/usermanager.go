@@ -227,16 +227,35 @@ func (m *userManager) EnsureToken(tokenName, description, kind, userName string)
 	return m.EnsureClusterToken("", tokenName, description, kind, userName)
 }
 
+// creates tokens with the kubeconfig token ttl of the cluster, 0 when the cluster doesn't limit it, and regenerates them
+// if they expired or outlive that ttl
 func (m *userManager) EnsureClusterToken(clusterName, tokenName, description, kind, userName string) (string, error) {
 	if strings.HasPrefix(tokenName, "token-") {
 		return "", errors.New("token names can't start with token-")
 	}
 
+	clusterTTL, err := m.clusterTokenTTL(clusterName)
+	if err != nil {
+		return "", err
+	}
+
 	token, err := m.tokenLister.Get("", tokenName)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return "", err
 	}
 
+	if token != nil && clusterTTL > 0 && (tokenUtil.IsExpired(*token) || tokenUtil.ExceedsTTL(token, clusterTTL)) {
+		logrus.Debugf("ensureClusterToken: regenerating token %s for the kubeconfig token ttl of cluster %s", tokenName, clusterName)
+		err := m.tokens.Delete(tokenName, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		token, err = m.newTokenForKubeconfig(clusterName, tokenName, description, kind, userName, clusterTTL, false)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	if token == nil {
 		key, err := randomtoken.Generate()
 		if err != nil {
@@ -251,7 +270,7 @@ func (m *userManager) EnsureClusterToken(clusterName, tokenName, description, ki
 					tokens.TokenKindLabel: kind,
 				},
 			},
-			TTLMillis:    0,
+			TTLMillis:    clusterTTL.Milliseconds(),
 			Description:  description,
 			UserID:       userName,
 			AuthProvider: "local",
@@ -278,6 +297,102 @@ func (m *userManager) EnsureClusterToken(clusterName, tokenName, description, ki
 	return token.Name + ":" + token.Token, nil
 }
 
+// creates tokens scoped to several clusters with the shortest kubeconfig token ttl of the clusters, regenerates them if
+// they expired or outlive that ttl and updates their clusters
+func (m *userManager) EnsureClustersToken(clusterNames []string, tokenName, description, kind, userName string) (string, error) {
+	if strings.HasPrefix(tokenName, "token-") {
+		return "", errors.New("token names can't start with token-")
+	}
+	if len(clusterNames) == 0 {
+		return "", errors.New("token must be scoped to at least one cluster")
+	}
+
+	var ttl time.Duration
+	for _, clusterName := range clusterNames {
+		clusterTTL, err := m.clusterTokenTTL(clusterName)
+		if err != nil {
+			return "", err
+		}
+		if clusterTTL > 0 && (ttl == 0 || clusterTTL < ttl) {
+			ttl = clusterTTL
+		}
+	}
+	clusters := strings.Join(clusterNames, ",")
+
+	token, err := m.tokenLister.Get("", tokenName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+
+	if token != nil && (tokenUtil.IsExpired(*token) || tokenUtil.ExceedsTTL(token, ttl)) {
+		logrus.Debugf("ensureClustersToken: deleting expired token %s", tokenName)
+		err := m.tokens.Delete(tokenName, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		token = nil
+	}
+
+	if token == nil {
+		key, err := randomtoken.Generate()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate token key")
+		}
+
+		token = &v3.Token{
+			ObjectMeta: v1.ObjectMeta{
+				Name: tokenName,
+				Labels: map[string]string{
+					tokens.UserIDLabel:    userName,
+					tokens.TokenKindLabel: kind,
+				},
+				Annotations: map[string]string{
+					tokens.TokenClustersAnnotation: clusters,
+				},
+			},
+			TTLMillis:    ttl.Milliseconds(),
+			Description:  description,
+			UserID:       userName,
+			AuthProvider: "local",
+			IsDerived:    true,
+			Token:        key,
+		}
+
+		logrus.Infof("Creating token for user %v", userName)
+		err = wait.ExponentialBackoff(backoff, func() (bool, error) {
+			createdToken, err := m.tokens.Create(token)
+			if apierrors.IsAlreadyExists(err) {
+				// the expired token is still being deleted
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			token = createdToken
+			return true, nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if token.Annotations[tokens.TokenClustersAnnotation] != clusters || (token.TTLMillis != 0 && token.ExpiresAt == "") {
+		token = token.DeepCopy()
+		if token.Annotations == nil {
+			token.Annotations = map[string]string{}
+		}
+		token.Annotations[tokens.TokenClustersAnnotation] = clusters
+		// SetTokenExpiresAt requires creationTS, so can only be set post create
+		tokenUtil.SetTokenExpiresAt(token)
+		token, err = m.tokens.Update(token)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return token.Name + ":" + token.Token, nil
+}
+
 func (m *userManager) newTokenForKubeconfig(clusterName, tokenName, description, kind, userName string, ttl time.Duration, useExisting bool) (*v3.Token, error) {
 	key, err := randomtoken.Generate()
 	if err != nil {
@@ -350,6 +465,14 @@ func (m *userManager) GetKubeconfigToken(clusterName, tokenName, description, ki
 		return nil, fmt.Errorf("failed to parse setting [%s]: %v", settings.KubeconfigTokenTTLMinutes.Name, err)
 	}
 
+	clusterTTL, err := m.clusterTokenTTL(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if clusterTTL > 0 && (tokenTTL == 0 || clusterTTL < tokenTTL) {
+		tokenTTL = clusterTTL
+	}
+
 	if token == nil {
 		createdToken, err := m.newTokenForKubeconfig(clusterName, tokenName, description, kind, userName, tokenTTL, true)
 		if err != nil {
@@ -358,7 +481,7 @@ func (m *userManager) GetKubeconfigToken(clusterName, tokenName, description, ki
 		token = createdToken
 	}
 
-	if token != nil && tokenUtil.IsExpired(*token) {
+	if token != nil && (tokenUtil.IsExpired(*token) || tokenUtil.ExceedsTTL(token, clusterTTL)) {
 		logrus.Debugf("getToken: deleting expired token %s", tokenName)
 		err := m.tokens.Delete(tokenName, &metav1.DeleteOptions{})
 		if err != nil && !apierrors.IsNotFound(err) {
@@ -415,6 +538,15 @@ func (m *userManager) GetKubeconfigToken(clusterName, tokenName, description, ki
 	return token, nil
 }
 
+// clusterTokenTTL returns the kubeconfig token ttl of a cluster, 0 when the token isn't scoped to a cluster or the
+// cluster doesn't limit it
+func (m *userManager) clusterTokenTTL(clusterName string) (time.Duration, error) {
+	if clusterName == "" {
+		return 0, nil
+	}
+	return tokenUtil.ClusterKubeconfigTokenTTL(clusterName)
+}
+
 func (m *userManager) EnsureUser(principalName, displayName string) (*v3.User, error) {
 	var user *v3.User
 	var err error
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3/fakes"
+	"github.com/rancher/rancher/pkg/settings"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTokenManager returns a user manager storing its tokens in a map
+func newTokenManager(tokens map[string]*v3.Token) *userManager {
+	get := func(namespace, name string) (*v3.Token, error) {
+		if token, ok := tokens[name]; ok {
+			return token.DeepCopy(), nil
+		}
+		return nil, apierrors.NewNotFound(v3.TokenGroupVersionResource.GroupResource(), name)
+	}
+	return &userManager{
+		tokenLister: &fakes.TokenListerMock{
+			GetFunc: get,
+		},
+		tokens: &fakes.TokenInterfaceMock{
+			CreateFunc: func(token *v3.Token) (*v3.Token, error) {
+				if _, ok := tokens[token.Name]; ok {
+					return nil, apierrors.NewAlreadyExists(v3.TokenGroupVersionResource.GroupResource(), token.Name)
+				}
+				token = token.DeepCopy()
+				token.CreationTimestamp = metav1.Now()
+				tokens[token.Name] = token
+				return token.DeepCopy(), nil
+			},
+			UpdateFunc: func(token *v3.Token) (*v3.Token, error) {
+				tokens[token.Name] = token.DeepCopy()
+				return token, nil
+			},
+			GetFunc: func(name string, opts metav1.GetOptions) (*v3.Token, error) {
+				return get("", name)
+			},
+			DeleteFunc: func(name string, options *metav1.DeleteOptions) error {
+				delete(tokens, name)
+				return nil
+			},
+		},
+	}
+}
+
+func TestEnsureClusterToken(t *testing.T) {
+	defer settings.KubeconfigClusterTokenTTLMinutes.Set(settings.KubeconfigClusterTokenTTLMinutes.Get())
+	assert.Nil(t, settings.KubeconfigClusterTokenTTLMinutes.Set(`{"c-limited": 60}`))
+
+	tokens := map[string]*v3.Token{}
+	m := newTokenManager(tokens)
+
+	value, err := m.EnsureClusterToken("c-unlimited", "kubeconfig-unlimited", "", "kubeconfig", "u-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "kubeconfig-unlimited:"+tokens["kubeconfig-unlimited"].Token, value)
+	assert.Equal(t, int64(0), tokens["kubeconfig-unlimited"].TTLMillis)
+
+	_, err = m.EnsureClusterToken("c-limited", "kubeconfig-limited", "", "kubeconfig", "u-1")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Hour.Milliseconds(), tokens["kubeconfig-limited"].TTLMillis)
+	assert.Equal(t, "c-limited", tokens["kubeconfig-limited"].ClusterName)
+
+	// the existing token is kept while it doesn't outlive the ttl of the cluster
+	value, err = m.EnsureClusterToken("c-limited", "kubeconfig-limited", "", "kubeconfig", "u-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "kubeconfig-limited:"+tokens["kubeconfig-limited"].Token, value)
+
+	// a token outliving the ttl of the cluster is regenerated
+	tokens["kubeconfig-old"] = &v3.Token{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubeconfig-old", CreationTimestamp: metav1.Now()},
+		Token:      "old",
+	}
+	value, err = m.EnsureClusterToken("c-limited", "kubeconfig-old", "", "kubeconfig", "u-1")
+	assert.Nil(t, err)
+	assert.NotEqual(t, "kubeconfig-old:old", value)
+	assert.Equal(t, time.Hour.Milliseconds(), tokens["kubeconfig-old"].TTLMillis)
+
+	_, err = m.EnsureClusterToken("", "token-1", "", "kubeconfig", "u-1")
+	assert.NotNil(t, err)
+}
+
+func TestGetKubeconfigToken(t *testing.T) {
+	defer settings.KubeconfigClusterTokenTTLMinutes.Set(settings.KubeconfigClusterTokenTTLMinutes.Get())
+	defer settings.KubeconfigTokenTTLMinutes.Set(settings.KubeconfigTokenTTLMinutes.Get())
+	assert.Nil(t, settings.KubeconfigClusterTokenTTLMinutes.Set(`{"c-limited": 60, "c-long": 1440}`))
+	assert.Nil(t, settings.KubeconfigTokenTTLMinutes.Set("120"))
+
+	tests := []struct {
+		name    string
+		cluster string
+		ttl     time.Duration
+	}{
+		{
+			name: "not scoped to a cluster",
+			ttl:  2 * time.Hour,
+		},
+		{
+			name:    "cluster without ttl",
+			cluster: "c-unlimited",
+			ttl:     2 * time.Hour,
+		},
+		{
+			name:    "cluster with shorter ttl",
+			cluster: "c-limited",
+			ttl:     time.Hour,
+		},
+		{
+			name:    "cluster with longer ttl",
+			cluster: "c-long",
+			ttl:     2 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		tokens := map[string]*v3.Token{}
+		m := newTokenManager(tokens)
+		token, err := m.GetKubeconfigToken(tt.cluster, "kubeconfig-u-1", "", "kubeconfig", "u-1")
+		if !assert.Nil(t, err, tt.name) {
+			continue
+		}
+		assert.Equal(t, tt.ttl.Milliseconds(), token.TTLMillis, tt.name)
+		assert.NotEmpty(t, token.ExpiresAt, tt.name)
+		assert.Equal(t, tokens["kubeconfig-u-1"], token, tt.name)
+	}
+
+	// a token outliving the ttl of the cluster is regenerated
+	tokens := map[string]*v3.Token{
+		"kubeconfig-u-1": {
+			ObjectMeta: metav1.ObjectMeta{Name: "kubeconfig-u-1", CreationTimestamp: metav1.Now()},
+			TTLMillis:  (2 * time.Hour).Milliseconds(),
+			Token:      "old",
+		},
+	}
+	token, err := newTokenManager(tokens).GetKubeconfigToken("c-limited", "kubeconfig-u-1", "", "kubeconfig", "u-1")
+	assert.Nil(t, err)
+	assert.NotEqual(t, "old", token.Token)
+	assert.Equal(t, time.Hour.Milliseconds(), token.TTLMillis)
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types/slice"
+	"github.com/rancher/rancher/pkg/auth/audit"
 	"github.com/rancher/rancher/pkg/auth/providerrefresh"
 	"github.com/rancher/rancher/pkg/auth/tokens"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
@@ -23,18 +25,29 @@ var (
 )
 
 type Authenticator interface {
-	Authenticate(req *http.Request) (authed bool, user string, groups []string, err error)
+	Authenticate(req *http.Request) (authed bool, user string, groups []string, token *v3.Token, err error)
 	TokenFromRequest(req *http.Request) (*v3.Token, error)
 }
 
 func ToAuthMiddleware(a Authenticator) auth.Middleware {
 	f := func(req *http.Request) (user.Info, bool, error) {
-		authed, u, groups, err := a.Authenticate(req)
-		return &user.DefaultInfo{
+		authed, u, groups, token, err := a.Authenticate(req)
+		info := &user.DefaultInfo{
 			Name:   u,
 			UID:    u,
 			Groups: groups,
-		}, authed, err
+		}
+		if authed && token != nil {
+			// the token and provider of the user are forwarded to the clusters for their audit logs
+			info.Extra = map[string][]string{
+				audit.ExtraTokenName:    {token.Name},
+				audit.ExtraAuthProvider: {token.AuthProvider},
+			}
+			if token.UserPrincipal.Name != "" {
+				info.Extra[audit.ExtraPrincipalID] = []string{token.UserPrincipal.Name}
+			}
+		}
+		return info, authed, err
 	}
 	return auth.ToMiddleware(auth.AuthenticatorFunc(f))
 }
@@ -81,31 +94,34 @@ func tokenKeyIndexer(obj interface{}) ([]string, error) {
 	return []string{token.Token}, nil
 }
 
-func (a *tokenAuthenticator) Authenticate(req *http.Request) (bool, string, []string, error) {
+func (a *tokenAuthenticator) Authenticate(req *http.Request) (bool, string, []string, *v3.Token, error) {
 	token, err := a.TokenFromRequest(req)
 	if err != nil {
-		return false, "", []string{}, err
+		return false, "", []string{}, nil, err
 	}
 
 	if token.Enabled != nil && !*token.Enabled {
-		return false, "", []string{}, errors.Wrapf(ErrMustAuthenticate, "user's token is not enabled")
+		return false, "", []string{}, nil, errors.Wrapf(ErrMustAuthenticate, "user's token is not enabled")
 	}
 	if token.ClusterName != "" && token.ClusterName != a.clusterRouter(req) {
-		return false, "", []string{}, errors.Wrapf(ErrMustAuthenticate, "clusterID does not match")
+		return false, "", []string{}, nil, errors.Wrapf(ErrMustAuthenticate, "clusterID does not match")
+	}
+	if clusters := tokens.TokenClusters(token); clusters != nil && !slice.ContainsString(clusters, a.clusterRouter(req)) {
+		return false, "", []string{}, nil, errors.Wrapf(ErrMustAuthenticate, "clusterID does not match")
 	}
 
 	attribs, err := a.userAttributeLister.Get("", token.UserID)
 	if err != nil && !apierrors.IsNotFound(err) {
-		return false, "", []string{}, err
+		return false, "", []string{}, nil, err
 	}
 
 	u, err := a.userLister.Get("", token.UserID)
 	if err != nil {
-		return false, "", []string{}, err
+		return false, "", []string{}, nil, err
 	}
 
 	if u.Enabled != nil && !*u.Enabled {
-		return false, "", []string{}, errors.Wrap(ErrMustAuthenticate, "user is not enabled")
+		return false, "", []string{}, nil, errors.Wrap(ErrMustAuthenticate, "user is not enabled")
 	}
 
 	var groups []string
@@ -137,7 +153,7 @@ func (a *tokenAuthenticator) Authenticate(req *http.Request) (bool, string, []st
 		go a.userAuthRefresher.TriggerUserRefresh(token.UserID, false)
 	}
 
-	return true, token.UserID, groups, nil
+	return true, token.UserID, groups, token, nil
 }
 
 func (a *tokenAuthenticator) TokenFromRequest(req *http.Request) (*v3.Token, error) {
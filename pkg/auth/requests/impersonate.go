@@ -29,6 +29,7 @@ func (h *impersonatingAuth) Authenticate(req *http.Request) (k8sUser.Info, bool,
 	}
 	user := userInfo.GetName()
 	groups := userInfo.GetGroups()
+	extra := userInfo.GetExtra()
 
 	var impersonateUser bool
 	var impersonateGroup bool
@@ -71,7 +72,9 @@ func (h *impersonatingAuth) Authenticate(req *http.Request) (k8sUser.Info, bool,
 
 	if impersonateUser || impersonateGroup {
 		if impersonateUser {
+			// the extra attributes describe the authenticated user, not the impersonated one
 			user = reqUser
+			extra = nil
 		}
 		if impersonateGroup {
 			groups = reqGroup
@@ -85,6 +88,7 @@ func (h *impersonatingAuth) Authenticate(req *http.Request) (k8sUser.Info, bool,
 		Name:   user,
 		UID:    user,
 		Groups: groups,
+		Extra:  extra,
 	}, true, nil
 }
 
@@ -39,6 +39,8 @@ const (
 	secretNameEnding       = "-secret"
 	secretNamespace        = "cattle-system"
 	KubeconfigResponseType = "kubeconfig"
+	// TokenClustersAnnotation scopes a token to a comma separated list of clusters
+	TokenClustersAnnotation = "authn.management.cattle.io/token-clusters"
 )
 
 var (
@@ -2,6 +2,7 @@ package tokens
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/types/convert"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/settings"
 	"github.com/rancher/rancher/pkg/user"
 	"github.com/sirupsen/logrus"
 )
@@ -57,6 +59,51 @@ func IsExpired(token v3.Token) bool {
 	return durationElapsed.Seconds() >= ttlDuration.Seconds()
 }
 
+// ParseClusterTokenTTLs parses the kubeconfig-cluster-token-ttl-minutes setting, a JSON object limiting the lifetime
+// in minutes of the kubeconfig tokens of the clusters by cluster ID, e.g. {"c-abcde": 60}
+func ParseClusterTokenTTLs(value string) (map[string]time.Duration, error) {
+	ttls := map[string]time.Duration{}
+	if value == "" {
+		return ttls, nil
+	}
+	var minutes map[string]int64
+	if err := json.Unmarshal([]byte(value), &minutes); err != nil {
+		return nil, fmt.Errorf("invalid cluster token ttls: %v", err)
+	}
+	for cluster, ttl := range minutes {
+		if ttl < 0 {
+			return nil, fmt.Errorf("invalid token ttl of cluster [%s]: ttl can't be negative", cluster)
+		}
+		ttls[cluster] = time.Duration(ttl) * time.Minute
+	}
+	return ttls, nil
+}
+
+// ClusterKubeconfigTokenTTL returns the lifetime of the kubeconfig tokens of a cluster set by the admins in the
+// kubeconfig-cluster-token-ttl-minutes setting, 0 when it is not limited
+func ClusterKubeconfigTokenTTL(clusterName string) (time.Duration, error) {
+	ttls, err := ParseClusterTokenTTLs(settings.KubeconfigClusterTokenTTLMinutes.Get())
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse setting [%s]: %v", settings.KubeconfigClusterTokenTTLMinutes.Name, err)
+	}
+	return ttls[clusterName], nil
+}
+
+// ExceedsTTL returns whether a token lives longer than a ttl, a ttl of 0 does not limit tokens
+func ExceedsTTL(token *v3.Token, ttl time.Duration) bool {
+	return ttl > 0 && (token.TTLMillis == 0 || token.TTLMillis > ttl.Milliseconds())
+}
+
+// TokenClusters returns the clusters of a token scoped to several clusters by its TokenClustersAnnotation, nil for
+// other tokens
+func TokenClusters(token *v3.Token) []string {
+	value := token.Annotations[TokenClustersAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
 func GetTokenAuthFromRequest(req *http.Request) string {
 	var tokenAuthValue string
 	authHeader := req.Header.Get(AuthHeaderName)
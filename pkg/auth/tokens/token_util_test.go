@@ -0,0 +1,69 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClusterTokenTTLs(t *testing.T) {
+	assert := assert.New(t)
+
+	ttls, err := ParseClusterTokenTTLs("")
+	assert.Nil(err)
+	assert.Empty(ttls)
+
+	ttls, err = ParseClusterTokenTTLs(`{"c-1": 60, "c-2": 0}`)
+	assert.Nil(err)
+	assert.Equal(map[string]time.Duration{"c-1": time.Hour, "c-2": 0}, ttls)
+
+	_, err = ParseClusterTokenTTLs(`{"c-1": "an hour"}`)
+	assert.NotNil(err)
+
+	_, err = ParseClusterTokenTTLs(`{"c-1": -5}`)
+	assert.NotNil(err)
+}
+
+func TestClusterKubeconfigTokenTTL(t *testing.T) {
+	assert := assert.New(t)
+	defer settings.KubeconfigClusterTokenTTLMinutes.Set(settings.KubeconfigClusterTokenTTLMinutes.Get())
+
+	assert.Nil(settings.KubeconfigClusterTokenTTLMinutes.Set(`{"c-1": 60}`))
+	ttl, err := ClusterKubeconfigTokenTTL("c-1")
+	assert.Nil(err)
+	assert.Equal(time.Hour, ttl)
+
+	ttl, err = ClusterKubeconfigTokenTTL("c-2")
+	assert.Nil(err)
+	assert.Equal(time.Duration(0), ttl)
+
+	assert.Nil(settings.KubeconfigClusterTokenTTLMinutes.Set("{"))
+	_, err = ClusterKubeconfigTokenTTL("c-1")
+	assert.NotNil(err)
+}
+
+func TestExceedsTTL(t *testing.T) {
+	assert := assert.New(t)
+
+	token := &v3.Token{}
+	assert.False(ExceedsTTL(token, 0))
+	assert.True(ExceedsTTL(token, time.Hour))
+
+	token.TTLMillis = time.Hour.Milliseconds()
+	assert.False(ExceedsTTL(token, time.Hour))
+	assert.False(ExceedsTTL(token, 2*time.Hour))
+	assert.True(ExceedsTTL(token, time.Minute))
+}
+
+func TestTokenClusters(t *testing.T) {
+	assert := assert.New(t)
+
+	token := &v3.Token{}
+	assert.Nil(TokenClusters(token))
+
+	token.Annotations = map[string]string{TokenClustersAnnotation: "c-1,c-2"}
+	assert.Equal([]string{"c-1", "c-2"}, TokenClusters(token))
+}
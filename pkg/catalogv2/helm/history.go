@@ -0,0 +1,49 @@
+package helm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	v1 "github.com/rancher/rancher/pkg/apis/catalog.cattle.io/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// History returns all revisions of the named release found in objs, newest first. Objects that
+// are not helm releases or belong to a different release are ignored.
+func History(name string, objs []runtime.Object) ([]*v1.ReleaseSpec, error) {
+	var result []*v1.ReleaseSpec
+	for _, obj := range objs {
+		spec, err := ToRelease(obj, nil)
+		if err == ErrNotHelmRelease {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if spec.Name != name {
+			continue
+		}
+		result = append(result, spec)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version > result[j].Version
+	})
+
+	return result, nil
+}
+
+// ValuesDigest returns a stable sha256 digest of the user supplied values of a release
+func ValuesDigest(values map[string]interface{}) (string, error) {
+	if len(values) == 0 {
+		values = map[string]interface{}{}
+	}
+	// encoding/json sorts map keys so the output is stable
+	bytes, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(bytes)
+	return hex.EncodeToString(digest[:]), nil
+}
@@ -24,6 +24,7 @@ func InfoFromTarball(input io.Reader) (*types.ChartInfo, error) {
 	result := &types.ChartInfo{
 		Values:    map[string]interface{}{},
 		Questions: map[string]interface{}{},
+		Schema:    map[string]interface{}{},
 		Chart:     map[string]interface{}{},
 	}
 
@@ -57,6 +58,10 @@ func InfoFromTarball(input io.Reader) (*types.ChartInfo, error) {
 			if err := decodeYAML(tarball, &result.Questions); err != nil {
 				return nil, err
 			}
+		case "values.schema.json":
+			if err := decodeYAML(tarball, &result.Schema); err != nil {
+				return nil, err
+			}
 		case "chart.yml":
 			fallthrough
 		case "chart.yaml":
@@ -0,0 +1,407 @@
+package helm
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/rancher/pkg/api/steve/catalog/types"
+	v3 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	"github.com/rancher/wrangler/pkg/data/convert"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v2"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	ErrCodeMissingRequired = "MissingRequired"
+	ErrCodeInvalidType     = "InvalidType"
+	ErrCodeInvalidOption   = "InvalidOption"
+	ErrCodeMinExceeded     = "MinLimitExceeded"
+	ErrCodeMaxExceeded     = "MaxLimitExceeded"
+	ErrCodeInvalidChars    = "InvalidCharacters"
+	ErrCodeSchema          = "SchemaViolation"
+)
+
+type questionsFile struct {
+	Questions []v3.Question `yaml:"questions,omitempty"`
+}
+
+type chartValidationFiles struct {
+	values    map[string]interface{}
+	schema    []byte
+	questions []v3.Question
+}
+
+// ValidateValues checks the user supplied values against the values.schema.json and questions.yaml
+// found in the chart tarball. The values are merged on top of the chart defaults and the values
+// helm reuses from the current release before validation, the same way helm does, and the defaults
+// of the questions fill in the variables that are still unset. Required questions must be answered by
+// the user supplied values or the values reused from the current release, the chart and question
+// defaults don't answer them. Validation failures are returned as field errors, err is only set if
+// the chart itself could not be read.
+func ValidateValues(chart io.Reader, current, values map[string]interface{}) ([]types.ChartValuesError, error) {
+	files, err := validationFilesFromTarball(chart)
+	if err != nil {
+		return nil, err
+	}
+
+	supplied := mergeValues(current, values)
+	merged := mergeValues(questionDefaults(files.questions), mergeValues(files.values, supplied))
+
+	var result []types.ChartValuesError
+	if len(files.schema) > 0 {
+		schemaErrors, err := validateSchema(files.schema, merged)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, schemaErrors...)
+	}
+
+	for _, q := range files.questions {
+		if !showIf(q.ShowIf, merged) {
+			continue
+		}
+		if err := validateRequired(q.Variable, q.Required, supplied); err != nil {
+			result = append(result, *err)
+			continue
+		}
+		value, ok := lookup(merged, q.Variable)
+		if err := validateQuestion(q.Variable, q.Type, q.Options, q.Min, q.Max, q.MinLength,
+			q.MaxLength, q.ValidChars, q.InvalidChars, value, ok); err != nil {
+			result = append(result, *err)
+			continue
+		}
+		if q.ShowSubquestionIf == "" || convert.ToString(value) != q.ShowSubquestionIf {
+			continue
+		}
+		for _, sub := range q.Subquestions {
+			if !showIf(sub.ShowIf, merged) {
+				continue
+			}
+			if err := validateRequired(sub.Variable, sub.Required, supplied); err != nil {
+				result = append(result, *err)
+				continue
+			}
+			value, ok := lookup(merged, sub.Variable)
+			if err := validateQuestion(sub.Variable, sub.Type, sub.Options, sub.Min, sub.Max, sub.MinLength,
+				sub.MaxLength, sub.ValidChars, sub.InvalidChars, value, ok); err != nil {
+				result = append(result, *err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func validationFilesFromTarball(input io.Reader) (*chartValidationFiles, error) {
+	result := &chartValidationFiles{
+		values: map[string]interface{}{},
+	}
+
+	gz, err := gzip.NewReader(input)
+	if err != nil {
+		return nil, err
+	}
+
+	tarball := tar.NewReader(gz)
+	for {
+		file, err := tarball.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		// only the top level chart is validated, subcharts are nested under charts/
+		parts := strings.SplitN(file.Name, "/", 2)
+		if len(parts) == 1 {
+			continue
+		}
+
+		switch strings.ToLower(parts[1]) {
+		case "values.yml":
+			fallthrough
+		case "values.yaml":
+			if err := decodeYAML(tarball, &result.values); err != nil {
+				return nil, err
+			}
+		case "values.schema.json":
+			result.schema, err = ioutil.ReadAll(tarball)
+			if err != nil {
+				return nil, err
+			}
+		case "questions.yml":
+			fallthrough
+		case "questions.yaml":
+			data, err := ioutil.ReadAll(tarball)
+			if err != nil {
+				return nil, err
+			}
+			questions := &questionsFile{}
+			if err := yaml.Unmarshal(data, questions); err != nil {
+				return nil, err
+			}
+			result.questions = questions.Questions
+		}
+	}
+
+	return result, nil
+}
+
+func validateSchema(schema []byte, values map[string]interface{}) ([]types.ChartValuesError, error) {
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	// values.schema.json may also be written in YAML, which is what helm accepts
+	schemaJSON, err := sigsyaml.YAMLToJSON(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schemaJSON), gojsonschema.NewBytesLoader(valuesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []types.ChartValuesError
+	for _, desc := range result.Errors() {
+		field := desc.Field()
+		if field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			field = ""
+		}
+		errs = append(errs, types.ChartValuesError{
+			Field:   field,
+			Code:    ErrCodeSchema,
+			Message: desc.Description(),
+		})
+	}
+	return errs, nil
+}
+
+// validateRequired checks that a required question is answered by the supplied values
+func validateRequired(variable string, required bool, supplied map[string]interface{}) *types.ChartValuesError {
+	if !required {
+		return nil
+	}
+	if value, ok := lookup(supplied, variable); ok && value != nil && value != "" {
+		return nil
+	}
+	return &types.ChartValuesError{
+		Field:   variable,
+		Code:    ErrCodeMissingRequired,
+		Message: fmt.Sprintf("%s is required", variable),
+	}
+}
+
+func validateQuestion(variable, questionType string, options []string, min, max, minLength, maxLength int,
+	validChars, invalidChars string, value interface{}, ok bool) *types.ChartValuesError {
+	newError := func(code, format string, args ...interface{}) *types.ChartValuesError {
+		return &types.ChartValuesError{
+			Field:   variable,
+			Code:    code,
+			Message: fmt.Sprintf(format, args...),
+		}
+	}
+
+	if !ok || value == nil || value == "" {
+		return nil
+	}
+
+	switch questionType {
+	case "int":
+		i, err := toInt(value)
+		if err != nil {
+			return newError(ErrCodeInvalidType, "%s must be an integer", variable)
+		}
+		if min != 0 && i < int64(min) {
+			return newError(ErrCodeMinExceeded, "%s must be at least %d", variable, min)
+		}
+		if max != 0 && i > int64(max) {
+			return newError(ErrCodeMaxExceeded, "%s must be at most %d", variable, max)
+		}
+		return nil
+	case "float":
+		if _, err := strconv.ParseFloat(convert.ToString(value), 64); err != nil {
+			return newError(ErrCodeInvalidType, "%s must be a number", variable)
+		}
+		return nil
+	case "boolean":
+		if _, err := strconv.ParseBool(convert.ToString(value)); err != nil {
+			return newError(ErrCodeInvalidType, "%s must be true or false", variable)
+		}
+		return nil
+	case "enum":
+		s := convert.ToString(value)
+		for _, option := range options {
+			if option == s {
+				return nil
+			}
+		}
+		return newError(ErrCodeInvalidOption, "%s must be one of %s", variable, strings.Join(options, ", "))
+	}
+
+	if strings.HasPrefix(questionType, "map[") || strings.HasPrefix(questionType, "array[") || questionType == "listofstrings" {
+		return nil
+	}
+
+	s, isString := value.(string)
+	if !isString {
+		return newError(ErrCodeInvalidType, "%s must be a string", variable)
+	}
+	if minLength != 0 && len(s) < minLength {
+		return newError(ErrCodeMinExceeded, "%s must be at least %d characters", variable, minLength)
+	}
+	if maxLength != 0 && len(s) > maxLength {
+		return newError(ErrCodeMaxExceeded, "%s must be at most %d characters", variable, maxLength)
+	}
+	if validChars != "" {
+		if re, err := regexp.Compile("^[" + validChars + "]*$"); err == nil && !re.MatchString(s) {
+			return newError(ErrCodeInvalidChars, "%s may only contain the characters %s", variable, validChars)
+		}
+	}
+	if invalidChars != "" {
+		if re, err := regexp.Compile("[" + invalidChars + "]"); err == nil && re.MatchString(s) {
+			return newError(ErrCodeInvalidChars, "%s may not contain the characters %s", variable, invalidChars)
+		}
+	}
+	return nil
+}
+
+func toInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case json.Number:
+		return v.Int64()
+	}
+	return strconv.ParseInt(convert.ToString(value), 10, 64)
+}
+
+// showIf evaluates the show_if expression of a question. The expression is a list of var=value
+// comparisons joined by && and ||, with && taking precedence.
+func showIf(expr string, values map[string]interface{}) bool {
+	if expr == "" {
+		return true
+	}
+	for _, or := range strings.Split(expr, "||") {
+		matched := true
+		for _, and := range strings.Split(or, "&&") {
+			parts := strings.SplitN(strings.TrimSpace(and), "=", 2)
+			if len(parts) != 2 {
+				matched = false
+				break
+			}
+			value, _ := lookup(values, parts[0])
+			if convert.ToString(value) != parts[1] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// questionDefaults returns the default values of the questions and subquestions, converted to the type of the question
+func questionDefaults(questions []v3.Question) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, q := range questions {
+		setDefault(result, q.Variable, q.Type, q.Default)
+		for _, sub := range q.Subquestions {
+			setDefault(result, sub.Variable, sub.Type, sub.Default)
+		}
+	}
+	return result
+}
+
+func setDefault(values map[string]interface{}, variable, questionType, value string) {
+	if value == "" {
+		return
+	}
+
+	var typed interface{} = value
+	switch questionType {
+	case "int":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			typed = i
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			typed = f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			typed = b
+		}
+	}
+
+	parts := strings.Split(variable, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := values[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			values[part] = next
+		}
+		values = next
+	}
+	values[parts[len(parts)-1]] = typed
+}
+
+func lookup(values map[string]interface{}, variable string) (interface{}, bool) {
+	var (
+		current interface{} = values
+		parts               = strings.Split(variable, ".")
+	)
+	for _, part := range parts {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// mergeValues returns a copy of defaults with overrides merged in recursively. A nil override
+// deletes the default value, same as helm.
+func mergeValues(defaults, overrides map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(defaults))
+	for k, v := range defaults {
+		result[k] = v
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		overrideMap, isMap := v.(map[string]interface{})
+		defaultMap, defaultIsMap := result[k].(map[string]interface{})
+		if isMap && defaultIsMap {
+			result[k] = mergeValues(defaultMap, overrideMap)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+package helm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/rancher/rancher/pkg/api/steve/catalog/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testValues = `
+replicas: 1
+ingress:
+  enabled: false
+  host: ""
+`
+	testSchema = `{
+  "type": "object",
+  "properties": {
+    "replicas": {"type": "integer", "minimum": 1},
+    "service": {
+      "type": "object",
+      "properties": {"port": {"type": "integer"}}
+    }
+  }
+}`
+	testQuestions = `
+questions:
+- variable: replicas
+  type: int
+  min: 1
+  max: 5
+- variable: ingress.enabled
+  type: boolean
+  show_subquestion_if: true
+  subquestions:
+  - variable: ingress.host
+    type: hostname
+    required: true
+- variable: mode
+  type: enum
+  options: [a, b]
+  show_if: ingress.enabled=true
+- variable: service.port
+  type: int
+  default: "80"
+  required: true
+`
+)
+
+func testChart(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"test/values.yaml":        testValues,
+		"test/values.schema.json": testSchema,
+		"test/questions.yaml":     testQuestions,
+	} {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestValidateValues(t *testing.T) {
+	chart := testChart(t)
+	// service.port is a required question, its default does not answer it
+	port := map[string]interface{}{"service": map[string]interface{}{"port": float64(80)}}
+	tests := []struct {
+		name     string
+		current  map[string]interface{}
+		values   map[string]interface{}
+		expected []types.ChartValuesError
+	}{
+		{
+			name:   "chart and question defaults are valid",
+			values: port,
+		},
+		{
+			name:   "question defaults don't answer required questions",
+			values: nil,
+			expected: []types.ChartValuesError{
+				{Field: "service.port", Code: ErrCodeMissingRequired, Message: "service.port is required"},
+			},
+		},
+		{
+			name:   "schema violation",
+			values: mergeValues(port, map[string]interface{}{"replicas": "two"}),
+			expected: []types.ChartValuesError{
+				{Field: "replicas", Code: ErrCodeSchema, Message: "Invalid type. Expected: integer, given: string"},
+				{Field: "replicas", Code: ErrCodeInvalidType, Message: "replicas must be an integer"},
+			},
+		},
+		{
+			name:   "question max",
+			values: mergeValues(port, map[string]interface{}{"replicas": float64(10)}),
+			expected: []types.ChartValuesError{
+				{Field: "replicas", Code: ErrCodeMaxExceeded, Message: "replicas must be at most 5"},
+			},
+		},
+		{
+			name: "required subquestion and shown enum",
+			values: mergeValues(port, map[string]interface{}{
+				"ingress": map[string]interface{}{"enabled": true},
+				"mode":    "c",
+			}),
+			expected: []types.ChartValuesError{
+				{Field: "ingress.host", Code: ErrCodeMissingRequired, Message: "ingress.host is required"},
+				{Field: "mode", Code: ErrCodeInvalidOption, Message: "mode must be one of a, b"},
+			},
+		},
+		{
+			name: "current release values are reused",
+			current: mergeValues(port, map[string]interface{}{
+				"ingress": map[string]interface{}{"enabled": true, "host": "example.com"},
+			}),
+			values: map[string]interface{}{
+				"mode": "a",
+			},
+		},
+		{
+			name: "hidden enum is ignored",
+			values: mergeValues(port, map[string]interface{}{
+				"mode": "c",
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := ValidateValues(bytes.NewReader(chart), tt.current, tt.values)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, errs)
+		})
+	}
+}
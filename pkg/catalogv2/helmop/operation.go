@@ -14,15 +14,18 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
+	"github.com/rancher/apiserver/pkg/apierror"
 	"github.com/rancher/apiserver/pkg/types"
 	types2 "github.com/rancher/rancher/pkg/api/steve/catalog/types"
 	catalog "github.com/rancher/rancher/pkg/apis/catalog.cattle.io/v1"
 	v3 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/catalogv2/content"
+	"github.com/rancher/rancher/pkg/catalogv2/helm"
 	catalogcontrollers "github.com/rancher/rancher/pkg/generated/controllers/catalog.cattle.io/v1"
 	namespaces "github.com/rancher/rancher/pkg/namespace"
 	"github.com/rancher/rancher/pkg/settings"
@@ -34,7 +37,9 @@ import (
 	"github.com/rancher/wrangler/pkg/schemas/validation"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/kubernetes"
@@ -138,6 +143,118 @@ func (s *Operations) Install(ctx context.Context, user user.Info, namespace, nam
 	return s.createOperation(ctx, user, status, cmds, imageOverride)
 }
 
+func (s *Operations) Rollback(ctx context.Context, user user.Info, namespace, name string, options io.Reader, imageOverride string) (*catalog.Operation, error) {
+	status, cmds, err := s.getRollbackArgs(ctx, namespace, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err = s.getUser(user, namespace, name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createOperation(ctx, user, status, cmds, imageOverride)
+}
+
+// History lists the revisions of the release backing the given app, for helm 3 releases and helm 2 releases stored by
+// Tiller alongside the app. The release objects are read with the credentials of the requesting user so the same RBAC
+// applies as running helm history directly.
+func (s *Operations) History(ctx context.Context, namespace, name string) (*types2.ChartHistory, error) {
+	rel, err := s.apps.Get(namespace, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	releases, _, err := s.releaseHistory(ctx, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &types2.ChartHistory{
+		ReleaseName:      rel.Spec.Name,
+		ReleaseNamespace: rel.Namespace,
+	}
+	for _, release := range releases {
+		revision := types2.ChartRevision{
+			Revision: release.Version,
+		}
+		if release.Chart != nil && release.Chart.Metadata != nil {
+			revision.ChartName = release.Chart.Metadata.Name
+			revision.ChartVersion = release.Chart.Metadata.Version
+			revision.AppVersion = release.Chart.Metadata.AppVersion
+		}
+		if release.Info != nil {
+			revision.Status = string(release.Info.Status)
+			revision.Description = release.Info.Description
+			revision.Updated = release.Info.LastDeployed
+		}
+		revision.ValuesDigest, err = helm.ValuesDigest(release.Values)
+		if err != nil {
+			return nil, err
+		}
+		history.Revisions = append(history.Revisions, revision)
+	}
+
+	return history, nil
+}
+
+// releaseHistory returns the revisions of the release of the app, newest first, and whether it is a helm 2 release
+func (s *Operations) releaseHistory(ctx context.Context, rel *catalog.App) ([]*catalog.ReleaseSpec, bool, error) {
+	client, err := s.cg.K8sInterface(types.GetAPIContext(ctx))
+	if err != nil {
+		return nil, false, err
+	}
+
+	helm3Selector := labels.SelectorFromSet(labels.Set{
+		"owner": "helm",
+		"name":  rel.Spec.Name,
+	}).String()
+	helm2Selector := labels.SelectorFromSet(labels.Set{
+		"OWNER": "TILLER",
+		"NAME":  rel.Spec.Name,
+	}).String()
+
+	secrets, err := client.CoreV1().Secrets(rel.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: helm3Selector,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	objs := make([]runtime.Object, 0, len(secrets.Items))
+	for i := range secrets.Items {
+		objs = append(objs, &secrets.Items[i])
+	}
+
+	helm2 := false
+	if len(objs) == 0 {
+		helm2Secrets, err := client.CoreV1().Secrets(rel.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: helm2Selector,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		for i := range helm2Secrets.Items {
+			objs = append(objs, &helm2Secrets.Items[i])
+		}
+
+		configMaps, err := client.CoreV1().ConfigMaps(rel.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: helm2Selector,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		for i := range configMaps.Items {
+			objs = append(objs, &configMaps.Items[i])
+		}
+		helm2 = len(objs) > 0
+	}
+
+	releases, err := helm.History(rel.Spec.Name, objs)
+	return releases, helm2, err
+}
+
 func decodeParams(req *http.Request, target runtime.Object) error {
 	return podOptionsCodec.DecodeParameters(req.URL.Query(), corev1.SchemeGroupVersion, target)
 }
@@ -289,6 +406,70 @@ func (s *Operations) getUninstallArgs(appNamespace, appName string, body io.Read
 	return status, Commands{cmd}, nil
 }
 
+func (s *Operations) getRollbackArgs(ctx context.Context, appNamespace, appName string, body io.Reader) (catalog.OperationStatus, Commands, error) {
+	rel, err := s.apps.Get(appNamespace, appName, metav1.GetOptions{})
+	if err != nil {
+		return catalog.OperationStatus{}, nil, err
+	}
+
+	rollbackArgs := &types2.ChartRollbackAction{}
+	if err := json.NewDecoder(body).Decode(rollbackArgs); err != nil {
+		return catalog.OperationStatus{}, nil, err
+	}
+
+	if rollbackArgs.Revision <= 0 {
+		return catalog.OperationStatus{}, nil, apierror.NewAPIError(validation.InvalidBodyContent, "revision must be greater than zero")
+	}
+
+	if rollbackArgs.Revision == rel.Spec.Version {
+		return catalog.OperationStatus{}, nil, apierror.NewAPIError(validation.InvalidBodyContent,
+			fmt.Sprintf("revision %d is the current revision of release %s", rollbackArgs.Revision, rel.Spec.Name))
+	}
+
+	releases, helm2, err := s.releaseHistory(ctx, rel)
+	if err != nil {
+		return catalog.OperationStatus{}, nil, err
+	}
+	if helm2 {
+		return catalog.OperationStatus{}, nil, apierror.NewAPIError(validation.InvalidAction, "rollback is not supported for helm 2 releases")
+	}
+	if !hasRevision(releases, rollbackArgs.Revision) {
+		return catalog.OperationStatus{}, nil, apierror.NewAPIError(validation.NotFound,
+			fmt.Sprintf("revision %d of release %s not found", rollbackArgs.Revision, rel.Spec.Name))
+	}
+
+	if rollbackArgs.MaxHistory == 0 {
+		rollbackArgs.MaxHistory = 5
+	}
+
+	cmd := Command{
+		Operation: "rollback",
+		ArgObjects: []interface{}{
+			rollbackArgs,
+		},
+		ReleaseName:      rel.Spec.Name,
+		ReleaseNamespace: rel.Namespace,
+		Revision:         rollbackArgs.Revision,
+	}
+
+	status := catalog.OperationStatus{
+		Action:    cmd.Operation,
+		Release:   rel.Spec.Name,
+		Namespace: appNamespace,
+	}
+
+	return status, Commands{cmd}, nil
+}
+
+func hasRevision(releases []*catalog.ReleaseSpec, revision int) bool {
+	for _, release := range releases {
+		if release.Version == revision {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Operations) getUpgradeCommand(repoNamespace, repoName string, body io.Reader) (catalog.OperationStatus, Commands, error) {
 	var (
 		upgradeArgs = &types2.ChartUpgradeAction{}
@@ -310,7 +491,11 @@ func (s *Operations) getUpgradeCommand(repoNamespace, repoName string, body io.R
 	}
 
 	for _, chartUpgrade := range upgradeArgs.Charts {
-		cmd, err := s.getChartCommand(repoNamespace, repoName, chartUpgrade.ChartName, chartUpgrade.Version, chartUpgrade.Annotations, chartUpgrade.Values)
+		current, err := s.reusedValues(status.Namespace, chartUpgrade)
+		if err != nil {
+			return status, nil, err
+		}
+		cmd, err := s.getChartCommand(repoNamespace, repoName, chartUpgrade.ChartName, chartUpgrade.Version, chartUpgrade.Annotations, current, chartUpgrade.Values)
 		if err != nil {
 			return status, nil, err
 		}
@@ -328,6 +513,21 @@ func (s *Operations) getUpgradeCommand(repoNamespace, repoName string, body io.R
 	return status, commands, nil
 }
 
+// reusedValues returns the values of the current release that helm reuses for the upgrade, which it does when the
+// upgrade neither resets nor sets any values
+func (s *Operations) reusedValues(namespace string, chartUpgrade types2.ChartUpgrade) (map[string]interface{}, error) {
+	if chartUpgrade.ResetValues || len(chartUpgrade.Values) > 0 {
+		return nil, nil
+	}
+	rel, err := s.apps.Get(namespace, chartUpgrade.ReleaseName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return rel.Spec.Values, nil
+}
+
 type Command struct {
 	Operation        string
 	ArgObjects       []interface{}
@@ -337,6 +537,7 @@ type Command struct {
 	Chart            []byte
 	ReleaseName      string
 	ReleaseNamespace string
+	Revision         int
 }
 
 type Commands []Command
@@ -414,6 +615,7 @@ func (c Command) renderArgs() ([]string, error) {
 	delete(dataMap, "releaseName")
 	delete(dataMap, "chartName")
 	delete(dataMap, "projectId")
+	delete(dataMap, "revision")
 	if v, ok := dataMap["disableOpenAPIValidation"]; ok {
 		delete(dataMap, "disableOpenAPIValidation")
 		dataMap["disableOpenapiValidation"] = v
@@ -442,6 +644,9 @@ func (c Command) renderArgs() ([]string, error) {
 	if c.ReleaseName != "" {
 		args = append(args, c.ReleaseName)
 	}
+	if c.Revision > 0 {
+		args = append(args, strconv.Itoa(c.Revision))
+	}
 	if len(c.Chart) > 0 {
 		args = append(args, filepath.Join(helmDataPath, c.ChartFile))
 	}
@@ -529,7 +734,7 @@ func addAnnotations(data []byte, annotations map[string]string) ([]byte, error)
 	return yaml.Marshal(chartData)
 }
 
-func (s *Operations) getChartCommand(namespace, name, chartName, chartVersion string, annotations map[string]string, values map[string]interface{}) (Command, error) {
+func (s *Operations) getChartCommand(namespace, name, chartName, chartVersion string, annotations map[string]string, current, values map[string]interface{}) (Command, error) {
 	chart, err := s.contentManager.Chart(namespace, name, chartName, chartVersion)
 	if err != nil {
 		return Command{}, err
@@ -540,6 +745,14 @@ func (s *Operations) getChartCommand(namespace, name, chartName, chartVersion st
 		return Command{}, err
 	}
 
+	valuesErrors, err := validateValues(chartName, chartData, current, values)
+	if err != nil {
+		return Command{}, err
+	}
+	if len(valuesErrors) > 0 {
+		return Command{}, toAPIError(valuesErrors)
+	}
+
 	chartData, err = injectAnnotation(chartData, annotations)
 	if err != nil {
 		return Command{}, err
@@ -561,6 +774,60 @@ func (s *Operations) getChartCommand(namespace, name, chartName, chartVersion st
 	return c, nil
 }
 
+// Validate checks the values of every chart in an install request against the chart's values schema and questions
+// without creating an operation.
+func (s *Operations) Validate(repoNamespace, repoName string, body io.Reader) (*types2.ChartValidationOutput, error) {
+	installArgs := &types2.ChartInstallAction{}
+	if err := json.NewDecoder(body).Decode(installArgs); err != nil {
+		return nil, err
+	}
+
+	result := &types2.ChartValidationOutput{}
+	for _, chartInstall := range installArgs.Charts {
+		chart, err := s.contentManager.Chart(repoNamespace, repoName, chartInstall.ChartName, chartInstall.Version)
+		if err != nil {
+			return nil, err
+		}
+		chartData, err := ioutil.ReadAll(chart)
+		chart.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		valuesErrors, err := validateValues(chartInstall.ChartName, chartData, nil, chartInstall.Values)
+		if err != nil {
+			return nil, err
+		}
+		result.Errors = append(result.Errors, valuesErrors...)
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+func validateValues(chartName string, chartData []byte, current, values map[string]interface{}) ([]types2.ChartValuesError, error) {
+	valuesErrors, err := helm.ValidateValues(bytes.NewReader(chartData), current, values)
+	if err != nil {
+		return nil, err
+	}
+	for i := range valuesErrors {
+		valuesErrors[i].ChartName = chartName
+	}
+	return valuesErrors, nil
+}
+
+func toAPIError(valuesErrors []types2.ChartValuesError) error {
+	var msgs []string
+	for _, valuesError := range valuesErrors {
+		if valuesError.Field == "" {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", valuesError.ChartName, valuesError.Message))
+		} else {
+			msgs = append(msgs, fmt.Sprintf("%s: %s: %s", valuesError.ChartName, valuesError.Field, valuesError.Message))
+		}
+	}
+	return apierror.NewFieldAPIError(validation.InvalidBodyContent, valuesErrors[0].Field, strings.Join(msgs, "; "))
+}
+
 func (s *Operations) getInstallCommand(repoNamespace, repoName string, body io.Reader) (catalog.OperationStatus, Commands, error) {
 	installArgs := &types2.ChartInstallAction{}
 	err := json.NewDecoder(body).Decode(installArgs)
@@ -576,7 +843,7 @@ func (s *Operations) getInstallCommand(repoNamespace, repoName string, body io.R
 	)
 
 	for _, chartInstall := range installArgs.Charts {
-		cmd, err := s.getChartCommand(repoNamespace, repoName, chartInstall.ChartName, chartInstall.Version, chartInstall.Annotations, chartInstall.Values)
+		cmd, err := s.getChartCommand(repoNamespace, repoName, chartInstall.ChartName, chartInstall.Version, chartInstall.Annotations, nil, chartInstall.Values)
 		if err != nil {
 			return status, nil, err
 		}
@@ -619,7 +886,7 @@ func namespace(ns string) string {
 }
 
 func (s *Operations) createOperation(ctx context.Context, user user.Info, status catalog.OperationStatus, cmds Commands, imageOverride string) (*catalog.Operation, error) {
-	if status.Action != "uninstall" {
+	if status.Action != "uninstall" && status.Action != "rollback" {
 		_, err := s.createNamespace(ctx, status.Namespace, status.ProjectID)
 		if err != nil {
 			return nil, err
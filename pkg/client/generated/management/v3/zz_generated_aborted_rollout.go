@@ -0,0 +1,12 @@
+package client
+
+const (
+	AbortedRolloutType                    = "abortedRollout"
+	AbortedRolloutFieldRollbackRevisionID = "rollbackRevisionId"
+	AbortedRolloutFieldSpecDigest         = "specDigest"
+)
+
+type AbortedRollout struct {
+	RollbackRevisionID string `json:"rollbackRevisionId,omitempty" yaml:"rollbackRevisionId,omitempty"`
+	SpecDigest         string `json:"specDigest,omitempty" yaml:"specDigest,omitempty"`
+}
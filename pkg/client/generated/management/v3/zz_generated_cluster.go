@@ -28,6 +28,7 @@ const (
 	ClusterFieldClusterTemplateRevisionID            = "clusterTemplateRevisionId"
 	ClusterFieldComponentStatuses                    = "componentStatuses"
 	ClusterFieldConditions                           = "conditions"
+	ClusterFieldConnectionMode                       = "connectionMode"
 	ClusterFieldCreated                              = "created"
 	ClusterFieldCreatorID                            = "creatorId"
 	ClusterFieldCurrentCisRunName                    = "currentCisRunName"
@@ -52,6 +53,7 @@ const (
 	ClusterFieldIstioEnabled                         = "istioEnabled"
 	ClusterFieldK3sConfig                            = "k3sConfig"
 	ClusterFieldLabels                               = "labels"
+	ClusterFieldLastConnectionMode                   = "lastConnectionMode"
 	ClusterFieldLimits                               = "limits"
 	ClusterFieldLocalClusterAuthEndpoint             = "localClusterAuthEndpoint"
 	ClusterFieldMonitoringStatus                     = "monitoringStatus"
@@ -98,6 +100,7 @@ type Cluster struct {
 	ClusterTemplateRevisionID            string                         `json:"clusterTemplateRevisionId,omitempty" yaml:"clusterTemplateRevisionId,omitempty"`
 	ComponentStatuses                    []ClusterComponentStatus       `json:"componentStatuses,omitempty" yaml:"componentStatuses,omitempty"`
 	Conditions                           []ClusterCondition             `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	ConnectionMode                       string                         `json:"connectionMode,omitempty" yaml:"connectionMode,omitempty"`
 	Created                              string                         `json:"created,omitempty" yaml:"created,omitempty"`
 	CreatorID                            string                         `json:"creatorId,omitempty" yaml:"creatorId,omitempty"`
 	CurrentCisRunName                    string                         `json:"currentCisRunName,omitempty" yaml:"currentCisRunName,omitempty"`
@@ -122,6 +125,7 @@ type Cluster struct {
 	IstioEnabled                         bool                           `json:"istioEnabled,omitempty" yaml:"istioEnabled,omitempty"`
 	K3sConfig                            *K3sConfig                     `json:"k3sConfig,omitempty" yaml:"k3sConfig,omitempty"`
 	Labels                               map[string]string              `json:"labels,omitempty" yaml:"labels,omitempty"`
+	LastConnectionMode                   string                         `json:"lastConnectionMode,omitempty" yaml:"lastConnectionMode,omitempty"`
 	Limits                               map[string]string              `json:"limits,omitempty" yaml:"limits,omitempty"`
 	LocalClusterAuthEndpoint             *LocalClusterAuthEndpoint      `json:"localClusterAuthEndpoint,omitempty" yaml:"localClusterAuthEndpoint,omitempty"`
 	MonitoringStatus                     *MonitoringStatus              `json:"monitoringStatus,omitempty" yaml:"monitoringStatus,omitempty"`
@@ -173,12 +177,14 @@ type ClusterOperations interface {
 
 	ActionExportYaml(resource *Cluster) (*ExportOutput, error)
 
-	ActionGenerateKubeconfig(resource *Cluster) (*GenerateKubeConfigOutput, error)
+	ActionGenerateKubeconfig(resource *Cluster, input *GenerateKubeConfigInput) (*GenerateKubeConfigOutput, error)
 
 	ActionImportYaml(resource *Cluster, input *ImportClusterYamlInput) (*ImportYamlOutput, error)
 
 	ActionRestoreFromEtcdBackup(resource *Cluster, input *RestoreFromEtcdBackupInput) error
 
+	ActionResume(resource *Cluster) error
+
 	ActionRotateCertificates(resource *Cluster, input *RotateCertificateInput) (*RotateCertificateOutput, error)
 
 	ActionRotateEncryptionKey(resource *Cluster) (*RotateEncryptionKeyOutput, error)
@@ -188,6 +194,8 @@ type ClusterOperations interface {
 	ActionSaveAsTemplate(resource *Cluster, input *SaveAsTemplateInput) (*SaveAsTemplateOutput, error)
 
 	ActionViewMonitoring(resource *Cluster) (*MonitoringOutput, error)
+
+	CollectionActionGenerateKubeconfig(resource *ClusterCollection, input *GenerateKubeConfigInput) (*GenerateKubeConfigOutput, error)
 }
 
 func newClusterClient(apiClient *Client) *ClusterClient {
@@ -285,9 +293,9 @@ func (c *ClusterClient) ActionExportYaml(resource *Cluster) (*ExportOutput, erro
 	return resp, err
 }
 
-func (c *ClusterClient) ActionGenerateKubeconfig(resource *Cluster) (*GenerateKubeConfigOutput, error) {
+func (c *ClusterClient) ActionGenerateKubeconfig(resource *Cluster, input *GenerateKubeConfigInput) (*GenerateKubeConfigOutput, error) {
 	resp := &GenerateKubeConfigOutput{}
-	err := c.apiClient.Ops.DoAction(ClusterType, "generateKubeconfig", &resource.Resource, nil, resp)
+	err := c.apiClient.Ops.DoAction(ClusterType, "generateKubeconfig", &resource.Resource, input, resp)
 	return resp, err
 }
 
@@ -302,6 +310,11 @@ func (c *ClusterClient) ActionRestoreFromEtcdBackup(resource *Cluster, input *Re
 	return err
 }
 
+func (c *ClusterClient) ActionResume(resource *Cluster) error {
+	err := c.apiClient.Ops.DoAction(ClusterType, "resume", &resource.Resource, nil, nil)
+	return err
+}
+
 func (c *ClusterClient) ActionRotateCertificates(resource *Cluster, input *RotateCertificateInput) (*RotateCertificateOutput, error) {
 	resp := &RotateCertificateOutput{}
 	err := c.apiClient.Ops.DoAction(ClusterType, "rotateCertificates", &resource.Resource, input, resp)
@@ -330,3 +343,9 @@ func (c *ClusterClient) ActionViewMonitoring(resource *Cluster) (*MonitoringOutp
 	err := c.apiClient.Ops.DoAction(ClusterType, "viewMonitoring", &resource.Resource, nil, resp)
 	return resp, err
 }
+
+func (c *ClusterClient) CollectionActionGenerateKubeconfig(resource *ClusterCollection, input *GenerateKubeConfigInput) (*GenerateKubeConfigOutput, error) {
+	resp := &GenerateKubeConfigOutput{}
+	err := c.apiClient.Ops.DoCollectionAction(ClusterType, "generateKubeconfig", &resource.Collection, input, resp)
+	return resp, err
+}
@@ -10,6 +10,7 @@ const (
 	ClusterSpecFieldClusterTemplateID                   = "clusterTemplateId"
 	ClusterSpecFieldClusterTemplateQuestions            = "questions"
 	ClusterSpecFieldClusterTemplateRevisionID           = "clusterTemplateRevisionId"
+	ClusterSpecFieldConnectionMode                      = "connectionMode"
 	ClusterSpecFieldDefaultClusterRoleForProjectMembers = "defaultClusterRoleForProjectMembers"
 	ClusterSpecFieldDefaultPodSecurityPolicyTemplateID  = "defaultPodSecurityPolicyTemplateId"
 	ClusterSpecFieldDescription                         = "description"
@@ -44,6 +45,7 @@ type ClusterSpec struct {
 	ClusterTemplateID                   string                         `json:"clusterTemplateId,omitempty" yaml:"clusterTemplateId,omitempty"`
 	ClusterTemplateQuestions            []Question                     `json:"questions,omitempty" yaml:"questions,omitempty"`
 	ClusterTemplateRevisionID           string                         `json:"clusterTemplateRevisionId,omitempty" yaml:"clusterTemplateRevisionId,omitempty"`
+	ConnectionMode                      string                         `json:"connectionMode,omitempty" yaml:"connectionMode,omitempty"`
 	DefaultClusterRoleForProjectMembers string                         `json:"defaultClusterRoleForProjectMembers,omitempty" yaml:"defaultClusterRoleForProjectMembers,omitempty"`
 	DefaultPodSecurityPolicyTemplateID  string                         `json:"defaultPodSecurityPolicyTemplateId,omitempty" yaml:"defaultPodSecurityPolicyTemplateId,omitempty"`
 	Description                         string                         `json:"description,omitempty" yaml:"description,omitempty"`
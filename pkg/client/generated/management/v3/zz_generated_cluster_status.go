@@ -23,6 +23,7 @@ const (
 	ClusterStatusFieldFailedSpec                           = "failedSpec"
 	ClusterStatusFieldGKEStatus                            = "gkeStatus"
 	ClusterStatusFieldIstioEnabled                         = "istioEnabled"
+	ClusterStatusFieldLastConnectionMode                   = "lastConnectionMode"
 	ClusterStatusFieldLimits                               = "limits"
 	ClusterStatusFieldMonitoringStatus                     = "monitoringStatus"
 	ClusterStatusFieldNodeCount                            = "nodeCount"
@@ -55,6 +56,7 @@ type ClusterStatus struct {
 	FailedSpec                           *ClusterSpec                `json:"failedSpec,omitempty" yaml:"failedSpec,omitempty"`
 	GKEStatus                            *GKEStatus                  `json:"gkeStatus,omitempty" yaml:"gkeStatus,omitempty"`
 	IstioEnabled                         bool                        `json:"istioEnabled,omitempty" yaml:"istioEnabled,omitempty"`
+	LastConnectionMode                   string                      `json:"lastConnectionMode,omitempty" yaml:"lastConnectionMode,omitempty"`
 	Limits                               map[string]string           `json:"limits,omitempty" yaml:"limits,omitempty"`
 	MonitoringStatus                     *MonitoringStatus           `json:"monitoringStatus,omitempty" yaml:"monitoringStatus,omitempty"`
 	NodeCount                            int64                       `json:"nodeCount,omitempty" yaml:"nodeCount,omitempty"`
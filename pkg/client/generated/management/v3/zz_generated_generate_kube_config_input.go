@@ -0,0 +1,12 @@
+package client
+
+const (
+	GenerateKubeConfigInputType                 = "generateKubeConfigInput"
+	GenerateKubeConfigInputFieldAuthMode        = "authMode"
+	GenerateKubeConfigInputFieldClusterSelector = "clusterSelector"
+)
+
+type GenerateKubeConfigInput struct {
+	AuthMode        string `json:"authMode,omitempty" yaml:"authMode,omitempty"`
+	ClusterSelector string `json:"clusterSelector,omitempty" yaml:"clusterSelector,omitempty"`
+}
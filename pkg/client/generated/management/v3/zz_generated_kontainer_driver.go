@@ -15,11 +15,13 @@ const (
 	KontainerDriverFieldCreated              = "created"
 	KontainerDriverFieldCreatorID            = "creatorId"
 	KontainerDriverFieldExecutablePath       = "executablePath"
+	KontainerDriverFieldHealth               = "health"
 	KontainerDriverFieldLabels               = "labels"
 	KontainerDriverFieldName                 = "name"
 	KontainerDriverFieldOwnerReferences      = "ownerReferences"
 	KontainerDriverFieldRemoved              = "removed"
 	KontainerDriverFieldState                = "state"
+	KontainerDriverFieldTimeouts             = "timeouts"
 	KontainerDriverFieldTransitioning        = "transitioning"
 	KontainerDriverFieldTransitioningMessage = "transitioningMessage"
 	KontainerDriverFieldUIURL                = "uiUrl"
@@ -30,26 +32,28 @@ const (
 
 type KontainerDriver struct {
 	types.Resource
-	Active               bool              `json:"active,omitempty" yaml:"active,omitempty"`
-	ActualURL            string            `json:"actualUrl,omitempty" yaml:"actualUrl,omitempty"`
-	Annotations          map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
-	BuiltIn              bool              `json:"builtIn,omitempty" yaml:"builtIn,omitempty"`
-	Checksum             string            `json:"checksum,omitempty" yaml:"checksum,omitempty"`
-	Conditions           []Condition       `json:"conditions,omitempty" yaml:"conditions,omitempty"`
-	Created              string            `json:"created,omitempty" yaml:"created,omitempty"`
-	CreatorID            string            `json:"creatorId,omitempty" yaml:"creatorId,omitempty"`
-	ExecutablePath       string            `json:"executablePath,omitempty" yaml:"executablePath,omitempty"`
-	Labels               map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
-	Name                 string            `json:"name,omitempty" yaml:"name,omitempty"`
-	OwnerReferences      []OwnerReference  `json:"ownerReferences,omitempty" yaml:"ownerReferences,omitempty"`
-	Removed              string            `json:"removed,omitempty" yaml:"removed,omitempty"`
-	State                string            `json:"state,omitempty" yaml:"state,omitempty"`
-	Transitioning        string            `json:"transitioning,omitempty" yaml:"transitioning,omitempty"`
-	TransitioningMessage string            `json:"transitioningMessage,omitempty" yaml:"transitioningMessage,omitempty"`
-	UIURL                string            `json:"uiUrl,omitempty" yaml:"uiUrl,omitempty"`
-	URL                  string            `json:"url,omitempty" yaml:"url,omitempty"`
-	UUID                 string            `json:"uuid,omitempty" yaml:"uuid,omitempty"`
-	WhitelistDomains     []string          `json:"whitelistDomains,omitempty" yaml:"whitelistDomains,omitempty"`
+	Active               bool                     `json:"active,omitempty" yaml:"active,omitempty"`
+	ActualURL            string                   `json:"actualUrl,omitempty" yaml:"actualUrl,omitempty"`
+	Annotations          map[string]string        `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	BuiltIn              bool                     `json:"builtIn,omitempty" yaml:"builtIn,omitempty"`
+	Checksum             string                   `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+	Conditions           []Condition              `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	Created              string                   `json:"created,omitempty" yaml:"created,omitempty"`
+	CreatorID            string                   `json:"creatorId,omitempty" yaml:"creatorId,omitempty"`
+	ExecutablePath       string                   `json:"executablePath,omitempty" yaml:"executablePath,omitempty"`
+	Health               *KontainerDriverHealth   `json:"health,omitempty" yaml:"health,omitempty"`
+	Labels               map[string]string        `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Name                 string                   `json:"name,omitempty" yaml:"name,omitempty"`
+	OwnerReferences      []OwnerReference         `json:"ownerReferences,omitempty" yaml:"ownerReferences,omitempty"`
+	Removed              string                   `json:"removed,omitempty" yaml:"removed,omitempty"`
+	State                string                   `json:"state,omitempty" yaml:"state,omitempty"`
+	Timeouts             *KontainerDriverTimeouts `json:"timeouts,omitempty" yaml:"timeouts,omitempty"`
+	Transitioning        string                   `json:"transitioning,omitempty" yaml:"transitioning,omitempty"`
+	TransitioningMessage string                   `json:"transitioningMessage,omitempty" yaml:"transitioningMessage,omitempty"`
+	UIURL                string                   `json:"uiUrl,omitempty" yaml:"uiUrl,omitempty"`
+	URL                  string                   `json:"url,omitempty" yaml:"url,omitempty"`
+	UUID                 string                   `json:"uuid,omitempty" yaml:"uuid,omitempty"`
+	WhitelistDomains     []string                 `json:"whitelistDomains,omitempty" yaml:"whitelistDomains,omitempty"`
 }
 
 type KontainerDriverCollection struct {
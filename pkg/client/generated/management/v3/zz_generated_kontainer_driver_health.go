@@ -0,0 +1,16 @@
+package client
+
+const (
+	KontainerDriverHealthType             = "kontainerDriverHealth"
+	KontainerDriverHealthFieldLastChecked = "lastChecked"
+	KontainerDriverHealthFieldMessage     = "message"
+	KontainerDriverHealthFieldRestarts    = "restarts"
+	KontainerDriverHealthFieldState       = "state"
+)
+
+type KontainerDriverHealth struct {
+	LastChecked string `json:"lastChecked,omitempty" yaml:"lastChecked,omitempty"`
+	Message     string `json:"message,omitempty" yaml:"message,omitempty"`
+	Restarts    int64  `json:"restarts,omitempty" yaml:"restarts,omitempty"`
+	State       string `json:"state,omitempty" yaml:"state,omitempty"`
+}
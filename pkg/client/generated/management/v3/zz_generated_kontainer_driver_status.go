@@ -6,11 +6,13 @@ const (
 	KontainerDriverStatusFieldConditions     = "conditions"
 	KontainerDriverStatusFieldDisplayName    = "displayName"
 	KontainerDriverStatusFieldExecutablePath = "executablePath"
+	KontainerDriverStatusFieldHealth         = "health"
 )
 
 type KontainerDriverStatus struct {
-	ActualURL      string      `json:"actualUrl,omitempty" yaml:"actualUrl,omitempty"`
-	Conditions     []Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
-	DisplayName    string      `json:"displayName,omitempty" yaml:"displayName,omitempty"`
-	ExecutablePath string      `json:"executablePath,omitempty" yaml:"executablePath,omitempty"`
+	ActualURL      string                 `json:"actualUrl,omitempty" yaml:"actualUrl,omitempty"`
+	Conditions     []Condition            `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	DisplayName    string                 `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	ExecutablePath string                 `json:"executablePath,omitempty" yaml:"executablePath,omitempty"`
+	Health         *KontainerDriverHealth `json:"health,omitempty" yaml:"health,omitempty"`
 }
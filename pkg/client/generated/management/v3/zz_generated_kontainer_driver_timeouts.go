@@ -0,0 +1,12 @@
+package client
+
+const (
+	KontainerDriverTimeoutsType                  = "kontainerDriverTimeouts"
+	KontainerDriverTimeoutsFieldCreateSeconds    = "createSeconds"
+	KontainerDriverTimeoutsFieldPostCheckSeconds = "postCheckSeconds"
+)
+
+type KontainerDriverTimeouts struct {
+	CreateSeconds    int64 `json:"createSeconds,omitempty" yaml:"createSeconds,omitempty"`
+	PostCheckSeconds int64 `json:"postCheckSeconds,omitempty" yaml:"postCheckSeconds,omitempty"`
+}
@@ -15,10 +15,12 @@ const (
 	MultiClusterAppFieldName                 = "name"
 	MultiClusterAppFieldOwnerReferences      = "ownerReferences"
 	MultiClusterAppFieldRemoved              = "removed"
+	MultiClusterAppFieldRevertDrift          = "revertDrift"
 	MultiClusterAppFieldRevisionHistoryLimit = "revisionHistoryLimit"
 	MultiClusterAppFieldRoles                = "roles"
 	MultiClusterAppFieldState                = "state"
 	MultiClusterAppFieldStatus               = "status"
+	MultiClusterAppFieldTargetSelectors      = "targetSelectors"
 	MultiClusterAppFieldTargets              = "targets"
 	MultiClusterAppFieldTemplateVersionID    = "templateVersionId"
 	MultiClusterAppFieldTimeout              = "timeout"
@@ -40,10 +42,12 @@ type MultiClusterApp struct {
 	Name                 string                 `json:"name,omitempty" yaml:"name,omitempty"`
 	OwnerReferences      []OwnerReference       `json:"ownerReferences,omitempty" yaml:"ownerReferences,omitempty"`
 	Removed              string                 `json:"removed,omitempty" yaml:"removed,omitempty"`
+	RevertDrift          bool                   `json:"revertDrift,omitempty" yaml:"revertDrift,omitempty"`
 	RevisionHistoryLimit int64                  `json:"revisionHistoryLimit,omitempty" yaml:"revisionHistoryLimit,omitempty"`
 	Roles                []string               `json:"roles,omitempty" yaml:"roles,omitempty"`
 	State                string                 `json:"state,omitempty" yaml:"state,omitempty"`
 	Status               *MultiClusterAppStatus `json:"status,omitempty" yaml:"status,omitempty"`
+	TargetSelectors      []TargetSelector       `json:"targetSelectors,omitempty" yaml:"targetSelectors,omitempty"`
 	Targets              []Target               `json:"targets,omitempty" yaml:"targets,omitempty"`
 	TemplateVersionID    string                 `json:"templateVersionId,omitempty" yaml:"templateVersionId,omitempty"`
 	Timeout              int64                  `json:"timeout,omitempty" yaml:"timeout,omitempty"`
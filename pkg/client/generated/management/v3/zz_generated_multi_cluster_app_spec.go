@@ -4,8 +4,10 @@ const (
 	MultiClusterAppSpecType                      = "multiClusterAppSpec"
 	MultiClusterAppSpecFieldAnswers              = "answers"
 	MultiClusterAppSpecFieldMembers              = "members"
+	MultiClusterAppSpecFieldRevertDrift          = "revertDrift"
 	MultiClusterAppSpecFieldRevisionHistoryLimit = "revisionHistoryLimit"
 	MultiClusterAppSpecFieldRoles                = "roles"
+	MultiClusterAppSpecFieldTargetSelectors      = "targetSelectors"
 	MultiClusterAppSpecFieldTargets              = "targets"
 	MultiClusterAppSpecFieldTemplateVersionID    = "templateVersionId"
 	MultiClusterAppSpecFieldTimeout              = "timeout"
@@ -16,8 +18,10 @@ const (
 type MultiClusterAppSpec struct {
 	Answers              []Answer         `json:"answers,omitempty" yaml:"answers,omitempty"`
 	Members              []Member         `json:"members,omitempty" yaml:"members,omitempty"`
+	RevertDrift          bool             `json:"revertDrift,omitempty" yaml:"revertDrift,omitempty"`
 	RevisionHistoryLimit int64            `json:"revisionHistoryLimit,omitempty" yaml:"revisionHistoryLimit,omitempty"`
 	Roles                []string         `json:"roles,omitempty" yaml:"roles,omitempty"`
+	TargetSelectors      []TargetSelector `json:"targetSelectors,omitempty" yaml:"targetSelectors,omitempty"`
 	Targets              []Target         `json:"targets,omitempty" yaml:"targets,omitempty"`
 	TemplateVersionID    string           `json:"templateVersionId,omitempty" yaml:"templateVersionId,omitempty"`
 	Timeout              int64            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
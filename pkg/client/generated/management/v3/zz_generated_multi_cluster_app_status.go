@@ -1,14 +1,18 @@
 package client
 
 const (
-	MultiClusterAppStatusType             = "multiClusterAppStatus"
-	MultiClusterAppStatusFieldConditions  = "conditions"
-	MultiClusterAppStatusFieldHelmVersion = "helmVersion"
-	MultiClusterAppStatusFieldRevisionID  = "revisionId"
+	MultiClusterAppStatusType                = "multiClusterAppStatus"
+	MultiClusterAppStatusFieldAbortedRollout = "abortedRollout"
+	MultiClusterAppStatusFieldConditions     = "conditions"
+	MultiClusterAppStatusFieldHelmVersion    = "helmVersion"
+	MultiClusterAppStatusFieldRevisionID     = "revisionId"
+	MultiClusterAppStatusFieldTargetStatuses = "targetStatuses"
 )
 
 type MultiClusterAppStatus struct {
-	Conditions  []AppCondition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
-	HelmVersion string         `json:"helmVersion,omitempty" yaml:"helmVersion,omitempty"`
-	RevisionID  string         `json:"revisionId,omitempty" yaml:"revisionId,omitempty"`
+	AbortedRollout *AbortedRollout `json:"abortedRollout,omitempty" yaml:"abortedRollout,omitempty"`
+	Conditions     []AppCondition  `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	HelmVersion    string          `json:"helmVersion,omitempty" yaml:"helmVersion,omitempty"`
+	RevisionID     string          `json:"revisionId,omitempty" yaml:"revisionId,omitempty"`
+	TargetStatuses []TargetStatus  `json:"targetStatuses,omitempty" yaml:"targetStatuses,omitempty"`
 }
@@ -1,12 +1,18 @@
 package client
 
 const (
-	RollingUpdateType           = "rollingUpdate"
-	RollingUpdateFieldBatchSize = "batchSize"
-	RollingUpdateFieldInterval  = "interval"
+	RollingUpdateType                   = "rollingUpdate"
+	RollingUpdateFieldBatchSize         = "batchSize"
+	RollingUpdateFieldBatchTimeout      = "batchTimeout"
+	RollingUpdateFieldInterval          = "interval"
+	RollingUpdateFieldOrderBy           = "orderBy"
+	RollingUpdateFieldRollbackOnFailure = "rollbackOnFailure"
 )
 
 type RollingUpdate struct {
-	BatchSize int64 `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
-	Interval  int64 `json:"interval,omitempty" yaml:"interval,omitempty"`
+	BatchSize         int64  `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+	BatchTimeout      int64  `json:"batchTimeout,omitempty" yaml:"batchTimeout,omitempty"`
+	Interval          int64  `json:"interval,omitempty" yaml:"interval,omitempty"`
+	OrderBy           string `json:"orderBy,omitempty" yaml:"orderBy,omitempty"`
+	RollbackOnFailure bool   `json:"rollbackOnFailure,omitempty" yaml:"rollbackOnFailure,omitempty"`
 }
@@ -4,13 +4,17 @@ const (
 	TargetType             = "target"
 	TargetFieldAppID       = "appId"
 	TargetFieldHealthstate = "healthState"
+	TargetFieldPriority    = "priority"
 	TargetFieldProjectID   = "projectId"
+	TargetFieldSelector    = "selector"
 	TargetFieldState       = "state"
 )
 
 type Target struct {
 	AppID       string `json:"appId,omitempty" yaml:"appId,omitempty"`
 	Healthstate string `json:"healthState,omitempty" yaml:"healthState,omitempty"`
+	Priority    int64  `json:"priority,omitempty" yaml:"priority,omitempty"`
 	ProjectID   string `json:"projectId,omitempty" yaml:"projectId,omitempty"`
+	Selector    string `json:"selector,omitempty" yaml:"selector,omitempty"`
 	State       string `json:"state,omitempty" yaml:"state,omitempty"`
 }
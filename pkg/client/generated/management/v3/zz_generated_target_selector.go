@@ -0,0 +1,16 @@
+package client
+
+const (
+	TargetSelectorType                 = "targetSelector"
+	TargetSelectorFieldClusterSelector = "clusterSelector"
+	TargetSelectorFieldName            = "name"
+	TargetSelectorFieldProjectSelector = "projectSelector"
+	TargetSelectorFieldValues          = "values"
+)
+
+type TargetSelector struct {
+	ClusterSelector *LabelSelector    `json:"clusterSelector,omitempty" yaml:"clusterSelector,omitempty"`
+	Name            string            `json:"name,omitempty" yaml:"name,omitempty"`
+	ProjectSelector *LabelSelector    `json:"projectSelector,omitempty" yaml:"projectSelector,omitempty"`
+	Values          map[string]string `json:"values,omitempty" yaml:"values,omitempty"`
+}
@@ -0,0 +1,14 @@
+package client
+
+const (
+	TargetStatusType            = "targetStatus"
+	TargetStatusFieldAppID      = "appId"
+	TargetStatusFieldConditions = "conditions"
+	TargetStatusFieldProjectID  = "projectId"
+)
+
+type TargetStatus struct {
+	AppID      string         `json:"appId,omitempty" yaml:"appId,omitempty"`
+	Conditions []AppCondition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	ProjectID  string         `json:"projectId,omitempty" yaml:"projectId,omitempty"`
+}
@@ -0,0 +1,10 @@
+package client
+
+const (
+	ArtifactsConfigType       = "artifactsConfig"
+	ArtifactsConfigFieldPaths = "paths"
+)
+
+type ArtifactsConfig struct {
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
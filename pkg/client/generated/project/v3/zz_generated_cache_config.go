@@ -0,0 +1,12 @@
+package client
+
+const (
+	CacheConfigType       = "cacheConfig"
+	CacheConfigFieldKey   = "key"
+	CacheConfigFieldPaths = "paths"
+)
+
+type CacheConfig struct {
+	Key   string   `json:"key,omitempty" yaml:"key,omitempty"`
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
@@ -4,9 +4,11 @@ const (
 	ConstraintsType        = "constraints"
 	ConstraintsFieldBranch = "branch"
 	ConstraintsFieldEvent  = "event"
+	ConstraintsFieldMatrix = "matrix"
 )
 
 type Constraints struct {
-	Branch *Constraint `json:"branch,omitempty" yaml:"branch,omitempty"`
-	Event  *Constraint `json:"event,omitempty" yaml:"event,omitempty"`
+	Branch *Constraint           `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Event  *Constraint           `json:"event,omitempty" yaml:"event,omitempty"`
+	Matrix map[string]Constraint `json:"matrix,omitempty" yaml:"matrix,omitempty"`
 }
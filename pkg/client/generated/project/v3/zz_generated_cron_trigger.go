@@ -0,0 +1,14 @@
+package client
+
+const (
+	CronTriggerType          = "cronTrigger"
+	CronTriggerFieldBranch   = "branch"
+	CronTriggerFieldEnv      = "env"
+	CronTriggerFieldSchedule = "schedule"
+)
+
+type CronTrigger struct {
+	Branch   string            `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Env      map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Schedule string            `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+}
@@ -0,0 +1,12 @@
+package client
+
+const (
+	GitApplyInputType              = "gitApplyInput"
+	GitApplyInputFieldKnownHosts   = "knownHosts"
+	GitApplyInputFieldRepositories = "repositories"
+)
+
+type GitApplyInput struct {
+	KnownHosts   string   `json:"knownHosts,omitempty" yaml:"knownHosts,omitempty"`
+	Repositories []string `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+}
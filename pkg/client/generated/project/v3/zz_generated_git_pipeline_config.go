@@ -0,0 +1,40 @@
+package client
+
+const (
+	GitPipelineConfigType                 = "gitPipelineConfig"
+	GitPipelineConfigFieldAnnotations     = "annotations"
+	GitPipelineConfigFieldCreated         = "created"
+	GitPipelineConfigFieldCreatorID       = "creatorId"
+	GitPipelineConfigFieldEnabled         = "enabled"
+	GitPipelineConfigFieldKnownHosts      = "knownHosts"
+	GitPipelineConfigFieldLabels          = "labels"
+	GitPipelineConfigFieldName            = "name"
+	GitPipelineConfigFieldNamespaceId     = "namespaceId"
+	GitPipelineConfigFieldOwnerReferences = "ownerReferences"
+	GitPipelineConfigFieldPrivateKey      = "privateKey"
+	GitPipelineConfigFieldProjectID       = "projectId"
+	GitPipelineConfigFieldPublicKey       = "publicKey"
+	GitPipelineConfigFieldRemoved         = "removed"
+	GitPipelineConfigFieldRepositories    = "repositories"
+	GitPipelineConfigFieldType            = "type"
+	GitPipelineConfigFieldUUID            = "uuid"
+)
+
+type GitPipelineConfig struct {
+	Annotations     map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Created         string            `json:"created,omitempty" yaml:"created,omitempty"`
+	CreatorID       string            `json:"creatorId,omitempty" yaml:"creatorId,omitempty"`
+	Enabled         bool              `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	KnownHosts      string            `json:"knownHosts,omitempty" yaml:"knownHosts,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Name            string            `json:"name,omitempty" yaml:"name,omitempty"`
+	NamespaceId     string            `json:"namespaceId,omitempty" yaml:"namespaceId,omitempty"`
+	OwnerReferences []OwnerReference  `json:"ownerReferences,omitempty" yaml:"ownerReferences,omitempty"`
+	PrivateKey      string            `json:"privateKey,omitempty" yaml:"privateKey,omitempty"`
+	ProjectID       string            `json:"projectId,omitempty" yaml:"projectId,omitempty"`
+	PublicKey       string            `json:"publicKey,omitempty" yaml:"publicKey,omitempty"`
+	Removed         string            `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Repositories    []string          `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+	Type            string            `json:"type,omitempty" yaml:"type,omitempty"`
+	UUID            string            `json:"uuid,omitempty" yaml:"uuid,omitempty"`
+}
@@ -0,0 +1,30 @@
+package client
+
+const (
+	GiteaProviderType                 = "giteaProvider"
+	GiteaProviderFieldAnnotations     = "annotations"
+	GiteaProviderFieldCreated         = "created"
+	GiteaProviderFieldCreatorID       = "creatorId"
+	GiteaProviderFieldLabels          = "labels"
+	GiteaProviderFieldName            = "name"
+	GiteaProviderFieldOwnerReferences = "ownerReferences"
+	GiteaProviderFieldProjectID       = "projectId"
+	GiteaProviderFieldRedirectURL     = "redirectUrl"
+	GiteaProviderFieldRemoved         = "removed"
+	GiteaProviderFieldType            = "type"
+	GiteaProviderFieldUUID            = "uuid"
+)
+
+type GiteaProvider struct {
+	Annotations     map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Created         string            `json:"created,omitempty" yaml:"created,omitempty"`
+	CreatorID       string            `json:"creatorId,omitempty" yaml:"creatorId,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Name            string            `json:"name,omitempty" yaml:"name,omitempty"`
+	OwnerReferences []OwnerReference  `json:"ownerReferences,omitempty" yaml:"ownerReferences,omitempty"`
+	ProjectID       string            `json:"projectId,omitempty" yaml:"projectId,omitempty"`
+	RedirectURL     string            `json:"redirectUrl,omitempty" yaml:"redirectUrl,omitempty"`
+	Removed         string            `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Type            string            `json:"type,omitempty" yaml:"type,omitempty"`
+	UUID            string            `json:"uuid,omitempty" yaml:"uuid,omitempty"`
+}
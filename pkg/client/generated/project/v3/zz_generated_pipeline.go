@@ -9,7 +9,10 @@ const (
 	PipelineFieldAnnotations            = "annotations"
 	PipelineFieldCreated                = "created"
 	PipelineFieldCreatorID              = "creatorId"
+	PipelineFieldCronTriggers           = "cronTriggers"
+	PipelineFieldCronTriggersHash       = "cronTriggersHash"
 	PipelineFieldLabels                 = "labels"
+	PipelineFieldLastCronCheck          = "lastCronCheck"
 	PipelineFieldLastExecutionID        = "lastExecutionId"
 	PipelineFieldLastRunState           = "lastRunState"
 	PipelineFieldLastStarted            = "lastStarted"
@@ -40,7 +43,10 @@ type Pipeline struct {
 	Annotations            map[string]string     `json:"annotations,omitempty" yaml:"annotations,omitempty"`
 	Created                string                `json:"created,omitempty" yaml:"created,omitempty"`
 	CreatorID              string                `json:"creatorId,omitempty" yaml:"creatorId,omitempty"`
+	CronTriggers           []CronTrigger         `json:"cronTriggers,omitempty" yaml:"cronTriggers,omitempty"`
+	CronTriggersHash       string                `json:"cronTriggersHash,omitempty" yaml:"cronTriggersHash,omitempty"`
 	Labels                 map[string]string     `json:"labels,omitempty" yaml:"labels,omitempty"`
+	LastCronCheck          string                `json:"lastCronCheck,omitempty" yaml:"lastCronCheck,omitempty"`
 	LastExecutionID        string                `json:"lastExecutionId,omitempty" yaml:"lastExecutionId,omitempty"`
 	LastRunState           string                `json:"lastRunState,omitempty" yaml:"lastRunState,omitempty"`
 	LastStarted            string                `json:"lastStarted,omitempty" yaml:"lastStarted,omitempty"`
@@ -16,6 +16,7 @@ const (
 	PipelineExecutionFieldCreatorID            = "creatorId"
 	PipelineExecutionFieldEmail                = "email"
 	PipelineExecutionFieldEnded                = "ended"
+	PipelineExecutionFieldEnv                  = "env"
 	PipelineExecutionFieldEvent                = "event"
 	PipelineExecutionFieldExecutionState       = "executionState"
 	PipelineExecutionFieldHTMLLink             = "htmlLink"
@@ -54,6 +55,7 @@ type PipelineExecution struct {
 	CreatorID            string              `json:"creatorId,omitempty" yaml:"creatorId,omitempty"`
 	Email                string              `json:"email,omitempty" yaml:"email,omitempty"`
 	Ended                string              `json:"ended,omitempty" yaml:"ended,omitempty"`
+	Env                  map[string]string   `json:"env,omitempty" yaml:"env,omitempty"`
 	Event                string              `json:"event,omitempty" yaml:"event,omitempty"`
 	ExecutionState       string              `json:"executionState,omitempty" yaml:"executionState,omitempty"`
 	HTMLLink             string              `json:"htmlLink,omitempty" yaml:"htmlLink,omitempty"`
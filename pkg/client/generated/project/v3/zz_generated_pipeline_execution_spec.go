@@ -7,6 +7,7 @@ const (
 	PipelineExecutionSpecFieldBranch         = "branch"
 	PipelineExecutionSpecFieldCommit         = "commit"
 	PipelineExecutionSpecFieldEmail          = "email"
+	PipelineExecutionSpecFieldEnv            = "env"
 	PipelineExecutionSpecFieldEvent          = "event"
 	PipelineExecutionSpecFieldHTMLLink       = "htmlLink"
 	PipelineExecutionSpecFieldMessage        = "message"
@@ -22,21 +23,22 @@ const (
 )
 
 type PipelineExecutionSpec struct {
-	Author         string          `json:"author,omitempty" yaml:"author,omitempty"`
-	AvatarURL      string          `json:"avatarUrl,omitempty" yaml:"avatarUrl,omitempty"`
-	Branch         string          `json:"branch,omitempty" yaml:"branch,omitempty"`
-	Commit         string          `json:"commit,omitempty" yaml:"commit,omitempty"`
-	Email          string          `json:"email,omitempty" yaml:"email,omitempty"`
-	Event          string          `json:"event,omitempty" yaml:"event,omitempty"`
-	HTMLLink       string          `json:"htmlLink,omitempty" yaml:"htmlLink,omitempty"`
-	Message        string          `json:"message,omitempty" yaml:"message,omitempty"`
-	PipelineConfig *PipelineConfig `json:"pipelineConfig,omitempty" yaml:"pipelineConfig,omitempty"`
-	PipelineID     string          `json:"pipelineId,omitempty" yaml:"pipelineId,omitempty"`
-	ProjectID      string          `json:"projectId,omitempty" yaml:"projectId,omitempty"`
-	Ref            string          `json:"ref,omitempty" yaml:"ref,omitempty"`
-	RepositoryURL  string          `json:"repositoryUrl,omitempty" yaml:"repositoryUrl,omitempty"`
-	Run            int64           `json:"run,omitempty" yaml:"run,omitempty"`
-	Title          string          `json:"title,omitempty" yaml:"title,omitempty"`
-	TriggerUserID  string          `json:"triggerUserId,omitempty" yaml:"triggerUserId,omitempty"`
-	TriggeredBy    string          `json:"triggeredBy,omitempty" yaml:"triggeredBy,omitempty"`
+	Author         string            `json:"author,omitempty" yaml:"author,omitempty"`
+	AvatarURL      string            `json:"avatarUrl,omitempty" yaml:"avatarUrl,omitempty"`
+	Branch         string            `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Commit         string            `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Email          string            `json:"email,omitempty" yaml:"email,omitempty"`
+	Env            map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Event          string            `json:"event,omitempty" yaml:"event,omitempty"`
+	HTMLLink       string            `json:"htmlLink,omitempty" yaml:"htmlLink,omitempty"`
+	Message        string            `json:"message,omitempty" yaml:"message,omitempty"`
+	PipelineConfig *PipelineConfig   `json:"pipelineConfig,omitempty" yaml:"pipelineConfig,omitempty"`
+	PipelineID     string            `json:"pipelineId,omitempty" yaml:"pipelineId,omitempty"`
+	ProjectID      string            `json:"projectId,omitempty" yaml:"projectId,omitempty"`
+	Ref            string            `json:"ref,omitempty" yaml:"ref,omitempty"`
+	RepositoryURL  string            `json:"repositoryUrl,omitempty" yaml:"repositoryUrl,omitempty"`
+	Run            int64             `json:"run,omitempty" yaml:"run,omitempty"`
+	Title          string            `json:"title,omitempty" yaml:"title,omitempty"`
+	TriggerUserID  string            `json:"triggerUserId,omitempty" yaml:"triggerUserId,omitempty"`
+	TriggeredBy    string            `json:"triggeredBy,omitempty" yaml:"triggeredBy,omitempty"`
 }
@@ -2,6 +2,7 @@ package client
 
 const (
 	PipelineSpecType                        = "pipelineSpec"
+	PipelineSpecFieldCronTriggers           = "cronTriggers"
 	PipelineSpecFieldDisplayName            = "displayName"
 	PipelineSpecFieldProjectID              = "projectId"
 	PipelineSpecFieldRepositoryURL          = "repositoryUrl"
@@ -12,11 +13,12 @@ const (
 )
 
 type PipelineSpec struct {
-	DisplayName            string `json:"displayName,omitempty" yaml:"displayName,omitempty"`
-	ProjectID              string `json:"projectId,omitempty" yaml:"projectId,omitempty"`
-	RepositoryURL          string `json:"repositoryUrl,omitempty" yaml:"repositoryUrl,omitempty"`
-	SourceCodeCredentialID string `json:"sourceCodeCredentialId,omitempty" yaml:"sourceCodeCredentialId,omitempty"`
-	TriggerWebhookPr       bool   `json:"triggerWebhookPr,omitempty" yaml:"triggerWebhookPr,omitempty"`
-	TriggerWebhookPush     bool   `json:"triggerWebhookPush,omitempty" yaml:"triggerWebhookPush,omitempty"`
-	TriggerWebhookTag      bool   `json:"triggerWebhookTag,omitempty" yaml:"triggerWebhookTag,omitempty"`
+	CronTriggers           []CronTrigger `json:"cronTriggers,omitempty" yaml:"cronTriggers,omitempty"`
+	DisplayName            string        `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	ProjectID              string        `json:"projectId,omitempty" yaml:"projectId,omitempty"`
+	RepositoryURL          string        `json:"repositoryUrl,omitempty" yaml:"repositoryUrl,omitempty"`
+	SourceCodeCredentialID string        `json:"sourceCodeCredentialId,omitempty" yaml:"sourceCodeCredentialId,omitempty"`
+	TriggerWebhookPr       bool          `json:"triggerWebhookPr,omitempty" yaml:"triggerWebhookPr,omitempty"`
+	TriggerWebhookPush     bool          `json:"triggerWebhookPush,omitempty" yaml:"triggerWebhookPush,omitempty"`
+	TriggerWebhookTag      bool          `json:"triggerWebhookTag,omitempty" yaml:"triggerWebhookTag,omitempty"`
 }
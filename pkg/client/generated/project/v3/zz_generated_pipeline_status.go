@@ -2,6 +2,8 @@ package client
 
 const (
 	PipelineStatusType                      = "pipelineStatus"
+	PipelineStatusFieldCronTriggersHash     = "cronTriggersHash"
+	PipelineStatusFieldLastCronCheck        = "lastCronCheck"
 	PipelineStatusFieldLastExecutionID      = "lastExecutionId"
 	PipelineStatusFieldLastRunState         = "lastRunState"
 	PipelineStatusFieldLastStarted          = "lastStarted"
@@ -14,6 +16,8 @@ const (
 )
 
 type PipelineStatus struct {
+	CronTriggersHash     string                `json:"cronTriggersHash,omitempty" yaml:"cronTriggersHash,omitempty"`
+	LastCronCheck        string                `json:"lastCronCheck,omitempty" yaml:"lastCronCheck,omitempty"`
 	LastExecutionID      string                `json:"lastExecutionId,omitempty" yaml:"lastExecutionId,omitempty"`
 	LastRunState         string                `json:"lastRunState,omitempty" yaml:"lastRunState,omitempty"`
 	LastStarted          string                `json:"lastStarted,omitempty" yaml:"lastStarted,omitempty"`
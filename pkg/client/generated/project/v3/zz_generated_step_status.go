@@ -3,12 +3,14 @@ package client
 const (
 	StepStatusType         = "stepStatus"
 	StepStatusFieldEnded   = "ended"
+	StepStatusFieldMatrix  = "matrix"
 	StepStatusFieldStarted = "started"
 	StepStatusFieldState   = "state"
 )
 
 type StepStatus struct {
-	Ended   string `json:"ended,omitempty" yaml:"ended,omitempty"`
-	Started string `json:"started,omitempty" yaml:"started,omitempty"`
-	State   string `json:"state,omitempty" yaml:"state,omitempty"`
+	Ended   string            `json:"ended,omitempty" yaml:"ended,omitempty"`
+	Matrix  map[string]string `json:"matrix,omitempty" yaml:"matrix,omitempty"`
+	Started string            `json:"started,omitempty" yaml:"started,omitempty"`
+	State   string            `json:"state,omitempty" yaml:"state,omitempty"`
 }
@@ -0,0 +1,211 @@
+package clusterrouter
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/rancher/pkg/clusterrouter/ratelimits"
+	"github.com/rancher/rancher/pkg/settings"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// Requests proxied to a cluster are admitted through token buckets, one per cluster and one per user of a cluster.
+// Watches, mutating and read requests go through separate lanes, each lane having its own buckets, so that a flood of
+// one kind of request can't starve the others. The limits of the lanes are set in package ratelimits.
+
+const (
+	bucketIdleTimeout = 10 * time.Minute
+)
+
+var (
+	rateLimitMetrics = false
+
+	throttledRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "cluster_router",
+			Name:      "throttled_requests_total",
+			Help:      "Number of requests proxied to a cluster rejected by the rate limits",
+		},
+		[]string{"cluster", "lane", "limit"},
+	)
+
+	tooManyRequests = httperror.ErrorCode{Code: "TooManyRequests", Status: http.StatusTooManyRequests}
+)
+
+// RegisterMetrics registers the metrics of the requests throttled by the cluster router
+func RegisterMetrics() {
+	rateLimitMetrics = true
+
+	prometheus.MustRegister(throttledRequests)
+}
+
+// bucket is a token bucket refilled at qps tokens per second up to burst tokens
+type bucket struct {
+	qps      int
+	burst    int
+	tokens   float64
+	last     time.Time
+	lastUsed time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.burst), b.tokens+now.Sub(b.last).Seconds()*float64(b.qps))
+	b.last = now
+}
+
+// wait is how long it takes until the bucket has a token
+func (b *bucket) wait() time.Duration {
+	return time.Duration(math.Ceil((1 - b.tokens) / float64(b.qps) * float64(time.Second)))
+}
+
+type rateLimiter struct {
+	sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	now       func() time.Time
+
+	rawRateLimits string
+	rateLimits    *ratelimits.RateLimits
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:    map[string]*bucket{},
+		now:        time.Now,
+		rateLimits: &ratelimits.RateLimits{},
+	}
+}
+
+// admit takes a token of the cluster bucket and of the user bucket of the lane of the request. If either bucket is
+// empty, no token is taken and the limit that rejected the request is returned with how long to wait before retrying.
+func (r *rateLimiter) admit(clusterID string, req *http.Request) (string, time.Duration) {
+	lane := requestLane(req)
+	user := ""
+	if userInfo, ok := request.UserFrom(req.Context()); ok {
+		user = userInfo.GetName()
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	r.prune()
+	limits := r.currentRateLimits().For(clusterID, lane)
+	clusterBucket := r.bucket("cluster/"+clusterID+"/"+lane, limits.ClusterQPS, limits.ClusterBurst)
+	if clusterBucket != nil && clusterBucket.tokens < 1 {
+		return r.throttled(clusterID, lane, "cluster", clusterBucket.wait())
+	}
+	var userBucket *bucket
+	if user != "" {
+		userBucket = r.bucket("user/"+clusterID+"/"+lane+"/"+user, limits.UserQPS, limits.UserBurst)
+	}
+	if userBucket != nil && userBucket.tokens < 1 {
+		return r.throttled(clusterID, lane, "user", userBucket.wait())
+	}
+
+	if clusterBucket != nil {
+		clusterBucket.tokens--
+	}
+	if userBucket != nil {
+		userBucket.tokens--
+	}
+	return "", 0
+}
+
+// currentRateLimits returns the parsed k8s-proxy-rate-limits setting, an invalid value is ignored
+func (r *rateLimiter) currentRateLimits() *ratelimits.RateLimits {
+	raw := settings.K8sProxyRateLimits.Get()
+	if raw == r.rawRateLimits {
+		return r.rateLimits
+	}
+	r.rawRateLimits = raw
+	rateLimits, err := ratelimits.Parse(raw)
+	if err != nil {
+		logrus.Errorf("ignoring setting %s: %v", settings.K8sProxyRateLimits.Name, err)
+		rateLimits = &ratelimits.RateLimits{}
+	}
+	r.rateLimits = rateLimits
+	return rateLimits
+}
+
+// bucket returns the refilled bucket of key, a qps of 0 disables the limit and returns nil
+func (r *rateLimiter) bucket(key string, qps, burst int) *bucket {
+	if qps <= 0 {
+		delete(r.buckets, key)
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	now := r.now()
+	b, ok := r.buckets[key]
+	if !ok || b.qps != qps || b.burst != burst {
+		b = &bucket{
+			qps:    qps,
+			burst:  burst,
+			tokens: float64(burst),
+			last:   now,
+		}
+		r.buckets[key] = b
+	}
+	b.refill(now)
+	b.lastUsed = now
+	return b
+}
+
+func (r *rateLimiter) throttled(clusterID, lane, limit string, retryAfter time.Duration) (string, time.Duration) {
+	if rateLimitMetrics {
+		throttledRequests.With(prometheus.Labels{
+			"cluster": clusterID,
+			"lane":    lane,
+			"limit":   limit,
+		}).Inc()
+	}
+	return limit, retryAfter
+}
+
+// prune drops the buckets that were not used recently, they would be full again
+func (r *rateLimiter) prune() {
+	now := r.now()
+	if now.Sub(r.lastPrune) < bucketIdleTimeout {
+		return
+	}
+	r.lastPrune = now
+	for key, b := range r.buckets {
+		if now.Sub(b.lastUsed) > bucketIdleTimeout {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+func requestLane(req *http.Request) string {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return ratelimits.LaneMutating
+	}
+
+	if watch := req.URL.Query().Get("watch"); watch == "true" || watch == "1" {
+		return ratelimits.LaneWatch
+	}
+	if strings.Contains(req.URL.Path, "/watch/") {
+		return ratelimits.LaneWatch
+	}
+	return ratelimits.LaneRead
+}
+
+func throttledResponse(rw http.ResponseWriter, limit string, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	rw.Header().Set("Retry-After", strconv.Itoa(seconds))
+	response(rw, tooManyRequests, "Too many requests to the cluster, "+limit+" rate limit exceeded")
+}
@@ -0,0 +1,137 @@
+package clusterrouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rancher/rancher/pkg/clusterrouter/ratelimits"
+	"github.com/rancher/rancher/pkg/settings"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestRequestLane(t *testing.T) {
+	for url, lane := range map[string]string{
+		"/k8s/clusters/c-1/api/v1/pods?watch=true": ratelimits.LaneWatch,
+		"/k8s/clusters/c-1/api/v1/watch/pods":      ratelimits.LaneWatch,
+		"/k8s/clusters/c-1/api/v1/pods":            ratelimits.LaneRead,
+	} {
+		assert.Equal(t, lane, requestLane(httptest.NewRequest(http.MethodGet, url, nil)), url)
+	}
+	assert.Equal(t, ratelimits.LaneMutating, requestLane(httptest.NewRequest(http.MethodPost, "/k8s/clusters/c-1/api/v1/pods?watch=true", nil)))
+}
+
+func TestRateLimiterAdmit(t *testing.T) {
+	defer settings.K8sProxyUserQPS.Set(settings.K8sProxyUserQPS.Get())
+	defer settings.K8sProxyUserBurst.Set(settings.K8sProxyUserBurst.Get())
+	defer settings.K8sProxyClusterQPS.Set(settings.K8sProxyClusterQPS.Get())
+	defer settings.K8sProxyClusterBurst.Set(settings.K8sProxyClusterBurst.Get())
+
+	now := time.Now()
+	r := newRateLimiter()
+	r.now = func() time.Time {
+		return now
+	}
+	admit := func(clusterID, userName, method string) (string, time.Duration) {
+		req := httptest.NewRequest(method, "/k8s/clusters/"+clusterID+"/api/v1/pods", nil)
+		return r.admit(clusterID, req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: userName})))
+	}
+
+	for i := 0; i < 10; i++ {
+		limit, _ := admit("c-1", "u-1", http.MethodGet)
+		assert.Equal(t, "", limit, "limits are disabled by default")
+	}
+
+	settings.K8sProxyUserQPS.Set("1")
+	settings.K8sProxyUserBurst.Set("2")
+	settings.K8sProxyClusterQPS.Set("1")
+	settings.K8sProxyClusterBurst.Set("3")
+
+	for i := 0; i < 2; i++ {
+		limit, _ := admit("c-1", "u-1", http.MethodGet)
+		assert.Equal(t, "", limit)
+	}
+	limit, retryAfter := admit("c-1", "u-1", http.MethodGet)
+	assert.Equal(t, "user", limit)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// mutating requests go through their own lane
+	limit, _ = admit("c-1", "u-1", http.MethodPut)
+	assert.Equal(t, "", limit)
+
+	// other users share the cluster bucket of the lane, the rejected request of u-1 did not take a cluster token
+	limit, _ = admit("c-1", "u-2", http.MethodGet)
+	assert.Equal(t, "", limit)
+	limit, _ = admit("c-1", "u-3", http.MethodGet)
+	assert.Equal(t, "cluster", limit)
+
+	// a request rejected by the cluster bucket does not take a user token
+	now = now.Add(time.Second)
+	limit, _ = admit("c-1", "u-3", http.MethodGet)
+	assert.Equal(t, "", limit)
+
+	// other clusters have their own buckets
+	limit, _ = admit("c-2", "u-1", http.MethodGet)
+	assert.Equal(t, "", limit)
+
+	// buckets are refilled over time
+	limit, retryAfter = admit("c-1", "u-1", http.MethodGet)
+	assert.Equal(t, "cluster", limit)
+	assert.Equal(t, time.Second, retryAfter)
+	now = now.Add(500 * time.Millisecond)
+	_, retryAfter = admit("c-1", "u-1", http.MethodGet)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+}
+
+func TestRateLimiterOverrides(t *testing.T) {
+	defer settings.K8sProxyUserQPS.Set(settings.K8sProxyUserQPS.Get())
+	defer settings.K8sProxyClusterQPS.Set(settings.K8sProxyClusterQPS.Get())
+	defer settings.K8sProxyClusterBurst.Set(settings.K8sProxyClusterBurst.Get())
+	defer settings.K8sProxyRateLimits.Set(settings.K8sProxyRateLimits.Get())
+
+	now := time.Now()
+	r := newRateLimiter()
+	r.now = func() time.Time {
+		return now
+	}
+	admitted := func(clusterID, method, url string) int {
+		count := 0
+		for i := 0; i < 10; i++ {
+			req := httptest.NewRequest(method, "/k8s/clusters/"+clusterID+url, nil)
+			if limit, _ := r.admit(clusterID, req); limit == "" {
+				count++
+			}
+		}
+		return count
+	}
+
+	settings.K8sProxyUserQPS.Set("0")
+	settings.K8sProxyClusterQPS.Set("1")
+	settings.K8sProxyClusterBurst.Set("2")
+	settings.K8sProxyRateLimits.Set(`{
+		"lanes": {"watch": {"clusterBurst": 1}, "mutating": {"clusterQps": 0}},
+		"clusters": {"c-2": {"clusterBurst": 4, "lanes": {"read": {"clusterBurst": 6}}}}
+	}`)
+
+	assert.Equal(t, 2, admitted("c-1", http.MethodGet, "/api/v1/pods"))
+	assert.Equal(t, 1, admitted("c-1", http.MethodGet, "/api/v1/pods?watch=true"))
+	assert.Equal(t, 10, admitted("c-1", http.MethodPost, "/api/v1/pods"))
+	assert.Equal(t, 6, admitted("c-2", http.MethodGet, "/api/v1/pods"))
+	assert.Equal(t, 4, admitted("c-2", http.MethodGet, "/api/v1/pods?watch=true"))
+	assert.Equal(t, 10, admitted("c-2", http.MethodPost, "/api/v1/pods"))
+
+	// an invalid setting is ignored
+	settings.K8sProxyRateLimits.Set(`{"lanes": {"other": {}}}`)
+	now = now.Add(time.Hour)
+	assert.Equal(t, 2, admitted("c-1", http.MethodGet, "/api/v1/pods?watch=true"))
+}
+
+func TestThrottledResponse(t *testing.T) {
+	rw := httptest.NewRecorder()
+	throttledResponse(rw, "user", 100*time.Millisecond)
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+	assert.Equal(t, "1", rw.Header().Get("Retry-After"))
+}
@@ -0,0 +1,132 @@
+package ratelimits
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/rancher/pkg/settings"
+)
+
+// The requests proxied to a cluster are rate limited per lane. The limits default to the k8s-proxy-* settings and can
+// be set per lane and per cluster in the k8s-proxy-rate-limits setting, which is how lanes are prioritized, e.g.
+//
+//	{
+//	  "lanes": {"watch": {"clusterQps": 20, "userQps": 2}, "mutating": {"clusterQps": 100}},
+//	  "clusters": {"c-abcde": {"userQps": 20, "lanes": {"watch": {"clusterQps": 50}}}}
+//	}
+
+const (
+	LaneWatch    = "watch"
+	LaneMutating = "mutating"
+	LaneRead     = "read"
+)
+
+// Limits overrides the limits of a lane, unset limits are inherited
+type Limits struct {
+	ClusterQPS   *int `json:"clusterQps,omitempty"`
+	ClusterBurst *int `json:"clusterBurst,omitempty"`
+	UserQPS      *int `json:"userQps,omitempty"`
+	UserBurst    *int `json:"userBurst,omitempty"`
+}
+
+// ClusterLimits overrides the limits of all lanes of a cluster, and of single lanes in Lanes
+type ClusterLimits struct {
+	Limits
+	Lanes map[string]Limits `json:"lanes,omitempty"`
+}
+
+// RateLimits is the value of the k8s-proxy-rate-limits setting
+type RateLimits struct {
+	Lanes    map[string]Limits        `json:"lanes,omitempty"`
+	Clusters map[string]ClusterLimits `json:"clusters,omitempty"`
+}
+
+// Parse parses and validates the value of the k8s-proxy-rate-limits setting
+func Parse(value string) (*RateLimits, error) {
+	rateLimits := &RateLimits{}
+	if strings.TrimSpace(value) == "" {
+		return rateLimits, nil
+	}
+	if err := json.Unmarshal([]byte(value), rateLimits); err != nil {
+		return nil, fmt.Errorf("invalid rate limits: %v", err)
+	}
+	if err := validateLanes(rateLimits.Lanes); err != nil {
+		return nil, err
+	}
+	for clusterID, clusterLimits := range rateLimits.Clusters {
+		if err := validateLimits(clusterLimits.Limits); err != nil {
+			return nil, fmt.Errorf("cluster %s: %v", clusterID, err)
+		}
+		if err := validateLanes(clusterLimits.Lanes); err != nil {
+			return nil, fmt.Errorf("cluster %s: %v", clusterID, err)
+		}
+	}
+	return rateLimits, nil
+}
+
+func validateLanes(lanes map[string]Limits) error {
+	for lane, limits := range lanes {
+		switch lane {
+		case LaneWatch, LaneMutating, LaneRead:
+		default:
+			return fmt.Errorf("unknown lane %s, expected one of %s, %s or %s", lane, LaneWatch, LaneMutating, LaneRead)
+		}
+		if err := validateLimits(limits); err != nil {
+			return fmt.Errorf("lane %s: %v", lane, err)
+		}
+	}
+	return nil
+}
+
+func validateLimits(limits Limits) error {
+	for name, value := range map[string]*int{
+		"clusterQps":   limits.ClusterQPS,
+		"clusterBurst": limits.ClusterBurst,
+		"userQps":      limits.UserQPS,
+		"userBurst":    limits.UserBurst,
+	} {
+		if value != nil && *value < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// LaneLimits are the limits in effect for a lane of a cluster, a qps of 0 disables the limit
+type LaneLimits struct {
+	ClusterQPS, ClusterBurst int
+	UserQPS, UserBurst       int
+}
+
+func (l *LaneLimits) override(limits Limits) {
+	if limits.ClusterQPS != nil {
+		l.ClusterQPS = *limits.ClusterQPS
+	}
+	if limits.ClusterBurst != nil {
+		l.ClusterBurst = *limits.ClusterBurst
+	}
+	if limits.UserQPS != nil {
+		l.UserQPS = *limits.UserQPS
+	}
+	if limits.UserBurst != nil {
+		l.UserBurst = *limits.UserBurst
+	}
+}
+
+// For returns the limits of a lane of a cluster, the settings are overridden by the limits of the lane, then by the
+// limits of the cluster and finally by the limits of the lane of the cluster
+func (r *RateLimits) For(clusterID, lane string) LaneLimits {
+	l := LaneLimits{
+		ClusterQPS:   settings.K8sProxyClusterQPS.GetInt(),
+		ClusterBurst: settings.K8sProxyClusterBurst.GetInt(),
+		UserQPS:      settings.K8sProxyUserQPS.GetInt(),
+		UserBurst:    settings.K8sProxyUserBurst.GetInt(),
+	}
+	l.override(r.Lanes[lane])
+	if clusterLimits, ok := r.Clusters[clusterID]; ok {
+		l.override(clusterLimits.Limits)
+		l.override(clusterLimits.Lanes[lane])
+	}
+	return l
+}
@@ -0,0 +1,24 @@
+package ratelimits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"":                                     true,
+		`{"lanes": {"watch": {"userQps": 1}}}`: true,
+		`{"clusters": {"c-1": {"clusterQps": 1}}}`:                     true,
+		`{"clusters": {"c-1": {"lanes": {"read": {"userBurst": 5}}}}}`: true,
+		`{"lanes": {"other": {"userQps": 1}}}`:                         false,
+		`{"lanes": {"watch": {"userQps": -1}}}`:                        false,
+		`{"clusters": {"c-1": {"lanes": {"write": {}}}}}`:              false,
+		`{"clusters": {"c-1": {"userBurst": -1}}}`:                     false,
+		`{"lanes": []}`: false,
+	} {
+		_, err := Parse(value)
+		assert.Equal(t, valid, err == nil, value)
+	}
+}
@@ -12,12 +12,14 @@ import (
 
 type Router struct {
 	serverFactory *factory
+	rateLimiter   *rateLimiter
 }
 
 func New(localConfig *rest.Config, lookup ClusterLookup, dialer dialer.Factory, clusterLister v3.ClusterLister) http.Handler {
 	serverFactory := newFactory(localConfig, dialer, lookup, clusterLister)
 	return &Router{
 		serverFactory: serverFactory,
+		rateLimiter:   newRateLimiter(),
 	}
 }
 
@@ -38,6 +40,11 @@ func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if limit, retryAfter := r.rateLimiter.admit(c.Name, req); limit != "" {
+		throttledResponse(rw, limit, retryAfter)
+		return
+	}
+
 	handler.ServeHTTP(rw, req)
 }
 
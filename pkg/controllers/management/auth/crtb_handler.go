@@ -51,7 +51,8 @@ var clusterManagmentPlaneResources = map[string]string{
 	"nodepools":                   "management.cattle.io",
 	"notifiers":                   "management.cattle.io",
 	"podsecuritypolicytemplateprojectbindings": "management.cattle.io",
-	"projects": "management.cattle.io",
+	"projects":        "management.cattle.io",
+	"shellrecordings": "management.cattle.io",
 }
 
 type crtbLifecycle struct {
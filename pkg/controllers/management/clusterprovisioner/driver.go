@@ -11,10 +11,16 @@ import (
 	"github.com/rancher/rancher/pkg/clusterprovisioninglogger"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/kontainer-engine/service"
+	"github.com/rancher/rancher/pkg/kontainer-engine/store"
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/rancher/rancher/pkg/ref"
 	"github.com/rancher/rke/services"
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const DriverNameField = "driverName"
@@ -34,9 +40,79 @@ func (p *Provisioner) driverCreate(cluster *v3.Cluster, spec v32.ClusterSpec) (a
 		return "", "", "", err
 	}
 
+	if cluster.Annotations[AdoptAnnotation] != "" {
+		state, err := p.getAdoptState(cluster)
+		if err != nil {
+			return "", "", "", err
+		}
+		return p.engineService.Adopt(ctx, cluster.Name, kontainerDriver, spec, state.Cluster)
+	}
+
 	return p.engineService.Create(ctx, cluster.Name, kontainerDriver, spec)
 }
 
+// getAdoptState decrypts the archive of a cluster exported by the kontainer-engine cli. The secret holding it must be in
+// the global data namespace and have been claimed for the cluster by the cluster store.
+func (p *Provisioner) getAdoptState(cluster *v3.Cluster) (*store.Archive, error) {
+	secret, err := p.getAdoptSecret(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return store.DecryptArchive(secret.Data["archive"], string(secret.Data["passphrase"]))
+}
+
+func (p *Provisioner) getAdoptSecret(cluster *v3.Cluster) (*corev1.Secret, error) {
+	secretRef := cluster.Annotations[AdoptAnnotation]
+	secretNamespace, name := ref.Parse(secretRef)
+	if secretNamespace != "" && secretNamespace != namespace.GlobalNamespace {
+		return nil, fmt.Errorf("adopt secret %s must be in namespace %s", secretRef, namespace.GlobalNamespace)
+	}
+	secret, err := p.SecretLister.Get(namespace.GlobalNamespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adopt secret %s: %v", secretRef, err)
+	}
+	if !IsAdoptSecretOf(secret, cluster) {
+		return nil, fmt.Errorf("adopt secret %s was not claimed for cluster %s", secretRef, cluster.Name)
+	}
+	return secret, nil
+}
+
+// deleteAdoptSecret deletes the adopt secret of a cluster once the cluster is provisioned, the archive holds the
+// credentials of the cluster
+func (p *Provisioner) deleteAdoptSecret(cluster *v3.Cluster) {
+	if cluster.Annotations[AdoptAnnotation] == "" {
+		return
+	}
+	secret, err := p.getAdoptSecret(cluster)
+	if err != nil {
+		logrus.Warnf("Not deleting the adopt secret of cluster [%s]: %v", cluster.Name, err)
+		return
+	}
+	if err := p.Secrets.DeleteNamespaced(secret.Namespace, secret.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		logrus.Warnf("Failed to delete the adopt secret of cluster [%s]: %v", cluster.Name, err)
+	}
+}
+
+// AdoptSecretOwnerReference is the owner reference set on an adopt secret when it is claimed for a cluster
+func AdoptSecretOwnerReference(clusterName string, clusterUID types.UID) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: v32.SchemeGroupVersion.String(),
+		Kind:       v3.ClusterGroupVersionKind.Kind,
+		Name:       clusterName,
+		UID:        clusterUID,
+		Controller: &controller,
+	}
+}
+
+// IsAdoptSecretOf reports whether an adopt secret was claimed for a cluster. Users can't forge the claim as it names
+// the UID the cluster got on creation.
+func IsAdoptSecretOf(secret *corev1.Secret, cluster *v3.Cluster) bool {
+	owner := metav1.GetControllerOf(secret)
+	return owner != nil && owner.APIVersion == v32.SchemeGroupVersion.String() && owner.Kind == v3.ClusterGroupVersionKind.Kind &&
+		owner.Name == cluster.Name && owner.UID == cluster.UID
+}
+
 func (p *Provisioner) getKontainerDriver(spec v32.ClusterSpec) (*v3.KontainerDriver, error) {
 	if spec.GenericEngineConfig != nil {
 		return p.KontainerDriverLister.Get("", (*spec.GenericEngineConfig)[DriverNameField].(string))
@@ -20,6 +20,7 @@ import (
 	util "github.com/rancher/rancher/pkg/cluster"
 	kd "github.com/rancher/rancher/pkg/controllers/management/kontainerdrivermetadata"
 	v1 "github.com/rancher/rancher/pkg/generated/norman/apps/v1"
+	corev1 "github.com/rancher/rancher/pkg/generated/norman/core/v1"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/kontainer-engine/drivers/rke"
 	"github.com/rancher/rancher/pkg/kontainer-engine/service"
@@ -42,6 +43,10 @@ const (
 	RKEDriverKey          = "rancherKubernetesEngineConfig"
 	KontainerEngineUpdate = "provisioner.cattle.io/ke-driver-update"
 	RkeRestoreAnnotation  = "rke.cattle.io/restore"
+	// AdoptAnnotation names the secret in the global data namespace holding the archive of a cluster exported by the
+	// kontainer-engine cli and its passphrase; the cluster is adopted instead of created. The cluster store claims the
+	// secret for the cluster when the cluster is created through the API, and the secret is deleted once adopted.
+	AdoptAnnotation = "provisioner.cattle.io/adopt-secret"
 )
 
 type Provisioner struct {
@@ -54,6 +59,8 @@ type Provisioner struct {
 	KontainerDriverLister v3.KontainerDriverLister
 	DynamicSchemasLister  v3.DynamicSchemaLister
 	DaemonsetLister       v1.DaemonSetLister
+	SecretLister          corev1.SecretLister
+	Secrets               corev1.SecretInterface
 	Backups               v3.EtcdBackupLister
 	RKESystemImages       v3.RkeK8sSystemImageInterface
 	RKESystemImagesLister v3.RkeK8sSystemImageLister
@@ -73,6 +80,8 @@ func Register(ctx context.Context, management *config.ManagementContext) {
 		RKESystemImagesLister: management.Management.RkeK8sSystemImages("").Controller().Lister(),
 		RKESystemImages:       management.Management.RkeK8sSystemImages(""),
 		DaemonsetLister:       management.Apps.DaemonSets("").Controller().Lister(),
+		SecretLister:          management.Core.Secrets("").Controller().Lister(),
+		Secrets:               management.Core.Secrets(""),
 	}
 	// Add handlers
 	p.Clusters.AddLifecycle(ctx, "cluster-provisioner-controller", p)
@@ -590,6 +599,10 @@ func (p *Provisioner) reconcileCluster(cluster *v3.Cluster, create bool) (*v3.Cl
 		return cluster, fmt.Errorf("failed to update cluster")
 	}
 
+	if create {
+		p.deleteAdoptSecret(cluster)
+	}
+
 	if cluster.Status.AppliedSpec.RancherKubernetesEngineConfig != nil {
 		logrus.Infof("Updated cluster [%s] with node version [%v]", cluster.Name, cluster.Status.NodeVersion)
 		p.reconcileForUpgrade(cluster.Name)
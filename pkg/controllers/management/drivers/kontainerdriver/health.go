@@ -0,0 +1,60 @@
+package kontainerdriver
+
+import (
+	"context"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/wrangler/pkg/ticker"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	healthSyncInterval = time.Minute
+)
+
+// healthSyncer reports the health of the driver processes run by the kontainer engine in the driver status. It is
+// registered with the management controllers and only runs on the leader, so the status is the health of the
+// processes of the leader and replicas don't overwrite each other. The health a previous leader reported for a driver
+// the leader does not run is cleared.
+type healthSyncer struct {
+	kontainerDrivers      v3.KontainerDriverInterface
+	kontainerDriverLister v3.KontainerDriverLister
+	driverHealth          func(name string) *v32.KontainerDriverHealth
+}
+
+func (s *healthSyncer) sync(ctx context.Context, syncInterval time.Duration) {
+	for range ticker.Context(ctx, syncInterval) {
+		s.syncHealth()
+	}
+}
+
+func (s *healthSyncer) syncHealth() {
+	kontainerDrivers, err := s.kontainerDriverLister.List("", labels.Everything())
+	if err != nil {
+		logrus.Errorf("Error listing kontainer drivers - %v", err)
+		return
+	}
+	for _, kontainerDriver := range kontainerDrivers {
+		health := s.driverHealth(kontainerDriver.Name)
+		if health == nil && kontainerDriver.Status.Health == nil ||
+			health != nil && !healthChanged(kontainerDriver.Status.Health, health) {
+			continue
+		}
+		toUpdate := kontainerDriver.DeepCopy()
+		toUpdate.Status.Health = health
+		if _, err := s.kontainerDrivers.Update(toUpdate); err != nil {
+			logrus.Warnf("failed to update health of kontainer driver %s: %v", kontainerDriver.Name, err)
+		}
+	}
+}
+
+// healthChanged ignores the time of the last check so that the status is only updated when the health changes
+func healthChanged(current, health *v32.KontainerDriverHealth) bool {
+	return current == nil ||
+		current.State != health.State ||
+		current.Message != health.Message ||
+		current.Restarts != health.Restarts
+}
@@ -0,0 +1,51 @@
+package kontainerdriver
+
+import (
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3/fakes"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSyncHealth(t *testing.T) {
+	healthy := &v32.KontainerDriverHealth{State: v32.KontainerDriverHealthy, LastChecked: "1"}
+	drivers := []*v3.KontainerDriver{
+		{ObjectMeta: metav1.ObjectMeta{Name: "unchanged"}, Status: v32.KontainerDriverStatus{Health: healthy}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "changed"}, Status: v32.KontainerDriverStatus{Health: healthy}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "stale"}, Status: v32.KontainerDriverStatus{Health: healthy}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "not-running"}},
+	}
+	health := map[string]*v32.KontainerDriverHealth{
+		"unchanged": {State: v32.KontainerDriverHealthy, LastChecked: "2"},
+		"changed":   {State: v32.KontainerDriverUnhealthy, Message: "timeout", LastChecked: "2"},
+	}
+
+	updated := map[string]*v32.KontainerDriverHealth{}
+	s := &healthSyncer{
+		kontainerDrivers: &fakes.KontainerDriverInterfaceMock{
+			UpdateFunc: func(in *v3.KontainerDriver) (*v3.KontainerDriver, error) {
+				updated[in.Name] = in.Status.Health
+				return in, nil
+			},
+		},
+		kontainerDriverLister: &fakes.KontainerDriverListerMock{
+			ListFunc: func(namespace string, selector labels.Selector) ([]*v3.KontainerDriver, error) {
+				return drivers, nil
+			},
+		},
+		driverHealth: func(name string) *v32.KontainerDriverHealth {
+			return health[name]
+		},
+	}
+	s.syncHealth()
+
+	assert.Equal(t, map[string]*v32.KontainerDriverHealth{
+		"changed": health["changed"],
+		"stale":   nil,
+	}, updated)
+	assert.Equal(t, healthy, drivers[2].Status.Health, "the cached driver is not modified")
+}
@@ -41,6 +41,13 @@ func Register(ctx context.Context, management *config.ManagementContext) {
 	}
 
 	management.Management.KontainerDrivers("").AddLifecycle(ctx, "mgmt-kontainer-driver-lifecycle", lifecycle)
+
+	syncer := &healthSyncer{
+		kontainerDrivers:      management.Management.KontainerDrivers(""),
+		kontainerDriverLister: management.Management.KontainerDrivers("").Controller().Lister(),
+		driverHealth:          service.GetDriverHealth,
+	}
+	go syncer.sync(ctx, healthSyncInterval)
 }
 
 type Lifecycle struct {
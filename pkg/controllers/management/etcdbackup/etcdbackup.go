@@ -24,6 +24,7 @@ import (
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/kontainer-engine/drivers/rke"
 	"github.com/rancher/rancher/pkg/kontainer-engine/service"
+	"github.com/rancher/rancher/pkg/maintenance"
 	"github.com/rancher/rancher/pkg/rkedialerfactory"
 	"github.com/rancher/rancher/pkg/types/config"
 	"github.com/rancher/rancher/pkg/types/config/dialer"
@@ -133,6 +134,10 @@ func (c *Controller) Updated(b *v3.EtcdBackup) (runtime.Object, error) {
 
 func (c *Controller) clusterBackupSync(ctx context.Context, interval time.Duration) error {
 	for range ticker.Context(ctx, interval) {
+		if maintenance.Enabled() {
+			logrus.Debugf("[etcd-backup] maintenance mode, skipping scheduled backups")
+			continue
+		}
 		clusters, err := c.clusterLister.List("", labels.NewSelector())
 		if err != nil {
 			logrus.Error(fmt.Errorf("[etcd-backup] clusterBackupSync faild: %v", err))
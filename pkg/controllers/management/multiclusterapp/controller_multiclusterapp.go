@@ -16,6 +16,7 @@ import (
 	"github.com/rancher/rancher/pkg/controllers/management/rbac"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	pv3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/maintenance"
 	"github.com/rancher/rancher/pkg/namespace"
 	"github.com/rancher/rancher/pkg/ref"
 	"github.com/rancher/rancher/pkg/types/config"
@@ -66,6 +67,7 @@ func StartMCAppManagementController(ctx context.Context, mgmt *config.Management
 	}
 	mcAppTickerData = map[string]*IntervalData{}
 	m.multiClusterApps.AddHandler(ctx, "multi-cluster-app-controller", m.sync)
+	m.multiClusterApps.AddHandler(ctx, "multi-cluster-app-drift-controller", m.detectDrift)
 }
 
 func (m *MCAppManager) sync(key string, mcapp *v3.MultiClusterApp) (runtime.Object, error) {
@@ -78,6 +80,12 @@ func (m *MCAppManager) sync(key string, mcapp *v3.MultiClusterApp) (runtime.Obje
 		return m.deleteApps(mcappName, mcapp)
 	}
 
+	if maintenance.Enabled() {
+		logrus.Debugf("maintenance mode, pausing rollout of multiclusterapp %s", mcapp.Name)
+		m.multiClusterApps.Controller().EnqueueAfter(mcapp.Namespace, mcapp.Name, maintenance.RecheckInterval)
+		return mcapp, nil
+	}
+
 	// creatorID is actual user who created mcapp, to be used for mcapp revisions of this mcapp
 	metaAccessor, err := meta.Accessor(mcapp)
 	if err != nil {
@@ -94,25 +102,49 @@ func (m *MCAppManager) sync(key string, mcapp *v3.MultiClusterApp) (runtime.Obje
 	}
 	systemUserName := systemUser.Name
 
-	answerMap, err := m.createAnswerMap(mcapp.Spec.Answers)
-	if err != nil {
+	mcapp = mcapp.DeepCopy()
+	if err := m.reconcileTargetsForDelete(mcapp); err != nil {
 		return mcapp, err
 	}
 
-	externalID, mcapp, err := m.getExternalID(mcapp)
+	changed, err := m.isChanged(mcapp)
 	if err != nil {
 		return mcapp, err
 	}
 
-	mcapp = mcapp.DeepCopy()
-	if err := m.reconcileTargetsForDelete(mcapp); err != nil {
+	aborted, err := isAborted(mcapp)
+	if err != nil {
 		return mcapp, err
 	}
+	if mcapp.Status.AbortedRollout != nil && !aborted {
+		// the spec changed since the rollout was aborted, a rollback in progress is only finished if the spec
+		// is back to the current revision, otherwise the new spec is rolled out
+		mcapp.Status.AbortedRollout = nil
+		if isRollingBack(mcapp) && changed {
+			setInstalledAborted(mcapp, "spec changed while rolling back")
+		}
+		return m.multiClusterApps.Update(mcapp)
+	}
 
-	changed, err := m.isChanged(mcapp)
+	templateVersionName, answers, err := m.rolloutSpec(mcapp)
+	if err != nil {
+		return mcapp, err
+	}
+	answerMap, err := m.createAnswerMap(answers)
 	if err != nil {
 		return mcapp, err
 	}
+	externalID, err := m.getExternalID(templateVersionName)
+	if err != nil {
+		return mcapp, err
+	}
+
+	// the apps that were upgraded before a rollout was aborted are rolled back to the revision recorded in the
+	// status, the spec still differs from the current revision
+	rollback := isRollingBack(mcapp)
+	if rollback {
+		changed = true
+	}
 
 	toUpdate := false
 	if changed {
@@ -123,7 +155,7 @@ func (m *MCAppManager) sync(key string, mcapp *v3.MultiClusterApp) (runtime.Obje
 	}
 
 	batchSize := len(mcapp.Spec.Targets)
-	if toUpdate && mcapp.Spec.UpgradeStrategy.RollingUpdate != nil {
+	if toUpdate && !rollback && mcapp.Spec.UpgradeStrategy.RollingUpdate != nil {
 		if mcapp.Spec.UpgradeStrategy.RollingUpdate.Interval != 0 {
 			batchSize = mcapp.Spec.UpgradeStrategy.RollingUpdate.BatchSize
 		}
@@ -141,12 +173,31 @@ func (m *MCAppManager) sync(key string, mcapp *v3.MultiClusterApp) (runtime.Obje
 		return mcapp, nil
 	}
 
-	if resp.count == len(mcapp.Spec.Targets) && v32.MultiClusterAppConditionInstalled.IsUnknown(mcapp) &&
-		v32.MultiClusterAppConditionInstalled.GetMessage(mcapp) == "upgrading" {
+	if resp.count == len(mcapp.Spec.Targets) && rollback {
+		setRolledBack(mcapp)
+		return m.updateCondition(mcapp, setRolledBack)
+	}
+
+	if resp.count == len(mcapp.Spec.Targets) && isUpgrading(mcapp) {
 		deleteContext(mcapp.Name)
 		return m.setRevisionAndUpdate(mcapp, creatorID)
 	}
 
+	reason, failed, err := rolloutFailed(mcapp, resp)
+	if err != nil {
+		return mcapp, err
+	}
+	if failed {
+		return m.abortRollout(mcapp, reason)
+	}
+
+	if isUpgrading(mcapp) && len(resp.pending) > 0 && mcapp.Spec.UpgradeStrategy.RollingUpdate != nil &&
+		mcapp.Spec.UpgradeStrategy.RollingUpdate.BatchTimeout > 0 {
+		// make sure the batch timeout is checked again even if none of the apps change
+		m.multiClusterApps.Controller().EnqueueAfter(namespace.GlobalNamespace, mcapp.Name,
+			time.Duration(mcapp.Spec.UpgradeStrategy.RollingUpdate.BatchTimeout)*time.Second)
+	}
+
 	if !toUpdate || resp.remaining <= 0 {
 		return mcapp, nil
 	}
@@ -161,6 +212,11 @@ func (m *MCAppManager) sync(key string, mcapp *v3.MultiClusterApp) (runtime.Obje
 		}
 	}
 
+	if rollback {
+		// stay rolling back so the mcapp is marked rolled back, not upgraded, once the apps are reverted
+		return mcapp, nil
+	}
+
 	setInstalledUnknown(mcapp)
 	upd, err := m.updateCondition(mcapp, setInstalledUnknown)
 	if err != nil {
@@ -176,6 +232,10 @@ type Response struct {
 	updateApps []*pv3.App
 	remaining  int
 	count      int
+	// pending are the already updated apps that are not installed and deployed yet
+	pending []string
+	// failed are the already updated apps that failed to install
+	failed []string
 }
 
 func (m *MCAppManager) createApps(mcapp *v3.MultiClusterApp, externalID string, answerMap map[string]map[string]string,
@@ -195,9 +255,15 @@ func (m *MCAppManager) createApps(mcapp *v3.MultiClusterApp, externalID string,
 	updateBatchSize := batchSize
 	count := 0
 
+	order, err := m.orderTargets(mcapp)
+	if err != nil {
+		return resp, err
+	}
+
 	// for all targets, create the App{} instance, so that helm controller App lifecycle can pick it up
 	// only one app per project named mcapp-{{mcapp.Name}}
-	for ind, t := range mcapp.Spec.Targets {
+	for _, ind := range order {
+		t := mcapp.Spec.Targets[ind]
 		split := strings.SplitN(t.ProjectName, ":", 2)
 		if len(split) != 2 {
 			return resp, fmt.Errorf("error in splitting project ID %v", t.ProjectName)
@@ -223,6 +289,11 @@ func (m *MCAppManager) createApps(mcapp *v3.MultiClusterApp, externalID string,
 				if !v33.AppConditionInstalled.IsTrue(app) || !v33.AppConditionDeployed.IsTrue(app) {
 					toUpdate = false
 					updateApps = []*pv3.App{}
+					if v33.AppConditionInstalled.IsFalse(app) {
+						resp.failed = append(resp.failed, app.Name)
+					} else {
+						resp.pending = append(resp.pending, app.Name)
+					}
 				}
 				continue
 			}
@@ -361,7 +432,13 @@ func (m *MCAppManager) isChanged(mcapp *v3.MultiClusterApp) (bool, error) {
 }
 
 func (m *MCAppManager) toUpdate(mcapp *v3.MultiClusterApp) (bool, error) {
-	if v32.MultiClusterAppConditionInstalled.IsUnknown(mcapp) && v32.MultiClusterAppConditionInstalled.GetMessage(mcapp) == "upgrading" {
+	if isRollingBack(mcapp) {
+		return true, nil
+	}
+	if aborted, err := isAborted(mcapp); err != nil || aborted {
+		return false, err
+	}
+	if isUpgrading(mcapp) {
 		lastUpdated, err := time.Parse(time.RFC3339, v32.MultiClusterAppConditionInstalled.GetLastUpdated(mcapp))
 		if err != nil {
 			return false, err
@@ -568,12 +645,14 @@ func (m *MCAppManager) updateCondition(mcappToUpdate *v3.MultiClusterApp, setCon
 
 func setInstalledUnknown(mcapp *v3.MultiClusterApp) {
 	v32.MultiClusterAppConditionInstalled.Unknown(mcapp)
-	v32.MultiClusterAppConditionInstalled.Message(mcapp, "upgrading")
+	v32.MultiClusterAppConditionInstalled.Reason(mcapp, "")
+	v32.MultiClusterAppConditionInstalled.Message(mcapp, upgrading)
 	v32.MultiClusterAppConditionInstalled.LastUpdated(mcapp, time.Now().Format(time.RFC3339))
 }
 
 func setInstalledDone(mcapp *v3.MultiClusterApp) {
 	v32.MultiClusterAppConditionInstalled.True(mcapp)
+	v32.MultiClusterAppConditionInstalled.Reason(mcapp, "")
 	v32.MultiClusterAppConditionInstalled.Message(mcapp, "")
 }
 
@@ -628,21 +707,21 @@ func (m *MCAppManager) createAnswerMap(answers []v32.Answer) (map[string]map[str
 }
 
 // getExternalID gets the TemplateVersion.Spec.ExternalID field
-func (m *MCAppManager) getExternalID(mcapp *v3.MultiClusterApp) (string, *v3.MultiClusterApp, error) {
+func (m *MCAppManager) getExternalID(templateVersionID string) (string, error) {
 	// create the externalID field, it's also present on the templateVersion. So get the templateVersion and read its externalID field
-	split := strings.SplitN(mcapp.Spec.TemplateVersionName, ":", 2)
+	split := strings.SplitN(templateVersionID, ":", 2)
 	templateVersionNamespace := split[0]
 	templateVersionName := split[1]
 	tv, err := m.templateVersionLister.Get(templateVersionNamespace, templateVersionName)
 	if err != nil {
-		return "", mcapp, err
+		return "", err
 	}
 	if tv == nil {
-		return "", mcapp, fmt.Errorf("invalid templateVersion provided: %v", mcapp.Spec.TemplateVersionName)
+		return "", fmt.Errorf("invalid templateVersion provided: %v", templateVersionID)
 	}
 
 	externalID := tv.Spec.ExternalID
-	return externalID, mcapp, nil
+	return externalID, nil
 }
 
 func getAppNamespaceName(mcappName, projectNS string) string {
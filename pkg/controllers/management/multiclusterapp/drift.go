@@ -0,0 +1,172 @@
+package multiclusterapp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v33 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/ref"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	driftDetectedReason = "DriftDetected"
+	driftRevertedReason = "DriftReverted"
+)
+
+// detectDrift compares the app of every target with the current revision of mcapp and reports the result in the
+// Drifted condition of the target in the status of mcapp. Apps are reset to the revision if the mcapp asks for drift
+// to be reverted.
+func (m *MCAppManager) detectDrift(key string, mcapp *v3.MultiClusterApp) (runtime.Object, error) {
+	if mcapp == nil || mcapp.DeletionTimestamp != nil || mcapp.Status.RevisionName == "" {
+		return mcapp, nil
+	}
+	// apps are expected to differ from the revision while a rollout is in progress
+	if isUpgrading(mcapp) || isRollingBack(mcapp) {
+		return mcapp, nil
+	}
+	if aborted, err := isAborted(mcapp); err != nil || aborted {
+		return mcapp, err
+	}
+	changed, err := m.isChanged(mcapp)
+	if err != nil || changed {
+		return mcapp, err
+	}
+
+	// the spec matches the current revision, so the template version and answers of the spec are the ones to compare with
+	externalID, err := m.getExternalID(mcapp.Spec.TemplateVersionName)
+	if err != nil {
+		return mcapp, err
+	}
+	answerMap, err := m.createAnswerMap(mcapp.Spec.Answers)
+	if err != nil {
+		return mcapp, err
+	}
+
+	var targetStatuses []v32.TargetStatus
+	for _, t := range mcapp.Spec.Targets {
+		if t.AppName == "" {
+			continue
+		}
+		_, projectNS := ref.Parse(t.ProjectName)
+		app, err := m.appLister.Get(projectNS, t.AppName)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return mcapp, err
+		}
+
+		conditions := targetConditions(mcapp.Status.TargetStatuses, t.ProjectName)
+		drift := appDrift(app.Spec.ExternalID, app.Spec.Answers, externalID, getAnswerMap(answerMap, t.ProjectName))
+		switch {
+		case drift == "":
+			conditions = setTargetDrift(conditions, v1.ConditionFalse, "", "")
+		case !mcapp.Spec.RevertDrift:
+			conditions = setTargetDrift(conditions, v1.ConditionTrue, driftDetectedReason, drift)
+		default:
+			logrus.Infof("[mcapp] reverting app %s of multiclusterapp %s in project %s: %s", t.AppName, mcapp.Name, t.ProjectName, drift)
+			if _, err := m.updateApp(app, answerMap, externalID, t.ProjectName); err != nil {
+				return mcapp, err
+			}
+			conditions = setTargetDrift(conditions, v1.ConditionTrue, driftRevertedReason, "reverted to revision "+mcapp.Status.RevisionName+": "+drift)
+		}
+		if len(conditions) > 0 {
+			targetStatuses = append(targetStatuses, v32.TargetStatus{
+				ProjectName: t.ProjectName,
+				AppName:     t.AppName,
+				Conditions:  conditions,
+			})
+		}
+	}
+
+	if len(targetStatuses) == 0 && len(mcapp.Status.TargetStatuses) == 0 || reflect.DeepEqual(targetStatuses, mcapp.Status.TargetStatuses) {
+		return mcapp, nil
+	}
+	toUpdate := mcapp.DeepCopy()
+	toUpdate.Status.TargetStatuses = targetStatuses
+	return m.multiClusterApps.Update(toUpdate)
+}
+
+// appDrift describes how the external ID and answers of an app differ from the expected ones, empty if they do not
+func appDrift(externalID string, answers map[string]string, expectedExternalID string, expectedAnswers map[string]string) string {
+	var drift []string
+	if externalID != expectedExternalID {
+		drift = append(drift, fmt.Sprintf("externalID is %s instead of %s", externalID, expectedExternalID))
+	}
+
+	var keys []string
+	for k, v := range answers {
+		if expected, ok := expectedAnswers[k]; !ok || expected != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range expectedAnswers {
+		if _, ok := answers[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		drift = append(drift, "answers changed: "+strings.Join(keys, ", "))
+	}
+	return strings.Join(drift, "; ")
+}
+
+// targetConditions returns the conditions reported in statuses for the target of projectName
+func targetConditions(statuses []v32.TargetStatus, projectName string) []v33.AppCondition {
+	for _, status := range statuses {
+		if status.ProjectName == projectName {
+			return status.Conditions
+		}
+	}
+	return nil
+}
+
+// setTargetDrift sets the Drifted condition in the conditions of a target. conditions is returned as is if the
+// condition did not change, and no condition is added for a target that never drifted.
+func setTargetDrift(conditions []v33.AppCondition, status v1.ConditionStatus, reason, message string) []v33.AppCondition {
+	existing := -1
+	for i, c := range conditions {
+		if c.Type == v32.TargetConditionDrifted {
+			existing = i
+			break
+		}
+	}
+	if existing == -1 && status == v1.ConditionFalse {
+		return conditions
+	}
+	if existing != -1 {
+		c := conditions[existing]
+		if c.Status == status && c.Reason == reason && c.Message == message {
+			return conditions
+		}
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	cond := v33.AppCondition{
+		Type:               v32.TargetConditionDrifted,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+	}
+	result := append([]v33.AppCondition{}, conditions...)
+	if existing == -1 {
+		return append(result, cond)
+	}
+	if result[existing].Status == status {
+		cond.LastTransitionTime = result[existing].LastTransitionTime
+	}
+	result[existing] = cond
+	return result
+}
@@ -0,0 +1,177 @@
+package multiclusterapp
+
+import (
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v33 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestAppDrift(t *testing.T) {
+	tests := []struct {
+		name               string
+		externalID         string
+		answers            map[string]string
+		expectedExternalID string
+		expectedAnswers    map[string]string
+		drift              string
+	}{
+		{
+			name:               "unchanged",
+			externalID:         "catalog://?catalog=library&template=mysql&version=1.0.0",
+			answers:            map[string]string{"a": "1"},
+			expectedExternalID: "catalog://?catalog=library&template=mysql&version=1.0.0",
+			expectedAnswers:    map[string]string{"a": "1"},
+		},
+		{
+			name:               "no answers",
+			externalID:         "v1",
+			expectedExternalID: "v1",
+			expectedAnswers:    map[string]string{},
+		},
+		{
+			name:               "external id changed",
+			externalID:         "v2",
+			expectedExternalID: "v1",
+			drift:              "externalID is v2 instead of v1",
+		},
+		{
+			name:               "answers changed, added and removed",
+			externalID:         "v1",
+			answers:            map[string]string{"c": "3", "a": "changed", "b": "2"},
+			expectedExternalID: "v1",
+			expectedAnswers:    map[string]string{"a": "1", "b": "2", "d": "4"},
+			drift:              "answers changed: a, c, d",
+		},
+		{
+			name:               "both changed",
+			externalID:         "v2",
+			answers:            map[string]string{"a": "2"},
+			expectedExternalID: "v1",
+			expectedAnswers:    map[string]string{"a": "1"},
+			drift:              "externalID is v2 instead of v1; answers changed: a",
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.drift, appDrift(tt.externalID, tt.answers, tt.expectedExternalID, tt.expectedAnswers), tt.name)
+	}
+}
+
+func TestSetTargetDrift(t *testing.T) {
+	drifted := func(status v1.ConditionStatus, reason, message string) v33.AppCondition {
+		return v33.AppCondition{
+			Type:               v32.TargetConditionDrifted,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			LastUpdateTime:     "2020-01-01T00:00:00Z",
+			LastTransitionTime: "2020-01-01T00:00:00Z",
+		}
+	}
+	other := v33.AppCondition{Type: "Other", Status: v1.ConditionTrue}
+
+	tests := []struct {
+		name       string
+		conditions []v33.AppCondition
+		status     v1.ConditionStatus
+		reason     string
+		message    string
+		// expected is the expected condition, nil if no Drifted condition is expected
+		expected        *v33.AppCondition
+		unchanged       bool
+		keptTransition  bool
+		otherConditions int
+	}{
+		{
+			name:      "never drifted",
+			status:    v1.ConditionFalse,
+			unchanged: true,
+		},
+		{
+			name:       "drift detected",
+			conditions: []v33.AppCondition{other},
+			status:     v1.ConditionTrue,
+			reason:     driftDetectedReason,
+			message:    "externalID is v2 instead of v1",
+			expected: &v33.AppCondition{
+				Status:  v1.ConditionTrue,
+				Reason:  driftDetectedReason,
+				Message: "externalID is v2 instead of v1",
+			},
+			otherConditions: 1,
+		},
+		{
+			name:       "same drift",
+			conditions: []v33.AppCondition{drifted(v1.ConditionTrue, driftDetectedReason, "answers changed: a")},
+			status:     v1.ConditionTrue,
+			reason:     driftDetectedReason,
+			message:    "answers changed: a",
+			unchanged:  true,
+		},
+		{
+			name:       "different drift",
+			conditions: []v33.AppCondition{drifted(v1.ConditionTrue, driftDetectedReason, "answers changed: a")},
+			status:     v1.ConditionTrue,
+			reason:     driftDetectedReason,
+			message:    "answers changed: a, b",
+			expected: &v33.AppCondition{
+				Status:  v1.ConditionTrue,
+				Reason:  driftDetectedReason,
+				Message: "answers changed: a, b",
+			},
+			keptTransition: true,
+		},
+		{
+			name:       "drift resolved",
+			conditions: []v33.AppCondition{other, drifted(v1.ConditionTrue, driftRevertedReason, "reverted")},
+			status:     v1.ConditionFalse,
+			expected: &v33.AppCondition{
+				Status: v1.ConditionFalse,
+			},
+			otherConditions: 1,
+		},
+	}
+	for _, tt := range tests {
+		var before []v33.AppCondition
+		before = append(before, tt.conditions...)
+		conditions := setTargetDrift(tt.conditions, tt.status, tt.reason, tt.message)
+		// the passed conditions belong to the cached object and must not be modified
+		assert.Equal(t, before, tt.conditions, tt.name)
+		if tt.unchanged {
+			assert.Equal(t, tt.conditions, conditions, tt.name)
+			continue
+		}
+
+		var cond *v33.AppCondition
+		others := 0
+		for i := range conditions {
+			if conditions[i].Type == v32.TargetConditionDrifted {
+				cond = &conditions[i]
+			} else {
+				others++
+			}
+		}
+		assert.Equal(t, tt.otherConditions, others, tt.name)
+		if !assert.NotNil(t, cond, tt.name) {
+			continue
+		}
+		assert.Equal(t, tt.expected.Status, cond.Status, tt.name)
+		assert.Equal(t, tt.expected.Reason, cond.Reason, tt.name)
+		assert.Equal(t, tt.expected.Message, cond.Message, tt.name)
+		assert.NotEqual(t, "2020-01-01T00:00:00Z", cond.LastUpdateTime, tt.name)
+		assert.Equal(t, tt.keptTransition, cond.LastTransitionTime == "2020-01-01T00:00:00Z", tt.name)
+	}
+}
+
+func TestTargetConditions(t *testing.T) {
+	conditions := []v33.AppCondition{{Type: v32.TargetConditionDrifted, Status: v1.ConditionTrue}}
+	statuses := []v32.TargetStatus{
+		{ProjectName: "c1:p1", AppName: "a1"},
+		{ProjectName: "c2:p2", AppName: "a2", Conditions: conditions},
+	}
+	assert.Equal(t, conditions, targetConditions(statuses, "c2:p2"))
+	assert.Nil(t, targetConditions(statuses, "c1:p1"))
+	assert.Nil(t, targetConditions(statuses, "c3:p3"))
+}
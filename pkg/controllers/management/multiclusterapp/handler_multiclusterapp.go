@@ -8,6 +8,7 @@ import (
 	"github.com/rancher/rancher/pkg/clustermanager"
 	"github.com/rancher/rancher/pkg/controllers/management/rbac"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	gaccess "github.com/rancher/rancher/pkg/globalnamespaceaccess"
 	"github.com/rancher/rancher/pkg/namespace"
 	"github.com/rancher/rancher/pkg/ref"
 	"github.com/rancher/rancher/pkg/types/config"
@@ -47,12 +48,14 @@ type ProjectController struct {
 	mcAppsLister  v3.MultiClusterAppLister
 	mcApps        v3.MultiClusterAppInterface
 	projectLister v3.ProjectLister
+	clusterLister v3.ClusterLister
 }
 
 type ClusterController struct {
 	mcAppsLister  v3.MultiClusterAppLister
 	mcApps        v3.MultiClusterAppInterface
 	clusterLister v3.ClusterLister
+	projectLister v3.ProjectLister
 }
 
 func Register(ctx context.Context, management *config.ManagementContext, clusterManager *clustermanager.Manager) {
@@ -75,18 +78,38 @@ func Register(ctx context.Context, management *config.ManagementContext, cluster
 		multiClusterAppRevisions: management.Management.MultiClusterAppRevisions(""),
 	}
 	projects := management.Management.Projects("")
+	clusters := management.Management.Clusters("")
 	p := ProjectController{
 		mcAppsLister:  mcApps.Controller().Lister(),
 		mcApps:        mcApps,
 		projectLister: projects.Controller().Lister(),
+		clusterLister: clusters.Controller().Lister(),
 	}
-	clusters := management.Management.Clusters("")
 	c := ClusterController{
 		mcAppsLister:  mcApps.Controller().Lister(),
 		mcApps:        mcApps,
 		clusterLister: clusters.Controller().Lister(),
+		projectLister: projects.Controller().Lister(),
+	}
+	sc := MCAppSelectorController{
+		mcApps:        mcApps,
+		clusterLister: clusters.Controller().Lister(),
+		projectLister: projects.Controller().Lister(),
+		access: &gaccess.MemberAccess{
+			Users:              management.Management.Users(""),
+			RoleTemplateLister: management.Management.RoleTemplates("").Controller().Lister(),
+			PrtbLister:         management.Management.ProjectRoleTemplateBindings("").Controller().Lister(),
+			CrtbLister:         management.Management.ClusterRoleTemplateBindings("").Controller().Lister(),
+			GrbLister:          management.Management.GlobalRoleBindings("").Controller().Lister(),
+			GrLister:           management.Management.GlobalRoles("").Controller().Lister(),
+			Prtbs:              management.Management.ProjectRoleTemplateBindings(""),
+			Crtbs:              management.Management.ClusterRoleTemplateBindings(""),
+			ProjectLister:      projects.Controller().Lister(),
+			ClusterLister:      clusters.Controller().Lister(),
+		},
 	}
 	m.multiClusterApps.AddHandler(ctx, "management-multiclusterapp-rbac-controller", m.sync)
+	m.multiClusterApps.AddHandler(ctx, "management-mcapp-selector-controller", sc.sync)
 	management.Management.MultiClusterAppRevisions("").AddHandler(ctx, "management-multiclusterapp-revisions-rbac", r.sync)
 	projects.AddHandler(ctx, "management-mcapp-project-controller", p.sync)
 	clusters.AddHandler(ctx, "management-mcapp-cluster-controller", c.sync)
@@ -268,7 +291,14 @@ func (r *MCAppRevisionController) updateOwnerRef(mcappRevision *v3.MultiClusterA
 
 func (p *ProjectController) sync(key string, project *v3.Project) (runtime.Object, error) {
 	if project != nil && project.DeletionTimestamp == nil {
-		return project, nil
+		// new or relabeled projects may be matched by target selectors
+		cluster, err := p.clusterLister.Get("", project.Namespace)
+		if apierrors.IsNotFound(err) {
+			return project, nil
+		} else if err != nil {
+			return project, err
+		}
+		return project, enqueueSelectorMCApps(p.mcAppsLister, p.mcApps, p.projectLister, cluster)
 	}
 	splitKey := strings.SplitN(key, "/", 2)
 	if len(splitKey) != 2 || splitKey[0] == "" || splitKey[1] == "" {
@@ -313,7 +343,8 @@ func (p *ProjectController) updateAnswersForProject(projectName string, mcapp *v
 
 func (c *ClusterController) sync(key string, cluster *v3.Cluster) (runtime.Object, error) {
 	if cluster != nil && cluster.DeletionTimestamp == nil {
-		return cluster, nil
+		// new or relabeled clusters may be matched by target selectors
+		return cluster, enqueueSelectorMCApps(c.mcAppsLister, c.mcApps, c.projectLister, cluster)
 	}
 	mcApps, err := c.mcAppsLister.List(namespace.GlobalNamespace, labels.NewSelector())
 	if err != nil {
@@ -0,0 +1,165 @@
+package multiclusterapp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	upgrading            = "upgrading"
+	rollingBack          = "rolling back"
+	orderByPriority      = "priority"
+	rolloutAbortedReason = "RolloutAborted"
+)
+
+// orderTargets returns the indexes of the targets of mcapp in the order they should be rolled out
+func (m *MCAppManager) orderTargets(mcapp *v3.MultiClusterApp) ([]int, error) {
+	order := make([]int, len(mcapp.Spec.Targets))
+	for i := range order {
+		order[i] = i
+	}
+
+	rollingUpdate := mcapp.Spec.UpgradeStrategy.RollingUpdate
+	if rollingUpdate == nil || rollingUpdate.OrderBy == "" {
+		return order, nil
+	}
+
+	keys := make([]string, len(mcapp.Spec.Targets))
+	for i, t := range mcapp.Spec.Targets {
+		if rollingUpdate.OrderBy == orderByPriority {
+			continue
+		}
+		cluster, err := m.clusterLister.Get("", t.ObjClusterName())
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = cluster.Labels[rollingUpdate.OrderBy]
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if rollingUpdate.OrderBy == orderByPriority {
+			return mcapp.Spec.Targets[a].Priority < mcapp.Spec.Targets[b].Priority
+		}
+		return keys[a] < keys[b]
+	})
+	return order, nil
+}
+
+// rolloutFailed checks if the current batch of a rolling update has failed, either because an app reported a failed
+// install or because the apps did not become healthy within the batch timeout.
+func rolloutFailed(mcapp *v3.MultiClusterApp, resp *Response) (string, bool, error) {
+	rollingUpdate := mcapp.Spec.UpgradeStrategy.RollingUpdate
+	if rollingUpdate == nil || !isUpgrading(mcapp) {
+		return "", false, nil
+	}
+	if len(resp.failed) > 0 {
+		return fmt.Sprintf("apps %s failed to install", strings.Join(resp.failed, ", ")), true, nil
+	}
+	if rollingUpdate.BatchTimeout == 0 || len(resp.pending) == 0 {
+		return "", false, nil
+	}
+	lastUpdated, err := time.Parse(time.RFC3339, v32.MultiClusterAppConditionInstalled.GetLastUpdated(mcapp))
+	if err != nil {
+		return "", false, err
+	}
+	if time.Since(lastUpdated) < time.Duration(rollingUpdate.BatchTimeout)*time.Second {
+		return "", false, nil
+	}
+	return fmt.Sprintf("apps %s did not become active within %ds", strings.Join(resp.pending, ", "), rollingUpdate.BatchTimeout), true, nil
+}
+
+// abortRollout stops the rolling update of mcapp and records it in the status, the spec is left as is. If the strategy
+// asks for it the apps are rolled back to the revision that was active before the rollout started, otherwise the
+// rollout stays halted until the spec is changed again.
+func (m *MCAppManager) abortRollout(mcapp *v3.MultiClusterApp, reason string) (*v3.MultiClusterApp, error) {
+	deleteContext(mcapp.Name)
+	logrus.Infof("[mcapp] aborting rollout of multiclusterapp %s: %s", mcapp.Name, reason)
+
+	digest, err := specDigest(mcapp)
+	if err != nil {
+		return mcapp, err
+	}
+	mcapp.Status.AbortedRollout = &v32.AbortedRollout{SpecDigest: digest}
+	if !mcapp.Spec.UpgradeStrategy.RollingUpdate.RollbackOnFailure || mcapp.Status.RevisionName == "" {
+		setInstalledAborted(mcapp, reason)
+		return m.multiClusterApps.Update(mcapp)
+	}
+
+	mcapp.Status.AbortedRollout.RollbackRevisionName = mcapp.Status.RevisionName
+	setRollingBack(mcapp, reason)
+	return m.multiClusterApps.Update(mcapp)
+}
+
+// isAborted returns true if the current spec of mcapp is the one a previous rollout was aborted for
+func isAborted(mcapp *v3.MultiClusterApp) (bool, error) {
+	if mcapp.Status.AbortedRollout == nil {
+		return false, nil
+	}
+	digest, err := specDigest(mcapp)
+	if err != nil {
+		return false, err
+	}
+	return mcapp.Status.AbortedRollout.SpecDigest == digest, nil
+}
+
+// rolloutSpec returns the template version and answers the apps of mcapp are rolled out with, the ones of the revision
+// the apps are rolled back to after an aborted rollout, the ones of the spec otherwise
+func (m *MCAppManager) rolloutSpec(mcapp *v3.MultiClusterApp) (string, []v32.Answer, error) {
+	if mcapp.Status.AbortedRollout == nil || mcapp.Status.AbortedRollout.RollbackRevisionName == "" {
+		return mcapp.Spec.TemplateVersionName, mcapp.Spec.Answers, nil
+	}
+	revision, err := m.multiClusterAppRevisionLister.Get(namespace.GlobalNamespace, mcapp.Status.AbortedRollout.RollbackRevisionName)
+	if err != nil {
+		return "", nil, err
+	}
+	return revision.TemplateVersionName, revision.Answers, nil
+}
+
+func specDigest(mcapp *v3.MultiClusterApp) (string, error) {
+	data, err := json.Marshal([]interface{}{mcapp.Spec.TemplateVersionName, mcapp.Spec.Answers})
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+func isUpgrading(mcapp *v3.MultiClusterApp) bool {
+	return v32.MultiClusterAppConditionInstalled.IsUnknown(mcapp) && v32.MultiClusterAppConditionInstalled.GetMessage(mcapp) == upgrading
+}
+
+func isRollingBack(mcapp *v3.MultiClusterApp) bool {
+	return v32.MultiClusterAppConditionInstalled.IsUnknown(mcapp) &&
+		strings.HasPrefix(v32.MultiClusterAppConditionInstalled.GetMessage(mcapp), rollingBack)
+}
+
+func setRollingBack(mcapp *v3.MultiClusterApp, reason string) {
+	v32.MultiClusterAppConditionInstalled.Unknown(mcapp)
+	v32.MultiClusterAppConditionInstalled.Reason(mcapp, rolloutAbortedReason)
+	v32.MultiClusterAppConditionInstalled.Message(mcapp, fmt.Sprintf("%s: %s", rollingBack, reason))
+	v32.MultiClusterAppConditionInstalled.LastUpdated(mcapp, time.Now().Format(time.RFC3339))
+}
+
+func setInstalledAborted(mcapp *v3.MultiClusterApp, reason string) {
+	v32.MultiClusterAppConditionInstalled.False(mcapp)
+	v32.MultiClusterAppConditionInstalled.Reason(mcapp, rolloutAbortedReason)
+	v32.MultiClusterAppConditionInstalled.Message(mcapp, "rollout aborted: "+reason)
+	v32.MultiClusterAppConditionInstalled.LastUpdated(mcapp, time.Now().Format(time.RFC3339))
+}
+
+func setRolledBack(mcapp *v3.MultiClusterApp) {
+	v32.MultiClusterAppConditionInstalled.True(mcapp)
+	v32.MultiClusterAppConditionInstalled.Reason(mcapp, rolloutAbortedReason)
+	v32.MultiClusterAppConditionInstalled.Message(mcapp, "rollout aborted, rolled back to revision "+mcapp.Status.RevisionName)
+}
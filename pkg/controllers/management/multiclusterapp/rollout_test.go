@@ -0,0 +1,266 @@
+package multiclusterapp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v33 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	mgmtfakes "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3/fakes"
+	pv3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	projectfakes "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3/fakes"
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/rancher/rancher/pkg/user"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type fakeUserManager struct {
+	user.Manager
+}
+
+func (f fakeUserManager) EnsureUser(principalName, displayName string) (*v32.User, error) {
+	return &v32.User{ObjectMeta: metav1.ObjectMeta{Name: "u-mcapp"}}, nil
+}
+
+type rolloutFixture struct {
+	mcapp *v3.MultiClusterApp
+	apps  map[string]*pv3.App
+}
+
+func (f *rolloutFixture) manager(ctx context.Context) *MCAppManager {
+	templateVersions := map[string]string{"tv1": "ext1", "tv2": "ext2"}
+	return &MCAppManager{
+		ctx: ctx,
+		apps: &projectfakes.AppInterfaceMock{
+			UpdateFunc: func(in *pv3.App) (*pv3.App, error) {
+				f.apps[in.Namespace+"/"+in.Name] = in.DeepCopy()
+				return in, nil
+			},
+		},
+		appLister: &projectfakes.AppListerMock{
+			GetFunc: func(namespace, name string) (*pv3.App, error) {
+				return f.apps[namespace+"/"+name], nil
+			},
+			ListFunc: func(namespace string, selector labels.Selector) ([]*pv3.App, error) {
+				var result []*pv3.App
+				for _, app := range f.apps {
+					if app.Namespace == namespace {
+						result = append(result, app)
+					}
+				}
+				return result, nil
+			},
+		},
+		multiClusterApps: &mgmtfakes.MultiClusterAppInterfaceMock{
+			UpdateFunc: func(in *v3.MultiClusterApp) (*v3.MultiClusterApp, error) {
+				f.mcapp = in.DeepCopy()
+				return in, nil
+			},
+			GetNamespacedFunc: func(namespace, name string, opts metav1.GetOptions) (*v3.MultiClusterApp, error) {
+				return f.mcapp.DeepCopy(), nil
+			},
+		},
+		multiClusterAppRevisionLister: &mgmtfakes.MultiClusterAppRevisionListerMock{
+			GetFunc: func(namespace, name string) (*v3.MultiClusterAppRevision, error) {
+				return &v3.MultiClusterAppRevision{
+					ObjectMeta:          metav1.ObjectMeta{Name: name, Namespace: namespace},
+					TemplateVersionName: "cattle-global-data:tv1",
+				}, nil
+			},
+		},
+		templateVersionLister: &mgmtfakes.CatalogTemplateVersionListerMock{
+			GetFunc: func(namespace, name string) (*v3.CatalogTemplateVersion, error) {
+				return &v3.CatalogTemplateVersion{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+					TemplateVersion: v32.TemplateVersion{
+						Spec: v32.TemplateVersionSpec{ExternalID: templateVersions[name]},
+					},
+				}, nil
+			},
+		},
+		clusterLister: &mgmtfakes.ClusterListerMock{
+			ListFunc: func(namespace string, selector labels.Selector) ([]*v3.Cluster, error) {
+				return nil, nil
+			},
+		},
+		userManager: fakeUserManager{},
+	}
+}
+
+func (f *rolloutFixture) setAppInstalled(name string, installed bool) {
+	for _, app := range f.apps {
+		if app.Name != name {
+			continue
+		}
+		if installed {
+			v33.AppConditionInstalled.True(app)
+		} else {
+			v33.AppConditionInstalled.False(app)
+		}
+	}
+}
+
+func (f *rolloutFixture) appExternalIDs() []string {
+	return []string{f.apps["p1/app1"].Spec.ExternalID, f.apps["p2/app2"].Spec.ExternalID}
+}
+
+func newTestApp(projectNS, name string) *pv3.App {
+	app := &pv3.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: projectNS,
+			Labels:    map[string]string{MultiClusterAppIDSelector: "mcapp"},
+		},
+		Spec: v33.AppSpec{
+			ExternalID: "ext1",
+			Answers:    map[string]string{},
+		},
+	}
+	v33.AppConditionInstalled.True(app)
+	v33.AppConditionDeployed.True(app)
+	return app
+}
+
+func TestFailedRolloutRollsBack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mcAppTickerData = map[string]*IntervalData{}
+
+	mcapp := &v3.MultiClusterApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mcapp",
+			Namespace:   namespace.GlobalNamespace,
+			Annotations: map[string]string{creatorIDAnn: "u-creator"},
+		},
+		Spec: v32.MultiClusterAppSpec{
+			TemplateVersionName: "cattle-global-data:tv2",
+			Targets: []v32.Target{
+				{ProjectName: "c1:p1", AppName: "app1"},
+				{ProjectName: "c1:p2", AppName: "app2"},
+			},
+			UpgradeStrategy: v32.UpgradeStrategy{
+				RollingUpdate: &v32.RollingUpdate{
+					BatchSize:         1,
+					Interval:          3600,
+					RollbackOnFailure: true,
+				},
+			},
+		},
+		Status: v32.MultiClusterAppStatus{RevisionName: "rev1"},
+	}
+	v32.MultiClusterAppConditionInstalled.True(mcapp)
+
+	f := &rolloutFixture{
+		mcapp: mcapp,
+		apps: map[string]*pv3.App{
+			"p1/app1": newTestApp("p1", "app1"),
+			"p2/app2": newTestApp("p2", "app2"),
+		},
+	}
+	m := f.manager(ctx)
+
+	// the first batch is upgraded
+	_, err := m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ext2", "ext1"}, f.appExternalIDs())
+	assert.True(t, isUpgrading(f.mcapp))
+
+	// the upgraded app fails, the rollout is aborted and recorded with the revision to roll back to, the spec is kept
+	f.setAppInstalled("app1", false)
+	_, err = m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.True(t, isRollingBack(f.mcapp))
+	assert.Equal(t, "cattle-global-data:tv2", f.mcapp.Spec.TemplateVersionName)
+	if assert.NotNil(t, f.mcapp.Status.AbortedRollout) {
+		assert.Equal(t, "rev1", f.mcapp.Status.AbortedRollout.RollbackRevisionName)
+	}
+
+	// the failed app is reverted and the mcapp keeps rolling back until it is installed
+	_, err = m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ext1", "ext1"}, f.appExternalIDs())
+	assert.True(t, isRollingBack(f.mcapp))
+
+	f.setAppInstalled("app1", true)
+	_, err = m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.True(t, v32.MultiClusterAppConditionInstalled.IsTrue(f.mcapp))
+	assert.Equal(t, rolloutAbortedReason, v32.MultiClusterAppConditionInstalled.GetReason(f.mcapp))
+	assert.True(t, strings.HasPrefix(v32.MultiClusterAppConditionInstalled.GetMessage(f.mcapp), "rollout aborted, rolled back"))
+	assert.Equal(t, "rev1", f.mcapp.Status.RevisionName)
+
+	// the aborted spec is not rolled out again
+	_, err = m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ext1", "ext1"}, f.appExternalIDs())
+	assert.True(t, v32.MultiClusterAppConditionInstalled.IsTrue(f.mcapp))
+
+	// changing the spec clears the aborted rollout, the new spec is rolled out
+	f.mcapp.Spec.Answers = []v32.Answer{{Values: map[string]string{"replicas": "2"}}}
+	_, err = m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.Nil(t, f.mcapp.Status.AbortedRollout)
+	_, err = m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ext2", "ext1"}, f.appExternalIDs())
+	assert.True(t, isUpgrading(f.mcapp))
+}
+
+func TestFailedRolloutHalts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mcAppTickerData = map[string]*IntervalData{}
+
+	mcapp := &v3.MultiClusterApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mcapp",
+			Namespace:   namespace.GlobalNamespace,
+			Annotations: map[string]string{creatorIDAnn: "u-creator"},
+		},
+		Spec: v32.MultiClusterAppSpec{
+			TemplateVersionName: "cattle-global-data:tv2",
+			Targets: []v32.Target{
+				{ProjectName: "c1:p1", AppName: "app1"},
+				{ProjectName: "c1:p2", AppName: "app2"},
+			},
+			UpgradeStrategy: v32.UpgradeStrategy{
+				RollingUpdate: &v32.RollingUpdate{
+					BatchSize: 1,
+					Interval:  1,
+				},
+			},
+		},
+		Status: v32.MultiClusterAppStatus{RevisionName: "rev1"},
+	}
+	v32.MultiClusterAppConditionInstalled.True(mcapp)
+
+	f := &rolloutFixture{
+		mcapp: mcapp,
+		apps: map[string]*pv3.App{
+			"p1/app1": newTestApp("p1", "app1"),
+			"p2/app2": newTestApp("p2", "app2"),
+		},
+	}
+	m := f.manager(ctx)
+
+	_, err := m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	f.setAppInstalled("app1", false)
+	_, err = m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.True(t, v32.MultiClusterAppConditionInstalled.IsFalse(f.mcapp))
+	if assert.NotNil(t, f.mcapp.Status.AbortedRollout) {
+		assert.Empty(t, f.mcapp.Status.AbortedRollout.RollbackRevisionName)
+	}
+
+	// the rollout stays halted
+	f.setAppInstalled("app1", true)
+	_, err = m.sync("cattle-global-data/mcapp", f.mcapp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ext2", "ext1"}, f.appExternalIDs())
+	assert.Equal(t, "cattle-global-data:tv2", f.mcapp.Spec.TemplateVersionName)
+}
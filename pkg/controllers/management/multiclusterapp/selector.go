@@ -0,0 +1,203 @@
+package multiclusterapp
+
+import (
+	"fmt"
+	"reflect"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/controllers/management/rbac"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	gaccess "github.com/rancher/rancher/pkg/globalnamespaceaccess"
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/rancher/rancher/pkg/project"
+	"github.com/rancher/rancher/pkg/ref"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type MCAppSelectorController struct {
+	mcApps        v3.MultiClusterAppInterface
+	clusterLister v3.ClusterLister
+	projectLister v3.ProjectLister
+	access        *gaccess.MemberAccess
+}
+
+// sync adds a target for every project matched by the target selectors of mcapp and removes the targets
+// the selectors no longer match. Projects are only added if the creator of mcapp has its roles in them, the same
+// as is required for the targets set through the API.
+func (s *MCAppSelectorController) sync(key string, mcapp *v3.MultiClusterApp) (runtime.Object, error) {
+	if mcapp == nil || mcapp.DeletionTimestamp != nil {
+		return mcapp, nil
+	}
+
+	toUpdate, err := s.reconcileSelectorTargets(mcapp)
+	if err != nil || toUpdate == nil {
+		return mcapp, err
+	}
+	return s.mcApps.Update(toUpdate)
+}
+
+// reconcileSelectorTargets returns an updated copy of mcapp if its selector managed targets or answers have to change,
+// nil otherwise.
+func (s *MCAppSelectorController) reconcileSelectorTargets(mcapp *v3.MultiClusterApp) (*v3.MultiClusterApp, error) {
+	if len(mcapp.Spec.TargetSelectors) == 0 && !hasSelectorTargets(mcapp) {
+		return nil, nil
+	}
+
+	selected, order, err := project.SelectTargets(s.clusterLister, s.projectLister, mcapp.Spec.TargetSelectors)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		targets  []v32.Target
+		existing = map[string]bool{}
+	)
+	for _, t := range mcapp.Spec.Targets {
+		if t.Selector == "" {
+			targets = append(targets, t)
+			existing[t.ProjectName] = true
+			continue
+		}
+		selector, ok := selected[t.ProjectName]
+		if !ok || existing[t.ProjectName] {
+			continue
+		}
+		t.Selector = selector.Name
+		targets = append(targets, t)
+		existing[t.ProjectName] = true
+	}
+	for _, projectName := range order {
+		if existing[projectName] {
+			continue
+		}
+		if err := s.access.EnsureRoleInTargets([]string{projectName}, mcapp.Spec.Roles, mcapp.Annotations[rbac.CreatorIDAnn]); err != nil {
+			logrus.Warnf("[mcapp] not targeting project %s selected by %s of multiclusterapp %s: %v", projectName,
+				selected[projectName].Name, mcapp.Name, err)
+			continue
+		}
+		targets = append(targets, v32.Target{
+			ProjectName: projectName,
+			Selector:    selected[projectName].Name,
+		})
+		existing[projectName] = true
+	}
+
+	selectorTargets := map[string]*v32.TargetSelector{}
+	for _, t := range targets {
+		if t.Selector != "" {
+			selectorTargets[t.ProjectName] = selected[t.ProjectName]
+		}
+	}
+
+	var answers []v32.Answer
+	for _, a := range mcapp.Spec.Answers {
+		if a.ProjectName == "" || !wasSelectorTarget(mcapp, a.ProjectName) {
+			answers = append(answers, a)
+		}
+	}
+	for _, t := range targets {
+		selector, ok := selectorTargets[t.ProjectName]
+		if !ok || len(selector.Values) == 0 {
+			continue
+		}
+		answers = append(answers, v32.Answer{
+			ProjectName: t.ProjectName,
+			Values:      selector.Values,
+		})
+	}
+
+	if reflect.DeepEqual(targets, mcapp.Spec.Targets) && reflect.DeepEqual(answers, mcapp.Spec.Answers) {
+		return nil, nil
+	}
+
+	toUpdate := mcapp.DeepCopy()
+	toUpdate.Spec.Targets = targets
+	toUpdate.Spec.Answers = answers
+	return toUpdate, nil
+}
+
+func hasSelectorTargets(mcapp *v3.MultiClusterApp) bool {
+	for _, t := range mcapp.Spec.Targets {
+		if t.Selector != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func wasSelectorTarget(mcapp *v3.MultiClusterApp, projectName string) bool {
+	for _, t := range mcapp.Spec.Targets {
+		if t.ProjectName == projectName {
+			return t.Selector != ""
+		}
+	}
+	return false
+}
+
+// enqueueSelectorMCApps enqueues the multi cluster apps whose target selectors match other projects of the cluster
+// than the ones they target, used whenever a cluster or project is created or relabeled
+func enqueueSelectorMCApps(mcAppsLister v3.MultiClusterAppLister, mcApps v3.MultiClusterAppInterface, projectLister v3.ProjectLister, cluster *v3.Cluster) error {
+	apps, err := mcAppsLister.List(namespace.GlobalNamespace, labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, mcapp := range apps {
+		if len(mcapp.Spec.TargetSelectors) == 0 && !hasSelectorTargets(mcapp) {
+			continue
+		}
+		changed, err := selectorTargetsChanged(projectLister, mcapp, cluster)
+		if err != nil {
+			return err
+		}
+		if changed {
+			mcApps.Controller().Enqueue(namespace.GlobalNamespace, mcapp.Name)
+		}
+	}
+	return nil
+}
+
+// selectorTargetsChanged returns whether the projects of the cluster matched by the target selectors of mcapp differ
+// from its selector targets in the cluster
+func selectorTargetsChanged(projectLister v3.ProjectLister, mcapp *v3.MultiClusterApp, cluster *v3.Cluster) (bool, error) {
+	targeted := map[string]bool{}
+	for _, t := range mcapp.Spec.Targets {
+		if clusterName, _ := ref.Parse(t.ProjectName); clusterName == cluster.Name {
+			targeted[t.ProjectName] = t.Selector != ""
+		}
+	}
+
+	matched := map[string]bool{}
+	for _, selector := range mcapp.Spec.TargetSelectors {
+		clusterSelector, projectSelector, err := project.TargetSelectorLabels(&selector)
+		if err != nil {
+			continue
+		}
+		if cluster.DeletionTimestamp != nil || !clusterSelector.Matches(labels.Set(cluster.Labels)) {
+			continue
+		}
+		projects, err := projectLister.List(cluster.Name, projectSelector)
+		if err != nil {
+			return false, err
+		}
+		for _, project := range projects {
+			if project.DeletionTimestamp == nil {
+				matched[fmt.Sprintf("%s:%s", cluster.Name, project.Name)] = true
+			}
+		}
+	}
+
+	for projectName := range matched {
+		// projects that are targeted explicitly are never selector targets
+		if _, ok := targeted[projectName]; !ok {
+			return true, nil
+		}
+	}
+	for projectName, bySelector := range targeted {
+		if bySelector && !matched[projectName] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,75 @@
+package multiclusterapp
+
+import (
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	mgmtfakes "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3/fakes"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSelectorTargetsChanged(t *testing.T) {
+	cluster := &v3.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1", Labels: map[string]string{"env": "prod"}}}
+	projectLister := &mgmtfakes.ProjectListerMock{
+		ListFunc: func(namespace string, selector labels.Selector) ([]*v3.Project, error) {
+			var result []*v3.Project
+			for _, name := range []string{"p1", "p2"} {
+				project := &v3.Project{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"team": name}}}
+				if selector.Matches(labels.Set(project.Labels)) {
+					result = append(result, project)
+				}
+			}
+			return result, nil
+		},
+	}
+	mcapp := func(targets ...v32.Target) *v3.MultiClusterApp {
+		return &v3.MultiClusterApp{Spec: v32.MultiClusterAppSpec{
+			Targets: targets,
+			TargetSelectors: []v32.TargetSelector{{
+				Name:            "prod",
+				ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+				ProjectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "p1"}},
+			}},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		mcapp   *v3.MultiClusterApp
+		changed bool
+	}{
+		{
+			name:    "selected project is not targeted",
+			mcapp:   mcapp(),
+			changed: true,
+		},
+		{
+			name:  "selected project is targeted",
+			mcapp: mcapp(v32.Target{ProjectName: "c1:p1", Selector: "prod"}),
+		},
+		{
+			name:  "selected project is targeted explicitly",
+			mcapp: mcapp(v32.Target{ProjectName: "c1:p1"}),
+		},
+		{
+			name:    "project is no longer selected",
+			mcapp:   mcapp(v32.Target{ProjectName: "c1:p1", Selector: "prod"}, v32.Target{ProjectName: "c1:p2", Selector: "prod"}),
+			changed: true,
+		},
+		{
+			name:  "targets of other clusters are ignored",
+			mcapp: mcapp(v32.Target{ProjectName: "c1:p1", Selector: "prod"}, v32.Target{ProjectName: "c2:p2", Selector: "prod"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, err := selectorTargetsChanged(projectLister, tt.mcapp, cluster)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.changed, changed)
+		})
+	}
+}
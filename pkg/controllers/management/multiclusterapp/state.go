@@ -42,7 +42,7 @@ func (m *MCAppStateController) sync(key string, mcapp *v3.MultiClusterApp) (runt
 		return mcapp, nil
 	}
 	mcappState := active
-	if v32.MultiClusterAppConditionInstalled.IsUnknown(mcapp) && v32.MultiClusterAppConditionInstalled.GetMessage(mcapp) == "upgrading" {
+	if isUpgrading(mcapp) || isRollingBack(mcapp) {
 		mcappState = ""
 	}
 	var toUpdate *v3.MultiClusterApp
@@ -13,6 +13,7 @@ import (
 
 	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/maintenance"
 	"github.com/rancher/rancher/pkg/ref"
 	"github.com/rancher/rancher/pkg/types/config"
 	"github.com/rancher/rke/services"
@@ -89,6 +90,12 @@ func (c *Controller) reconcile(nodePool *v3.NodePool, nodes []*v3.Node) {
 }
 
 func (c *Controller) Updated(nodePool *v3.NodePool) (runtime.Object, error) {
+	if maintenance.Enabled() {
+		logrus.Debugf("[nodepool] maintenance mode, pausing reconcile of %s", nodePool.Name)
+		c.NodePoolController.EnqueueAfter(nodePool.Namespace, nodePool.Name, maintenance.RecheckInterval)
+		return nodePool, nil
+	}
+
 	obj, err := v32.NodePoolConditionUpdated.Do(nodePool, func() (runtime.Object, error) {
 		anno, _ := nodePool.Annotations[ReconcileAnnotation]
 		if anno == "" {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/rancher/rancher/pkg/auth/tokens"
 	"github.com/rancher/rancher/pkg/controllers/managementuser/clusterauthtoken/common"
 	managementv3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/types/config"
@@ -50,6 +51,7 @@ func Register(ctx context.Context, cluster *config.UserContext) {
 		clusterName,
 		&tokenHandler{
 			namespace,
+			clusterName,
 			clusterAuthToken,
 			clusterAuthTokenLister,
 			clusterUserAttribute,
@@ -59,6 +61,18 @@ func Register(ctx context.Context, cluster *config.UserContext) {
 			userAttributeLister,
 		})
 
+	cluster.Management.Management.Tokens("").AddHandler(ctx, "cat-clusters-token-controller", (&tokenHandler{
+		namespace,
+		clusterName,
+		clusterAuthToken,
+		clusterAuthTokenLister,
+		clusterUserAttribute,
+		clusterUserAttributeLister,
+		tokenIndexer,
+		userLister,
+		userAttributeLister,
+	}).ClustersTokenSync)
+
 	cluster.Management.Management.Users("").AddHandler(ctx, "cat-user-controller", (&userHandler{
 		namespace,
 		clusterUserAttribute,
@@ -77,8 +91,8 @@ func Register(ctx context.Context, cluster *config.UserContext) {
 	}).Sync)
 }
 
-func tokenUserClusterKey(token *managementv3.Token) string {
-	return fmt.Sprintf("%s/%s", token.UserID, token.ClusterName)
+func tokenUserClusterKey(userID, clusterName string) string {
+	return fmt.Sprintf("%s/%s", userID, clusterName)
 }
 
 func tokenByUserAndCluster(obj interface{}) ([]string, error) {
@@ -86,5 +100,12 @@ func tokenByUserAndCluster(obj interface{}) ([]string, error) {
 	if !ok {
 		return []string{}, nil
 	}
-	return []string{tokenUserClusterKey(t)}, nil
+	if clusters := tokens.TokenClusters(t); clusters != nil {
+		keys := make([]string, 0, len(clusters))
+		for _, clusterName := range clusters {
+			keys = append(keys, tokenUserClusterKey(t.UserID, clusterName))
+		}
+		return keys, nil
+	}
+	return []string{tokenUserClusterKey(t.UserID, t.ClusterName)}, nil
 }
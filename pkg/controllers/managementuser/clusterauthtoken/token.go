@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"sort"
 
+	"github.com/rancher/norman/types/slice"
+	"github.com/rancher/rancher/pkg/auth/tokens"
 	"github.com/rancher/rancher/pkg/controllers/managementuser/clusterauthtoken/common"
 	clusterv3 "github.com/rancher/rancher/pkg/generated/norman/cluster.cattle.io/v3"
 	managementv3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
@@ -21,6 +23,7 @@ type tokenAttributeCompare struct {
 
 type tokenHandler struct {
 	namespace                  string
+	clusterName                string
 	clusterAuthToken           clusterv3.ClusterAuthTokenInterface
 	clusterAuthTokenLister     clusterv3.ClusterAuthTokenLister
 	clusterUserAttribute       clusterv3.ClusterUserAttributeInterface
@@ -92,28 +95,62 @@ func (h *tokenHandler) Updated(token *managementv3.Token) (runtime.Object, error
 }
 
 func (h *tokenHandler) Remove(token *managementv3.Token) (runtime.Object, error) {
-
-	tokens, err := h.tokenIndexer.ByIndex(tokenByUserAndClusterIndex, tokenUserClusterKey(token))
+	err := h.clusterAuthToken.Delete(token.Name, &metav1.DeleteOptions{})
 	if err != nil && !errors.IsNotFound(err) {
 		return nil, err
 	}
-	err = h.clusterAuthToken.Delete(token.Name, &metav1.DeleteOptions{})
+	return nil, h.removeClusterUserAttribute(token.UserID, token.Name)
+}
+
+// ClustersTokenSync syncs the tokens scoped to several clusters, the cluster scoped lifecycle only syncs the tokens
+// scoped to a single cluster
+func (h *tokenHandler) ClustersTokenSync(key string, token *managementv3.Token) (runtime.Object, error) {
+	if token == nil || token.DeletionTimestamp != nil {
+		return nil, h.removeClusterAuthToken(key)
+	}
+
+	clusters := tokens.TokenClusters(token)
+	if token.ClusterName != "" || clusters == nil {
+		return nil, nil
+	}
+	if slice.ContainsString(clusters, h.clusterName) {
+		return h.Create(token)
+	}
+	// the token is no longer scoped to this cluster
+	return nil, h.removeClusterAuthToken(token.Name)
+}
+
+func (h *tokenHandler) removeClusterAuthToken(name string) error {
+	clusterAuthToken, err := h.clusterAuthTokenLister.Get(h.namespace, name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	err = h.clusterAuthToken.Delete(name, &metav1.DeleteOptions{})
 	if err != nil && !errors.IsNotFound(err) {
-		return nil, err
+		return err
 	}
+	return h.removeClusterUserAttribute(clusterAuthToken.UserName, name)
+}
 
-	if len(tokens) == 1 {
-		lastToken := tokens[0].(*managementv3.Token)
-		if token.Name == lastToken.Name {
-			// we are about to remove the last token for this user & cluster,
-			// also remove user data from cluster
-			err = h.clusterUserAttribute.Delete(token.UserID, &metav1.DeleteOptions{})
-			if err != nil && !errors.IsNotFound(err) {
-				return nil, err
-			}
+// removeClusterUserAttribute removes the user data from the cluster when the token being removed is the last token of
+// the user for this cluster, whether scoped to this cluster only or to several clusters
+func (h *tokenHandler) removeClusterUserAttribute(userID, tokenName string) error {
+	tokens, err := h.tokenIndexer.ByIndex(tokenByUserAndClusterIndex, tokenUserClusterKey(userID, h.clusterName))
+	if err != nil {
+		return err
+	}
+	for _, obj := range tokens {
+		if obj.(*managementv3.Token).Name != tokenName {
+			return nil
 		}
 	}
-	return nil, nil
+	err = h.clusterUserAttribute.Delete(userID, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
 }
 
 func (h *tokenHandler) updateClusterUserAttribute(token *managementv3.Token) error {
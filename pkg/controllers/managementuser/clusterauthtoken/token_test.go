@@ -0,0 +1,134 @@
+package clusterauthtoken
+
+import (
+	"testing"
+
+	"github.com/rancher/rancher/pkg/auth/tokens"
+	clusterv3 "github.com/rancher/rancher/pkg/generated/norman/cluster.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/generated/norman/cluster.cattle.io/v3/fakes"
+	managementv3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newToken(name, userID, clusterName, clusters string) *managementv3.Token {
+	token := &managementv3.Token{
+		ObjectMeta:  metav1.ObjectMeta{Name: name},
+		UserID:      userID,
+		ClusterName: clusterName,
+	}
+	if clusters != "" {
+		token.Annotations = map[string]string{tokens.TokenClustersAnnotation: clusters}
+	}
+	return token
+}
+
+func TestRemoveClusterUserAttribute(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  []*managementv3.Token
+		remove  func(h *tokenHandler) error
+		deleted []string
+	}{
+		{
+			name: "last clusters token deleted",
+			remove: func(h *tokenHandler) error {
+				_, err := h.ClustersTokenSync("kubeconfig-u-1", nil)
+				return err
+			},
+			deleted: []string{"u-1"},
+		},
+		{
+			name: "clusters token deleted with another token for the cluster",
+			tokens: []*managementv3.Token{
+				newToken("kubeconfig-u-1-c-1", "u-1", "c-1", ""),
+			},
+			remove: func(h *tokenHandler) error {
+				_, err := h.ClustersTokenSync("kubeconfig-u-1", nil)
+				return err
+			},
+		},
+		{
+			name: "clusters token being deleted",
+			tokens: []*managementv3.Token{
+				newToken("kubeconfig-u-1", "u-1", "", "c-1,c-2"),
+			},
+			remove: func(h *tokenHandler) error {
+				token := newToken("kubeconfig-u-1", "u-1", "", "c-1,c-2")
+				token.DeletionTimestamp = &metav1.Time{}
+				_, err := h.ClustersTokenSync("kubeconfig-u-1", token)
+				return err
+			},
+			deleted: []string{"u-1"},
+		},
+		{
+			name: "clusters token no longer scoped to the cluster",
+			tokens: []*managementv3.Token{
+				newToken("kubeconfig-u-1", "u-1", "", "c-2"),
+			},
+			remove: func(h *tokenHandler) error {
+				_, err := h.ClustersTokenSync("kubeconfig-u-1", newToken("kubeconfig-u-1", "u-1", "", "c-2"))
+				return err
+			},
+			deleted: []string{"u-1"},
+		},
+		{
+			name: "last cluster token removed",
+			tokens: []*managementv3.Token{
+				newToken("kubeconfig-u-1-c-1", "u-1", "c-1", ""),
+			},
+			remove: func(h *tokenHandler) error {
+				_, err := h.Remove(newToken("kubeconfig-u-1-c-1", "u-1", "c-1", ""))
+				return err
+			},
+			deleted: []string{"u-1"},
+		},
+		{
+			name: "cluster token removed with a clusters token for the cluster",
+			tokens: []*managementv3.Token{
+				newToken("kubeconfig-u-1-c-1", "u-1", "c-1", ""),
+				newToken("kubeconfig-u-1", "u-1", "", "c-1,c-2"),
+			},
+			remove: func(h *tokenHandler) error {
+				_, err := h.Remove(newToken("kubeconfig-u-1-c-1", "u-1", "c-1", ""))
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{tokenByUserAndClusterIndex: tokenByUserAndCluster})
+		for _, token := range tt.tokens {
+			assert.Nil(t, indexer.Add(token), tt.name)
+		}
+
+		var deleted []string
+		h := &tokenHandler{
+			namespace:   "cattle-system",
+			clusterName: "c-1",
+			clusterAuthToken: &fakes.ClusterAuthTokenInterfaceMock{
+				DeleteFunc: func(name string, options *metav1.DeleteOptions) error {
+					return nil
+				},
+			},
+			clusterAuthTokenLister: &fakes.ClusterAuthTokenListerMock{
+				GetFunc: func(namespace string, name string) (*clusterv3.ClusterAuthToken, error) {
+					if name != "kubeconfig-u-1" {
+						return nil, errors.NewNotFound(clusterv3.ClusterAuthTokenGroupVersionResource.GroupResource(), name)
+					}
+					return &clusterv3.ClusterAuthToken{UserName: "u-1"}, nil
+				},
+			},
+			clusterUserAttribute: &fakes.ClusterUserAttributeInterfaceMock{
+				DeleteFunc: func(name string, options *metav1.DeleteOptions) error {
+					deleted = append(deleted, name)
+					return nil
+				},
+			},
+			tokenIndexer: indexer,
+		}
+		assert.Nil(t, tt.remove(h), tt.name)
+		assert.Equal(t, tt.deleted, deleted, tt.name)
+	}
+}
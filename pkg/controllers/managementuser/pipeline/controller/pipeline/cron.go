@@ -0,0 +1,189 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rancher/norman/controller"
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/providers"
+	"github.com/rancher/rancher/pkg/pipeline/remote"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/rancher/rancher/pkg/ref"
+	"github.com/rancher/wrangler/pkg/ticker"
+	"github.com/robfig/cron"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// This syncer runs the pipelines with cron triggers on schedule. A trigger whose schedule came up since the last
+// recorded check runs the pipeline on the head of its branch with the cron event. The time of the check and a hash of
+// the checked triggers are recorded in the pipeline status when a trigger fires or the triggers change.
+
+const (
+	cronSyncInterval = time.Minute
+)
+
+type CronSyncer struct {
+	clusterName string
+
+	pipelines                  v3.PipelineInterface
+	pipelineLister             v3.PipelineLister
+	pipelineExecutions         v3.PipelineExecutionInterface
+	sourceCodeCredentials      v3.SourceCodeCredentialInterface
+	sourceCodeCredentialLister v3.SourceCodeCredentialLister
+}
+
+func (s *CronSyncer) sync(ctx context.Context, syncInterval time.Duration) {
+	for range ticker.Context(ctx, syncInterval) {
+		s.syncCronTriggers(time.Now())
+	}
+}
+
+func (s *CronSyncer) syncCronTriggers(now time.Time) {
+	pipelines, err := s.pipelineLister.List("", labels.Everything())
+	if err != nil {
+		logrus.Errorf("Error listing pipelines - %v", err)
+		return
+	}
+	for _, pipeline := range pipelines {
+		if !controller.ObjectInCluster(s.clusterName, pipeline) || pipeline.DeletionTimestamp != nil {
+			continue
+		}
+		if err := s.checkPipeline(pipeline, now); err != nil {
+			logrus.Warnf("failed to check cron triggers of pipeline %s: %v", ref.Ref(pipeline), err)
+		}
+	}
+}
+
+func (s *CronSyncer) checkPipeline(pipeline *v3.Pipeline, now time.Time) error {
+	if len(pipeline.Spec.CronTriggers) == 0 || pipeline.Status.PipelineState == "inactive" {
+		if pipeline.Status.LastCronCheck == "" && pipeline.Status.NextStart == "" && pipeline.Status.CronTriggersHash == "" {
+			return nil
+		}
+		toUpdate := pipeline.DeepCopy()
+		toUpdate.Status.LastCronCheck = ""
+		toUpdate.Status.NextStart = ""
+		toUpdate.Status.CronTriggersHash = ""
+		_, err := s.pipelines.Update(toUpdate)
+		return err
+	}
+
+	triggersHash, err := cronTriggersHash(pipeline.Spec.CronTriggers)
+	if err != nil {
+		return err
+	}
+	lastCheck, err := time.Parse(time.RFC3339, pipeline.Status.LastCronCheck)
+	firstCheck := err != nil
+	var (
+		due       []v32.CronTrigger
+		nextStart time.Time
+	)
+	for _, trigger := range pipeline.Spec.CronTriggers {
+		schedule, err := cron.ParseStandard(trigger.Schedule)
+		if err != nil {
+			logrus.Warnf("invalid cron schedule %q of pipeline %s: %v", trigger.Schedule, ref.Ref(pipeline), err)
+			continue
+		}
+		if !firstCheck && !schedule.Next(lastCheck).After(now) {
+			due = append(due, trigger)
+		}
+		nextStart = earliest(nextStart, schedule.Next(now))
+	}
+
+	//the first check and the first check after the triggers changed only record the time, missed schedules run once
+	if firstCheck || triggersHash != pipeline.Status.CronTriggersHash {
+		due = nil
+	} else if len(due) == 0 {
+		//nothing changed since the last check, the status is only written when a trigger fires
+		return nil
+	}
+
+	//record the check before running the pipeline so that a failed update does not run it twice
+	toUpdate := pipeline.DeepCopy()
+	toUpdate.Status.LastCronCheck = now.Format(time.RFC3339)
+	toUpdate.Status.NextStart = formatTime(nextStart)
+	toUpdate.Status.CronTriggersHash = triggersHash
+	updated, err := s.pipelines.Update(toUpdate)
+	if err != nil {
+		return err
+	}
+
+	for _, trigger := range due {
+		if err := s.trigger(updated, trigger); err != nil {
+			logrus.Warnf("failed to run pipeline %s on schedule %q: %v", ref.Ref(pipeline), trigger.Schedule, err)
+		}
+	}
+	return nil
+}
+
+// cronTriggersHash identifies the cron triggers a check was made for, so that changed triggers are detected
+func cronTriggersHash(triggers []v32.CronTrigger) (string, error) {
+	b, err := json.Marshal(triggers)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func (s *CronSyncer) trigger(pipeline *v3.Pipeline, trigger v32.CronTrigger) error {
+	if pipeline.Spec.SourceCodeCredentialName == "" {
+		return fmt.Errorf("pipeline has no source code credential")
+	}
+	ns, name := ref.Parse(pipeline.Spec.SourceCodeCredentialName)
+	credential, err := s.sourceCodeCredentialLister.Get(ns, name)
+	if err != nil {
+		return err
+	}
+	_, projID := ref.Parse(pipeline.Spec.ProjectName)
+	scpConfig, err := providers.GetSourceCodeProviderConfig(credential.Spec.SourceCodeType, projID)
+	if err != nil {
+		return err
+	}
+	scpRemote, err := remote.New(scpConfig)
+	if err != nil {
+		return err
+	}
+	accessToken, err := utils.EnsureAccessToken(s.sourceCodeCredentials, scpRemote, credential)
+	if err != nil {
+		return err
+	}
+	info, err := scpRemote.GetHeadInfo(pipeline.Spec.RepositoryURL, trigger.Branch, accessToken)
+	if err != nil {
+		return err
+	}
+
+	pipelineConfig, err := providers.GetPipelineConfigByBranch(s.sourceCodeCredentials, s.sourceCodeCredentialLister, pipeline, trigger.Branch)
+	if err != nil {
+		return err
+	}
+	if pipelineConfig == nil {
+		return fmt.Errorf("find no pipeline config in branch %s", trigger.Branch)
+	}
+	info.TriggerType = utils.TriggerTypeCron
+	info.Event = utils.EventCron
+	info.Branch = trigger.Branch
+	info.Env = trigger.Env
+	_, err = utils.GenerateExecution(s.pipelineExecutions, pipeline, pipelineConfig, info)
+	return err
+}
@@ -0,0 +1,154 @@
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3/fakes"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// credentialLister counts the credential lookups of the triggered pipelines and fails them
+type credentialLister struct {
+	gets int
+}
+
+func (c *credentialLister) List(namespace string, selector labels.Selector) ([]*v3.SourceCodeCredential, error) {
+	return nil, nil
+}
+
+func (c *credentialLister) Get(namespace, name string) (*v3.SourceCodeCredential, error) {
+	c.gets++
+	return nil, fmt.Errorf("credential %s not found", name)
+}
+
+func TestCheckPipeline(t *testing.T) {
+	now := time.Date(2020, 1, 1, 10, 30, 0, 0, time.UTC)
+	hash := func(triggers []v32.CronTrigger) string {
+		h, err := cronTriggersHash(triggers)
+		assert.Nil(t, err)
+		return h
+	}
+	hourly := []v32.CronTrigger{{Schedule: "0 * * * *", Branch: "master"}}
+	hourlyHash := hash(hourly)
+	dev := []v32.CronTrigger{{Schedule: "0 * * * *", Branch: "dev"}}
+	withInvalid := append([]v32.CronTrigger{{Schedule: "invalid", Branch: "master"}}, hourly...)
+
+	newPipeline := func(triggers []v32.CronTrigger, lastCheck, triggersHash string) *v3.Pipeline {
+		return &v3.Pipeline{
+			Spec: v32.PipelineSpec{
+				SourceCodeCredentialName: "u-1:credential",
+				CronTriggers:             triggers,
+			},
+			Status: v32.PipelineStatus{
+				LastCronCheck:    lastCheck,
+				NextStart:        "2020-01-01T10:00:00Z",
+				CronTriggersHash: triggersHash,
+			},
+		}
+	}
+	inactive := newPipeline(hourly, "2020-01-01T09:50:00Z", hourlyHash)
+	inactive.Status.PipelineState = "inactive"
+
+	tests := []struct {
+		name     string
+		pipeline *v3.Pipeline
+		// status is the expected status update, nil if the pipeline is not expected to be updated
+		status    *v32.PipelineStatus
+		triggered int
+	}{
+		{
+			name:     "no triggers",
+			pipeline: &v3.Pipeline{},
+		},
+		{
+			name:     "triggers removed",
+			pipeline: newPipeline(nil, "2020-01-01T09:50:00Z", hourlyHash),
+			status:   &v32.PipelineStatus{},
+		},
+		{
+			name:     "inactive",
+			pipeline: inactive,
+			status:   &v32.PipelineStatus{PipelineState: "inactive"},
+		},
+		{
+			name:     "first check",
+			pipeline: newPipeline(hourly, "", ""),
+			status: &v32.PipelineStatus{
+				LastCronCheck:    "2020-01-01T10:30:00Z",
+				NextStart:        "2020-01-01T11:00:00Z",
+				CronTriggersHash: hourlyHash,
+			},
+		},
+		{
+			name:     "not due",
+			pipeline: newPipeline(hourly, "2020-01-01T10:10:00Z", hourlyHash),
+		},
+		{
+			name:     "due",
+			pipeline: newPipeline(hourly, "2020-01-01T09:50:00Z", hourlyHash),
+			status: &v32.PipelineStatus{
+				LastCronCheck:    "2020-01-01T10:30:00Z",
+				NextStart:        "2020-01-01T11:00:00Z",
+				CronTriggersHash: hourlyHash,
+			},
+			triggered: 1,
+		},
+		{
+			name:     "missed schedules run once",
+			pipeline: newPipeline(hourly, "2020-01-01T06:50:00Z", hourlyHash),
+			status: &v32.PipelineStatus{
+				LastCronCheck:    "2020-01-01T10:30:00Z",
+				NextStart:        "2020-01-01T11:00:00Z",
+				CronTriggersHash: hourlyHash,
+			},
+			triggered: 1,
+		},
+		{
+			name:     "triggers changed",
+			pipeline: newPipeline(dev, "2020-01-01T09:50:00Z", hourlyHash),
+			status: &v32.PipelineStatus{
+				LastCronCheck:    "2020-01-01T10:30:00Z",
+				NextStart:        "2020-01-01T11:00:00Z",
+				CronTriggersHash: hash(dev),
+			},
+		},
+		{
+			name:     "invalid schedules are skipped",
+			pipeline: newPipeline(withInvalid, "2020-01-01T09:50:00Z", hash(withInvalid)),
+			status: &v32.PipelineStatus{
+				LastCronCheck:    "2020-01-01T10:30:00Z",
+				NextStart:        "2020-01-01T11:00:00Z",
+				CronTriggersHash: hash(withInvalid),
+			},
+			triggered: 1,
+		},
+	}
+	for _, tt := range tests {
+		var updated *v3.Pipeline
+		lister := &credentialLister{}
+		s := &CronSyncer{
+			pipelines: &fakes.PipelineInterfaceMock{
+				UpdateFunc: func(in1 *v3.Pipeline) (*v3.Pipeline, error) {
+					updated = in1
+					return in1, nil
+				},
+			},
+			sourceCodeCredentialLister: lister,
+		}
+		before := tt.pipeline.DeepCopy()
+
+		assert.Nil(t, s.checkPipeline(tt.pipeline, now), tt.name)
+		assert.Equal(t, before, tt.pipeline, tt.name)
+		if tt.status == nil {
+			assert.Nil(t, updated, tt.name)
+		} else if assert.NotNil(t, updated, tt.name) {
+			assert.Equal(t, *tt.status, updated.Status, tt.name)
+		}
+		assert.Equal(t, tt.triggered, lister.gets, tt.name)
+	}
+}
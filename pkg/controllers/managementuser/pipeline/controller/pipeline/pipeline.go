@@ -15,7 +15,9 @@ import (
 )
 
 // This controller is responsible for watching pipelines and handling
-// webhook management in source code providers.
+// webhook management in source code providers. Pipelines of git servers
+// without webhooks are triggered by the ref poller instead, and pipelines
+// with cron triggers are run on schedule by the cron syncer.
 
 type Lifecycle struct {
 	sourceCodeCredentialLister v3.SourceCodeCredentialLister
@@ -33,6 +35,26 @@ func Register(ctx context.Context, cluster *config.UserContext) {
 	}
 
 	pipelines.AddClusterScopedLifecycle(ctx, "pipeline-controller", cluster.ClusterName, pipelineLifecycle)
+
+	refPoller := &RefPoller{
+		clusterName:                cluster.ClusterName,
+		pipelineLister:             pipelines.Controller().Lister(),
+		pipelineExecutions:         cluster.Management.Project.PipelineExecutions(""),
+		sourceCodeCredentials:      sourceCodeCredentials,
+		sourceCodeCredentialLister: sourceCodeCredentialLister,
+		refs:                       map[string]map[string]string{},
+	}
+	go refPoller.sync(ctx, pollRefsInterval)
+
+	cronSyncer := &CronSyncer{
+		clusterName:                cluster.ClusterName,
+		pipelines:                  pipelines,
+		pipelineLister:             pipelines.Controller().Lister(),
+		pipelineExecutions:         cluster.Management.Project.PipelineExecutions(""),
+		sourceCodeCredentials:      sourceCodeCredentials,
+		sourceCodeCredentialLister: sourceCodeCredentialLister,
+	}
+	go cronSyncer.sync(ctx, cronSyncInterval)
 }
 
 func (l *Lifecycle) Create(obj *v3.Pipeline) (runtime.Object, error) {
@@ -0,0 +1,159 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rancher/norman/controller"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/providers"
+	"github.com/rancher/rancher/pkg/pipeline/remote"
+	"github.com/rancher/rancher/pkg/pipeline/remote/model"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/rancher/rancher/pkg/ref"
+	"github.com/rancher/wrangler/pkg/ticker"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// This poller triggers the pipelines of repositories on git servers that cannot send webhooks. It lists the refs of
+// each repository and runs the pipeline for every branch or tag whose commit changed since the last poll.
+
+const (
+	pollRefsInterval = time.Minute
+	refsBranchPrefix = "refs/heads/"
+	refsTagPrefix    = "refs/tags/"
+)
+
+type RefPoller struct {
+	clusterName string
+
+	pipelineLister             v3.PipelineLister
+	pipelineExecutions         v3.PipelineExecutionInterface
+	sourceCodeCredentials      v3.SourceCodeCredentialInterface
+	sourceCodeCredentialLister v3.SourceCodeCredentialLister
+
+	// refs holds the commits of the refs of each polled pipeline seen by the last poll
+	refs map[string]map[string]string
+}
+
+func (p *RefPoller) sync(ctx context.Context, syncInterval time.Duration) {
+	for range ticker.Context(ctx, syncInterval) {
+		p.poll()
+	}
+}
+
+func (p *RefPoller) poll() {
+	pipelines, err := p.pipelineLister.List("", labels.Everything())
+	if err != nil {
+		logrus.Errorf("Error listing pipelines - %v", err)
+		return
+	}
+	polled := map[string]bool{}
+	for _, pipeline := range pipelines {
+		if !controller.ObjectInCluster(p.clusterName, pipeline) || pipeline.DeletionTimestamp != nil {
+			continue
+		}
+		if pipeline.Status.PipelineState == "inactive" ||
+			(!pipeline.Spec.TriggerWebhookPush && !pipeline.Spec.TriggerWebhookTag) {
+			continue
+		}
+		pipelineID := ref.Ref(pipeline)
+		refs, err := p.listRefs(pipeline)
+		if err != nil {
+			logrus.Warnf("failed to poll repository of pipeline %s: %v", pipelineID, err)
+			polled[pipelineID] = true
+			continue
+		} else if refs == nil {
+			continue
+		}
+		polled[pipelineID] = true
+		previous, ok := p.refs[pipelineID]
+		p.refs[pipelineID] = refs
+		if !ok {
+			//the first poll only records the current refs
+			continue
+		}
+		for name, commit := range refs {
+			if previous[name] == commit {
+				continue
+			}
+			if err := p.trigger(pipeline, name, commit); err != nil {
+				logrus.Warnf("failed to trigger pipeline %s for %s: %v", pipelineID, name, err)
+			}
+		}
+	}
+	for pipelineID := range p.refs {
+		if !polled[pipelineID] {
+			delete(p.refs, pipelineID)
+		}
+	}
+}
+
+// listRefs returns the refs of the repository of a pipeline, or nil if the pipeline is triggered by webhooks
+func (p *RefPoller) listRefs(pipeline *v3.Pipeline) (map[string]string, error) {
+	if pipeline.Spec.SourceCodeCredentialName == "" {
+		return nil, nil
+	}
+	ns, name := ref.Parse(pipeline.Spec.SourceCodeCredentialName)
+	credential, err := p.sourceCodeCredentialLister.Get(ns, name)
+	if err != nil {
+		return nil, err
+	}
+	if credential.Spec.SourceCodeType != model.GitType {
+		return nil, nil
+	}
+	_, projID := ref.Parse(pipeline.Spec.ProjectName)
+	scpConfig, err := providers.GetSourceCodeProviderConfig(credential.Spec.SourceCodeType, projID)
+	if err != nil {
+		return nil, err
+	}
+	scpRemote, err := remote.New(scpConfig)
+	if err != nil {
+		return nil, err
+	}
+	refLister, ok := scpRemote.(model.RefLister)
+	if !ok {
+		return nil, nil
+	}
+	accessToken, err := utils.EnsureAccessToken(p.sourceCodeCredentials, scpRemote, credential)
+	if err != nil {
+		return nil, err
+	}
+	return refLister.ListRefs(pipeline.Spec.RepositoryURL, accessToken)
+}
+
+func (p *RefPoller) trigger(pipeline *v3.Pipeline, name string, commit string) error {
+	info := &model.BuildInfo{
+		TriggerType: utils.TriggerTypeWebhook,
+		Commit:      commit,
+		Ref:         name,
+	}
+	if strings.HasPrefix(name, refsTagPrefix) {
+		if !pipeline.Spec.TriggerWebhookTag {
+			return nil
+		}
+		info.Event = utils.WebhookEventTag
+		info.Branch = strings.TrimPrefix(name, refsTagPrefix)
+		info.Message = "tag " + info.Branch
+	} else if strings.HasPrefix(name, refsBranchPrefix) {
+		if !pipeline.Spec.TriggerWebhookPush {
+			return nil
+		}
+		info.Event = utils.WebhookEventPush
+		info.Branch = strings.TrimPrefix(name, refsBranchPrefix)
+	} else {
+		return nil
+	}
+
+	pipelineConfig, err := providers.GetPipelineConfigByBranch(p.sourceCodeCredentials, p.sourceCodeCredentialLister, pipeline, info.Branch)
+	if err != nil {
+		return err
+	}
+	if pipelineConfig == nil || !utils.Match(pipelineConfig.Branch, info.Branch) {
+		return nil
+	}
+	_, err = utils.GenerateExecution(p.pipelineExecutions, pipeline, pipelineConfig, info)
+	return err
+}
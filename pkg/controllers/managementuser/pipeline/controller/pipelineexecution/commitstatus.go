@@ -0,0 +1,220 @@
+package pipelineexecution
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/providers"
+	"github.com/rancher/rancher/pkg/pipeline/remote"
+	"github.com/rancher/rancher/pkg/pipeline/remote/model"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/rancher/rancher/pkg/ref"
+	"github.com/sirupsen/logrus"
+)
+
+const commitStatusContextPrefix = "rancher/pipeline"
+
+// commitStatusReporter reports the state of each stage of an execution on the commit it runs for, so the results
+// show up on the pull requests and commits of the repository. The execution controller queues the executions as they
+// change and a worker sends their statuses, so that slow source code providers don't hold up the controller.
+type commitStatusReporter struct {
+	sync.Mutex
+	pipelineLister             v3.PipelineLister
+	sourceCodeCredentials      v3.SourceCodeCredentialInterface
+	sourceCodeCredentialLister v3.SourceCodeCredentialLister
+	// queued holds the latest version of the executions to report
+	queued map[string]*v3.PipelineExecution
+	// reported holds the statuses reported for each execution, by context
+	reported map[string]map[string]string
+	wake     chan struct{}
+	// remote returns the source code provider of an execution and the access token of its repository
+	remote func(execution *v3.PipelineExecution) (model.Remote, string, error)
+}
+
+func newCommitStatusReporter(pipelineLister v3.PipelineLister, sourceCodeCredentials v3.SourceCodeCredentialInterface,
+	sourceCodeCredentialLister v3.SourceCodeCredentialLister) *commitStatusReporter {
+	r := &commitStatusReporter{
+		pipelineLister:             pipelineLister,
+		sourceCodeCredentials:      sourceCodeCredentials,
+		sourceCodeCredentialLister: sourceCodeCredentialLister,
+		queued:                     map[string]*v3.PipelineExecution{},
+		reported:                   map[string]map[string]string{},
+		wake:                       make(chan struct{}, 1),
+	}
+	r.remote = r.getRemote
+	return r
+}
+
+// enqueue queues the execution to report the statuses of its stages that changed
+func (r *commitStatusReporter) enqueue(execution *v3.PipelineExecution) {
+	if execution.Spec.Commit == "" {
+		return
+	}
+	r.Lock()
+	r.queued[ref.Ref(execution)] = execution.DeepCopy()
+	r.Unlock()
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// forget drops the statuses reported for a removed execution
+func (r *commitStatusReporter) forget(execution *v3.PipelineExecution) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.queued, ref.Ref(execution))
+	delete(r.reported, ref.Ref(execution))
+}
+
+func (r *commitStatusReporter) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		}
+
+		r.Lock()
+		queued := r.queued
+		r.queued = map[string]*v3.PipelineExecution{}
+		r.Unlock()
+		for _, execution := range queued {
+			r.report(execution)
+		}
+	}
+}
+
+// report sets the commit statuses of the execution that differ from the ones already reported. Failures are logged
+// and do not affect the execution.
+func (r *commitStatusReporter) report(execution *v3.PipelineExecution) {
+	key := ref.Ref(execution)
+	r.Lock()
+	var statuses []*model.CommitStatus
+	for _, status := range getCommitStatuses(execution) {
+		if r.reported[key][status.Context] != reportedState(status) {
+			statuses = append(statuses, status)
+		}
+	}
+	r.Unlock()
+	if len(statuses) == 0 {
+		return
+	}
+
+	scpRemote, accessToken, err := r.remote(execution)
+	if err != nil {
+		logrus.Warnf("failed to report commit status of pipeline execution %s: %v", execution.Name, err)
+		return
+	} else if scpRemote == nil {
+		return
+	}
+	for _, status := range statuses {
+		if err := scpRemote.SetCommitStatus(execution.Spec.RepositoryURL, execution.Spec.Commit, status, accessToken); err != nil {
+			logrus.Warnf("failed to report commit status of pipeline execution %s: %v", execution.Name, err)
+			return
+		}
+		r.Lock()
+		if r.reported[key] == nil {
+			r.reported[key] = map[string]string{}
+		}
+		r.reported[key][status.Context] = reportedState(status)
+		r.Unlock()
+	}
+}
+
+func reportedState(status *model.CommitStatus) string {
+	return status.State + ":" + status.Description
+}
+
+func (r *commitStatusReporter) getRemote(execution *v3.PipelineExecution) (model.Remote, string, error) {
+	ns, name := ref.Parse(execution.Spec.PipelineName)
+	pipeline, err := r.pipelineLister.Get(ns, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if pipeline.Spec.SourceCodeCredentialName == "" {
+		// public repositories without credentials cannot receive statuses
+		return nil, "", nil
+	}
+	ns, name = ref.Parse(pipeline.Spec.SourceCodeCredentialName)
+	credential, err := r.sourceCodeCredentialLister.Get(ns, name)
+	if err != nil {
+		return nil, "", err
+	}
+	_, projID := ref.Parse(pipeline.Spec.ProjectName)
+	scpConfig, err := providers.GetSourceCodeProviderConfig(credential.Spec.SourceCodeType, projID)
+	if err != nil {
+		return nil, "", err
+	}
+	scpRemote, err := remote.New(scpConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	accessToken, err := utils.EnsureAccessToken(r.sourceCodeCredentials, scpRemote, credential)
+	if err != nil {
+		return nil, "", err
+	}
+	return scpRemote, accessToken, nil
+}
+
+// getCommitStatuses returns the statuses of the stages of an execution that have a state to report
+func getCommitStatuses(execution *v3.PipelineExecution) []*model.CommitStatus {
+	var statuses []*model.CommitStatus
+	for i := range execution.Status.Stages {
+		if i >= len(execution.Spec.PipelineConfig.Stages) {
+			break
+		}
+		if status := getStageCommitStatus(execution, i); status != nil {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+func getStageCommitStatus(execution *v3.PipelineExecution, stageOrdinal int) *model.CommitStatus {
+	stage := execution.Spec.PipelineConfig.Stages[stageOrdinal]
+	status := &model.CommitStatus{
+		Context:   fmt.Sprintf("%s/%s", commitStatusContextPrefix, stage.Name),
+		TargetURL: utils.GetExecutionURL(execution),
+	}
+	state := execution.Status.Stages[stageOrdinal].State
+	if utils.IsFinishState(execution.Status.ExecutionState) && !utils.IsFinishState(state) {
+		// the stages that did not run when the execution ended, as when it failed to start or was stopped, take the
+		// state of the execution
+		switch execution.Status.ExecutionState {
+		case utils.StateFailed:
+			status.State = model.CommitStateFailure
+			status.Description = fmt.Sprintf("Stage %s did not run, the execution failed", stage.Name)
+		case utils.StateAborted, utils.StateDenied:
+			status.State = model.CommitStateError
+			status.Description = fmt.Sprintf("Stage %s did not run, the execution was %s", stage.Name, strings.ToLower(execution.Status.ExecutionState))
+		default:
+			return nil
+		}
+		return status
+	}
+
+	switch state {
+	case utils.StateBuilding:
+		status.State = model.CommitStatePending
+		status.Description = fmt.Sprintf("Stage %s is running", stage.Name)
+	case utils.StateSuccess:
+		status.State = model.CommitStateSuccess
+		status.Description = fmt.Sprintf("Stage %s succeeded", stage.Name)
+	case utils.StateSkipped:
+		status.State = model.CommitStateSuccess
+		status.Description = fmt.Sprintf("Stage %s was skipped", stage.Name)
+	case utils.StateFailed:
+		status.State = model.CommitStateFailure
+		status.Description = fmt.Sprintf("Stage %s failed", stage.Name)
+	case utils.StateAborted, utils.StateDenied:
+		status.State = model.CommitStateError
+		status.Description = fmt.Sprintf("Stage %s was %s", stage.Name, strings.ToLower(state))
+	default:
+		return nil
+	}
+	return status
+}
@@ -0,0 +1,125 @@
+package pipelineexecution
+
+import (
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/remote/model"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeRemote struct {
+	model.Remote
+	statuses []*model.CommitStatus
+}
+
+func (f *fakeRemote) SetCommitStatus(repoURL string, commit string, status *model.CommitStatus, accessToken string) error {
+	f.statuses = append(f.statuses, status)
+	return nil
+}
+
+func newExecution(executionState string, stageStates ...string) *v3.PipelineExecution {
+	execution := &v3.PipelineExecution{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "p-1",
+			Name:      "pipeline-1",
+		},
+		Spec: v32.PipelineExecutionSpec{
+			Commit: "abc",
+		},
+		Status: v32.PipelineExecutionStatus{
+			ExecutionState: executionState,
+		},
+	}
+	for i, state := range stageStates {
+		name := string(rune('a' + i))
+		execution.Spec.PipelineConfig.Stages = append(execution.Spec.PipelineConfig.Stages, v32.Stage{Name: name})
+		execution.Status.Stages = append(execution.Status.Stages, v32.StageStatus{State: state})
+	}
+	return execution
+}
+
+func TestGetCommitStatuses(t *testing.T) {
+	tests := []struct {
+		name      string
+		execution *v3.PipelineExecution
+		states    []string
+	}{
+		{
+			name:      "running",
+			execution: newExecution(utils.StateBuilding, utils.StateSuccess, utils.StateBuilding, utils.StateWaiting),
+			states:    []string{model.CommitStateSuccess, model.CommitStatePending},
+		},
+		{
+			name:      "succeeded",
+			execution: newExecution(utils.StateSuccess, utils.StateSuccess, utils.StateSkipped),
+			states:    []string{model.CommitStateSuccess, model.CommitStateSuccess},
+		},
+		{
+			name:      "stage failed",
+			execution: newExecution(utils.StateFailed, utils.StateSuccess, utils.StateFailed, utils.StateWaiting),
+			states:    []string{model.CommitStateSuccess, model.CommitStateFailure, model.CommitStateFailure},
+		},
+		{
+			name:      "failed to start",
+			execution: newExecution(utils.StateFailed, utils.StateWaiting, utils.StateWaiting),
+			states:    []string{model.CommitStateFailure, model.CommitStateFailure},
+		},
+		{
+			name:      "stopped",
+			execution: newExecution(utils.StateAborted, utils.StateSuccess, utils.StateAborted, utils.StateWaiting),
+			states:    []string{model.CommitStateSuccess, model.CommitStateError, model.CommitStateError},
+		},
+		{
+			name:      "queueing",
+			execution: newExecution(utils.StateQueueing, utils.StateWaiting),
+		},
+	}
+	for _, tt := range tests {
+		var states []string
+		for _, status := range getCommitStatuses(tt.execution) {
+			states = append(states, status.State)
+		}
+		assert.Equal(t, tt.states, states, tt.name)
+	}
+}
+
+func TestCommitStatusReporter(t *testing.T) {
+	remote := &fakeRemote{}
+	r := newCommitStatusReporter(nil, nil, nil)
+	r.remote = func(execution *v3.PipelineExecution) (model.Remote, string, error) {
+		return remote, "token", nil
+	}
+
+	// the queued versions of an execution are coalesced
+	r.enqueue(newExecution(utils.StateBuilding, utils.StateBuilding, utils.StateWaiting))
+	r.enqueue(newExecution(utils.StateBuilding, utils.StateSuccess, utils.StateBuilding))
+	assert.Len(t, r.queued, 1)
+	for _, execution := range r.queued {
+		r.report(execution)
+	}
+	assert.Len(t, remote.statuses, 2)
+
+	// only the changed statuses are reported again
+	remote.statuses = nil
+	r.report(newExecution(utils.StateSuccess, utils.StateSuccess, utils.StateSuccess))
+	if assert.Len(t, remote.statuses, 1) {
+		assert.Equal(t, "rancher/pipeline/b", remote.statuses[0].Context)
+		assert.Equal(t, model.CommitStateSuccess, remote.statuses[0].State)
+	}
+
+	remote.statuses = nil
+	r.forget(newExecution(utils.StateSuccess))
+	r.report(newExecution(utils.StateSuccess, utils.StateSuccess, utils.StateSuccess))
+	assert.Len(t, remote.statuses, 2)
+
+	// executions without a commit are not reported
+	execution := newExecution(utils.StateBuilding, utils.StateBuilding)
+	execution.Spec.Commit = ""
+	r.queued = map[string]*v3.PipelineExecution{}
+	r.enqueue(execution)
+	assert.Empty(t, r.queued)
+}
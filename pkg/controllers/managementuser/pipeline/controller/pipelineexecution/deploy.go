@@ -43,6 +43,9 @@ func (l *Lifecycle) deploy(projectName string) error {
 	clusterID, projectID := ref.Parse(projectName)
 	ns := getPipelineNamespace(clusterID, projectID)
 	if _, err := l.namespaceLister.Get("", ns.Name); err == nil {
+		if err := l.deployEngine(projectName); err != nil {
+			return err
+		}
 		return l.reconcileRb(projectName)
 	} else if !apierrors.IsNotFound(err) {
 		return err
@@ -102,13 +105,8 @@ func (l *Lifecycle) deploy(projectName string) error {
 	if _, err := l.networkPolicies.Create(np); err != nil && !apierrors.IsAlreadyExists(err) {
 		return errors.Wrapf(err, "Error create a pipeline networkpolicy")
 	}
-	jenkinsService := getJenkinsService(nsName)
-	if _, err := l.services.Create(jenkinsService); err != nil && !apierrors.IsAlreadyExists(err) {
-		return errors.Wrapf(err, "Error creating the jenkins service")
-	}
-	jenkinsDeployment := GetJenkinsDeployment(nsName)
-	if _, err := l.deployments.Create(jenkinsDeployment); err != nil && !apierrors.IsAlreadyExists(err) {
-		return errors.Wrapf(err, "Error creating the jenkins deployment")
+	if err := l.deployEngine(projectName); err != nil {
+		return err
 	}
 	registryService := getRegistryService(nsName)
 	if _, err := l.services.Create(registryService); err != nil && !apierrors.IsAlreadyExists(err) {
@@ -118,14 +116,6 @@ func (l *Lifecycle) deploy(projectName string) error {
 	if _, err := l.deployments.Create(registryDeployment); err != nil && !apierrors.IsAlreadyExists(err) {
 		return errors.Wrapf(err, "Error creating the registry deployment")
 	}
-	minioService := getMinioService(nsName)
-	if _, err := l.services.Create(minioService); err != nil && !apierrors.IsAlreadyExists(err) {
-		return errors.Wrapf(err, "Error creating the minio service")
-	}
-	minioDeployment := GetMinioDeployment(nsName)
-	if _, err := l.deployments.Create(minioDeployment); err != nil && !apierrors.IsAlreadyExists(err) {
-		return errors.Wrapf(err, "Error creating the minio deployment")
-	}
 
 	if err := l.reconcileProxyConfigMap(projectID); err != nil {
 		return err
@@ -142,6 +132,43 @@ func (l *Lifecycle) deploy(projectName string) error {
 	return l.reconcileRb(projectName)
 }
 
+// deployEngine deploys jenkins and minio, which stores the step logs of jenkins, if the project runs its pipelines
+// with jenkins. The tekton engine relies on tekton being installed in the cluster and needs no workloads.
+func (l *Lifecycle) deployEngine(projectName string) error {
+	_, projectID := ref.Parse(projectName)
+	engine, err := utils.GetEngine(l.pipelineSettingLister, projectID)
+	if err != nil {
+		return err
+	}
+	nsName := utils.GetPipelineCommonName(projectName)
+	if engine != utils.EngineJenkins {
+		return nil
+	}
+	if _, err := l.serviceLister.Get(nsName, utils.JenkinsName); err == nil {
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	jenkinsService := getJenkinsService(nsName)
+	if _, err := l.services.Create(jenkinsService); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "Error creating the jenkins service")
+	}
+	jenkinsDeployment := GetJenkinsDeployment(nsName)
+	if _, err := l.deployments.Create(jenkinsDeployment); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "Error creating the jenkins deployment")
+	}
+	minioService := getMinioService(nsName)
+	if _, err := l.services.Create(minioService); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "Error creating the minio service")
+	}
+	minioDeployment := GetMinioDeployment(nsName)
+	if _, err := l.deployments.Create(minioDeployment); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "Error creating the minio deployment")
+	}
+	return nil
+}
+
 func (l *Lifecycle) waitResourceQuotaInitCondition(namespace string) error {
 	tries := 0
 	for tries <= 3 {
@@ -22,9 +22,9 @@ import (
 	rbacv1 "github.com/rancher/rancher/pkg/generated/norman/rbac.authorization.k8s.io/v1"
 	"github.com/rancher/rancher/pkg/notifiers"
 	"github.com/rancher/rancher/pkg/pipeline/engine"
+	"github.com/rancher/rancher/pkg/pipeline/engine/jenkins"
 	"github.com/rancher/rancher/pkg/pipeline/utils"
 	"github.com/rancher/rancher/pkg/ref"
-	"github.com/rancher/rancher/pkg/settings"
 	"github.com/rancher/rancher/pkg/systemaccount"
 	"github.com/rancher/rancher/pkg/types/config"
 	"github.com/rancher/rancher/pkg/types/config/dialer"
@@ -96,6 +96,7 @@ type Lifecycle struct {
 	pipelineSettingLister      v3.PipelineSettingLister
 	pipelineEngine             engine.PipelineEngine
 	sourceCodeCredentialLister v3.SourceCodeCredentialLister
+	commitStatusReporter       *commitStatusReporter
 
 	DialerFactory dialer.Factory
 }
@@ -131,6 +132,7 @@ func Register(ctx context.Context, cluster *config.UserContext) {
 	notifierLister := cluster.Management.Management.Notifiers("").Controller().Lister()
 
 	pipelineEngine := engine.New(cluster, true)
+	commitStatusReporter := newCommitStatusReporter(pipelineLister, cluster.Management.Project.SourceCodeCredentials(""), sourceCodeCredentialLister)
 	pipelineExecutionLifecycle := &Lifecycle{
 		ctx:                        ctx,
 		systemAccountManager:       systemaccount.NewManager(cluster.Management),
@@ -157,6 +159,7 @@ func Register(ctx context.Context, cluster *config.UserContext) {
 		pipelineSettingLister:      pipelineSettingLister,
 		pipelineEngine:             pipelineEngine,
 		sourceCodeCredentialLister: sourceCodeCredentialLister,
+		commitStatusReporter:       commitStatusReporter,
 		notifierLister:             notifierLister,
 
 		DialerFactory: cluster.Management.Dialer,
@@ -181,10 +184,26 @@ func Register(ctx context.Context, cluster *config.UserContext) {
 		pipelineSettingLister:   pipelineSettingLister,
 	}
 
+	stepStorageCleaner := &StepStorageCleaner{
+		namespaceLister:       namespaceLister,
+		serviceLister:         serviceLister,
+		pipelineSettingLister: pipelineSettingLister,
+		jenkinsEngine: &jenkins.Engine{
+			UseCache:      true,
+			ServiceLister: serviceLister,
+			Secrets:       secrets,
+			SecretLister:  secretLister,
+			Dialer:        cluster.Management.Dialer,
+			ClusterName:   clusterName,
+		},
+	}
+
 	pipelineExecutions.AddClusterScopedLifecycle(ctx, pipelineExecutionLifecycle.GetName(), cluster.ClusterName, pipelineExecutionLifecycle)
 
 	go stateSyncer.sync(ctx, syncStateInterval)
+	go commitStatusReporter.run(ctx)
 	go registryCertSyncer.sync(ctx, checkCertRotateInterval)
+	go stepStorageCleaner.sync(ctx, pruneStorageInterval)
 
 }
 
@@ -201,6 +220,8 @@ func (l *Lifecycle) Sync(obj *v3.PipelineExecution) (runtime.Object, error) {
 		return obj, nil
 	}
 
+	l.commitStatusReporter.enqueue(obj)
+
 	//doIfAbort
 	if obj.Status.ExecutionState == utils.StateAborted {
 		if err := l.doStop(obj); err != nil {
@@ -441,6 +462,7 @@ func (l *Lifecycle) doCleanup(obj *v3.PipelineExecution) error {
 }
 
 func (l *Lifecycle) Remove(obj *v3.PipelineExecution) (runtime.Object, error) {
+	l.commitStatusReporter.forget(obj)
 	if utils.IsFinishState(obj.Status.ExecutionState) {
 		return obj, nil
 	}
@@ -593,12 +615,7 @@ Message: {{.Message}}
 	} else {
 		logrus.Warnf("cannot parse duration of pipeline execution %s: %v,%v", execution.Name, err1, err2)
 	}
-	buildLink := fmt.Sprintf("%s/p/%s/pipeline/pipelines/%s/run/%d",
-		settings.ServerURL.Get(),
-		execution.Spec.ProjectName,
-		execution.Spec.PipelineName,
-		execution.Spec.Run,
-	)
+	buildLink := utils.GetExecutionURL(execution)
 	builtMessage := "Success"
 	if v32.PipelineExecutionConditionBuilt.IsFalse(execution) {
 		builtMessage = v32.PipelineExecutionConditionBuilt.GetMessage(execution)
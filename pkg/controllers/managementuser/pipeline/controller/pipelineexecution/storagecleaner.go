@@ -0,0 +1,66 @@
+package pipelineexecution
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/rancher/rancher/pkg/generated/norman/core/v1"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/engine/jenkins"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/rancher/rancher/pkg/ref"
+	"github.com/rancher/wrangler/pkg/ticker"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// This cleaner keeps the step caches and artifacts in the minio store of each project within the expiry and
+// size limits of its pipeline settings
+
+const pruneStorageInterval = time.Hour
+
+type StepStorageCleaner struct {
+	namespaceLister       v1.NamespaceLister
+	serviceLister         v1.ServiceLister
+	pipelineSettingLister v3.PipelineSettingLister
+	jenkinsEngine         *jenkins.Engine
+}
+
+func (s *StepStorageCleaner) sync(ctx context.Context, syncInterval time.Duration) {
+	for range ticker.Context(ctx, syncInterval) {
+		s.pruneStorage()
+	}
+}
+
+func (s *StepStorageCleaner) pruneStorage() {
+	labelsSearchSet := labels.Set{utils.PipelineNamespaceLabel: "true"}
+	namespaces, err := s.namespaceLister.List("", labels.SelectorFromSet(labelsSearchSet))
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+	for _, ns := range namespaces {
+		if ns.DeletionTimestamp != nil {
+			continue
+		}
+		if _, err := s.serviceLister.Get(ns.Name, utils.MinioName); apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			logrus.Error(err)
+			continue
+		}
+		_, projectID := ref.Parse(getProjectID(ns))
+		if projectID == "" {
+			continue
+		}
+		settings, err := utils.GetStepStorageSettings(s.pipelineSettingLister, projectID)
+		if err != nil {
+			logrus.Errorf("failed to get pipeline storage settings of %s project: %v", projectID, err)
+			continue
+		}
+		if err := s.jenkinsEngine.PruneStepStorage(ns.Name, settings); err != nil {
+			logrus.Errorf("failed to prune pipeline caches and artifacts of %s project: %v", projectID, err)
+		}
+	}
+}
@@ -30,6 +30,11 @@ var settings = map[string]string{
 	utils.SettingExecutorMemoryLimit:   utils.SettingExecutorMemoryLimitDefault,
 	utils.SettingExecutorCPURequest:    utils.SettingExecutorCPURequestDefault,
 	utils.SettingExecutorCPULimit:      utils.SettingExecutorCPULimitDefault,
+	utils.SettingEngine:                utils.SettingEngineDefault,
+	utils.SettingCacheExpiry:           utils.SettingCacheExpiryDefault,
+	utils.SettingCacheSizeLimit:        utils.SettingCacheSizeLimitDefault,
+	utils.SettingArtifactExpiry:        utils.SettingArtifactExpiryDefault,
+	utils.SettingArtifactSizeLimit:     utils.SettingArtifactSizeLimitDefault,
 }
 
 func Register(ctx context.Context, cluster *config.UserContext) {
@@ -83,6 +88,8 @@ func (l *Syncer) addSourceCodeProviderConfigs(obj *v3.Project) error {
 		model.GitlabType:          pclient.GitlabPipelineConfigType,
 		model.BitbucketCloudType:  pclient.BitbucketCloudPipelineConfigType,
 		model.BitbucketServerType: pclient.BitbucketServerPipelineConfigType,
+		model.GiteaType:           pclient.GiteaPipelineConfigType,
+		model.GitType:             pclient.GitPipelineConfigType,
 	}
 	for name, pType := range supportedProviders {
 		if err := l.addSourceCodeProviderConfig(name, pType, false, obj); err != nil {
@@ -93,11 +93,9 @@ func (l *PipelineService) ensureSecrets(namespace *corev1.Namespace) error {
 }
 
 func (l *PipelineService) upgradeComponents(ns string) error {
+	// projects running their pipelines with tekton have no jenkins deployment
 	jenkinsDeployment := pipelineexecution.GetJenkinsDeployment(ns)
-	if _, err := l.deployments.Update(jenkinsDeployment); err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil
-		}
+	if _, err := l.deployments.Update(jenkinsDeployment); err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("upgrade system service %s:%s failed, %v", jenkinsDeployment.Namespace, jenkinsDeployment.Name, err)
 	}
 
@@ -68,6 +68,8 @@ func addRoles(wrangler *wrangler.Context, management *config.ManagementContext)
 		addRule().apiGroups("management.cattle.io").resources("clustertemplaterevisions").verbs("create")
 	rb.addRole("View Rancher Metrics", "view-rancher-metrics").
 		addRule().apiGroups("management.cattle.io").resources("ranchermetrics").verbs("get")
+	rb.addRole("View Shell Recordings", "shellrecordings-view").
+		addRule().apiGroups("management.cattle.io").resources("shellrecordings").verbs("get", "list")
 
 	rb.addRole("Admin", "admin").
 		addRule().apiGroups("*").resources("*").verbs("*").
@@ -199,6 +201,9 @@ func addRoles(wrangler *wrangler.Context, management *config.ManagementContext)
 	rb.addRoleTemplate("Manage Cluster Backups", "backups-manage", "cluster", false, false, false).
 		addRule().apiGroups("management.cattle.io").resources("etcdbackups").verbs("*")
 
+	rb.addRoleTemplate("View Shell Recordings", "shellrecordings-view", "cluster", false, false, false).
+		addRule().apiGroups("management.cattle.io").resources("shellrecordings").verbs("get", "list")
+
 	// Project roles
 	rb.addRoleTemplate("Project Owner", "project-owner", "project", false, false, false).
 		addRule().apiGroups("management.cattle.io").resources("projectroletemplatebindings").verbs("*").
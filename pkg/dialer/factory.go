@@ -20,6 +20,7 @@ import (
 	"github.com/rancher/rke/services"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -34,6 +35,7 @@ func NewFactory(apiContext *config.ScaledContext) (*Factory, error) {
 
 	return &Factory{
 		clusterLister:    apiContext.Management.Clusters("").Controller().Lister(),
+		clusters:         apiContext.Management.Clusters(""),
 		nodeLister:       apiContext.Management.Nodes("").Controller().Lister(),
 		TunnelServer:     tunneler,
 		TunnelAuthorizer: authorizer,
@@ -43,6 +45,8 @@ func NewFactory(apiContext *config.ScaledContext) (*Factory, error) {
 type Factory struct {
 	nodeLister       v3.NodeLister
 	clusterLister    v3.ClusterLister
+	clusters         v3.ClusterInterface
+	health           connectionHealth
 	TunnelServer     *remotedialer.Server
 	TunnelAuthorizer *tunnelserver.Authorizer
 }
@@ -51,9 +55,14 @@ func (f *Factory) ClusterDialer(clusterName string) (dialer.Dialer, error) {
 	return func(ctx context.Context, network, address string) (net.Conn, error) {
 		d, err := f.clusterDialer(clusterName, address)
 		if err != nil {
+			tunnelserver.Sessions.DialError(clusterName)
 			return nil, err
 		}
-		return d(ctx, network, address)
+		conn, err := d(ctx, network, address)
+		if err != nil {
+			tunnelserver.Sessions.DialError(clusterName)
+		}
+		return conn, err
 	}, nil
 }
 
@@ -168,7 +177,10 @@ func (f *Factory) translateClusterAddress(cluster *v3.Cluster, clusterHostPort,
 
 func (f *Factory) clusterDialer(clusterName, address string) (dialer.Dialer, error) {
 	cluster, err := f.clusterLister.Get("", clusterName)
-	if err != nil {
+	if apierrors.IsNotFound(err) {
+		f.health.forget(clusterName)
+		return nil, err
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -184,16 +196,14 @@ func (f *Factory) clusterDialer(clusterName, address string) (dialer.Dialer, err
 		return native()
 	}
 
+	if mode := connectionMode(cluster); mode != v32.ClusterConnectionModeAuto {
+		logrus.Tracef("dialerFactory: connection mode of cluster [%s] is forced to [%s]", cluster.Name, mode)
+		return f.forcedDialer(cluster, mode, hostPort, address)
+	}
+
 	if f.TunnelServer.HasSession(cluster.Name) {
 		logrus.Tracef("dialerFactory: tunnel session found for cluster [%s]", cluster.Name)
-		cd := f.TunnelServer.Dialer(cluster.Name)
-		return func(ctx context.Context, network, address string) (net.Conn, error) {
-			if cluster.Status.Driver == v32.ClusterDriverRKE {
-				address = f.translateClusterAddress(cluster, hostPort, address)
-			}
-			logrus.Tracef("dialerFactory: returning network [%s] and address [%s] as clusterDialer", network, address)
-			return cd(ctx, network, address)
-		}, nil
+		return f.failoverDialer(cluster, hostPort, f.tunnelDialer(cluster, hostPort)), nil
 	}
 	logrus.Tracef("dialerFactory: no tunnel session found for cluster [%s], falling back to nodeDialer", cluster.Name)
 
@@ -214,7 +224,7 @@ func (f *Factory) clusterDialer(clusterName, address string) (dialer.Dialer, err
 			logrus.Tracef("dialerFactory: using node [%s]/[%s] for nodeDialer",
 				node.Labels["management.cattle.io/nodename"], node.Name)
 			if nodeDialer, err := f.nodeDialer(clusterName, node.Name); err == nil {
-				return func(ctx context.Context, network, address string) (net.Conn, error) {
+				return f.failoverDialer(cluster, hostPort, func(ctx context.Context, network, address string) (net.Conn, error) {
 					if address == hostPort && localAPIEndpoint {
 						logrus.Trace("dialerFactory: rewriting address/port to 127.0.0.1:6443 as node may not" +
 							" have direct kube-api access")
@@ -223,7 +233,7 @@ func (f *Factory) clusterDialer(clusterName, address string) (dialer.Dialer, err
 					}
 					logrus.Tracef("dialerFactory: Returning network [%s] and address [%s] as nodeDialer", network, address)
 					return nodeDialer(ctx, network, address)
-				}, nil
+				}), nil
 			}
 		}
 	}
@@ -232,21 +242,30 @@ func (f *Factory) clusterDialer(clusterName, address string) (dialer.Dialer, err
 	for i := 0; i < 4; i++ {
 		if f.TunnelServer.HasSession(cluster.Name) {
 			logrus.Debugf("Cluster [%s] has reconnected, resuming", cluster.Name)
-			cd := f.TunnelServer.Dialer(cluster.Name)
-			return func(ctx context.Context, network, address string) (net.Conn, error) {
-				if cluster.Status.Driver == v32.ClusterDriverRKE {
-					address = f.translateClusterAddress(cluster, hostPort, address)
-				}
-				logrus.Tracef("dialerFactory: returning network [%s] and address [%s] as clusterDialer", network, address)
-				return cd(ctx, network, address)
-			}, nil
+			return f.failoverDialer(cluster, hostPort, f.tunnelDialer(cluster, hostPort)), nil
 		}
 		time.Sleep(wait.Jitter(5*time.Second, 1))
 	}
 
+	if address == hostPort && len(f.fallbackDialers(cluster)) > 0 {
+		logrus.Debugf("Cluster [%s] has not reconnected, connecting to its API endpoint without the tunnel", cluster.Name)
+		return f.failoverDialer(cluster, hostPort, nil), nil
+	}
+
 	return nil, fmt.Errorf(WaitForAgentError, cluster.Name)
 }
 
+func (f *Factory) tunnelDialer(cluster *v3.Cluster, hostPort string) dialer.Dialer {
+	cd := f.TunnelServer.Dialer(cluster.Name)
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if cluster.Status.Driver == v32.ClusterDriverRKE {
+			address = f.translateClusterAddress(cluster, hostPort, address)
+		}
+		logrus.Tracef("dialerFactory: returning network [%s] and address [%s] as clusterDialer", network, address)
+		return cd(ctx, network, address)
+	}
+}
+
 func hostPort(cluster *v3.Cluster) string {
 	u, err := url.Parse(cluster.Status.APIEndpoint)
 	if err != nil {
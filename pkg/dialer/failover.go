@@ -0,0 +1,266 @@
+package dialer
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/settings"
+	"github.com/rancher/rancher/pkg/types/config/dialer"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/retry"
+)
+
+// The API endpoint of a cluster is reached through the agent tunnel, then directly with the credentials stored on the
+// cluster, then through the cluster-connect-proxy. A way to connect that fails is skipped for unhealthyTimeout. The
+// connection mode in the cluster spec, which only admins can set, forces a single way to connect, the way last used is
+// in the cluster status.
+
+const unhealthyTimeout = 30 * time.Second
+
+type connectionHealth struct {
+	sync.Mutex
+	unhealthyUntil map[string]time.Time
+	modes          map[string]string
+}
+
+func (h *connectionHealth) healthy(clusterName, mode string) bool {
+	h.Lock()
+	defer h.Unlock()
+	return time.Now().After(h.unhealthyUntil[clusterName+"/"+mode])
+}
+
+func (h *connectionHealth) failed(clusterName, mode string) {
+	h.Lock()
+	defer h.Unlock()
+	if h.unhealthyUntil == nil {
+		h.unhealthyUntil = map[string]time.Time{}
+	}
+	now := time.Now()
+	for key, until := range h.unhealthyUntil {
+		if now.After(until) {
+			delete(h.unhealthyUntil, key)
+		}
+	}
+	h.unhealthyUntil[clusterName+"/"+mode] = now.Add(unhealthyTimeout)
+}
+
+// forget drops the state of a removed cluster
+func (h *connectionHealth) forget(clusterName string) {
+	h.Lock()
+	defer h.Unlock()
+	for key := range h.unhealthyUntil {
+		if strings.HasPrefix(key, clusterName+"/") {
+			delete(h.unhealthyUntil, key)
+		}
+	}
+	delete(h.modes, clusterName)
+}
+
+// used returns whether the mode changed since it was last used for the cluster
+func (h *connectionHealth) used(clusterName, mode string) bool {
+	h.Lock()
+	defer h.Unlock()
+	if h.modes == nil {
+		h.modes = map[string]string{}
+	}
+	if h.modes[clusterName] == mode {
+		return false
+	}
+	h.modes[clusterName] = mode
+	return true
+}
+
+func connectionMode(cluster *v3.Cluster) string {
+	switch mode := cluster.Spec.ConnectionMode; mode {
+	case v32.ClusterConnectionModeTunnel, v32.ClusterConnectionModeDirect, v32.ClusterConnectionModeProxy:
+		return mode
+	case "", v32.ClusterConnectionModeAuto:
+	default:
+		logrus.Warnf("dialerFactory: invalid connection mode [%s] of cluster [%s], using auto", mode, cluster.Name)
+	}
+	return v32.ClusterConnectionModeAuto
+}
+
+// canConnectDirectly returns whether the stored API endpoint and credentials of the cluster allow connecting to it
+// without the tunnel
+func canConnectDirectly(cluster *v3.Cluster) bool {
+	return cluster.Status.APIEndpoint != "" && cluster.Status.CACert != "" && cluster.Status.ServiceAccountToken != ""
+}
+
+// fallbackDialers returns the direct and proxy dialers of the cluster API endpoint, in order
+func (f *Factory) fallbackDialers(cluster *v3.Cluster) map[string]dialer.Dialer {
+	dialers := map[string]dialer.Dialer{}
+	if !canConnectDirectly(cluster) {
+		return dialers
+	}
+
+	direct, _ := native()
+	dialers[v32.ClusterConnectionModeDirect] = direct
+	if proxyURL, err := connectProxyURL(settings.ClusterConnectProxy.Get()); err != nil {
+		logrus.Warnf("Failed to parse %s setting: %v", settings.ClusterConnectProxy.Name, err)
+	} else if proxyURL != nil {
+		dialers[v32.ClusterConnectionModeProxy] = connectProxyDialer(proxyURL)
+	}
+	return dialers
+}
+
+// connectProxyURL parses the address of the HTTP proxy the clusters are connected through, the proxies speaking other
+// protocols are rejected as connectProxyDialer only speaks HTTP CONNECT in clear text
+func connectProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := parseProxy(proxy)
+	if err != nil || proxyURL == nil {
+		return nil, err
+	}
+	if proxyURL.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported proxy scheme %q, only http is supported", proxyURL.Scheme)
+	}
+	if proxyURL.Port() == "" {
+		proxyURL.Host = net.JoinHostPort(proxyURL.Hostname(), "80")
+	}
+	return proxyURL, nil
+}
+
+// failoverDialer dials the cluster with the tunnel dialer, and the API endpoint with the fallback dialers when the
+// tunnel dialer is nil or fails
+func (f *Factory) failoverDialer(cluster *v3.Cluster, hostPort string, tunnel dialer.Dialer) dialer.Dialer {
+	fallbacks := f.fallbackDialers(cluster)
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		var lastErr error
+		if tunnel != nil {
+			conn, err := tunnel(ctx, network, address)
+			if err == nil {
+				f.connected(cluster, v32.ClusterConnectionModeTunnel)
+				return conn, nil
+			}
+			lastErr = err
+			if address != hostPort {
+				return nil, err
+			}
+		}
+
+		for _, mode := range []string{v32.ClusterConnectionModeDirect, v32.ClusterConnectionModeProxy} {
+			d, ok := fallbacks[mode]
+			if !ok || !f.health.healthy(cluster.Name, mode) {
+				continue
+			}
+			conn, err := d(ctx, network, address)
+			if err == nil {
+				f.connected(cluster, mode)
+				return conn, nil
+			}
+			logrus.Debugf("dialerFactory: %s connection to cluster [%s] failed: %v", mode, cluster.Name, err)
+			f.health.failed(cluster.Name, mode)
+			lastErr = err
+		}
+
+		if lastErr == nil {
+			lastErr = fmt.Errorf(WaitForAgentError, cluster.Name)
+		}
+		return nil, lastErr
+	}
+}
+
+// forcedDialer dials the cluster only with the way to connect forced by its spec
+func (f *Factory) forcedDialer(cluster *v3.Cluster, mode, hostPort, address string) (dialer.Dialer, error) {
+	if mode == v32.ClusterConnectionModeTunnel {
+		if !f.TunnelServer.HasSession(cluster.Name) {
+			return nil, fmt.Errorf(WaitForAgentError, cluster.Name)
+		}
+		f.connected(cluster, mode)
+		return f.tunnelDialer(cluster, hostPort), nil
+	}
+
+	d, ok := f.fallbackDialers(cluster)[mode]
+	if !ok {
+		return nil, fmt.Errorf("cluster [%s] can not be connected with mode %s", cluster.Name, mode)
+	}
+	if address != hostPort && !isProxyAddress(address) {
+		return nil, fmt.Errorf("cluster [%s] in %s mode can only be connected on its API endpoint", cluster.Name, mode)
+	}
+	f.connected(cluster, mode)
+	return d, nil
+}
+
+// connected records the way the cluster was last connected in its status
+func (f *Factory) connected(cluster *v3.Cluster, mode string) {
+	if !f.health.used(cluster.Name, mode) || f.clusters == nil {
+		return
+	}
+
+	go func() {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			current, err := f.clusterLister.Get("", cluster.Name)
+			if err != nil {
+				return err
+			}
+			if current.Status.LastConnectionMode == mode {
+				return nil
+			}
+			current = current.DeepCopy()
+			current.Status.LastConnectionMode = mode
+			_, err = f.clusters.Update(current)
+			return err
+		})
+		if err != nil {
+			logrus.Debugf("dialerFactory: failed to record connection mode of cluster [%s]: %v", cluster.Name, err)
+		}
+	}()
+}
+
+// connectProxyDialer dials addresses through an HTTP proxy with the CONNECT method
+func connectProxyDialer(proxyURL *url.URL) dialer.Dialer {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		netDialer, _ := native()
+		conn, err := netDialer(ctx, network, proxyURL.Host)
+		if err != nil {
+			return nil, err
+		}
+
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: address},
+			Host:   address,
+			Header: http.Header{},
+		}
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			auth := proxyURL.User.Username() + ":" + password
+			req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+			defer conn.SetDeadline(time.Time{})
+		}
+		if err := req.Write(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, req)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			conn.Close()
+			return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyURL.Host, address, resp.Status)
+		}
+		if reader.Buffered() > 0 {
+			conn.Close()
+			return nil, fmt.Errorf("proxy %s sent data before the connection to %s was established", proxyURL.Host, address)
+		}
+		return conn, nil
+	}
+}
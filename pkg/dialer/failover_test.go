@@ -0,0 +1,168 @@
+package dialer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConnectionMode(t *testing.T) {
+	cluster := &v32.Cluster{}
+	assert.Equal(t, v32.ClusterConnectionModeAuto, connectionMode(cluster))
+
+	cluster.Spec.ConnectionMode = v32.ClusterConnectionModeDirect
+	assert.Equal(t, v32.ClusterConnectionModeDirect, connectionMode(cluster))
+
+	cluster.Spec.ConnectionMode = "bogus"
+	assert.Equal(t, v32.ClusterConnectionModeAuto, connectionMode(cluster))
+}
+
+func TestConnectionHealth(t *testing.T) {
+	h := connectionHealth{}
+	assert.True(t, h.healthy("c1", v32.ClusterConnectionModeDirect))
+
+	h.failed("c1", v32.ClusterConnectionModeDirect)
+	assert.False(t, h.healthy("c1", v32.ClusterConnectionModeDirect))
+	assert.True(t, h.healthy("c1", v32.ClusterConnectionModeProxy))
+	assert.True(t, h.healthy("c2", v32.ClusterConnectionModeDirect))
+
+	assert.True(t, h.used("c1", v32.ClusterConnectionModeTunnel))
+	assert.False(t, h.used("c1", v32.ClusterConnectionModeTunnel))
+	assert.True(t, h.used("c1", v32.ClusterConnectionModeDirect))
+
+	// the expired failures are pruned
+	h.unhealthyUntil["c2/"+v32.ClusterConnectionModeDirect] = time.Now().Add(-time.Second)
+	h.failed("c3", v32.ClusterConnectionModeDirect)
+	assert.Len(t, h.unhealthyUntil, 2)
+
+	h.forget("c1")
+	assert.True(t, h.healthy("c1", v32.ClusterConnectionModeDirect))
+	assert.Len(t, h.unhealthyUntil, 1)
+	assert.True(t, h.used("c1", v32.ClusterConnectionModeDirect))
+}
+
+func TestConnectProxyURL(t *testing.T) {
+	proxyURL, err := connectProxyURL("")
+	assert.Nil(t, err)
+	assert.Nil(t, proxyURL)
+
+	proxyURL, err = connectProxyURL("proxy.example.com:3128")
+	assert.Nil(t, err)
+	assert.Equal(t, "proxy.example.com:3128", proxyURL.Host)
+
+	proxyURL, err = connectProxyURL("http://proxy.example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "proxy.example.com:80", proxyURL.Host)
+
+	for _, proxy := range []string{"https://proxy.example.com", "socks5://proxy.example.com:1080"} {
+		_, err = connectProxyURL(proxy)
+		assert.NotNil(t, err, proxy)
+	}
+}
+
+func TestFailoverDialer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	cluster := &v32.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+		Status: v32.ClusterStatus{
+			APIEndpoint:         "https://" + listener.Addr().String(),
+			CACert:              "ca",
+			ServiceAccountToken: "token",
+		},
+	}
+	f := &Factory{}
+	tunnelErr := errors.New("tunnel down")
+	tunnel := func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, tunnelErr
+	}
+	d := f.failoverDialer(cluster, hostPort(cluster), tunnel)
+
+	// the API endpoint is connected directly when the tunnel fails
+	conn, err := d(context.Background(), "tcp", listener.Addr().String())
+	assert.Nil(t, err)
+	conn.Close()
+	assert.False(t, f.health.used("c1", v32.ClusterConnectionModeDirect))
+
+	// other addresses are only reachable through the tunnel
+	_, err = d(context.Background(), "tcp", "10.0.0.1:10250")
+	assert.Equal(t, tunnelErr, err)
+}
+
+func TestConnectProxyDialer(t *testing.T) {
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer target.Close()
+	go func() {
+		conn, err := target.Accept()
+		if err != nil {
+			return
+		}
+		io.Copy(conn, conn)
+		conn.Close()
+	}()
+
+	var proxyAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		proxyAuth = req.Header.Get("Proxy-Authorization")
+		if req.Method != http.MethodConnect || req.Host != target.Addr().String() {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+		upstream, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := rw.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+		go io.Copy(upstream, conn)
+		go func() {
+			io.Copy(conn, upstream)
+			conn.Close()
+		}()
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("user", "pass")
+	d := connectProxyDialer(proxyURL)
+
+	conn, err := d(context.Background(), "tcp", target.Addr().String())
+	assert.Nil(t, err)
+	conn.Write([]byte("hello"))
+	data := make([]byte, 5)
+	_, err = io.ReadFull(conn, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, "Basic dXNlcjpwYXNz", proxyAuth)
+	conn.Close()
+
+	_, err = d(context.Background(), "tcp", "127.0.0.1:1")
+	assert.NotNil(t, err)
+}
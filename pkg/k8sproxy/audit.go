@@ -0,0 +1,77 @@
+package k8sproxy
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rancher/rancher/pkg/auth/audit"
+	"github.com/rancher/rancher/pkg/clusterrouter"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+const impersonateExtraHeaderPrefix = "Impersonate-Extra-"
+
+var requestInfoFactory = &request.RequestInfoFactory{
+	APIPrefixes:          sets.NewString("api", "apis"),
+	GrouplessAPIPrefixes: sets.NewString("api"),
+}
+
+// auditHandler correlates the requests proxied to the clusters with the rancher audit log. The audit ID, token and
+// provider of the request are forwarded as extra impersonation attributes, which the clusters record in their audit
+// logs, and the cluster and resource targeted by the request are recorded in the rancher audit log.
+func auditHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		clusterID := clusterrouter.GetClusterID(req)
+		if clusterID == "" {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		if auditID, ok := audit.IDFromContext(req.Context()); ok {
+			setImpersonateExtra(req, audit.ExtraAuditID, auditID)
+		}
+		if user, ok := request.UserFrom(req.Context()); ok {
+			for _, key := range []string{audit.ExtraTokenName, audit.ExtraAuthProvider} {
+				if values := user.GetExtra()[key]; len(values) > 0 {
+					setImpersonateExtra(req, key, values[0])
+				}
+			}
+		}
+
+		audit.SetProxyTarget(req.Context(), clusterID, targetResource(req, clusterID))
+		next.ServeHTTP(rw, req)
+	})
+}
+
+func setImpersonateExtra(req *http.Request, key, value string) {
+	if value == "" {
+		return
+	}
+	req.Header.Set(impersonateExtraHeaderPrefix+key, value)
+}
+
+// targetResource returns the kubernetes resource targeted by a request to the API of the cluster
+func targetResource(req *http.Request, clusterID string) *audit.Resource {
+	clusterReq := &http.Request{
+		Method: req.Method,
+		URL: &url.URL{
+			Path:     strings.TrimPrefix(req.URL.Path, "/k8s/clusters/"+clusterID),
+			RawQuery: req.URL.RawQuery,
+		},
+	}
+	info, err := requestInfoFactory.NewRequestInfo(clusterReq)
+	if err != nil || !info.IsResourceRequest {
+		return nil
+	}
+	return &audit.Resource{
+		Verb:        info.Verb,
+		APIGroup:    info.APIGroup,
+		APIVersion:  info.APIVersion,
+		Resource:    info.Resource,
+		Subresource: info.Subresource,
+		Namespace:   info.Namespace,
+		Name:        info.Name,
+	}
+}
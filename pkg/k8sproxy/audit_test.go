@@ -0,0 +1,133 @@
+package k8sproxy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/rancher/pkg/auth/audit"
+	"github.com/rancher/rancher/pkg/auth/requests"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	steveauth "github.com/rancher/steve/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/natefinch/lumberjack.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+type fakeAuthenticator struct {
+	requests.Authenticator
+}
+
+func (fakeAuthenticator) Authenticate(req *http.Request) (bool, string, []string, *v3.Token, error) {
+	return true, "u-1", []string{user.AllAuthenticated}, &v3.Token{
+		ObjectMeta:   metav1.ObjectMeta{Name: "token-xyz"},
+		AuthProvider: "github",
+	}, nil
+}
+
+type fakeSAR struct{}
+
+func (fakeSAR) UserCanImpersonateUser(req *http.Request, user, impUser string) (bool, error) {
+	return true, nil
+}
+
+func (fakeSAR) UserCanImpersonateGroups(req *http.Request, user string, groups []string) (bool, error) {
+	return true, nil
+}
+
+// newAuthedHandler chains the handler behind the authentication and audit of rancher and the impersonation of the
+// authenticated routes, like the proxy to the clusters
+func newAuthedHandler(logPath string, handler http.Handler) http.Handler {
+	auditFilter := audit.NewAuditLogMiddleware(&audit.LogWriter{
+		Level:  1,
+		Output: &lumberjack.Logger{Filename: logPath},
+	})
+	impersonation := steveauth.ToMiddleware(requests.NewImpersonatingAuth(fakeSAR{}))
+	return requests.ToAuthMiddleware(fakeAuthenticator{}).Chain(auditFilter).Chain(impersonation)(auditHandler(handler))
+}
+
+func TestAuditHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	logPath := filepath.Join(dir, "audit.log")
+
+	var proxied http.Header
+	handler := newAuthedHandler(logPath, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		proxied = req.Header
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodDelete, "/k8s/clusters/c-abc/apis/apps/v1/namespaces/ns1/deployments/web", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "token-xyz", proxied.Get("Impersonate-Extra-"+audit.ExtraTokenName))
+	assert.Equal(t, "github", proxied.Get("Impersonate-Extra-"+audit.ExtraAuthProvider))
+	auditID := proxied.Get("Impersonate-Extra-" + audit.ExtraAuditID)
+	assert.NotEmpty(t, auditID)
+
+	data, err := ioutil.ReadFile(logPath)
+	assert.Nil(t, err)
+	entry := struct {
+		AuditID   string          `json:"auditID"`
+		ClusterID string          `json:"clusterID"`
+		User      *audit.User     `json:"user"`
+		Resource  *audit.Resource `json:"resource"`
+	}{}
+	assert.Nil(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, auditID, entry.AuditID)
+	assert.Equal(t, "c-abc", entry.ClusterID)
+	assert.Equal(t, "token-xyz", entry.User.TokenName)
+	assert.Equal(t, "github", entry.User.AuthProvider)
+	assert.Equal(t, &audit.Resource{
+		Verb:       "delete",
+		APIGroup:   "apps",
+		APIVersion: "v1",
+		Resource:   "deployments",
+		Namespace:  "ns1",
+		Name:       "web",
+	}, entry.Resource)
+}
+
+func TestAuditHandlerImpersonation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var proxied http.Header
+	handler := newAuthedHandler(filepath.Join(dir, "audit.log"), http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		proxied = req.Header
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	// the token of the user is not forwarded as an attribute of the user it impersonates
+	req := httptest.NewRequest(http.MethodGet, "/k8s/clusters/c-abc/api/v1/pods", nil)
+	req.Header.Set("Impersonate-User", "u-2")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, proxied.Get("Impersonate-Extra-"+audit.ExtraTokenName))
+	assert.Empty(t, proxied.Get("Impersonate-Extra-"+audit.ExtraAuthProvider))
+	assert.NotEmpty(t, proxied.Get("Impersonate-Extra-"+audit.ExtraAuditID))
+
+	// impersonating groups keeps the user
+	req = httptest.NewRequest(http.MethodGet, "/k8s/clusters/c-abc/api/v1/pods", nil)
+	req.Header.Set("Impersonate-Group", "g-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "token-xyz", proxied.Get("Impersonate-Extra-"+audit.ExtraTokenName))
+}
+
+func TestTargetResource(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/k8s/clusters/c-abc/api/v1/pods?watch=true", nil)
+	assert.Equal(t, &audit.Resource{
+		Verb:       "watch",
+		APIVersion: "v1",
+		Resource:   "pods",
+	}, targetResource(req, "c-abc"))
+
+	req = httptest.NewRequest(http.MethodGet, "/k8s/clusters/c-abc/version", nil)
+	assert.Nil(t, targetResource(req, "c-abc"))
+}
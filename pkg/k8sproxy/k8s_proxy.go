@@ -10,6 +10,6 @@ import (
 )
 
 func New(scaledContext *config.ScaledContext, dialer dialer.Factory) http.Handler {
-	return clusterrouter.New(&scaledContext.RESTConfig, k8slookup.New(scaledContext, true), dialer,
-		scaledContext.Management.Clusters("").Controller().Lister())
+	return auditHandler(clusterrouter.New(&scaledContext.RESTConfig, k8slookup.New(scaledContext, true), dialer,
+		scaledContext.Management.Clusters("").Controller().Lister()))
 }
@@ -6,6 +6,7 @@ import (
 	errors2 "errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/rancher/rancher/pkg/kontainer-engine/logstream"
 	"github.com/rancher/rancher/pkg/kontainer-engine/types"
@@ -23,11 +24,22 @@ const (
 	Init        = "Init"
 )
 
+const (
+	DefaultCreateTimeout    = time.Hour
+	DefaultPostCheckTimeout = 15 * time.Minute
+)
+
 var (
 	// ErrClusterExists This error is checked in rancher, don't change the string
 	ErrClusterExists = errors2.New("cluster already exists")
 )
 
+// Timeouts bound the phases of creating a cluster, zero uses the default timeout of the phase
+type Timeouts struct {
+	Create    time.Duration
+	PostCheck time.Duration
+}
+
 // Cluster represents a kubernetes cluster
 type Cluster struct {
 	// The cluster driver to provision cluster
@@ -62,6 +74,11 @@ type Cluster struct {
 	// Metadata store specific driver options per cloud provider
 	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 
+	// The last phase of the create completed, a failed create resumes after it
+	Checkpoint string `json:"checkpoint,omitempty" yaml:"checkpoint,omitempty"`
+
+	Timeouts Timeouts `json:"-" yaml:"-"`
+
 	PersistStore PersistentStore `json:"-" yaml:"-"`
 
 	ConfigGetter ConfigGetter `json:"-" yaml:"-"`
@@ -104,6 +121,10 @@ func (c *Cluster) create(ctx context.Context, clusterInfo *types.ClusterInfo) er
 	if c.Status == PostCheck {
 		return nil
 	}
+	if c.Checkpoint == Creating {
+		logrus.Infof("Cluster %s was created by the driver, resuming from post check", c.Name)
+		return nil
+	}
 
 	if err := c.PersistStore.PersistStatus(*c, PreCreating); err != nil {
 		return err
@@ -125,11 +146,19 @@ func (c *Cluster) create(ctx context.Context, clusterInfo *types.ClusterInfo) er
 	}
 
 	// create cluster
-	info, err := c.Driver.Create(ctx, &driverOpts, clusterInfo)
+	createCtx, cancel := context.WithTimeout(ctx, timeoutOrDefault(c.Timeouts.Create, DefaultCreateTimeout))
+	defer cancel()
+	info, err := c.Driver.Create(createCtx, &driverOpts, clusterInfo)
 	if info != nil {
 		transformClusterInfo(c, info)
 	}
-	return err
+	if err != nil {
+		return phaseError(createCtx, Creating, err)
+	}
+
+	// checkpoint the cluster info so that a failed post check does not create the cluster again
+	c.Checkpoint = Creating
+	return c.PersistStore.PersistStatus(*c, Creating)
 }
 
 func (c *Cluster) PostCheck(ctx context.Context) error {
@@ -138,17 +167,36 @@ func (c *Cluster) PostCheck(ctx context.Context) error {
 	}
 
 	// receive cluster info back
-	info, err := c.Driver.PostCheck(ctx, toInfo(c))
+	postCheckCtx, cancel := context.WithTimeout(ctx, timeoutOrDefault(c.Timeouts.PostCheck, DefaultPostCheckTimeout))
+	defer cancel()
+	info, err := c.Driver.PostCheck(postCheckCtx, toInfo(c))
 	if err != nil {
-		return err
+		return phaseError(postCheckCtx, PostCheck, err)
 	}
 
 	transformClusterInfo(c, info)
+	c.Checkpoint = PostCheck
 
 	// persist cluster info
 	return c.Store()
 }
 
+func timeoutOrDefault(timeout, defaultTimeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout
+}
+
+// phaseError reports a driver call of a phase that ran out of time as a timeout rather than the error of the
+// canceled call
+func phaseError(ctx context.Context, phase string, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s phase timed out: %v", phase, err)
+	}
+	return err
+}
+
 func (c *Cluster) GenerateServiceAccount(ctx context.Context) error {
 	if err := c.restore(); err != nil {
 		return err
@@ -200,6 +248,31 @@ func (c *Cluster) createInner(ctx context.Context) error {
 	return c.PostCheck(ctx)
 }
 
+// Adopt takes over a cluster whose state was persisted by another store, the state is stored under the name of the
+// cluster and checked with the driver. A cluster whose adoption did not complete can be adopted again.
+func (c *Cluster) Adopt(ctx context.Context, state Cluster) error {
+	if status, err := c.getState(); err == nil && status != "" && status != Init && status != Error {
+		return ErrClusterExists
+	}
+	if state.DriverName != c.DriverName {
+		return fmt.Errorf("cluster state was created by driver %s, not %s", state.DriverName, c.DriverName)
+	}
+
+	state.Name = c.Name
+	if err := c.PersistStore.PersistStatus(state, Init); err != nil {
+		return err
+	}
+	if err := c.restore(); err != nil {
+		return err
+	}
+
+	if err := c.PostCheck(ctx); err != nil {
+		c.PersistStore.PersistStatus(*c, Error)
+		return err
+	}
+	return c.PersistStore.PersistStatus(*c, Running)
+}
+
 // Update updates a cluster
 func (c *Cluster) Update(ctx context.Context) error {
 	if err := c.restore(); err != nil {
@@ -353,6 +426,7 @@ func (c *Cluster) restore() error {
 	}
 	info := toInfo(&cluster)
 	transformClusterInfo(c, info)
+	c.Checkpoint = cluster.Checkpoint
 	return nil
 }
 
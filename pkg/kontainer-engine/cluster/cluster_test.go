@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rancher/rancher/pkg/kontainer-engine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDriver struct {
+	types.CloseableDriver
+	creates      int
+	postChecks   int
+	postCheckErr error
+	hang         bool
+}
+
+func (d *fakeDriver) Create(ctx context.Context, opts *types.DriverOptions, clusterInfo *types.ClusterInfo) (*types.ClusterInfo, error) {
+	d.creates++
+	if d.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &types.ClusterInfo{Endpoint: "1.2.3.4", Metadata: map[string]string{"id": "created"}}, nil
+}
+
+func (d *fakeDriver) PostCheck(ctx context.Context, clusterInfo *types.ClusterInfo) (*types.ClusterInfo, error) {
+	d.postChecks++
+	if d.postCheckErr != nil {
+		return nil, d.postCheckErr
+	}
+	clusterInfo.ServiceAccountToken = "token"
+	return clusterInfo, nil
+}
+
+type fakeStore struct {
+	clusters map[string]Cluster
+}
+
+func (s *fakeStore) GetStatus(name string) (string, error) {
+	return s.clusters[name].Status, nil
+}
+
+func (s *fakeStore) Get(name string) (Cluster, error) {
+	return s.clusters[name], nil
+}
+
+func (s *fakeStore) Remove(name string) error {
+	delete(s.clusters, name)
+	return nil
+}
+
+func (s *fakeStore) Store(cluster Cluster) error {
+	s.clusters[cluster.Name] = cluster
+	return nil
+}
+
+func (s *fakeStore) PersistStatus(cluster Cluster, status string) error {
+	cluster.Status = status
+	return s.Store(cluster)
+}
+
+type fakeConfigGetter struct{}
+
+func (fakeConfigGetter) GetConfig() (types.DriverOptions, error) {
+	return types.DriverOptions{StringOptions: map[string]string{}}, nil
+}
+
+func newTestCluster(driver *fakeDriver, store *fakeStore) *Cluster {
+	return &Cluster{
+		Driver:       driver,
+		Name:         "c-test",
+		ConfigGetter: fakeConfigGetter{},
+		PersistStore: store,
+	}
+}
+
+func TestCreateResumesFromCheckpoint(t *testing.T) {
+	driver := &fakeDriver{postCheckErr: errors.New("api not ready")}
+	store := &fakeStore{clusters: map[string]Cluster{}}
+
+	err := newTestCluster(driver, store).Create(context.Background())
+	assert.EqualError(t, err, "api not ready")
+	assert.Equal(t, Error, store.clusters["c-test"].Status)
+	assert.Equal(t, Creating, store.clusters["c-test"].Checkpoint)
+	assert.Equal(t, "1.2.3.4", store.clusters["c-test"].Endpoint)
+
+	driver.postCheckErr = nil
+	c := newTestCluster(driver, store)
+	assert.Nil(t, c.Create(context.Background()))
+	assert.Equal(t, 1, driver.creates)
+	assert.Equal(t, 2, driver.postChecks)
+	assert.Equal(t, Running, store.clusters["c-test"].Status)
+	assert.Equal(t, PostCheck, store.clusters["c-test"].Checkpoint)
+	assert.Equal(t, "token", c.ServiceAccountToken)
+}
+
+func TestCreateTimeout(t *testing.T) {
+	driver := &fakeDriver{hang: true}
+	store := &fakeStore{clusters: map[string]Cluster{}}
+
+	c := newTestCluster(driver, store)
+	c.Timeouts.Create = 10 * time.Millisecond
+	err := c.Create(context.Background())
+	assert.EqualError(t, err, "Creating phase timed out: context deadline exceeded")
+	assert.Equal(t, Error, store.clusters["c-test"].Status)
+	assert.Equal(t, "", store.clusters["c-test"].Checkpoint)
+	assert.Equal(t, 0, driver.postChecks)
+}
+
+func TestAdopt(t *testing.T) {
+	driver := &fakeDriver{}
+	store := &fakeStore{clusters: map[string]Cluster{}}
+	state := Cluster{
+		DriverName: "fake",
+		Name:       "cli-cluster",
+		Status:     Running,
+		Endpoint:   "1.2.3.4",
+		Metadata:   map[string]string{"state": "{}"},
+	}
+
+	c := newTestCluster(driver, store)
+	c.DriverName = "other"
+	assert.EqualError(t, c.Adopt(context.Background(), state), "cluster state was created by driver fake, not other")
+	assert.Empty(t, store.clusters)
+
+	c.DriverName = "fake"
+	assert.Nil(t, c.Adopt(context.Background(), state))
+	assert.Equal(t, 0, driver.creates)
+	assert.Equal(t, 1, driver.postChecks)
+	assert.Equal(t, Running, store.clusters["c-test"].Status)
+	assert.Equal(t, "1.2.3.4", store.clusters["c-test"].Endpoint)
+	assert.Equal(t, "{}", store.clusters["c-test"].Metadata["state"])
+	assert.Equal(t, "token", c.ServiceAccountToken)
+
+	assert.Equal(t, ErrClusterExists, newTestCluster(driver, store).Adopt(context.Background(), state))
+
+	failed := store.clusters["c-test"]
+	failed.Status = Error
+	store.clusters["c-test"] = failed
+	c = newTestCluster(driver, store)
+	c.DriverName = "fake"
+	assert.Nil(t, c.Adopt(context.Background(), state))
+	assert.Equal(t, Running, store.clusters["c-test"].Status)
+}
@@ -144,7 +144,15 @@ func create(ctx *cli.Context) error {
 		logrus.Error("Cluster name is required")
 		return cli.ShowCommandHelp(ctx, "create")
 	}
-	return cls.Create(context.Background())
+	if err := cls.Create(context.Background()); err != nil {
+		return err
+	}
+	// keep the options the cluster was created with so that they can be exported with it
+	driverOpts, err := configGetter.GetConfig()
+	if err != nil {
+		return err
+	}
+	return persistStore.StoreDriverOptions(cls.Name, driverOpts)
 }
 
 func lookUpDebugFlag() bool {
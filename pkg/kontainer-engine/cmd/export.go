@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/rancher/rancher/pkg/kontainer-engine/store"
+	"github.com/urfave/cli"
+)
+
+var passphraseFlag = cli.StringFlag{
+	Name:   "passphrase",
+	Usage:  "Passphrase to encrypt or decrypt the cluster archive",
+	EnvVar: "KONTAINER_ENGINE_PASSPHRASE",
+}
+
+// ExportCommand defines the export command
+func ExportCommand() cli.Command {
+	return cli.Command{
+		Name:      "export",
+		Usage:     "Export the state of a kubernetes cluster into an encrypted archive",
+		ArgsUsage: "[cluster-name]",
+		Action:    exportCluster,
+		Flags: []cli.Flag{
+			passphraseFlag,
+			cli.StringFlag{
+				Name:  "output,o",
+				Usage: "File to write the archive to, defaults to [cluster-name].archive",
+			},
+		},
+	}
+}
+
+func exportCluster(ctx *cli.Context) error {
+	name := ctx.Args().Get(0)
+	if name == "" || name == "--help" {
+		return cli.ShowCommandHelp(ctx, "export")
+	}
+
+	archive, err := store.CLIPersistStore{}.NewArchive(name)
+	if err != nil {
+		return err
+	}
+	data, err := archive.Encrypt(ctx.String("passphrase"))
+	if err != nil {
+		return err
+	}
+
+	output := ctx.String("output")
+	if output == "" {
+		output = name + ".archive"
+	}
+	if err := ioutil.WriteFile(output, data, 0600); err != nil {
+		return err
+	}
+
+	fmt.Println(output)
+	return nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/rancher/rancher/pkg/kontainer-engine/store"
+	"github.com/urfave/cli"
+)
+
+// ImportCommand defines the import command
+func ImportCommand() cli.Command {
+	return cli.Command{
+		Name:      "import",
+		Usage:     "Import the state of a kubernetes cluster from an encrypted archive",
+		ArgsUsage: "[archive-file]",
+		Action:    importCluster,
+		Flags: []cli.Flag{
+			passphraseFlag,
+		},
+	}
+}
+
+func importCluster(ctx *cli.Context) error {
+	file := ctx.Args().Get(0)
+	if file == "" || file == "--help" {
+		return cli.ShowCommandHelp(ctx, "import")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	archive, err := store.DecryptArchive(data, ctx.String("passphrase"))
+	if err != nil {
+		return err
+	}
+	if err := (store.CLIPersistStore{}).Import(archive); err != nil {
+		return err
+	}
+
+	fmt.Println(archive.Cluster.Name)
+	return nil
+}
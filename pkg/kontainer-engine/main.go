@@ -62,6 +62,8 @@ func main() {
 		cmd.SetVersionCommand(),
 		cmd.GetClusterSizeCommand(),
 		cmd.SetClusterSizeCommand(),
+		cmd.ExportCommand(),
+		cmd.ImportCommand(),
 	}
 	app.Flags = []cli.Flag{
 		cli.BoolFlag{
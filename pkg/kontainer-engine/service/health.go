@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/kontainer-engine/types"
+	"github.com/sirupsen/logrus"
+)
+
+// The processes of external drivers are supervised while they run: they are health checked over their grpc connection
+// and restarted on the same port with a backoff when they exit or keep failing their health checks. The first health
+// check of a process waits for healthCheckStartPeriod so that it has time to listen. The latest health of the driver
+// processes of this server is kept in driverHealth, the kontainer driver controller of the leader reports it.
+
+const (
+	maxHealthCheckFailures = 3
+	maxRestarts            = 5
+)
+
+var (
+	healthCheckInterval = 30 * time.Second
+	healthCheckTimeout  = 10 * time.Second
+	// a started process is given this long to listen before its first health check
+	healthCheckStartPeriod = 15 * time.Second
+	restartBackoff         = time.Second
+	maxRestartBackoff      = 30 * time.Second
+	// a process that ran this long before exiting starts over with a fresh restart count and backoff
+	restartResetPeriod = 10 * time.Minute
+
+	driverHealth = healthRegistry{
+		drivers: map[string]*v32.KontainerDriverHealth{},
+	}
+)
+
+type healthRegistry struct {
+	sync.Mutex
+	drivers map[string]*v32.KontainerDriverHealth
+}
+
+func (h *healthRegistry) update(name, state, message string, restarted bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	health, ok := h.drivers[name]
+	if !ok {
+		health = &v32.KontainerDriverHealth{}
+		h.drivers[name] = health
+	}
+	health.State = state
+	health.Message = message
+	health.LastChecked = time.Now().UTC().Format(time.RFC3339)
+	if restarted {
+		health.Restarts++
+	}
+}
+
+// GetDriverHealth returns the latest health of the processes of a driver, nil if this server did not run the driver
+func GetDriverHealth(name string) *v32.KontainerDriverHealth {
+	driverHealth.Lock()
+	defer driverHealth.Unlock()
+
+	return driverHealth.drivers[name].DeepCopy()
+}
+
+// supervise restarts the driver process until it is stopped or has been restarted maxRestarts times in a row without
+// running for restartResetPeriod
+func (r *RunningDriver) supervise(ctx context.Context, cmd *exec.Cmd, port string) {
+	var err error
+	for restarts := 0; ; restarts++ {
+		if restarts > 0 {
+			if restarts > maxRestarts {
+				logrus.Errorf("kontainerdriver %v failed after %d restarts: %v", r.Name, maxRestarts, err)
+				driverHealth.update(r.Name, v32.KontainerDriverFailed, fmt.Sprintf("failed after %d restarts: %v", maxRestarts, err), false)
+				return
+			}
+
+			backoff := getRestartBackoff(restarts)
+			logrus.Warnf("kontainerdriver %v restarting in %v: %v", r.Name, backoff, err)
+			driverHealth.update(r.Name, v32.KontainerDriverRestarting, err.Error(), true)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			cmd, err = r.startProcess(ctx, port)
+			if err != nil {
+				continue
+			}
+		}
+
+		started := time.Now()
+		err = r.monitor(ctx, cmd)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) >= restartResetPeriod {
+			restarts = 0
+		}
+	}
+}
+
+// monitor health checks the driver process until it exits, the process is killed once it failed
+// maxHealthCheckFailures health checks in a row
+func (r *RunningDriver) monitor(ctx context.Context, cmd *exec.Cmd) error {
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	kill := func(err error) error {
+		cmd.Process.Kill()
+		<-exited
+		return err
+	}
+
+	client, err := types.NewClient(r.Name, r.listenAddress)
+	if err != nil {
+		return kill(err)
+	}
+	defer client.Close()
+	checker, ok := client.(types.HealthChecker)
+	if !ok {
+		return kill(fmt.Errorf("driver client does not support health checks"))
+	}
+
+	failures := 0
+	timer := time.NewTimer(healthCheckStartPeriod)
+	defer timer.Stop()
+	for {
+		select {
+		case err := <-exited:
+			if err == nil {
+				return fmt.Errorf("driver process exited")
+			}
+			return fmt.Errorf("driver process exited: %v", err)
+		case <-timer.C:
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			err := checker.HealthCheck(checkCtx)
+			cancel()
+			timer.Reset(healthCheckInterval)
+			if ctx.Err() != nil {
+				continue
+			}
+			if err == nil {
+				failures = 0
+				driverHealth.update(r.Name, v32.KontainerDriverHealthy, "", false)
+				continue
+			}
+
+			failures++
+			driverHealth.update(r.Name, v32.KontainerDriverUnhealthy, err.Error(), false)
+			if failures >= maxHealthCheckFailures {
+				return kill(fmt.Errorf("driver failed %d health checks: %v", failures, err))
+			}
+		}
+	}
+}
+
+func getRestartBackoff(restarts int) time.Duration {
+	backoff := restartBackoff
+	for i := 1; i < restarts && backoff < maxRestartBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRestartBackoff {
+		return maxRestartBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRestartBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, getRestartBackoff(1))
+	assert.Equal(t, 2*time.Second, getRestartBackoff(2))
+	assert.Equal(t, 16*time.Second, getRestartBackoff(5))
+	assert.Equal(t, 30*time.Second, getRestartBackoff(6))
+	assert.Equal(t, 30*time.Second, getRestartBackoff(100))
+}
+
+func TestSuperviseCrashingDriver(t *testing.T) {
+	os.Setenv("CATTLE_DEV_MODE", "true")
+	defer os.Unsetenv("CATTLE_DEV_MODE")
+	defer func(backoff, maxBackoff time.Duration) {
+		restartBackoff, maxRestartBackoff = backoff, maxBackoff
+	}(restartBackoff, maxRestartBackoff)
+	restartBackoff, maxRestartBackoff = time.Millisecond, 4*time.Millisecond
+
+	r := &RunningDriver{
+		Name:          "crashing",
+		Path:          "false",
+		listenAddress: "127.0.0.1:1",
+	}
+	cmd, err := r.startProcess(context.Background(), "1")
+	assert.Nil(t, err)
+	r.supervise(context.Background(), cmd, "1")
+
+	health := GetDriverHealth("crashing")
+	if assert.NotNil(t, health) {
+		assert.Equal(t, v32.KontainerDriverFailed, health.State)
+		assert.Equal(t, int64(maxRestarts), health.Restarts)
+		assert.Contains(t, health.Message, "driver process exited")
+	}
+	assert.Nil(t, GetDriverHealth("unknown"))
+}
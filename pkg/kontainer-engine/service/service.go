@@ -243,6 +243,7 @@ func (e *EngineService) Create(ctx context.Context, name string, kontainerDriver
 
 	defer cls.Driver.Close()
 
+	cls.Timeouts = getTimeouts(kontainerDriver)
 	if err := cls.Create(ctx); err != nil {
 		return "", "", "", err
 	}
@@ -253,6 +254,38 @@ func (e *EngineService) Create(ctx context.Context, name string, kontainerDriver
 	return endpoint, cls.ServiceAccountToken, cls.RootCACert, nil
 }
 
+// Adopt takes over a cluster created by the kontainer-engine cli with its driver state intact
+func (e *EngineService) Adopt(ctx context.Context, name string, kontainerDriver *v3.KontainerDriver, clusterSpec v32.ClusterSpec, state cluster.Cluster) (string, string, string, error) {
+	runningDriver, err := e.getRunningDriver(kontainerDriver, clusterSpec)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	listenAddr, err := runningDriver.Start()
+	if err != nil {
+		return "", "", "", fmt.Errorf("error starting driver: %v", err)
+	}
+
+	defer runningDriver.Stop()
+
+	cls, err := e.convertCluster(name, listenAddr, clusterSpec)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	defer cls.Driver.Close()
+
+	cls.Timeouts = getTimeouts(kontainerDriver)
+	if err := cls.Adopt(ctx, state); err != nil {
+		return "", "", "", err
+	}
+	endpoint := cls.Endpoint
+	if !strings.HasPrefix(endpoint, "https://") {
+		endpoint = fmt.Sprintf("https://%s", cls.Endpoint)
+	}
+	return endpoint, cls.ServiceAccountToken, cls.RootCACert, nil
+}
+
 func (e *EngineService) getRunningDriver(kontainerDriver *v3.KontainerDriver, clusterSpec v32.ClusterSpec) (*RunningDriver, error) {
 	return &RunningDriver{
 		Name:    kontainerDriver.Name,
@@ -261,6 +294,17 @@ func (e *EngineService) getRunningDriver(kontainerDriver *v3.KontainerDriver, cl
 	}, nil
 }
 
+func getTimeouts(kontainerDriver *v3.KontainerDriver) cluster.Timeouts {
+	timeouts := kontainerDriver.Spec.Timeouts
+	if timeouts == nil {
+		return cluster.Timeouts{}
+	}
+	return cluster.Timeouts{
+		Create:    time.Duration(timeouts.CreateSeconds) * time.Second,
+		PostCheck: time.Duration(timeouts.PostCheckSeconds) * time.Second,
+	}
+}
+
 // Update creates the stub for cluster manager to call
 func (e *EngineService) Update(ctx context.Context, name string, kontainerDriver *v3.KontainerDriver, clusterSpec v32.ClusterSpec) (string, string, string, error) {
 	runningDriver, err := e.getRunningDriver(kontainerDriver, clusterSpec)
@@ -282,6 +326,8 @@ func (e *EngineService) Update(ctx context.Context, name string, kontainerDriver
 
 	defer cls.Driver.Close()
 
+	// a cluster that failed to create is created again by the update
+	cls.Timeouts = getTimeouts(kontainerDriver)
 	if err := cls.Update(ctx); err != nil {
 		return "", "", "", err
 	}
@@ -443,25 +489,16 @@ func (r *RunningDriver) Start() (string, error) {
 		var processContext context.Context
 		processContext, r.cancel = context.WithCancel(context.Background())
 
-		cmd := exec.CommandContext(processContext, r.Path, port)
-		cmd.Env = []string{"PATH=/usr/bin"}
-		cmd, err = jailer.JailCommand(cmd, "/opt/jail/driver-jail")
-		if err != nil {
-			return "", errors.WithMessage(err, "failed to setup jail command")
-		}
-
-		// redirect output to console
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
-		err := cmd.Start()
+		cmd, err := r.startProcess(processContext, port)
 		if err != nil {
-			return "", fmt.Errorf("error starting driver: %v", err)
+			r.cancel()
+			return "", err
 		}
 
 		time.Sleep(5 * time.Second)
 
 		r.listenAddress = listenAddress
+		go r.supervise(processContext, cmd, port)
 	}
 
 	logrus.Infof("kontainerdriver %v listening on address %v", r.Name, r.listenAddress)
@@ -469,6 +506,24 @@ func (r *RunningDriver) Start() (string, error) {
 	return r.listenAddress, nil
 }
 
+func (r *RunningDriver) startProcess(ctx context.Context, port string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, r.Path, port)
+	cmd.Env = []string{"PATH=/usr/bin"}
+	cmd, err := jailer.JailCommand(cmd, "/opt/jail/driver-jail")
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to setup jail command")
+	}
+
+	// redirect output to console
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting driver: %v", err)
+	}
+	return cmd, nil
+}
+
 // portOnly attempts to return port fragment of address
 func portOnly(address string) (string, error) {
 	portParseErr := fmt.Errorf("failed to parse port from address [%s]", address)
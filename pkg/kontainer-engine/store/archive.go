@@ -0,0 +1,248 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/rancher/rancher/pkg/kontainer-engine/cluster"
+	"github.com/rancher/rancher/pkg/kontainer-engine/types"
+	"github.com/rancher/rancher/pkg/kontainer-engine/utils"
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	driverOptionsName = "options.json"
+
+	archiveMagic = "KEA1"
+	saltLen      = 16
+	keyLen       = 32
+)
+
+// ErrBadPassphrase is returned when an archive can't be decrypted with the given passphrase
+var ErrBadPassphrase = errors.New("failed to decrypt archive, wrong passphrase or corrupted archive")
+
+// Archive bundles the persisted state of a cluster so that it can be moved to another store
+type Archive struct {
+	// The persisted cluster, including the driver state in its metadata
+	Cluster cluster.Cluster `json:"cluster"`
+	// The driver options the cluster was created with
+	DriverOptions *types.DriverOptions `json:"driverOptions,omitempty"`
+	// The kubeconfig of the cluster, with only the entries of the cluster
+	KubeConfig string `json:"kubeConfig,omitempty"`
+}
+
+// NewArchive bundles the state of a cluster stored by the cli
+func (c CLIPersistStore) NewArchive(name string) (*Archive, error) {
+	cls, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	driverOptions, err := c.GetDriverOptions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{
+		Cluster:       cls,
+		DriverOptions: driverOptions,
+	}
+
+	config, err := getConfigFromFile()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	config = filterConfigByName(config, name)
+	if len(config.Clusters) > 0 {
+		data, err := yaml.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+		archive.KubeConfig = string(data)
+	}
+	return archive, nil
+}
+
+// Import stores the cluster state of an archive, the kubeconfig entries of the cluster replace the existing ones. The
+// cluster is stored last so that a failed import can be retried.
+func (c CLIPersistStore) Import(archive *Archive) error {
+	name := archive.Cluster.Name
+	if name == "" {
+		return errors.New("archive has no cluster name")
+	}
+	if _, err := c.Get(name); err == nil {
+		return fmt.Errorf("cluster %v already exists", name)
+	}
+
+	if archive.KubeConfig != "" {
+		if err := importConfig(name, archive.KubeConfig); err != nil {
+			return err
+		}
+	}
+	if archive.DriverOptions != nil {
+		if err := c.StoreDriverOptions(name, *archive.DriverOptions); err != nil {
+			return err
+		}
+	}
+	return c.Store(archive.Cluster)
+}
+
+// importConfig replaces the kubeconfig entries of a cluster with the ones of an archive
+func importConfig(name, kubeConfig string) error {
+	imported := KubeConfig{}
+	if err := yaml.Unmarshal([]byte(kubeConfig), &imported); err != nil {
+		return err
+	}
+	imported = filterConfigByName(imported, name)
+	config, err := getConfigFromFile()
+	if os.IsNotExist(err) {
+		config = KubeConfig{
+			APIVersion: imported.APIVersion,
+			Kind:       imported.Kind,
+		}
+	} else if err != nil {
+		return err
+	}
+	deleteConfigByName(&config, name)
+	config.Clusters = append(config.Clusters, imported.Clusters...)
+	config.Users = append(config.Users, imported.Users...)
+	config.Contexts = append(config.Contexts, imported.Contexts...)
+	return setConfigToFile(config)
+}
+
+// StoreDriverOptions stores the driver options a cluster was created with
+func (c CLIPersistStore) StoreDriverOptions(name string, driverOptions types.DriverOptions) error {
+	data, err := json.Marshal(driverOptions)
+	if err != nil {
+		return err
+	}
+	return utils.WriteToFile(data, filepath.Join(utils.HomeDir(), "clusters", name, driverOptionsName))
+}
+
+// GetDriverOptions returns the driver options a cluster was created with, nil if they were not stored
+func (c CLIPersistStore) GetDriverOptions(name string) (*types.DriverOptions, error) {
+	data, err := ioutil.ReadFile(filepath.Join(utils.HomeDir(), "clusters", name, driverOptionsName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	driverOptions := &types.DriverOptions{}
+	return driverOptions, json.Unmarshal(data, driverOptions)
+}
+
+// Encrypt compresses the archive and encrypts it with a key derived from the passphrase
+func (a *Archive) Encrypt(passphrase string) ([]byte, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newArchiveCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	result := append([]byte(archiveMagic), salt...)
+	result = append(result, nonce...)
+	return gcm.Seal(result, nonce, buf.Bytes(), []byte(archiveMagic)), nil
+}
+
+// DecryptArchive decrypts an archive created by Encrypt
+func DecryptArchive(data []byte, passphrase string) (*Archive, error) {
+	if !bytes.HasPrefix(data, []byte(archiveMagic)) {
+		return nil, errors.New("not a kontainer-engine archive")
+	}
+	data = data[len(archiveMagic):]
+	if len(data) < saltLen {
+		return nil, ErrBadPassphrase
+	}
+	gcm, err := newArchiveCipher(passphrase, data[:saltLen])
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltLen:]
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrBadPassphrase
+	}
+	compressed, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(archiveMagic))
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	content, err := ioutil.ReadAll(gz)
+	if err != nil {
+		return nil, err
+	}
+	archive := &Archive{}
+	return archive, json.Unmarshal(content, archive)
+}
+
+func newArchiveCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is required")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// filterConfigByName returns a copy of the kubeconfig with only the entries of a cluster
+func filterConfigByName(config KubeConfig, name string) KubeConfig {
+	filtered := KubeConfig{
+		APIVersion: config.APIVersion,
+		Kind:       config.Kind,
+	}
+	for _, context := range config.Contexts {
+		if context.Name == name {
+			filtered.Contexts = append(filtered.Contexts, context)
+		}
+	}
+	for _, cls := range config.Clusters {
+		if cls.Name == name {
+			filtered.Clusters = append(filtered.Clusters, cls)
+		}
+	}
+	for _, user := range config.Users {
+		if user.Name == name {
+			filtered.Users = append(filtered.Users, user)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/rancher/rancher/pkg/kontainer-engine/cluster"
+	"github.com/rancher/rancher/pkg/kontainer-engine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveEncryption(t *testing.T) {
+	archive := &Archive{
+		Cluster: cluster.Cluster{
+			Name:       "test",
+			DriverName: "googlekubernetesengine",
+			Metadata:   map[string]string{"state": `{"zone":"us-east1-b"}`},
+		},
+		DriverOptions: &types.DriverOptions{
+			StringOptions: map[string]string{"zone": "us-east1-b"},
+		},
+		KubeConfig: "apiVersion: v1\n",
+	}
+
+	data, err := archive.Encrypt("secret")
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "us-east1-b")
+
+	decrypted, err := DecryptArchive(data, "secret")
+	assert.Nil(t, err)
+	assert.Equal(t, archive, decrypted)
+
+	_, err = DecryptArchive(data, "wrong")
+	assert.Equal(t, ErrBadPassphrase, err)
+
+	_, err = archive.Encrypt("")
+	assert.EqualError(t, err, "passphrase is required")
+}
+
+func TestFilterConfigByName(t *testing.T) {
+	config := KubeConfig{
+		APIVersion: "v1",
+		Clusters:   []ConfigCluster{{Name: "a"}, {Name: "b"}},
+		Contexts:   []ConfigContext{{Name: "a"}, {Name: "b"}},
+		Users:      []ConfigUser{{Name: "a"}, {Name: "b"}},
+	}
+	assert.Equal(t, KubeConfig{
+		APIVersion: "v1",
+		Clusters:   []ConfigCluster{{Name: "b"}},
+		Contexts:   []ConfigContext{{Name: "b"}},
+		Users:      []ConfigUser{{Name: "b"}},
+	}, filterConfigByName(config, "b"))
+}
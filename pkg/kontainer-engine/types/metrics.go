@@ -0,0 +1,63 @@
+package types
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	driverMetrics = false
+
+	driverCalls = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "kontainer_driver",
+			Name:      "calls_total",
+			Help:      "Number of grpc calls made to a kontainer driver",
+		},
+		[]string{"driver", "method"},
+	)
+
+	driverErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "kontainer_driver",
+			Name:      "errors_total",
+			Help:      "Number of grpc calls to a kontainer driver that returned an error",
+		},
+		[]string{"driver", "method"},
+	)
+
+	driverLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: "kontainer_driver",
+			Name:      "call_duration_seconds",
+			Help:      "Duration of the grpc calls made to a kontainer driver",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		},
+		[]string{"driver", "method"},
+	)
+)
+
+// RegisterMetrics registers the metrics of the calls made to kontainer drivers
+func RegisterMetrics() {
+	driverMetrics = true
+
+	prometheus.MustRegister(driverCalls)
+	prometheus.MustRegister(driverErrors)
+	prometheus.MustRegister(driverLatency)
+}
+
+func observeCall(driver, method string, duration time.Duration, err error) {
+	if !driverMetrics {
+		return
+	}
+	labels := prometheus.Labels{
+		"driver": driver,
+		"method": method,
+	}
+	driverCalls.With(labels).Inc()
+	if err != nil {
+		driverErrors.With(labels).Inc()
+	}
+	driverLatency.With(labels).Observe(duration.Seconds())
+}
@@ -2,6 +2,8 @@ package types
 
 import (
 	"context"
+	"path"
+	"time"
 
 	"errors"
 
@@ -12,7 +14,7 @@ import (
 
 // NewClient creates a grpc client for a driver plugin
 func NewClient(driverName string, addr string) (CloseableDriver, error) {
-	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithUnaryInterceptor(metricsInterceptor(driverName)))
 	if err != nil {
 		return nil, err
 	}
@@ -24,6 +26,11 @@ func NewClient(driverName string, addr string) (CloseableDriver, error) {
 	}, nil
 }
 
+// HealthChecker is implemented by the grpc clients of driver plugins
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
 // grpcClient defines the grpc client struct
 type grpcClient struct {
 	client     DriverClient
@@ -129,6 +136,28 @@ func (rpc *grpcClient) RemoveLegacyServiceAccount(ctx context.Context, info *Clu
 	return handlErr(err)
 }
 
+// healthCheckKey marks the context of health checks, they are not recorded in the call metrics
+type healthCheckKey struct{}
+
+// HealthCheck checks that the driver plugin answers over the grpc connection
+func (rpc *grpcClient) HealthCheck(ctx context.Context) error {
+	_, err := rpc.client.GetCapabilities(context.WithValue(ctx, healthCheckKey{}, true), &Empty{})
+	return handlErr(err)
+}
+
+// metricsInterceptor records the calls made to a driver plugin, except for health checks
+func metricsInterceptor(driverName string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if ctx.Value(healthCheckKey{}) != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observeCall(driverName, path.Base(method), time.Since(start), err)
+		return err
+	}
+}
+
 func handlErr(err error) error {
 	if st, ok := status.FromError(err); ok {
 		if st.Code() == codes.Unknown && st.Message() != "" {
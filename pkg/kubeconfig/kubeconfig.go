@@ -41,6 +41,19 @@ type data struct {
 	Nodes           []node
 }
 
+type user struct {
+	Name      string
+	ClusterID string
+}
+
+type multiClusterData struct {
+	Host           string
+	Token          string
+	CurrentContext string
+	Users          []user
+	Nodes          []node
+}
+
 func ForBasic(host, username, password string) (string, error) {
 	data := &data{
 		ClusterName: "cluster",
@@ -122,6 +135,89 @@ func ForClusterTokenBased(cluster *managementv3.Cluster, clusterID, host, token
 		clusterName = clusterID
 	}
 
+	data := &data{
+		ClusterName:     clusterName,
+		ClusterID:       clusterID,
+		Host:            host,
+		Cert:            caCertString(),
+		User:            clusterName,
+		Token:           token,
+		Nodes:           clusterNodes(cluster, clusterName, clusterID, host),
+		EndpointEnabled: true,
+	}
+
+	buf := &bytes.Buffer{}
+	err := tokenTemplate.Execute(buf, data)
+	return buf.String(), err
+}
+
+// ForExecCredential returns a kubeconfig without token, kubectl runs the rancher CLI as exec credential plugin to log
+// in through the auth providers and to get short-lived tokens scoped to the cluster
+func ForExecCredential(cluster *managementv3.Cluster, clusterID, host string) (string, error) {
+	clusterName := cluster.Name
+	if clusterName == "" {
+		clusterName = clusterID
+	}
+
+	nodes := []node{getDefaultNode(clusterName, clusterID, host)}
+	if cluster.LocalClusterAuthEndpoint != nil && cluster.LocalClusterAuthEndpoint.Enabled {
+		nodes = clusterNodes(cluster, clusterName, clusterID, host)
+	}
+
+	data := &data{
+		ClusterName:     clusterName,
+		ClusterID:       clusterID,
+		Host:            host,
+		Cert:            caCertString(),
+		User:            clusterName,
+		Nodes:           nodes,
+		EndpointEnabled: true,
+	}
+
+	buf := &bytes.Buffer{}
+	err := tokenTemplate.Execute(buf, data)
+	return buf.String(), err
+}
+
+// ForClusters returns a kubeconfig with the contexts of several clusters, and of their authorized cluster endpoints
+// when enabled. Without token, kubectl runs the rancher CLI as exec credential plugin for each cluster.
+func ForClusters(clusters []managementv3.Cluster, host, token string) (string, error) {
+	data := &multiClusterData{
+		Host:  host,
+		Token: token,
+	}
+
+	names := map[string]bool{}
+	for i := range clusters {
+		cluster := &clusters[i]
+		clusterName := cluster.Name
+		if clusterName == "" || names[clusterName] {
+			// cluster names aren't unique, contexts are
+			clusterName = cluster.ID
+		}
+		names[clusterName] = true
+
+		if cluster.LocalClusterAuthEndpoint != nil && cluster.LocalClusterAuthEndpoint.Enabled {
+			data.Nodes = append(data.Nodes, clusterNodes(cluster, clusterName, cluster.ID, host)...)
+		} else {
+			data.Nodes = append(data.Nodes, getDefaultNode(clusterName, cluster.ID, host))
+		}
+		data.Users = append(data.Users, user{
+			Name:      clusterName,
+			ClusterID: cluster.ID,
+		})
+	}
+	if len(data.Nodes) > 0 {
+		data.CurrentContext = data.Nodes[0].ClusterName
+	}
+
+	buf := &bytes.Buffer{}
+	err := multiClusterTemplate.Execute(buf, data)
+	return buf.String(), err
+}
+
+// clusterNodes returns the rancher server and the authorized cluster endpoint nodes of a cluster
+func clusterNodes(cluster *managementv3.Cluster, clusterName, clusterID, host string) []node {
 	nodes := []node{getDefaultNode(clusterName, clusterID, host)}
 
 	if cluster.LocalClusterAuthEndpoint.FQDN != "" {
@@ -151,19 +247,5 @@ func ForClusterTokenBased(cluster *managementv3.Cluster, clusterID, host, token
 			nodes = append(nodes, clusterNode)
 		}
 	}
-
-	data := &data{
-		ClusterName:     clusterName,
-		ClusterID:       clusterID,
-		Host:            host,
-		Cert:            caCertString(),
-		User:            clusterName,
-		Token:           token,
-		Nodes:           nodes,
-		EndpointEnabled: true,
-	}
-
-	buf := &bytes.Buffer{}
-	err := tokenTemplate.Execute(buf, data)
-	return buf.String(), err
+	return nodes
 }
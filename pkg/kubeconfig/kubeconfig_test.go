@@ -0,0 +1,90 @@
+package kubeconfig
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/types"
+	managementv3 "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestForClusters(t *testing.T) {
+	assert := assert.New(t)
+
+	clusters := []managementv3.Cluster{
+		{
+			Resource: types.Resource{ID: "c-1"},
+			Name:     "prod",
+		},
+		{
+			Resource: types.Resource{ID: "c-2"},
+			Name:     "prod",
+			LocalClusterAuthEndpoint: &managementv3.LocalClusterAuthEndpoint{
+				Enabled: true,
+				FQDN:    "prod.example.com",
+			},
+		},
+	}
+
+	cfg, err := ForClusters(clusters, "rancher.example.com", "kubeconfig-u-1:secret")
+	assert.Nil(err)
+	config, err := clientcmd.Load([]byte(cfg))
+	assert.Nil(err)
+
+	assert.Equal("prod", config.CurrentContext)
+	assert.Len(config.Contexts, 3)
+	assert.Equal("https://rancher.example.com/k8s/clusters/c-1", config.Clusters["prod"].Server)
+	assert.Equal("https://rancher.example.com/k8s/clusters/c-2", config.Clusters["c-2"].Server)
+	assert.Equal("https://prod.example.com", config.Clusters["c-2-fqdn"].Server)
+	assert.Equal("c-2", config.Contexts["c-2-fqdn"].AuthInfo)
+	assert.Equal("kubeconfig-u-1:secret", config.AuthInfos["prod"].Token)
+	assert.Equal("kubeconfig-u-1:secret", config.AuthInfos["c-2"].Token)
+
+	cfg, err = ForClusters(clusters, "rancher.example.com", "")
+	assert.Nil(err)
+	config, err = clientcmd.Load([]byte(cfg))
+	assert.Nil(err)
+
+	exec := config.AuthInfos["c-2"].Exec
+	assert.NotNil(exec)
+	assert.Equal("rancher", exec.Command)
+	assert.Contains(exec.Args, "--cluster=c-2")
+}
+
+func TestForExecCredential(t *testing.T) {
+	assert := assert.New(t)
+
+	cluster := &managementv3.Cluster{Name: "prod"}
+	cfg, err := ForExecCredential(cluster, "c-1", "rancher.example.com")
+	assert.Nil(err)
+	config, err := clientcmd.Load([]byte(cfg))
+	assert.Nil(err)
+
+	assert.Equal("prod", config.CurrentContext)
+	assert.Len(config.Contexts, 1)
+	assert.Equal("https://rancher.example.com/k8s/clusters/c-1", config.Clusters["prod"].Server)
+	assert.Empty(config.AuthInfos["prod"].Token)
+	exec := config.AuthInfos["prod"].Exec
+	if assert.NotNil(exec) {
+		assert.Equal("rancher", exec.Command)
+		assert.Equal([]string{"token", "--server=rancher.example.com", "--user=prod", "--cluster=c-1"}, exec.Args)
+	}
+
+	cluster = &managementv3.Cluster{
+		LocalClusterAuthEndpoint: &managementv3.LocalClusterAuthEndpoint{
+			Enabled: true,
+			FQDN:    "prod.example.com",
+		},
+	}
+	cfg, err = ForExecCredential(cluster, "c-1", "rancher.example.com")
+	assert.Nil(err)
+	config, err = clientcmd.Load([]byte(cfg))
+	assert.Nil(err)
+
+	assert.Equal("c-1", config.CurrentContext)
+	assert.Len(config.Contexts, 2)
+	assert.Equal("https://prod.example.com", config.Clusters["c-1-fqdn"].Server)
+	assert.Equal("c-1", config.Contexts["c-1-fqdn"].AuthInfo)
+	assert.NotNil(config.AuthInfos["c-1"].Exec)
+}
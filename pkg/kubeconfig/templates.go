@@ -42,6 +42,47 @@ contexts:
 {{- end}}
 
 current-context: "{{.ClusterName}}"
+`
+
+	multiClusterTemplateText = `apiVersion: v1
+kind: Config
+clusters:
+{{- range .Nodes}}
+- name: "{{.ClusterName}}"
+  cluster:
+    server: "{{.Server}}"
+{{- if ne .Cert "" }}
+    certificate-authority-data: "{{.Cert}}"
+{{- end }}
+{{- end}}
+
+users:
+{{- range .Users}}
+- name: "{{.Name}}"
+  user:
+{{- if $.Token }}
+    token: "{{$.Token}}"
+{{ else }}
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      args:
+        - token
+        - --server={{$.Host}}
+        - --user={{.Name}}
+        - --cluster={{.ClusterID}}
+      command: rancher
+{{- end }}
+{{- end}}
+
+contexts:
+{{- range .Nodes}}
+- name: "{{.ClusterName}}"
+  context:
+    user: "{{.User}}"
+    cluster: "{{.ClusterName}}"
+{{- end}}
+
+current-context: "{{.CurrentContext}}"
 `
 
 	basicTemplateText = `apiVersion: v1
@@ -69,6 +110,7 @@ current-context: "{{.ClusterName}}"
 )
 
 var (
-	basicTemplate = template.Must(template.New("basicTemplate").Parse(basicTemplateText))
-	tokenTemplate = template.Must(template.New("tokenTemplate").Parse(tokenTemplateText))
+	basicTemplate        = template.Must(template.New("basicTemplate").Parse(basicTemplateText))
+	tokenTemplate        = template.Must(template.New("tokenTemplate").Parse(tokenTemplateText))
+	multiClusterTemplate = template.Must(template.New("multiClusterTemplate").Parse(multiClusterTemplateText))
 )
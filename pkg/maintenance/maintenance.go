@@ -0,0 +1,146 @@
+package maintenance
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rancher/norman/types/slice"
+	"github.com/rancher/rancher/pkg/auth/util"
+	"github.com/rancher/rancher/pkg/settings"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// Maintenance mode freezes rancher while it is upgraded or migrated. The norman and steve APIs, including the steve API
+// of the downstream clusters, and the kubernetes API of the local cluster reject mutating requests, except from the
+// members of the maintenance-mode-allowed-groups, and the controllers scaling node pools, scheduling etcd backups and
+// rolling out multi-cluster apps are paused until it is disabled. The kubernetes API of the downstream clusters is not
+// frozen, their workloads are not managed by rancher. The cluster shells and the pod exec of the local cluster are frozen
+// as well although they are websocket GET requests.
+
+const (
+	// RecheckInterval is how often paused controllers check whether maintenance mode was disabled
+	RecheckInterval = time.Minute
+
+	maintenanceSettingPrefix = "maintenance-mode"
+)
+
+var (
+	safeMethods = map[string]bool{
+		http.MethodGet:     true,
+		http.MethodHead:    true,
+		http.MethodOptions: true,
+	}
+	// frozenPrefixes are the APIs of rancher, the requests to the local cluster reach them once RewriteLocalCluster
+	// removed their /k8s/clusters/local prefix
+	frozenPrefixes = []string{
+		"/v3/",
+		"/v1/",
+		"/api/",
+		"/apis/",
+	}
+	// exemptPrefixes are not frozen so that agents stay connected
+	exemptPrefixes = []string{
+		"/v3/connect",
+		"/v3/import/",
+		"/v3/tokenreview",
+	}
+	settingsPrefixes = []string{
+		"/v3/settings/",
+		"/v1/management.cattle.io.settings/",
+		"/apis/management.cattle.io/v3/settings/",
+	}
+)
+
+// Enabled returns whether rancher is in maintenance mode
+func Enabled() bool {
+	return strings.EqualFold(settings.MaintenanceMode.Get(), "true")
+}
+
+// NewMiddleware rejects the mutating requests to the norman and steve APIs in maintenance mode
+func NewMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !Enabled() || !frozen(req) || allowed(req) {
+			next.ServeHTTP(rw, req)
+			return
+		}
+		util.ReturnHTTPError(rw, req, http.StatusServiceUnavailable, settings.MaintenanceModeMessage.Get())
+	})
+}
+
+// frozen returns whether the request changes rancher through its APIs. Logging out and changing the maintenance mode
+// settings is always possible.
+func frozen(req *http.Request) bool {
+	if httpstream.IsUpgradeRequest(req) {
+		return upgradeFrozen(req)
+	}
+	if safeMethods[req.Method] {
+		return false
+	}
+
+	path := req.URL.Path
+	if clusterSteveRequest(path) {
+		return true
+	}
+	if !hasPrefix(path, frozenPrefixes) {
+		return false
+	}
+	if hasPrefix(path, exemptPrefixes) {
+		return false
+	}
+	for _, prefix := range settingsPrefixes {
+		if strings.HasPrefix(path, prefix+maintenanceSettingPrefix) {
+			return false
+		}
+	}
+	if strings.HasPrefix(path, "/v3/tokens") && req.URL.Query().Get("action") == "logout" {
+		return false
+	}
+	return true
+}
+
+// upgradeFrozen returns whether the upgraded connection of a request can change rancher: the exec, attach and port
+// forward of the pods of the local cluster and the cluster shells, which run kubectl. The websocket subscriptions of
+// the APIs are read-only.
+func upgradeFrozen(req *http.Request) bool {
+	path := req.URL.Path
+	if strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/apis/") {
+		return true
+	}
+	query := req.URL.Query()
+	return query.Get("link") == "shell" || query.Get("shell") == "true"
+}
+
+// clusterSteveRequest returns whether the request is proxied to the steve API of a downstream cluster
+func clusterSteveRequest(path string) bool {
+	if !strings.HasPrefix(path, "/k8s/clusters/") {
+		return false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(path, "/k8s/clusters/"), "/", 3)
+	return len(parts) > 1 && parts[1] == "v1"
+}
+
+func hasPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed returns whether the user of the request is a member of an allowed group
+func allowed(req *http.Request) bool {
+	user, ok := request.UserFrom(req.Context())
+	if !ok {
+		return false
+	}
+	for _, group := range strings.Split(settings.MaintenanceModeAllowedGroups.Get(), ",") {
+		group = strings.TrimSpace(group)
+		if group != "" && slice.ContainsString(user.GetGroups(), group) {
+			return true
+		}
+	}
+	return false
+}
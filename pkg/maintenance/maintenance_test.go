@@ -0,0 +1,77 @@
+package maintenance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher/rancher/pkg/settings"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// websocket serves a websocket upgrade request
+const websocket = "WEBSOCKET"
+
+func TestMiddleware(t *testing.T) {
+	handler := NewMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method, path string, groups ...string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if method == websocket {
+			req.Method = http.MethodGet
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+		}
+		req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: "u-1", Groups: groups}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/v3/clusters"))
+
+	settings.MaintenanceMode.Set("true")
+	settings.MaintenanceModeAllowedGroups.Set("github_team://1, local://admins")
+	defer func() {
+		settings.MaintenanceMode.Set("false")
+		settings.MaintenanceModeAllowedGroups.Set("")
+	}()
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPost, "/v3/clusters"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPut, "/v1/management.cattle.io.clusters/c-1"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodDelete, "/v3/settings/server-url"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/v3/clusters"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/v3/clusters", "local://admins"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/v3-public/localProviders/local?action=login"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/v3/tokens?action=logout"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/v3/connect/credential"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/v3/settings/maintenance-mode"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/v1/management.cattle.io.settings/maintenance-mode-message"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/apis/management.cattle.io/v3/settings/maintenance-mode"))
+
+	// the requests to the local cluster reach the middleware without their /k8s/clusters/local prefix, its kubernetes
+	// API stores the state of rancher
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPost, "/apis/management.cattle.io/v3/clusters"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodDelete, "/api/v1/namespaces/cattle-system/secrets/s1"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/apis/management.cattle.io/v3/clusters"))
+
+	// the steve API of the downstream clusters is frozen, their kubernetes API is not since rancher does not manage
+	// their workloads
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPost, "/k8s/clusters/c-1/v1/apps.deployments"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/k8s/clusters/c-1/v1/apps.deployments"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/k8s/clusters/c-1/v1/apps.deployments", "local://admins"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/k8s/clusters/c-1/api/v1/namespaces"))
+
+	// the websocket upgrades are GET requests, the shells and the pod exec of the local cluster run kubectl against it,
+	// the subscriptions are read-only
+	assert.Equal(t, http.StatusServiceUnavailable, serve(websocket, "/v1/management.cattle.io.clusters/local?link=shell"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(websocket, "/v3/clusters/local?shell=true"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(websocket, "/api/v1/namespaces/cattle-system/pods/rancher-1/exec?command=sh"))
+	assert.Equal(t, http.StatusOK, serve(websocket, "/api/v1/namespaces/cattle-system/pods/rancher-1/exec?command=sh", "local://admins"))
+	assert.Equal(t, http.StatusOK, serve(websocket, "/v1/subscribe"))
+	assert.Equal(t, http.StatusOK, serve(websocket, "/v3/subscribe?sockId=1"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/v1/management.cattle.io.clusters/local"))
+}
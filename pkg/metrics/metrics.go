@@ -12,7 +12,10 @@ import (
 	"github.com/rancher/norman/httperror"
 	"github.com/rancher/rancher/pkg/auth/util"
 	"github.com/rancher/rancher/pkg/clustermanager"
+	"github.com/rancher/rancher/pkg/clusterrouter"
+	"github.com/rancher/rancher/pkg/kontainer-engine/types"
 	"github.com/rancher/rancher/pkg/settings"
+	"github.com/rancher/rancher/pkg/tunnelserver"
 	"github.com/rancher/rancher/pkg/types/config"
 	"github.com/rancher/wrangler/pkg/ticker"
 	authV1 "k8s.io/api/authorization/v1"
@@ -222,6 +225,15 @@ func Register(ctx context.Context, scaledContext *config.ScaledContext) {
 	// Cluster Owner
 	prometheus.MustRegister(clusterOwner)
 
+	// Kontainer Drivers
+	types.RegisterMetrics()
+
+	// Cluster Router
+	clusterrouter.RegisterMetrics()
+
+	// Tunnel Sessions
+	tunnelserver.RegisterMetrics()
+
 	gc := metricGarbageCollector{
 		clusterLister:  scaledContext.Management.Clusters("").Controller().Lister(),
 		nodeLister:     scaledContext.Management.Nodes("").Controller().Lister(),
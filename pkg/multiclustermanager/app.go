@@ -77,6 +77,7 @@ func buildScaledContext(ctx context.Context, wranglerContext *wrangler.Context,
 	if err != nil {
 		return nil, nil, err
 	}
+	tunnelserver.RegisterCredentialRevocation(ctx, scaledContext)
 
 	userManager, err := common.NewUserManager(scaledContext)
 	if err != nil {
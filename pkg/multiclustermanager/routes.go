@@ -32,6 +32,7 @@ import (
 	"github.com/rancher/rancher/pkg/rbac"
 	"github.com/rancher/rancher/pkg/rkenodeconfigserver"
 	"github.com/rancher/rancher/pkg/telemetry"
+	"github.com/rancher/rancher/pkg/tunnelserver"
 	"github.com/rancher/rancher/pkg/types/config"
 	"github.com/rancher/steve/pkg/auth"
 )
@@ -39,7 +40,8 @@ import (
 func router(ctx context.Context, localClusterEnabled bool, scaledContext *config.ScaledContext, clusterManager *clustermanager.Manager) (func(http.Handler) http.Handler, error) {
 	var (
 		k8sProxy             = k8sProxyPkg.New(scaledContext, scaledContext.Dialer)
-		connectHandler       = scaledContext.Dialer.(*rancherdialer.Factory).TunnelServer
+		tunnelServer         = scaledContext.Dialer.(*rancherdialer.Factory).TunnelServer
+		connectHandler       = tunnelserver.NewSessionHandler(tunnelServer)
 		connectConfigHandler = rkenodeconfigserver.Handler(scaledContext.Dialer.(*rancherdialer.Factory).TunnelAuthorizer, scaledContext)
 		clusterImport        = clusterregistrationtokens.ClusterImport{Clusters: scaledContext.Management.Clusters("")}
 	)
@@ -67,11 +69,15 @@ func router(ctx context.Context, localClusterEnabled bool, scaledContext *config
 
 	unauthed.Path("/").MatcherFunc(parse.MatchNotBrowser).Handler(managementAPI)
 	unauthed.Handle("/v3/connect/config", connectConfigHandler)
+	unauthed.Handle("/v3/connect/credential", tunnelserver.NewCredentialHandler(scaledContext.Dialer.(*rancherdialer.Factory).TunnelAuthorizer))
 	unauthed.Handle("/v3/connect", connectHandler)
 	unauthed.Handle("/v3/connect/register", connectHandler)
+	unauthed.Path("/meta/tunnelsessions").Methods(http.MethodGet).MatcherFunc(tunnelserver.IsPeerRequest).Handler(tunnelserver.NewPeerSessionsHandler(tunnelServer))
 	unauthed.Handle("/v3/import/{token}_{clusterId}.yaml", http.HandlerFunc(clusterImport.ClusterImportHandler))
 	unauthed.Handle("/v3/settings/cacerts", managementAPI).MatcherFunc(onlyGet)
 	unauthed.Handle("/v3/settings/first-login", managementAPI).MatcherFunc(onlyGet)
+	unauthed.Handle("/v3/settings/maintenance-mode", managementAPI).MatcherFunc(onlyGet)
+	unauthed.Handle("/v3/settings/maintenance-mode-message", managementAPI).MatcherFunc(onlyGet)
 	unauthed.Handle("/v3/settings/ui-banners", managementAPI).MatcherFunc(onlyGet)
 	unauthed.Handle("/v3/settings/ui-issues", managementAPI).MatcherFunc(onlyGet)
 	unauthed.Handle("/v3/settings/ui-pl", managementAPI).MatcherFunc(onlyGet)
@@ -93,6 +99,7 @@ func router(ctx context.Context, localClusterEnabled bool, scaledContext *config
 	authed.Path("/meta/{resource:gke.+}").Handler(gke.NewGKEHandler(scaledContext))
 	authed.Path("/meta/oci/{resource}").Handler(oci.NewOCIHandler(scaledContext))
 	authed.Path("/meta/vsphere/{field}").Handler(vsphere.NewVsphereHandler(scaledContext))
+	authed.Path("/meta/tunnelsessions").Methods(http.MethodGet).Handler(tunnelserver.NewSessionsAPIHandler(tunnelServer, scaledContext.K8sClient, scaledContext.Core.Secrets("").Controller().Lister(), clusterManager.GetHTTPSPort()))
 	authed.Path("/v3/tokenreview").Methods(http.MethodPost).Handler(&webhook.TokenReviewer{})
 	authed.Path("/metrics").Handler(metricsHandler)
 	authed.Path("/metrics/{clusterID}").Handler(metricsHandler)
@@ -3,6 +3,8 @@ package engine
 import (
 	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/pipeline/engine/jenkins"
+	"github.com/rancher/rancher/pkg/pipeline/engine/tekton"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
 	"github.com/rancher/rancher/pkg/types/config"
 )
 
@@ -27,7 +29,7 @@ func New(cluster *config.UserContext, useCache bool) PipelineEngine {
 	pipelineSettingLister := cluster.Management.Project.PipelineSettings("").Controller().Lister()
 	dialer := cluster.Management.Dialer
 
-	engine := &jenkins.Engine{
+	jenkinsEngine := &jenkins.Engine{
 		UseCache:                   useCache,
 		ServiceLister:              serviceLister,
 		PodLister:                  podLister,
@@ -42,5 +44,99 @@ func New(cluster *config.UserContext, useCache bool) PipelineEngine {
 		Dialer:      dialer,
 		ClusterName: cluster.ClusterName,
 	}
-	return engine
+	tektonEngine := &tekton.Engine{
+		DynamicClient:              cluster.DynamicClient,
+		K8sClient:                  cluster.K8sClient,
+		Secrets:                    secrets,
+		SecretLister:               secretLister,
+		ManagementSecretLister:     managementSecretLister,
+		SourceCodeCredentials:      sourceCodeCredentials,
+		SourceCodeCredentialLister: sourceCodeCredentialLister,
+		PipelineLister:             pipelineLister,
+		PipelineSettingLister:      pipelineSettingLister,
+	}
+	return &projectEngine{
+		engines: map[string]PipelineEngine{
+			utils.EngineJenkins: jenkinsEngine,
+			utils.EngineTekton:  tektonEngine,
+		},
+		pipelineSettingLister: pipelineSettingLister,
+	}
+}
+
+// projectEngine runs each execution with the engine configured in the pipeline settings of its project. The engine
+// is recorded on the execution when it starts, so changing the setting does not affect executions already running.
+type projectEngine struct {
+	engines               map[string]PipelineEngine
+	pipelineSettingLister v3.PipelineSettingLister
+}
+
+func (p *projectEngine) getEngine(execution *v3.PipelineExecution) (PipelineEngine, error) {
+	name := execution.Labels[utils.PipelineEngineLabel]
+	if name == "" {
+		var err error
+		if name, err = utils.GetEngine(p.pipelineSettingLister, execution.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	if engine, ok := p.engines[name]; ok {
+		return engine, nil
+	}
+	return p.engines[utils.EngineJenkins], nil
+}
+
+func (p *projectEngine) PreCheck(execution *v3.PipelineExecution) (bool, error) {
+	engine, err := p.getEngine(execution)
+	if err != nil {
+		return false, err
+	}
+	return engine.PreCheck(execution)
+}
+
+func (p *projectEngine) RunPipelineExecution(execution *v3.PipelineExecution) error {
+	name, err := utils.GetEngine(p.pipelineSettingLister, execution.Namespace)
+	if err != nil {
+		return err
+	}
+	if execution.Labels == nil {
+		execution.Labels = map[string]string{}
+	}
+	execution.Labels[utils.PipelineEngineLabel] = name
+	engine, err := p.getEngine(execution)
+	if err != nil {
+		return err
+	}
+	return engine.RunPipelineExecution(execution)
+}
+
+func (p *projectEngine) RerunExecution(execution *v3.PipelineExecution) error {
+	engine, err := p.getEngine(execution)
+	if err != nil {
+		return err
+	}
+	return engine.RerunExecution(execution)
+}
+
+func (p *projectEngine) StopExecution(execution *v3.PipelineExecution) error {
+	engine, err := p.getEngine(execution)
+	if err != nil {
+		return err
+	}
+	return engine.StopExecution(execution)
+}
+
+func (p *projectEngine) GetStepLog(execution *v3.PipelineExecution, stage int, step int) (string, error) {
+	engine, err := p.getEngine(execution)
+	if err != nil {
+		return "", err
+	}
+	return engine.GetStepLog(execution, stage, step)
+}
+
+func (p *projectEngine) SyncExecution(execution *v3.PipelineExecution) (bool, error) {
+	engine, err := p.getEngine(execution)
+	if err != nil {
+		return false, err
+	}
+	return engine.SyncExecution(execution)
 }
@@ -26,4 +26,8 @@ const (
 	FlowDefinitionClass  = "org.jenkinsci.plugins.workflow.cps.CpsFlowDefinition"
 	FlowDefinitionPlugin = "workflow-cps@2.43"
 	JenkinsJobPrefix     = "pipeline_"
+
+	UsernamePasswordCredentialClass  = "com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl"
+	SSHPrivateKeyCredentialClass     = "com.cloudbees.jenkins.plugins.sshcredentials.impl.BasicSSHUserPrivateKey"
+	DirectEntryPrivateKeySourceClass = SSHPrivateKeyCredentialClass + "$DirectEntryPrivateKeySource"
 )
@@ -161,6 +161,12 @@ func (j *Engine) RunPipelineExecution(execution *v3.PipelineExecution) error {
 }
 
 func (j *Engine) preparePipeline(execution *v3.PipelineExecution) error {
+	return PreparePipeline(j.Secrets, j.ManagementSecretLister, execution)
+}
+
+// PreparePipeline stores the docker credentials used by the publish image steps of the execution in the
+// pipeline namespace of its project
+func PreparePipeline(secrets v1.SecretInterface, managementSecretLister v1.SecretLister, execution *v3.PipelineExecution) error {
 	for _, stage := range execution.Spec.PipelineConfig.Stages {
 		for _, step := range stage.Steps {
 			if step.PublishImageConfig != nil {
@@ -172,7 +178,7 @@ func (j *Engine) preparePipeline(execution *v3.PipelineExecution) error {
 					_, projectID := ref.Parse(execution.Spec.ProjectName)
 					registry = fmt.Sprintf("%s.%s-pipeline", utils.LocalRegistry, projectID)
 				}
-				if err := j.prepareRegistryCredential(execution, registry); err != nil {
+				if err := prepareRegistryCredential(secrets, managementSecretLister, execution, registry); err != nil {
 					return err
 				}
 			}
@@ -181,14 +187,14 @@ func (j *Engine) preparePipeline(execution *v3.PipelineExecution) error {
 	return nil
 }
 
-func (j *Engine) prepareRegistryCredential(execution *v3.PipelineExecution, registry string) error {
-	secrets, err := j.ManagementSecretLister.List(execution.Namespace, labels.Everything())
+func prepareRegistryCredential(secrets v1.SecretInterface, managementSecretLister v1.SecretLister, execution *v3.PipelineExecution, registry string) error {
+	managementSecrets, err := managementSecretLister.List(execution.Namespace, labels.Everything())
 	if err != nil {
 		return err
 	}
 	username := ""
 	password := ""
-	for _, s := range secrets {
+	for _, s := range managementSecrets {
 		if s.Type == "kubernetes.io/dockerconfigjson" {
 			m := map[string]interface{}{}
 			if err := json.Unmarshal(s.Data[".dockerconfigjson"], &m); err != nil {
@@ -227,9 +233,9 @@ func (j *Engine) prepareRegistryCredential(execution *v3.PipelineExecution, regi
 			utils.PublishSecretPwKey:   []byte(password),
 		},
 	}
-	_, err = j.Secrets.Create(secret)
+	_, err = secrets.Create(secret)
 	if apierrors.IsAlreadyExists(err) {
-		if _, err := j.Secrets.Update(secret); err != nil {
+		if _, err := secrets.Update(secret); err != nil {
 			return err
 		}
 		return nil
@@ -239,7 +245,7 @@ func (j *Engine) prepareRegistryCredential(execution *v3.PipelineExecution, regi
 
 func (j *Engine) createPipelineJob(client *Client, execution *v3.PipelineExecution) error {
 	logrus.Debug("create jenkins job for pipeline")
-	converter, err := initJenkinsPipelineConverter(execution, j.PipelineSettingLister, j.SecretLister)
+	converter, err := j.initPipelineConverter(execution)
 	if err != nil {
 		return err
 	}
@@ -254,7 +260,7 @@ func (j *Engine) createPipelineJob(client *Client, execution *v3.PipelineExecuti
 
 func (j *Engine) updatePipelineJob(client *Client, execution *v3.PipelineExecution) error {
 	logrus.Debug("update jenkins job for pipeline")
-	converter, err := initJenkinsPipelineConverter(execution, j.PipelineSettingLister, j.SecretLister)
+	converter, err := j.initPipelineConverter(execution)
 	if err != nil {
 		return err
 	}
@@ -267,6 +273,19 @@ func (j *Engine) updatePipelineJob(client *Client, execution *v3.PipelineExecuti
 	return client.updateJob(jobName, bconf)
 }
 
+func (j *Engine) initPipelineConverter(execution *v3.PipelineExecution) (*jenkinsPipelineConverter, error) {
+	converter, err := initJenkinsPipelineConverter(execution, j.PipelineSettingLister, j.SecretLister)
+	if err != nil {
+		return nil, err
+	}
+	if usesStepStorage(execution) {
+		if converter.opts.storage, err = j.getStepStorage(execution); err != nil {
+			return nil, err
+		}
+	}
+	return converter, nil
+}
+
 func (j *Engine) RerunExecution(execution *v3.PipelineExecution) error {
 	return j.RunPipelineExecution(execution)
 }
@@ -578,10 +597,18 @@ func (j Engine) GetStepLog(execution *v3.PipelineExecution, stage int, step int)
 	curStep := execution.Status.Stages[stage].Steps[step]
 	if curStep.State == utils.StateWaiting {
 		return "", nil
-	} else if curStep.State != utils.StateBuilding {
-		return j.getStepLogFromMinioStore(execution, stage, step)
 	}
-	return j.getStepLogFromJenkins(execution, stage, step)
+	var log string
+	var err error
+	if curStep.State != utils.StateBuilding {
+		log, err = j.getStepLogFromMinioStore(execution, stage, step)
+	} else {
+		log, err = j.getStepLogFromJenkins(execution, stage, step)
+	}
+	if err != nil {
+		return "", err
+	}
+	return j.maskSecrets(execution, log)
 }
 
 func (j Engine) getStepLogFromJenkins(execution *v3.PipelineExecution, stage int, step int) (string, error) {
@@ -646,7 +673,7 @@ func (j Engine) setCredential(client *Client, execution *v3.PipelineExecution, c
 	}
 	//set credential when it is not exist
 	jenkinsCred := &Credential{}
-	jenkinsCred.Class = "com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl"
+	jenkinsCred.Class = UsernamePasswordCredentialClass
 	jenkinsCred.Scope = "GLOBAL"
 	jenkinsCred.ID = execution.Name
 
@@ -670,6 +697,15 @@ func (j Engine) setCredential(client *Client, execution *v3.PipelineExecution, c
 	} else if accessToken != credential.Spec.AccessToken {
 		jenkinsCred.Password = accessToken
 	}
+	if gitConfig, ok := scpConfig.(*v32.GitPipelineConfig); ok {
+		//plain git repositories are cloned over ssh with the deploy key of the project
+		jenkinsCred.Class = SSHPrivateKeyCredentialClass
+		jenkinsCred.Password = ""
+		jenkinsCred.PrivateKeySource = &PrivateKeySource{
+			PrivateKey: gitConfig.PrivateKey,
+			Class:      DirectEntryPrivateKeySourceClass,
+		}
+	}
 
 	bodyContent := map[string]interface{}{}
 	bodyContent["credentials"] = jenkinsCred
@@ -35,6 +35,7 @@ type executeOptions struct {
 	executorMemoryLimit   string
 	executorCPURequest    string
 	executorCPULimit      string
+	storage               *stepStorage
 }
 
 func initJenkinsPipelineConverter(execution *v3.PipelineExecution, pipelineSettingLister v3.PipelineSettingLister, secretLister apiv1.SecretLister) (*jenkinsPipelineConverter, error) {
@@ -89,6 +90,44 @@ func initJenkinsPipelineConverter(execution *v3.PipelineExecution, pipelineSetti
 	}, nil
 }
 
+// StepContainers holds the containers running the steps of an execution, indexed by stage and step, together
+// with the pod level options they depend on.
+type StepContainers struct {
+	Containers           [][]v1.Container
+	ImagePullSecretNames []string
+	GitCaCerts           string
+}
+
+// GetStepContainers converts the steps of an execution to containers the same way the jenkins engine does,
+// so that other engines run steps with the same images, environment and resources.
+func GetStepContainers(execution *v3.PipelineExecution, pipelineSettingLister v3.PipelineSettingLister, secretLister apiv1.SecretLister) (*StepContainers, error) {
+	c, err := initJenkinsPipelineConverter(execution, pipelineSettingLister, secretLister)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ValidPipelineConfig(c.execution.Spec.PipelineConfig); err != nil {
+		return nil, err
+	}
+	parsePreservedEnvVar(c.execution)
+
+	result := &StepContainers{
+		ImagePullSecretNames: c.opts.imagePullSecretNames,
+		GitCaCerts:           c.opts.gitCaCerts,
+	}
+	for i, stage := range c.execution.Spec.PipelineConfig.Stages {
+		var containers []v1.Container
+		for j := range stage.Steps {
+			container, err := c.getStepContainer(i, j)
+			if err != nil {
+				return nil, err
+			}
+			containers = append(containers, container)
+		}
+		result.Containers = append(result.Containers, containers)
+	}
+	return result, nil
+}
+
 func validateQuantity(value string) error {
 	if value == "" {
 		return nil
@@ -127,8 +166,9 @@ func (c *jenkinsPipelineConverter) convertStep(stageOrdinal int, stepOrdinal int
 	stepName := fmt.Sprintf("step-%d-%d", stageOrdinal, stepOrdinal)
 
 	command := c.getJenkinsStepCommand(stageOrdinal, stepOrdinal)
+	before, after := c.getStepStorageScripts(stageOrdinal, stepOrdinal)
 
-	return fmt.Sprintf(stepBlock, stepName, stepName, stepName, command)
+	return fmt.Sprintf(stepBlock, stepName, stepName, before, stepName, command, after)
 }
 
 func (c *jenkinsPipelineConverter) convertStage(stageOrdinal int) string {
@@ -422,10 +462,10 @@ parallel %s
 `
 
 const stepBlock = `'%s': {
-  stage('%s'){
+  stage('%s'){%s
     container(name: '%s') {
       %s
-    }
+    }%s
   }
 }`
 
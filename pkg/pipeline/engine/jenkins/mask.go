@@ -0,0 +1,15 @@
+package jenkins
+
+import (
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/providers"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+)
+
+func (j Engine) maskSecrets(execution *v3.PipelineExecution, log string) (string, error) {
+	values, err := utils.GetSecretValues(j.SecretLister, j.PipelineLister, j.SourceCodeCredentialLister, execution, providers.GetSourceCodeProviderConfig)
+	if err != nil {
+		return "", err
+	}
+	return utils.MaskSecrets(log, values), nil
+}
@@ -4,17 +4,23 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/minio/minio-go"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/rancher/rancher/pkg/ref"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// maxPresignExpiry is the longest validity of presigned urls minio accepts
+const maxPresignExpiry = 7 * 24 * time.Hour
+
 type minioClient struct {
 	client minio.Client
 }
@@ -117,7 +123,167 @@ func (j *Engine) saveStepLogToMinio(execution *v3.PipelineExecution, stage int,
 	if err != nil {
 		return err
 	}
+	message, err = j.maskSecrets(execution, message)
+	if err != nil {
+		return err
+	}
 
 	_, err = client.PutObject(bucketName, logName, strings.NewReader(message), int64(len(message)), minio.PutObjectOptions{})
 	return err
 }
+
+func ensureBucket(client *minio.Client, bucketName string) error {
+	exists, err := client.BucketExists(bucketName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return client.MakeBucket(bucketName, utils.MinioBucketLocation)
+	}
+	return nil
+}
+
+// getStepStorage presigns the urls the build pod of an execution uses to restore and save step caches and
+// artifacts. The urls stay valid for the timeout of the execution plus an hour for it to be scheduled.
+func (j *Engine) getStepStorage(execution *v3.PipelineExecution) (*stepStorage, error) {
+	ns := utils.GetPipelineCommonName(execution.Spec.ProjectName)
+	_, projectID := ref.Parse(execution.Spec.ProjectName)
+	settings, err := utils.GetStepStorageSettings(j.PipelineSettingLister, projectID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := j.getMinioClient(ns)
+	if err != nil {
+		return nil, err
+	}
+	for _, bucketName := range []string{utils.MinioCacheBucket, utils.MinioArtifactBucket} {
+		if err := ensureBucket(client, bucketName); err != nil {
+			return nil, err
+		}
+	}
+
+	timeout := utils.DefaultTimeout
+	if execution.Spec.PipelineConfig.Timeout > 0 {
+		timeout = execution.Spec.PipelineConfig.Timeout
+	}
+	expiry := time.Duration(timeout)*time.Minute + time.Hour
+	if expiry > maxPresignExpiry {
+		expiry = maxPresignExpiry
+	}
+
+	storage := &stepStorage{
+		cacheKeys:         map[string]string{},
+		cacheGetURLs:      map[string]string{},
+		cachePutURLs:      map[string]string{},
+		artifactGetURLs:   map[string]string{},
+		artifactPutURLs:   map[string]string{},
+		cacheSizeLimit:    settings.CacheSizeLimit,
+		artifactSizeLimit: settings.ArtifactSizeLimit,
+	}
+	for i, stage := range execution.Spec.PipelineConfig.Stages {
+		for k, step := range stage.Steps {
+			stepName := fmt.Sprintf("step-%d-%d", i, k)
+			if step.Cache != nil {
+				objectName := getCacheObjectName(execution, i, k)
+				storage.cacheKeys[stepName] = objectName
+				if storage.cacheGetURLs[stepName], storage.cachePutURLs[stepName], err = presignObject(client, utils.MinioCacheBucket, objectName, expiry); err != nil {
+					return nil, err
+				}
+			}
+			if step.Artifacts != nil {
+				objectName := getArtifactObjectName(execution, i, k)
+				if storage.artifactGetURLs[stepName], storage.artifactPutURLs[stepName], err = presignObject(client, utils.MinioArtifactBucket, objectName, expiry); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return storage, nil
+}
+
+func presignObject(client *minio.Client, bucketName string, objectName string, expiry time.Duration) (string, string, error) {
+	getURL, err := client.PresignedGetObject(bucketName, objectName, expiry, url.Values{})
+	if err != nil {
+		return "", "", err
+	}
+	putURL, err := client.PresignedPutObject(bucketName, objectName, expiry)
+	if err != nil {
+		return "", "", err
+	}
+	return getURL.String(), putURL.String(), nil
+}
+
+// PruneStepStorage removes the step caches and artifacts of a pipeline namespace that expired, then evicts the
+// least recently saved caches until the caches fit in the size limit.
+func (j *Engine) PruneStepStorage(ns string, settings *utils.StepStorageSettings) error {
+	client, err := j.getMinioClient(ns)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := pruneBucket(client, utils.MinioArtifactBucket, func(objects []minio.ObjectInfo) []string {
+		return expiredObjects(objects, now, settings.ArtifactExpiry)
+	}); err != nil {
+		return err
+	}
+	return pruneBucket(client, utils.MinioCacheBucket, func(objects []minio.ObjectInfo) []string {
+		return append(expiredObjects(objects, now, settings.CacheExpiry), oversizedObjects(objects, now, settings.CacheExpiry, settings.CacheSizeLimit)...)
+	})
+}
+
+func pruneBucket(client *minio.Client, bucketName string, selectObjects func([]minio.ObjectInfo) []string) error {
+	exists, err := client.BucketExists(bucketName)
+	if err != nil || !exists {
+		return err
+	}
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	var objects []minio.ObjectInfo
+	for object := range client.ListObjectsV2(bucketName, "", true, doneCh) {
+		if object.Err != nil {
+			return object.Err
+		}
+		objects = append(objects, object)
+	}
+	for _, name := range selectObjects(objects) {
+		logrus.Debugf("pruning %s/%s from the pipeline store", bucketName, name)
+		if err := client.RemoveObject(bucketName, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expiredObjects(objects []minio.ObjectInfo, now time.Time, expiry time.Duration) []string {
+	var result []string
+	for _, object := range objects {
+		if now.Sub(object.LastModified) > expiry {
+			result = append(result, object.Key)
+		}
+	}
+	return result
+}
+
+// oversizedObjects returns the oldest of the objects that are not expired, so that the rest fit in the size limit
+func oversizedObjects(objects []minio.ObjectInfo, now time.Time, expiry time.Duration, sizeLimit int64) []string {
+	var kept []minio.ObjectInfo
+	for _, object := range objects {
+		if now.Sub(object.LastModified) <= expiry {
+			kept = append(kept, object)
+		}
+	}
+	sort.Slice(kept, func(i, k int) bool {
+		return kept[i].LastModified.After(kept[k].LastModified)
+	})
+	var (
+		result []string
+		total  int64
+	)
+	for _, object := range kept {
+		total += object.Size
+		if total > sizeLimit {
+			result = append(result, object.Key)
+		}
+	}
+	return result
+}
@@ -262,12 +262,18 @@ type BuildInfo struct {
 }
 
 type Credential struct {
-	Scope       string `json:"scope"`
-	ID          string `json:"id"`
-	Username    string `json:"username"`
-	Password    string `json:"password"`
-	Description string `json:"description"`
-	Class       string `json:"$class"`
+	Scope            string            `json:"scope"`
+	ID               string            `json:"id"`
+	Username         string            `json:"username"`
+	Password         string            `json:"password,omitempty"`
+	PrivateKeySource *PrivateKeySource `json:"privateKeySource,omitempty"`
+	Description      string            `json:"description"`
+	Class            string            `json:"$class"`
+}
+
+type PrivateKeySource struct {
+	PrivateKey string `json:"privateKey"`
+	Class      string `json:"stapler-class"`
 }
 
 type WFBuildInfo struct {
@@ -0,0 +1,136 @@
+package jenkins
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/rancher/rancher/pkg/ref"
+)
+
+// stepStorage holds the presigned minio urls the build pod uses to restore and save the caches and artifacts of
+// the steps of an execution, keyed by step name
+type stepStorage struct {
+	cacheKeys         map[string]string
+	cacheGetURLs      map[string]string
+	cachePutURLs      map[string]string
+	artifactGetURLs   map[string]string
+	artifactPutURLs   map[string]string
+	cacheSizeLimit    int64
+	artifactSizeLimit int64
+}
+
+var invalidCacheKeyChars = regexp.MustCompile("[^a-zA-Z0-9._-]+")
+
+// getCacheObjectName returns the object a step cache is stored as. Caches are shared by all executions of a
+// pipeline, the key is rendered with the environment variables of the execution and the step.
+func getCacheObjectName(execution *v3.PipelineExecution, stageOrdinal int, stepOrdinal int) string {
+	step := execution.Spec.PipelineConfig.Stages[stageOrdinal].Steps[stepOrdinal]
+	key := substituteEnvVar(utils.GetEnvVarMap(execution), step.Cache.Key)
+	key = substituteEnvVar(step.Env, key)
+	key = invalidCacheKeyChars.ReplaceAllString(key, "-")
+	_, pipelineName := ref.Parse(execution.Spec.PipelineName)
+	return fmt.Sprintf("%s/%s.tgz", pipelineName, key)
+}
+
+// getArtifactObjectName returns the object the artifacts of a step are stored as
+func getArtifactObjectName(execution *v3.PipelineExecution, stageOrdinal int, stepOrdinal int) string {
+	return fmt.Sprintf("%s/step-%d-%d.tgz", execution.Name, stageOrdinal, stepOrdinal)
+}
+
+func usesStepStorage(execution *v3.PipelineExecution) bool {
+	for _, stage := range execution.Spec.PipelineConfig.Stages {
+		for _, step := range stage.Steps {
+			if step.Cache != nil || step.Artifacts != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getStepStorageScripts returns the scripts the agent container runs in the workspace before and after a step to
+// restore and save its cache and artifacts. Artifacts of the steps of earlier stages are restored without
+// overwriting files already in the workspace.
+func (c *jenkinsPipelineConverter) getStepStorageScripts(stageOrdinal int, stepOrdinal int) (string, string) {
+	storage := c.opts.storage
+	stage := c.execution.Spec.PipelineConfig.Stages[stageOrdinal]
+	step := stage.Steps[stepOrdinal]
+	if storage == nil || !utils.MatchAll(stage.When, c.execution) || !utils.MatchAll(step.When, c.execution) {
+		return "", ""
+	}
+	stepName := fmt.Sprintf("step-%d-%d", stageOrdinal, stepOrdinal)
+
+	var before, after []string
+	for i := 0; i < stageOrdinal; i++ {
+		for j := range c.execution.Spec.PipelineConfig.Stages[i].Steps {
+			name := fmt.Sprintf("step-%d-%d", i, j)
+			if url, ok := storage.artifactGetURLs[name]; ok {
+				before = append(before, fmt.Sprintf(restoreArtifactsScript, stepName, url, stepName, name, name, stepName))
+			}
+		}
+	}
+	if step.Cache != nil {
+		key := storage.cacheKeys[stepName]
+		before = append(before, fmt.Sprintf(restoreCacheScript, stepName, storage.cacheGetURLs[stepName], stepName, key, key, stepName))
+		after = append(after, fmt.Sprintf(saveCacheScript, stepName, quotePaths(step.Cache.Paths), stepName,
+			storage.cacheSizeLimit, storage.cacheSizeLimit, stepName, storage.cachePutURLs[stepName], key, stepName))
+	}
+	if step.Artifacts != nil {
+		after = append(after, fmt.Sprintf(saveArtifactsScript, stepName, quotePaths(step.Artifacts.Paths), stepName,
+			storage.artifactSizeLimit, storage.artifactSizeLimit, stepName, storage.artifactPutURLs[stepName], stepName))
+	}
+	return agentScriptBlock(before), agentScriptBlock(after)
+}
+
+func agentScriptBlock(scripts []string) string {
+	if len(scripts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(agentBlock, utils.JenkinsAgentContainerName, strings.Join(scripts, "\n"))
+}
+
+func quotePaths(paths []string) string {
+	quoted := make([]string, 0, len(paths))
+	for _, p := range paths {
+		quoted = append(quoted, "'"+p+"'")
+	}
+	return strings.Join(quoted, " ")
+}
+
+const agentBlock = `
+    container(name: '%s') {
+      sh '''set +x
+%s
+'''
+    }`
+
+// jenkins runs the scripts with set -e, cache failures are caught so they do not fail the step
+const restoreArtifactsScript = `if curl -fsS -o /tmp/%s-artifacts.tgz '%s'; then tar xzf /tmp/%s-artifacts.tgz --skip-old-files || echo 'failed to restore artifacts of %s'; else echo 'no artifacts found for %s'; fi
+rm -f /tmp/%s-artifacts.tgz`
+
+const restoreCacheScript = `if curl -fsS -o /tmp/%s-cache.tgz '%s'; then tar xzf /tmp/%s-cache.tgz || echo 'failed to restore cache %s'; else echo 'no cache found for %s'; fi
+rm -f /tmp/%s-cache.tgz`
+
+const saveCacheScript = `if tar czf /tmp/%s-cache.tgz %s; then
+  size=$(stat -c %%s /tmp/%s-cache.tgz)
+  if [ "$size" -gt %d ]; then
+    echo "cache of $size bytes exceeds the limit of %d bytes, skip saving it"
+  else
+    curl -fsS -T /tmp/%s-cache.tgz '%s' || echo 'failed to save cache %s'
+  fi
+else
+  echo 'cache paths not found, skip saving the cache'
+fi
+rm -f /tmp/%s-cache.tgz`
+
+const saveArtifactsScript = `tar czf /tmp/%s-artifacts.tgz %s
+size=$(stat -c %%s /tmp/%s-artifacts.tgz)
+if [ "$size" -gt %d ]; then
+  echo "artifacts of $size bytes exceed the limit of %d bytes"
+  exit 1
+fi
+curl -fsS -T /tmp/%s-artifacts.tgz '%s'
+rm -f /tmp/%s-artifacts.tgz`
@@ -0,0 +1,73 @@
+package jenkins
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go"
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStepStorageScripts(t *testing.T) {
+	execution := &v3.PipelineExecution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipeline-1",
+		},
+		Spec: v32.PipelineExecutionSpec{
+			PipelineName: "p-abc:pipeline",
+			Branch:       "master",
+			PipelineConfig: v32.PipelineConfig{
+				Stages: []v32.Stage{
+					{Name: "clone", Steps: []v32.Step{{SourceCodeConfig: &v32.SourceCodeConfig{}}}},
+					{Name: "build", Steps: []v32.Step{{
+						RunScriptConfig: &v32.RunScriptConfig{ShellScript: "go build -o bin/app"},
+						Cache:           &v32.CacheConfig{Key: "go-${CICD_GIT_BRANCH}", Paths: []string{".cache/go"}},
+						Artifacts:       &v32.ArtifactsConfig{Paths: []string{"bin"}},
+					}}},
+					{Name: "test", Steps: []v32.Step{{RunScriptConfig: &v32.RunScriptConfig{ShellScript: "bin/app"}}}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, "pipeline/go-master.tgz", getCacheObjectName(execution, 1, 0))
+
+	c := &jenkinsPipelineConverter{
+		execution: execution,
+		opts: &executeOptions{storage: &stepStorage{
+			cacheKeys:       map[string]string{"step-1-0": "pipeline/go-master.tgz"},
+			cacheGetURLs:    map[string]string{"step-1-0": "http://minio/cache-get"},
+			cachePutURLs:    map[string]string{"step-1-0": "http://minio/cache-put"},
+			artifactGetURLs: map[string]string{"step-1-0": "http://minio/artifacts-get"},
+			artifactPutURLs: map[string]string{"step-1-0": "http://minio/artifacts-put"},
+		}},
+	}
+	before, after := c.getStepStorageScripts(0, 0)
+	assert.Empty(t, before)
+	assert.Empty(t, after)
+
+	before, after = c.getStepStorageScripts(1, 0)
+	assert.Contains(t, before, "'http://minio/cache-get'")
+	assert.NotContains(t, before, "artifacts-get")
+	assert.Contains(t, after, "tar czf /tmp/step-1-0-cache.tgz '.cache/go'")
+	assert.Contains(t, after, "'http://minio/artifacts-put'")
+
+	before, after = c.getStepStorageScripts(2, 0)
+	assert.True(t, strings.Contains(before, "'http://minio/artifacts-get'"))
+	assert.Empty(t, after)
+}
+
+func TestPruneObjects(t *testing.T) {
+	now := time.Now()
+	objects := []minio.ObjectInfo{
+		{Key: "expired", LastModified: now.Add(-3 * time.Hour), Size: 1},
+		{Key: "old", LastModified: now.Add(-2 * time.Hour), Size: 5},
+		{Key: "recent", LastModified: now.Add(-time.Hour), Size: 5},
+		{Key: "latest", LastModified: now, Size: 5},
+	}
+	assert.Equal(t, []string{"expired"}, expiredObjects(objects, now, 150*time.Minute))
+	assert.Equal(t, []string{"old"}, oversizedObjects(objects, now, 150*time.Minute, 12))
+}
@@ -0,0 +1,51 @@
+package tekton
+
+import (
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	APIVersion      = "tekton.dev/v1beta1"
+	PipelineRunKind = "PipelineRun"
+
+	SourceWorkspace     = "source"
+	SourceWorkspacePath = "/workspace/source"
+	SourceWorkspaceSize = "1Gi"
+
+	// StepName is the name of the only step of each task, tekton runs it in the container step-run
+	StepName          = "run"
+	StepContainerName = "step-" + StepName
+
+	PipelineRunCancelled = "PipelineRunCancelled"
+	TaskRunCancelled     = "TaskRunCancelled"
+	ConditionSucceeded   = "Succeeded"
+
+	GitUsernameKey   = "username"
+	GitPasswordKey   = "password"
+	GitSSHKeyKey     = "ssh-privatekey"
+	GitKnownHostsKey = "known-hosts"
+
+	cloneScript = `set -e
+if [ -n "$GIT_PASSWORD" ]; then
+  git config --global credential.helper '!f() { echo "username=$GIT_USERNAME"; echo "password=$GIT_PASSWORD"; }; f'
+fi
+if [ -n "$GIT_SSH_KEY" ]; then
+  printf "%s\n" "$GIT_SSH_KEY" > /tmp/git-ssh-key && chmod 600 /tmp/git-ssh-key
+  printf "%s\n" "$GIT_KNOWN_HOSTS" > /tmp/git-known-hosts
+  export GIT_SSH_COMMAND="ssh -i /tmp/git-ssh-key -o IdentitiesOnly=yes -o StrictHostKeyChecking=yes -o UserKnownHostsFile=/tmp/git-known-hosts"
+fi
+git init -q .
+git fetch -q -- "$CICD_GIT_URL" "+$CICD_GIT_REF:refs/remotes/local/temp"
+git checkout -qf local/temp`
+
+	configCrtScript = `printf "%s" "$CA_CERT" > ` + utils.GitCaCertPath + "/ca.crt"
+)
+
+var (
+	PipelineRunResource = schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "pipelineruns",
+	}
+)
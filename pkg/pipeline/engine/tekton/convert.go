@@ -0,0 +1,263 @@
+package tekton
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v33 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	images "github.com/rancher/rancher/pkg/image"
+	"github.com/rancher/rancher/pkg/pipeline/engine/jenkins"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// convertPipelineExecutionToPipelineRun translates an execution into a tekton PipelineRun. Every step becomes a task
+// running a single container, the steps of a stage run in parallel after all tasks of the previous stage. Steps
+// skipped by their conditions are left out of the run.
+func convertPipelineExecutionToPipelineRun(execution *v3.PipelineExecution, steps *jenkins.StepContainers, gitSecretName string) (*unstructured.Unstructured, error) {
+	var (
+		tasks    []interface{}
+		runAfter []interface{}
+	)
+	for i, stage := range execution.Spec.PipelineConfig.Stages {
+		var stageTasks []interface{}
+		if utils.MatchAll(stage.When, execution) {
+			for j, step := range stage.Steps {
+				if !utils.MatchAll(step.When, execution) {
+					continue
+				}
+				container := steps.Containers[i][j]
+				configStepContainer(&container, execution, i, j, steps.GitCaCerts, gitSecretName)
+				task, err := getTask(getTaskName(i, j), container, steps.GitCaCerts)
+				if err != nil {
+					return nil, err
+				}
+				if len(runAfter) > 0 {
+					task["runAfter"] = runAfter
+				}
+				tasks = append(tasks, task)
+				stageTasks = append(stageTasks, getTaskName(i, j))
+			}
+		}
+		if len(stageTasks) > 0 {
+			runAfter = stageTasks
+		}
+	}
+
+	var pullSecrets []interface{}
+	for _, name := range steps.ImagePullSecretNames {
+		pullSecrets = append(pullSecrets, map[string]interface{}{"name": name})
+	}
+	timeout := utils.DefaultTimeout
+	if execution.Spec.PipelineConfig.Timeout > 0 {
+		timeout = execution.Spec.PipelineConfig.Timeout
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": APIVersion,
+			"kind":       PipelineRunKind,
+			"metadata": map[string]interface{}{
+				"name":      execution.Name,
+				"namespace": utils.GetPipelineCommonName(execution.Spec.ProjectName),
+				"labels": map[string]interface{}{
+					utils.LabelKeyExecution: execution.Name,
+				},
+			},
+			"spec": map[string]interface{}{
+				// the jenkins service account is bound to the same roles the jenkins agents use
+				"serviceAccountName": utils.JenkinsName,
+				"timeout":            fmt.Sprintf("%dm", timeout),
+				"podTemplate": map[string]interface{}{
+					"imagePullSecrets": pullSecrets,
+				},
+				"workspaces": []interface{}{
+					map[string]interface{}{
+						"name": SourceWorkspace,
+						"volumeClaimTemplate": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"accessModes": []interface{}{string(v1.ReadWriteOnce)},
+								"resources": map[string]interface{}{
+									"requests": map[string]interface{}{
+										string(v1.ResourceStorage): SourceWorkspaceSize,
+									},
+								},
+							},
+						},
+					},
+				},
+				"pipelineSpec": map[string]interface{}{
+					"workspaces": []interface{}{
+						map[string]interface{}{"name": SourceWorkspace},
+					},
+					"tasks": tasks,
+				},
+			},
+		},
+	}, nil
+}
+
+func getTask(name string, container v1.Container, gitCaCerts string) (map[string]interface{}, error) {
+	volumes := []v1.Volume{
+		{
+			Name: utils.RegistryCrtVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: utils.RegistryCrtSecretName,
+				},
+			},
+		},
+	}
+	var containers []v1.Container
+	if gitCaCerts != "" && mountsGitCaCert(container) {
+		volumes = append(volumes, v1.Volume{
+			Name: utils.GitCaCertVolumeName,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		})
+		containers = append(containers, v1.Container{
+			Name:    "config-crt",
+			Image:   images.Resolve(v33.ToolsSystemImages.PipelineSystemImages.AlpineGit),
+			Command: []string{"sh", "-c", configCrtScript},
+			Env: []v1.EnvVar{
+				{
+					Name:  "CA_CERT",
+					Value: gitCaCerts,
+				},
+			},
+			VolumeMounts: []v1.VolumeMount{
+				{
+					Name:      utils.GitCaCertVolumeName,
+					MountPath: utils.GitCaCertPath,
+				},
+			},
+		})
+	}
+	containers = append(containers, container)
+
+	var taskSteps, taskVolumes []interface{}
+	for i := range containers {
+		step, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&containers[i])
+		if err != nil {
+			return nil, err
+		}
+		taskSteps = append(taskSteps, step)
+	}
+	for i := range volumes {
+		volume, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&volumes[i])
+		if err != nil {
+			return nil, err
+		}
+		taskVolumes = append(taskVolumes, volume)
+	}
+
+	return map[string]interface{}{
+		"name": name,
+		"workspaces": []interface{}{
+			map[string]interface{}{
+				"name":      SourceWorkspace,
+				"workspace": SourceWorkspace,
+			},
+		},
+		"taskSpec": map[string]interface{}{
+			"workspaces": []interface{}{
+				map[string]interface{}{
+					"name":      SourceWorkspace,
+					"mountPath": SourceWorkspacePath,
+				},
+			},
+			"volumes": taskVolumes,
+			"steps":   taskSteps,
+		},
+	}, nil
+}
+
+// configStepContainer turns a step container of the jenkins build pod, which idles until jenkins execs the step
+// command in it, into a container running the step command itself.
+func configStepContainer(container *v1.Container, execution *v3.PipelineExecution, stageOrdinal int, stepOrdinal int, gitCaCerts string, gitSecretName string) {
+	step := execution.Spec.PipelineConfig.Stages[stageOrdinal].Steps[stepOrdinal]
+
+	container.Name = StepName
+	container.TTY = false
+	container.WorkingDir = SourceWorkspacePath
+	container.Args = nil
+
+	command := ""
+	if step.SourceCodeConfig != nil {
+		command = cloneScript
+		if gitSecretName != "" {
+			container.Env = append(container.Env,
+				secretEnvVar("GIT_USERNAME", gitSecretName, GitUsernameKey),
+				secretEnvVar("GIT_PASSWORD", gitSecretName, GitPasswordKey),
+				secretEnvVar("GIT_SSH_KEY", gitSecretName, GitSSHKeyKey),
+				secretEnvVar("GIT_KNOWN_HOSTS", gitSecretName, GitKnownHostsKey))
+		}
+		if gitCaCerts != "" {
+			container.Env = append(container.Env, v1.EnvVar{
+				Name:  "GIT_SSL_CAINFO",
+				Value: utils.GitCaCertPath + "/ca.crt",
+			})
+			container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+				Name:      utils.GitCaCertVolumeName,
+				MountPath: utils.GitCaCertPath,
+			})
+		}
+	} else if step.RunScriptConfig != nil {
+		command = step.RunScriptConfig.ShellScript
+	} else if step.PublishImageConfig != nil {
+		command = "/usr/local/bin/dockerd-entrypoint.sh /bin/drone-docker"
+	} else if step.ApplyYamlConfig != nil {
+		command = "kube-apply"
+	} else if step.PublishCatalogConfig != nil {
+		command = "publish-catalog"
+	} else if step.ApplyAppConfig != nil {
+		command = "apply-app"
+	}
+	container.Command = []string{"sh", "-c", command}
+}
+
+func secretEnvVar(name, secretName, key string) v1.EnvVar {
+	return v1.EnvVar{
+		Name: name,
+		ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{
+				Name: secretName,
+			},
+			Key: key,
+		}},
+	}
+}
+
+func mountsGitCaCert(container v1.Container) bool {
+	for _, m := range container.VolumeMounts {
+		if m.Name == utils.GitCaCertVolumeName {
+			return true
+		}
+	}
+	return false
+}
+
+func getTaskName(stageOrdinal int, stepOrdinal int) string {
+	return fmt.Sprintf("step-%d-%d", stageOrdinal, stepOrdinal)
+}
+
+func parseTaskName(name string) (int, int, bool) {
+	parts := strings.Split(name, "-")
+	if len(parts) != 3 || parts[0] != "step" {
+		return 0, 0, false
+	}
+	stage, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	step, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, 0, false
+	}
+	return stage, step, true
+}
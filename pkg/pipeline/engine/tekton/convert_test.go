@@ -0,0 +1,113 @@
+package tekton
+
+import (
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/engine/jenkins"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConvertPipelineExecutionToPipelineRun(t *testing.T) {
+	execution := &v3.PipelineExecution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pipeline-1",
+			Namespace: "p-abc",
+		},
+		Spec: v32.PipelineExecutionSpec{
+			ProjectName: "c-abc:p-abc",
+			Branch:      "master",
+			PipelineConfig: v32.PipelineConfig{
+				Stages: []v32.Stage{
+					{Name: "clone", Steps: []v32.Step{{SourceCodeConfig: &v32.SourceCodeConfig{}}}},
+					{Name: "test", Steps: []v32.Step{
+						{RunScriptConfig: &v32.RunScriptConfig{Image: "golang", ShellScript: "go test ./..."}},
+						{RunScriptConfig: &v32.RunScriptConfig{Image: "golang", ShellScript: "go vet ./..."}},
+					}},
+					{Name: "release", When: &v32.Constraints{Branch: &v32.Constraint{Include: []string{"release"}}}, Steps: []v32.Step{
+						{RunScriptConfig: &v32.RunScriptConfig{Image: "alpine", ShellScript: "release"}},
+					}},
+					{Name: "build", Steps: []v32.Step{{RunScriptConfig: &v32.RunScriptConfig{Image: "golang", ShellScript: "go build"}}}},
+				},
+			},
+		},
+	}
+	steps := &jenkins.StepContainers{
+		Containers: [][]v1.Container{
+			{{Name: "step-0-0", Image: "alpine/git", TTY: true, Command: []string{"cat"}}},
+			{{Name: "step-1-0", Image: "golang", TTY: true, Command: []string{"cat"}}, {Name: "step-1-1", Image: "golang", TTY: true, Command: []string{"cat"}}},
+			{{Name: "step-2-0", Image: "alpine", TTY: true, Command: []string{"cat"}}},
+			{{Name: "step-3-0", Image: "golang", TTY: true, Command: []string{"cat"}}},
+		},
+		ImagePullSecretNames: []string{"registry"},
+	}
+
+	pipelineRun, err := convertPipelineExecutionToPipelineRun(execution, steps, "git-credential")
+	assert.Nil(t, err)
+	assert.Equal(t, "pipeline-1", pipelineRun.GetName())
+	assert.Equal(t, "p-abc-pipeline", pipelineRun.GetNamespace())
+
+	tasks, _, _ := unstructured.NestedSlice(pipelineRun.Object, "spec", "pipelineSpec", "tasks")
+	var names []string
+	runAfter := map[string][]interface{}{}
+	for _, task := range tasks {
+		name, _, _ := unstructured.NestedString(task.(map[string]interface{}), "name")
+		names = append(names, name)
+		after, _, _ := unstructured.NestedSlice(task.(map[string]interface{}), "runAfter")
+		runAfter[name] = after
+	}
+	assert.Equal(t, []string{"step-0-0", "step-1-0", "step-1-1", "step-3-0"}, names)
+	assert.Empty(t, runAfter["step-0-0"])
+	assert.Equal(t, []interface{}{"step-0-0"}, runAfter["step-1-0"])
+	assert.Equal(t, []interface{}{"step-1-0", "step-1-1"}, runAfter["step-3-0"])
+
+	cloneSteps, _, _ := unstructured.NestedSlice(tasks[0].(map[string]interface{}), "taskSpec", "steps")
+	assert.Len(t, cloneSteps, 1)
+	clone := cloneSteps[0].(map[string]interface{})
+	assert.Equal(t, StepName, clone["name"])
+	assert.Equal(t, SourceWorkspacePath, clone["workingDir"])
+	assert.Equal(t, []interface{}{"sh", "-c", cloneScript}, clone["command"])
+	assert.Len(t, clone["env"], 4)
+}
+
+func TestSkipSteps(t *testing.T) {
+	execution := &v3.PipelineExecution{
+		Spec: v32.PipelineExecutionSpec{
+			Branch: "master",
+			PipelineConfig: v32.PipelineConfig{
+				Stages: []v32.Stage{
+					{Name: "clone", Steps: []v32.Step{{SourceCodeConfig: &v32.SourceCodeConfig{}}}},
+					{Name: "test", Steps: []v32.Step{
+						{RunScriptConfig: &v32.RunScriptConfig{}},
+						{RunScriptConfig: &v32.RunScriptConfig{}, When: &v32.Constraints{Branch: &v32.Constraint{Exclude: []string{"master"}}}},
+					}},
+				},
+			},
+		},
+		Status: v32.PipelineExecutionStatus{
+			Stages: []v32.StageStatus{
+				{State: "Waiting", Steps: []v32.StepStatus{{State: "Waiting"}}},
+				{State: "Waiting", Steps: []v32.StepStatus{{State: "Waiting"}, {State: "Waiting"}}},
+			},
+		},
+	}
+
+	skipSteps(execution)
+	assert.Equal(t, "Waiting", execution.Status.Stages[1].State)
+	assert.Equal(t, "Waiting", execution.Status.Stages[1].Steps[0].State)
+	assert.Equal(t, "Skipped", execution.Status.Stages[1].Steps[1].State)
+}
+
+func TestParseTaskName(t *testing.T) {
+	stage, step, ok := parseTaskName(getTaskName(2, 3))
+	assert.True(t, ok)
+	assert.Equal(t, 2, stage)
+	assert.Equal(t, 3, step)
+
+	_, _, ok = parseTaskName("config-crt")
+	assert.False(t, ok)
+}
@@ -0,0 +1,424 @@
+package tekton
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+
+	"github.com/pkg/errors"
+	v1 "github.com/rancher/rancher/pkg/generated/norman/core/v1"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/engine/jenkins"
+	"github.com/rancher/rancher/pkg/pipeline/providers"
+	"github.com/rancher/rancher/pkg/pipeline/remote"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/rancher/rancher/pkg/ref"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Engine runs pipeline executions as tekton PipelineRuns in the pipeline namespace of their project. Unlike the
+// jenkins engine it needs no long running workloads, step logs are read from the pods of the TaskRuns.
+//
+// The engine is written against the tekton.dev/v1beta1 API of Tekton Pipelines v0.11 to v0.43: it reads the TaskRuns
+// of a PipelineRun from status.taskRuns and cancels it with the PipelineRunCancelled status, which later releases
+// replace with status.childReferences and the Cancelled status.
+type Engine struct {
+	DynamicClient dynamic.Interface
+	K8sClient     kubernetes.Interface
+
+	Secrets                    v1.SecretInterface
+	SecretLister               v1.SecretLister
+	ManagementSecretLister     v1.SecretLister
+	SourceCodeCredentials      v3.SourceCodeCredentialInterface
+	SourceCodeCredentialLister v3.SourceCodeCredentialLister
+	PipelineLister             v3.PipelineLister
+	PipelineSettingLister      v3.PipelineSettingLister
+}
+
+type taskRun struct {
+	podName   string
+	status    string
+	reason    string
+	message   string
+	started   string
+	completed string
+}
+
+func (t *Engine) pipelineRuns(execution *v3.PipelineExecution) (dynamic.ResourceInterface, error) {
+	ns := utils.GetPipelineCommonName(execution.Spec.ProjectName)
+	return t.DynamicClient.Resource(PipelineRunResource).Namespace(ns), nil
+}
+
+func (t *Engine) PreCheck(execution *v3.PipelineExecution) (bool, error) {
+	pipelineRuns, err := t.pipelineRuns(execution)
+	if err != nil {
+		return false, err
+	}
+	if _, err := pipelineRuns.List(context.TODO(), metav1.ListOptions{Limit: 1}); apierrors.IsNotFound(err) {
+		return false, errors.New("tekton pipelines is not installed in the cluster")
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *Engine) RunPipelineExecution(execution *v3.PipelineExecution) error {
+	logrus.Debug("start RunPipelineExecution")
+	pipelineRuns, err := t.pipelineRuns(execution)
+	if err != nil {
+		return err
+	}
+	steps, err := jenkins.GetStepContainers(execution, t.PipelineSettingLister, t.SecretLister)
+	if err != nil {
+		return err
+	}
+	if err := jenkins.PreparePipeline(t.Secrets, t.ManagementSecretLister, execution); err != nil {
+		return err
+	}
+	ns, name := ref.Parse(execution.Spec.PipelineName)
+	pipeline, err := t.PipelineLister.Get(ns, name)
+	if err != nil {
+		return err
+	}
+	gitSecretName, err := t.setCredential(execution, pipeline.Spec.SourceCodeCredentialName)
+	if err != nil {
+		return err
+	}
+	pipelineRun, err := convertPipelineExecutionToPipelineRun(execution, steps, gitSecretName)
+	if err != nil {
+		return err
+	}
+
+	// a rerun replaces the PipelineRun of the previous run
+	if err := pipelineRuns.Delete(context.TODO(), execution.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if _, err := pipelineRuns.Create(context.TODO(), pipelineRun, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	skipSteps(execution)
+	return nil
+}
+
+func (t *Engine) RerunExecution(execution *v3.PipelineExecution) error {
+	return t.RunPipelineExecution(execution)
+}
+
+func (t *Engine) StopExecution(execution *v3.PipelineExecution) error {
+	pipelineRuns, err := t.pipelineRuns(execution)
+	if err != nil {
+		return err
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"status":"%s"}}`, PipelineRunCancelled))
+	if _, err := pipelineRuns.Patch(context.TODO(), execution.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (t *Engine) SyncExecution(execution *v3.PipelineExecution) (bool, error) {
+	pipelineRun, taskRuns, err := t.getPipelineRun(execution)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	updated := false
+	for taskName, run := range taskRuns {
+		stage, step, ok := parseTaskName(taskName)
+		if !ok {
+			continue
+		}
+		if len(execution.Status.Stages) <= stage || len(execution.Status.Stages[stage].Steps) <= step {
+			return false, errors.New("error sync execution - index out of range")
+		}
+		state := execution.Status.Stages[stage].Steps[step].State
+		switch {
+		case run.status == string(corev1.ConditionTrue) && state != utils.StateSuccess:
+			updated = true
+			successStep(execution, stage, step, run)
+		case run.status == string(corev1.ConditionFalse) && state != utils.StateFailed && state != utils.StateAborted:
+			updated = true
+			failStep(execution, stage, step, run)
+		case run.status != string(corev1.ConditionTrue) && run.status != string(corev1.ConditionFalse) &&
+			run.started != "" && state != utils.StateBuilding:
+			updated = true
+			buildingStep(execution, stage, step, run)
+		}
+	}
+
+	status, reason, message := getSucceededCondition(pipelineRun.Object, "status", "conditions")
+	switch status {
+	case string(corev1.ConditionTrue):
+		if execution.Status.ExecutionState != utils.StateSuccess {
+			updated = true
+			execution.Labels[utils.PipelineFinishLabel] = "true"
+			execution.Status.ExecutionState = utils.StateSuccess
+			if execution.Status.Ended == "" {
+				execution.Status.Ended = time.Now().Format(time.RFC3339)
+			}
+			v32.PipelineExecutionConditionProvisioned.True(execution)
+			v32.PipelineExecutionConditionBuilt.True(execution)
+		}
+	case string(corev1.ConditionFalse):
+		if execution.Status.ExecutionState != utils.StateAborted && execution.Status.ExecutionState != utils.StateFailed {
+			updated = true
+			execution.Labels[utils.PipelineFinishLabel] = "true"
+			execution.Status.ExecutionState = utils.StateFailed
+			if execution.Status.Ended == "" {
+				execution.Status.Ended = time.Now().Format(time.RFC3339)
+			}
+			if v32.PipelineExecutionConditionProvisioned.IsUnknown(execution) {
+				v32.PipelineExecutionConditionProvisioned.True(execution)
+			}
+			v32.PipelineExecutionConditionBuilt.False(execution)
+			v32.PipelineExecutionConditionBuilt.Reason(execution, reason)
+			v32.PipelineExecutionConditionBuilt.Message(execution, message)
+		}
+	default:
+		if execution.Status.ExecutionState == utils.StateBuilding && v32.PipelineExecutionConditionProvisioned.IsUnknown(execution) {
+			updated = true
+			v32.PipelineExecutionConditionProvisioned.True(execution)
+		}
+	}
+
+	return updated, nil
+}
+
+func (t *Engine) GetStepLog(execution *v3.PipelineExecution, stage int, step int) (string, error) {
+	if len(execution.Status.Stages) <= stage || len(execution.Status.Stages[stage].Steps) <= step {
+		return "", errors.New("invalid step index")
+	}
+	curStep := execution.Status.Stages[stage].Steps[step]
+	if curStep.State == utils.StateWaiting || curStep.State == utils.StateSkipped {
+		return "", nil
+	}
+	_, taskRuns, err := t.getPipelineRun(execution)
+	if err != nil {
+		return "", err
+	}
+	run, ok := taskRuns[getTaskName(stage, step)]
+	if !ok || run.podName == "" {
+		return "", nil
+	}
+
+	ns := utils.GetPipelineCommonName(execution.Spec.ProjectName)
+	log, err := t.K8sClient.CoreV1().Pods(ns).GetLogs(run.podName, &corev1.PodLogOptions{
+		Container:  StepContainerName,
+		Timestamps: true,
+	}).DoRaw(context.TODO())
+	if apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("log of step %s is no longer available, its pod %s was removed", getTaskName(stage, step), run.podName)
+	} else if err != nil {
+		return "", err
+	}
+	values, err := utils.GetSecretValues(t.SecretLister, t.PipelineLister, t.SourceCodeCredentialLister, execution, providers.GetSourceCodeProviderConfig)
+	if err != nil {
+		return "", err
+	}
+	return utils.MaskSecrets(string(log), values), nil
+}
+
+// getPipelineRun returns the PipelineRun of an execution and the status of its TaskRuns by pipeline task name
+func (t *Engine) getPipelineRun(execution *v3.PipelineExecution) (*unstructured.Unstructured, map[string]taskRun, error) {
+	pipelineRuns, err := t.pipelineRuns(execution)
+	if err != nil {
+		return nil, nil, err
+	}
+	pipelineRun, err := pipelineRuns.Get(context.TODO(), execution.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := map[string]taskRun{}
+	taskRuns, _, _ := unstructured.NestedMap(pipelineRun.Object, "status", "taskRuns")
+	for _, obj := range taskRuns {
+		data, ok := obj.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		taskName, _, _ := unstructured.NestedString(data, "pipelineTaskName")
+		run := taskRun{}
+		run.podName, _, _ = unstructured.NestedString(data, "status", "podName")
+		run.started, _, _ = unstructured.NestedString(data, "status", "startTime")
+		run.completed, _, _ = unstructured.NestedString(data, "status", "completionTime")
+		run.status, run.reason, run.message = getSucceededCondition(data, "status", "conditions")
+		result[taskName] = run
+	}
+	return pipelineRun, result, nil
+}
+
+func getSucceededCondition(obj map[string]interface{}, fields ...string) (string, string, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj, fields...)
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != ConditionSucceeded {
+			continue
+		}
+		status, _, _ := unstructured.NestedString(cond, "status")
+		reason, _, _ := unstructured.NestedString(cond, "reason")
+		message, _, _ := unstructured.NestedString(cond, "message")
+		return status, reason, message
+	}
+	return "", "", ""
+}
+
+// setCredential stores the git credential of the pipeline in the pipeline namespace for the clone step, it returns
+// the name of the secret or an empty string if the repository is cloned without credential.
+func (t *Engine) setCredential(execution *v3.PipelineExecution, credentialID string) (string, error) {
+	if credentialID == "" {
+		return "", nil
+	}
+	ns, name := ref.Parse(credentialID)
+	credential, err := t.SourceCodeCredentialLister.Get(ns, name)
+	if err != nil {
+		return "", err
+	}
+	_, projID := ref.Parse(execution.Spec.ProjectName)
+	scpConfig, err := providers.GetSourceCodeProviderConfig(credential.Spec.SourceCodeType, projID)
+	if err != nil {
+		return "", err
+	}
+	remote, err := remote.New(scpConfig)
+	if err != nil {
+		return "", err
+	}
+
+	password := credential.Spec.AccessToken
+	if credential.Spec.GitCloneToken != "" {
+		password = credential.Spec.GitCloneToken
+	}
+	if accessToken, err := utils.EnsureAccessToken(t.SourceCodeCredentials, remote, credential); err != nil {
+		return "", err
+	} else if accessToken != credential.Spec.AccessToken {
+		password = accessToken
+	}
+	sshKey, knownHosts := "", ""
+	if gitConfig, ok := scpConfig.(*v32.GitPipelineConfig); ok {
+		//plain git repositories are cloned over ssh with the deploy key of the project
+		sshKey = gitConfig.PrivateKey
+		knownHosts = gitConfig.KnownHosts
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: utils.GetPipelineCommonName(execution.Spec.ProjectName),
+			Name:      "git-" + name,
+		},
+		Data: map[string][]byte{
+			GitUsernameKey:   []byte(credential.Spec.GitLoginName),
+			GitPasswordKey:   []byte(password),
+			GitSSHKeyKey:     []byte(sshKey),
+			GitKnownHostsKey: []byte(knownHosts),
+		},
+	}
+	if _, err := t.Secrets.Create(secret); apierrors.IsAlreadyExists(err) {
+		if _, err := t.Secrets.Update(secret); err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", err
+	}
+	return secret.Name, nil
+}
+
+// skipSteps marks the steps and stages left out of the PipelineRun because their conditions do not match
+func skipSteps(execution *v3.PipelineExecution) {
+	for i, stage := range execution.Spec.PipelineConfig.Stages {
+		if i >= len(execution.Status.Stages) {
+			return
+		}
+		stageMatched := utils.MatchAll(stage.When, execution)
+		skipStage := true
+		for j, step := range stage.Steps {
+			if j >= len(execution.Status.Stages[i].Steps) {
+				break
+			}
+			if stageMatched && utils.MatchAll(step.When, execution) {
+				skipStage = false
+				continue
+			}
+			execution.Status.Stages[i].Steps[j].State = utils.StateSkipped
+		}
+		if skipStage {
+			execution.Status.Stages[i].State = utils.StateSkipped
+		}
+	}
+}
+
+func buildingStep(execution *v3.PipelineExecution, stage int, step int, run taskRun) {
+	execution.Status.Stages[stage].Steps[step].State = utils.StateBuilding
+	if execution.Status.Stages[stage].Steps[step].Started == "" {
+		execution.Status.Stages[stage].Steps[step].Started = run.started
+	}
+	if execution.Status.Stages[stage].State == utils.StateWaiting {
+		execution.Status.Stages[stage].State = utils.StateBuilding
+	}
+	if execution.Status.Stages[stage].Started == "" {
+		execution.Status.Stages[stage].Started = run.started
+	}
+	if execution.Status.ExecutionState == utils.StateWaiting {
+		execution.Status.ExecutionState = utils.StateBuilding
+	}
+	if execution.Status.Started == "" {
+		execution.Status.Started = run.started
+	}
+
+	stageName := execution.Spec.PipelineConfig.Stages[stage].Name
+	v32.PipelineExecutionConditionBuilt.CreateUnknownIfNotExists(execution)
+	v32.PipelineExecutionConditionBuilt.Message(execution, fmt.Sprintf("Running '%s' stage", stageName))
+}
+
+func successStep(execution *v3.PipelineExecution, stage int, step int, run taskRun) {
+	buildingStep(execution, stage, step, run)
+	execution.Status.Stages[stage].Steps[step].State = utils.StateSuccess
+	execution.Status.Stages[stage].Steps[step].Ended = run.completed
+	if utils.IsStageSuccess(execution.Status.Stages[stage]) {
+		execution.Status.Stages[stage].State = utils.StateSuccess
+		execution.Status.Stages[stage].Ended = run.completed
+	}
+}
+
+func failStep(execution *v3.PipelineExecution, stage int, step int, run taskRun) {
+	buildingStep(execution, stage, step, run)
+	state := utils.StateFailed
+	if run.reason == TaskRunCancelled {
+		state = utils.StateAborted
+	}
+	execution.Status.Stages[stage].Steps[step].State = state
+	execution.Status.Stages[stage].Steps[step].Ended = run.completed
+	execution.Status.Stages[stage].State = utils.StateFailed
+	if execution.Status.Stages[stage].Ended == "" {
+		execution.Status.Stages[stage].Ended = run.completed
+	}
+	if execution.Status.ExecutionState != utils.StateAborted {
+		execution.Status.ExecutionState = utils.StateFailed
+		v32.PipelineExecutionConditionBuilt.False(execution)
+		v32.PipelineExecutionConditionBuilt.Message(execution, fmt.Sprintf("Got FAILED status in '%s' stage", execution.Spec.PipelineConfig.Stages[stage].Name))
+	}
+	if execution.Status.Ended == "" {
+		execution.Status.Ended = run.completed
+	}
+
+	//clean waiting status of the stages and steps that will not run
+	for i := range execution.Status.Stages {
+		if execution.Status.Stages[i].State == utils.StateWaiting {
+			execution.Status.Stages[i].State = ""
+		}
+		for j := range execution.Status.Stages[i].Steps {
+			if execution.Status.Stages[i].Steps[j].State == utils.StateWaiting {
+				execution.Status.Stages[i].Steps[j].State = ""
+			}
+		}
+	}
+}
@@ -44,4 +44,10 @@ func RegisterDrivers(Management *config.ScaledContext) {
 		SourceCodeCredentials:      sourceCodeCredentials,
 		SourceCodeCredentialLister: sourceCodeCredentialLister,
 	}
+	Drivers[drivers.GiteaWebhookHeader] = drivers.GiteaDriver{
+		PipelineLister:             pipelineLister,
+		PipelineExecutions:         pipelineExecutions,
+		SourceCodeCredentials:      sourceCodeCredentials,
+		SourceCodeCredentialLister: sourceCodeCredentialLister,
+	}
 }
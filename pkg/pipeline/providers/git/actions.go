@@ -0,0 +1,174 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rancher/norman/api/access"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	client "github.com/rancher/rancher/pkg/client/generated/project/v3"
+	"github.com/rancher/rancher/pkg/pipeline/remote/git"
+	"github.com/rancher/rancher/pkg/pipeline/remote/model"
+	"github.com/rancher/rancher/pkg/ref"
+	"github.com/rancher/rke/pki/cert"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	actionDisable      = "disable"
+	actionTestAndApply = "testAndApply"
+	actionGenerateKeys = "generateKeys"
+	actionLogin        = "login"
+)
+
+func (g *GitProvider) Formatter(apiContext *types.APIContext, resource *types.RawResource) {
+	if convert.ToBool(resource.Values["enabled"]) {
+		resource.AddAction(apiContext, actionDisable)
+	}
+
+	resource.AddAction(apiContext, actionGenerateKeys)
+	resource.AddAction(apiContext, actionTestAndApply)
+}
+
+func (g *GitProvider) ActionHandler(actionName string, action *types.Action, request *types.APIContext) error {
+	if actionName == actionTestAndApply {
+		return g.testAndApply(request)
+	} else if actionName == actionDisable {
+		return g.DisableAction(request, g.GetName())
+	} else if actionName == actionGenerateKeys {
+		return g.generateKeys(request)
+	}
+
+	return httperror.NewAPIError(httperror.ActionNotAvailable, "")
+}
+
+func (g *GitProvider) providerFormatter(apiContext *types.APIContext, resource *types.RawResource) {
+	resource.AddAction(apiContext, actionLogin)
+}
+
+func (g *GitProvider) providerActionHandler(actionName string, action *types.Action, request *types.APIContext) error {
+	if actionName == actionLogin {
+		return g.login(request)
+	}
+
+	return httperror.NewAPIError(httperror.ActionNotAvailable, "")
+}
+
+// generateKeys generates the deploy key of the project, the public key is added to the repositories on the git server
+func (g *GitProvider) generateKeys(apiContext *types.APIContext) error {
+	ns, _ := ref.Parse(apiContext.ID)
+	pConfig, err := g.GetProviderConfig(ns)
+	if err != nil {
+		return err
+	}
+	storedGitPipelineConfig, ok := pConfig.(*v32.GitPipelineConfig)
+	if !ok {
+		return fmt.Errorf("Failed to get git provider config")
+	}
+	toUpdate := storedGitPipelineConfig.DeepCopy()
+	pub, private, err := generateDeployKey()
+	if err != nil {
+		return err
+	}
+	toUpdate.PrivateKey = private
+	toUpdate.PublicKey = pub
+	if _, err = g.SourceCodeProviderConfigs.ObjectClient().Update(toUpdate.Name, toUpdate); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func generateDeployKey() (string, string, error) {
+	key, err := cert.NewPrivateKey()
+	if err != nil {
+		return "", "", err
+	}
+	publicKey, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+	return string(ssh.MarshalAuthorizedKey(publicKey)), string(cert.EncodePrivateKeyPEM(key)), nil
+}
+
+func (g *GitProvider) testAndApply(apiContext *types.APIContext) error {
+	applyInput := &v32.GitApplyInput{}
+
+	if err := json.NewDecoder(apiContext.Request.Body).Decode(applyInput); err != nil {
+		return httperror.NewAPIError(httperror.InvalidBodyContent,
+			fmt.Sprintf("Failed to parse body: %v", err))
+	}
+
+	ns, _ := ref.Parse(apiContext.ID)
+	pConfig, err := g.GetProviderConfig(ns)
+	if err != nil {
+		return err
+	}
+	storedGitPipelineConfig, ok := pConfig.(*v32.GitPipelineConfig)
+	if !ok {
+		return fmt.Errorf("Failed to get git provider config")
+	}
+	if storedGitPipelineConfig.PrivateKey == "" {
+		return httperror.NewAPIError(httperror.InvalidState, "deploy key is not generated")
+	}
+	toUpdate := storedGitPipelineConfig.DeepCopy()
+	toUpdate.Repositories = applyInput.Repositories
+	toUpdate.KnownHosts = applyInput.KnownHosts
+	if err := git.TestRepositories(toUpdate); err != nil {
+		return httperror.NewAPIError(httperror.InvalidBodyContent, err.Error())
+	}
+
+	userName := apiContext.Request.Header.Get("Impersonate-User")
+	sourceCodeCredential, err := g.AuthAddAccount(userName, "", toUpdate, toUpdate.ProjectName, model.GitType)
+	if err != nil {
+		return err
+	}
+	if _, err = g.RefreshReposByCredentialAndConfig(sourceCodeCredential, toUpdate); err != nil {
+		return err
+	}
+	toUpdate.Enabled = true
+	//update git pipeline config
+	if _, err = g.SourceCodeProviderConfigs.ObjectClient().Update(toUpdate.Name, toUpdate); err != nil {
+		return err
+	}
+
+	apiContext.WriteResponse(http.StatusOK, nil)
+	return nil
+}
+
+func (g *GitProvider) login(apiContext *types.APIContext) error {
+	ns, _ := ref.Parse(apiContext.ID)
+	pConfig, err := g.GetProviderConfig(ns)
+	if err != nil {
+		return err
+	}
+	config, ok := pConfig.(*v32.GitPipelineConfig)
+	if !ok {
+		return fmt.Errorf("Failed to get git provider config")
+	}
+	if !config.Enabled {
+		return errors.New("git repositories are not configured")
+	}
+
+	userName := apiContext.Request.Header.Get("Impersonate-User")
+	account, err := g.AuthAddAccount(userName, "", config, config.ProjectName, model.GitType)
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{}
+	if err := access.ByID(apiContext, apiContext.Version, client.SourceCodeCredentialType, account.Name, &data); err != nil {
+		return err
+	}
+
+	if _, err := g.RefreshReposByCredentialAndConfig(account, config); err != nil {
+		return err
+	}
+
+	apiContext.WriteResponse(http.StatusOK, data)
+	return nil
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"fmt"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rancher/norman/store/subtype"
+	"github.com/rancher/norman/types"
+	client "github.com/rancher/rancher/pkg/client/generated/project/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/providers/common"
+	"github.com/rancher/rancher/pkg/pipeline/remote/model"
+	schema "github.com/rancher/rancher/pkg/schemas/project.cattle.io/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type GitProvider struct {
+	common.BaseProvider
+}
+
+func (g *GitProvider) CustomizeSchemas(schemas *types.Schemas) {
+	scpConfigBaseSchema := schemas.Schema(&schema.Version, client.SourceCodeProviderConfigType)
+	configSchema := schemas.Schema(&schema.Version, client.GitPipelineConfigType)
+	configSchema.ActionHandler = g.ActionHandler
+	configSchema.Formatter = g.Formatter
+	configSchema.Store = subtype.NewSubTypeStore(client.GitPipelineConfigType, scpConfigBaseSchema.Store)
+
+	providerBaseSchema := schemas.Schema(&schema.Version, client.SourceCodeProviderType)
+	providerSchema := schemas.Schema(&schema.Version, client.GitProviderType)
+	providerSchema.Formatter = g.providerFormatter
+	providerSchema.ActionHandler = g.providerActionHandler
+	providerSchema.Store = subtype.NewSubTypeStore(client.GitProviderType, providerBaseSchema.Store)
+}
+
+func (g *GitProvider) GetName() string {
+	return model.GitType
+}
+
+func (g *GitProvider) TransformToSourceCodeProvider(config map[string]interface{}) map[string]interface{} {
+	return g.BaseProvider.TransformToSourceCodeProvider(config, client.GitProviderType)
+}
+
+func (g *GitProvider) GetProviderConfig(projectID string) (interface{}, error) {
+	scpConfigObj, err := g.SourceCodeProviderConfigs.ObjectClient().UnstructuredClient().GetNamespaced(projectID, model.GitType, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve GitConfig, error: %v", err)
+	}
+
+	u, ok := scpConfigObj.(runtime.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("failed to retrieve GitConfig, cannot read k8s Unstructured data")
+	}
+	storedGitPipelineConfigMap := u.UnstructuredContent()
+
+	storedGitPipelineConfig := &v32.GitPipelineConfig{}
+	if err := mapstructure.Decode(storedGitPipelineConfigMap, storedGitPipelineConfig); err != nil {
+		return nil, fmt.Errorf("failed to decode the config, error: %v", err)
+	}
+
+	objectMeta, err := common.ObjectMetaFromUnstructureContent(storedGitPipelineConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	storedGitPipelineConfig.ObjectMeta = *objectMeta
+	storedGitPipelineConfig.APIVersion = "project.cattle.io/v3"
+	storedGitPipelineConfig.Kind = v3.SourceCodeProviderConfigGroupVersionKind.Kind
+	return storedGitPipelineConfig, nil
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/rancher/rancher/pkg/pipeline/providers/bitbucketcloud"
 	"github.com/rancher/rancher/pkg/pipeline/providers/bitbucketserver"
 	"github.com/rancher/rancher/pkg/pipeline/providers/common"
+	"github.com/rancher/rancher/pkg/pipeline/providers/git"
+	"github.com/rancher/rancher/pkg/pipeline/providers/gitea"
 	"github.com/rancher/rancher/pkg/pipeline/providers/github"
 	"github.com/rancher/rancher/pkg/pipeline/providers/gitlab"
 	"github.com/rancher/rancher/pkg/pipeline/remote/model"
@@ -85,15 +87,25 @@ func configure(management *config.ScaledContext) {
 	bsProvider := &bitbucketserver.BsProvider{
 		BaseProvider: baseProvider,
 	}
+	gtProvider := &gitea.GtProvider{
+		BaseProvider: baseProvider,
+	}
+	gitProvider := &git.GitProvider{
+		BaseProvider: baseProvider,
+	}
 
 	providers[model.GithubType] = ghProvider
 	providers[model.GitlabType] = glProvider
 	providers[model.BitbucketCloudType] = bcProvider
 	providers[model.BitbucketServerType] = bsProvider
+	providers[model.GiteaType] = gtProvider
+	providers[model.GitType] = gitProvider
 
 	providersByType[client.GithubPipelineConfigType] = ghProvider
 	providersByType[client.GitlabPipelineConfigType] = glProvider
 	providersByType[client.BitbucketCloudPipelineConfigType] = bcProvider
 	providersByType[client.BitbucketServerPipelineConfigType] = bsProvider
+	providersByType[client.GiteaPipelineConfigType] = gtProvider
+	providersByType[client.GitPipelineConfigType] = gitProvider
 
 }
@@ -2,6 +2,7 @@ package bitbucketcloud
 
 import (
 	"bytes"
+	"crypto/md5"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,6 +32,8 @@ const (
 	tokenURL      = "https://bitbucket.org/site/oauth2/access_token"
 	maxPerPage    = "100"
 	cloneUserName = "x-token-auth"
+
+	maxBuildStatusKeyLength = 40
 )
 
 type client struct {
@@ -307,6 +310,32 @@ func (c *client) GetHeadInfo(repoURL string, branch string, accessToken string)
 	return info, nil
 }
 
+func (c *client) SetCommitStatus(repoURL string, commit string, status *model.CommitStatus, accessToken string) error {
+	user, repo, err := getUserRepoFromURL(repoURL)
+	if err != nil {
+		return err
+	}
+	buildStatus := BuildStatus{
+		State:       model.BitbucketBuildState(status.State),
+		Key:         status.Context,
+		Name:        status.Context,
+		URL:         status.TargetURL,
+		Description: status.Description,
+	}
+	if len(buildStatus.Key) > maxBuildStatusKeyLength {
+		//bitbucket cloud limits the length of keys
+		buildStatus.Key = fmt.Sprintf("%x", md5.Sum([]byte(status.Context)))
+	}
+	b, err := json.Marshal(buildStatus)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repositories/%s/%s/commit/%s/statuses/build", apiEndpoint, user, repo, commit)
+	header := map[string]string{"Content-Type": "application/json"}
+	_, err = doRequestToBitbucket(http.MethodPost, url, accessToken, header, bytes.NewReader(b))
+	return err
+}
+
 func convertUser(bitbucketUser *User) *v3.SourceCodeCredential {
 
 	if bitbucketUser == nil {
@@ -58,6 +58,14 @@ type Hook struct {
 	Events               []string `json:"events"`
 }
 
+type BuildStatus struct {
+	State       string `json:"state"`
+	Key         string `json:"key"`
+	Name        string `json:"name,omitempty"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
 type PaginatedHooks struct {
 	Paging
 	Values []Hook `json:"values"`
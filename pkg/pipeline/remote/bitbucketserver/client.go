@@ -446,6 +446,23 @@ func (c *client) GetHeadInfo(repoURL string, branch string, accessToken string)
 	return info, nil
 }
 
+func (c *client) SetCommitStatus(repoURL string, commit string, status *model.CommitStatus, accessToken string) error {
+	buildStatus := BuildStatus{
+		State:       model.BitbucketBuildState(status.State),
+		Key:         status.Context,
+		Name:        status.Context,
+		URL:         status.TargetURL,
+		Description: status.Description,
+	}
+	b, err := json.Marshal(buildStatus)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/rest/build-status/1.0/commits/%s", c.BaseURL, commit)
+	_, err = c.doRequestToBitbucket(http.MethodPost, url, accessToken, nil, bytes.NewReader(b))
+	return err
+}
+
 func convertUser(bitbucketUser *User) *v3.SourceCodeCredential {
 
 	if bitbucketUser == nil {
@@ -84,6 +84,14 @@ type HookConfiguration struct {
 	Secret string `json:"secret"`
 }
 
+type BuildStatus struct {
+	State       string `json:"state"`
+	Key         string `json:"key"`
+	Name        string `json:"name,omitempty"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
 type PaginatedHooks struct {
 	paging
 	Values []Hook `json:"values"`
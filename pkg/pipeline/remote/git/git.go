@@ -0,0 +1,375 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+
+	"github.com/pkg/errors"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/pipeline/remote/model"
+	"github.com/rancher/rancher/pkg/pipeline/utils"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	gitLoginName    = "git"
+	gitTimeout      = 2 * time.Minute
+	commitUserName  = "Rancher Pipeline"
+	commitUserEmail = "pipeline@rancher.local"
+
+	// PollingHookID is set as the webhook ID of pipelines of plain git repositories, they are polled for changes
+	// instead
+	PollingHookID = "polling"
+
+	refsHeadsPrefix = "refs/heads/"
+	refsTagsPrefix  = "refs/tags/"
+	peeledTagSuffix = "^{}"
+)
+
+var (
+	// scpLikeURL matches the user@host:path form of ssh URLs
+	scpLikeURL = regexp.MustCompile(`^(?:[a-zA-Z0-9][a-zA-Z0-9._-]*@)?[a-zA-Z0-9][a-zA-Z0-9.-]*:[^:]`)
+	hostName   = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.-]*$`)
+	userName   = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+)
+
+// client accesses repositories on git servers without an API with the git command line over ssh, authenticated by
+// the deploy key of the project
+type client struct {
+	Repositories []string
+	PrivateKey   string
+	KnownHosts   string
+}
+
+func New(config *v32.GitPipelineConfig) (model.Remote, error) {
+	if config == nil {
+		return nil, errors.New("empty git config")
+	}
+	return newClient(config), nil
+}
+
+func newClient(config *v32.GitPipelineConfig) *client {
+	return &client{
+		Repositories: config.Repositories,
+		PrivateKey:   config.PrivateKey,
+		KnownHosts:   config.KnownHosts,
+	}
+}
+
+// ValidateRepositoryURL checks that the repository is accessed over ssh, other transports like file:// URLs or local
+// paths would give access to the repositories on the rancher server with the deploy key.
+func ValidateRepositoryURL(repoURL string) error {
+	if strings.HasPrefix(repoURL, "ssh://") || strings.HasPrefix(repoURL, "git+ssh://") {
+		u, err := url.Parse(repoURL)
+		if err == nil && hostName.MatchString(u.Hostname()) && (u.User == nil || userName.MatchString(u.User.Username())) {
+			return nil
+		}
+	} else if !strings.Contains(repoURL, "://") && scpLikeURL.MatchString(repoURL) {
+		return nil
+	}
+	return fmt.Errorf("repository %s is not an ssh URL", repoURL)
+}
+
+// ValidateKnownHosts checks that knownHosts holds at least one host key in known_hosts format
+func ValidateKnownHosts(knownHosts string) error {
+	rest := []byte(knownHosts)
+	found := false
+	for len(rest) > 0 {
+		var err error
+		_, _, _, _, rest, err = ssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid known hosts: %v", err)
+		}
+		found = true
+	}
+	if !found {
+		return errors.New("known hosts of the git servers are required")
+	}
+	return nil
+}
+
+// checkRepository only allows the configured repositories, a pipeline must not clone arbitrary URLs with the deploy
+// key of the project
+func (c *client) checkRepository(repoURL string) error {
+	for _, repository := range c.Repositories {
+		if repository == repoURL {
+			return ValidateRepositoryURL(repoURL)
+		}
+	}
+	return fmt.Errorf("repository %s is not configured", repoURL)
+}
+
+func (c *client) Type() string {
+	return model.GitType
+}
+
+// Login returns the credential shared by all users of the project, plain git servers have no user accounts to log
+// in to
+func (c *client) Login(code string) (*v3.SourceCodeCredential, error) {
+	cred := &v3.SourceCodeCredential{}
+	cred.Spec.SourceCodeType = model.GitType
+	cred.Spec.LoginName = gitLoginName
+	cred.Spec.GitLoginName = gitLoginName
+	cred.Spec.DisplayName = gitLoginName
+	return cred, nil
+}
+
+func (c *client) Repos(account *v3.SourceCodeCredential) ([]v3.SourceCodeRepository, error) {
+	result := []v3.SourceCodeRepository{}
+	for _, repoURL := range c.Repositories {
+		r := v3.SourceCodeRepository{}
+		r.Spec.URL = repoURL
+		defaultBranch, err := c.GetDefaultBranch(repoURL, "")
+		if err != nil {
+			logrus.Debugf("failed to get default branch of %s: %v", repoURL, err)
+		}
+		r.Spec.DefaultBranch = defaultBranch
+		r.Spec.Permissions.Admin = true
+		r.Spec.Permissions.Pull = true
+		r.Spec.Permissions.Push = true
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// TestRepositories checks that every configured repository can be read with the deploy key
+func TestRepositories(config *v32.GitPipelineConfig) error {
+	if err := ValidateKnownHosts(config.KnownHosts); err != nil {
+		return err
+	}
+	c := newClient(config)
+	for _, repoURL := range c.Repositories {
+		if err := ValidateRepositoryURL(repoURL); err != nil {
+			return err
+		}
+		if _, err := c.run("", "ls-remote", "--heads", "--", repoURL); err != nil {
+			return fmt.Errorf("failed to access repository %s: %v", repoURL, err)
+		}
+	}
+	return nil
+}
+
+func (c *client) CreateHook(pipeline *v3.Pipeline, accessToken string) (string, error) {
+	if err := c.checkRepository(pipeline.Spec.RepositoryURL); err != nil {
+		return "", err
+	}
+	return PollingHookID, nil
+}
+
+func (c *client) DeleteHook(pipeline *v3.Pipeline, accessToken string) error {
+	return nil
+}
+
+func (c *client) GetPipelineFileInRepo(repoURL string, ref string, accessToken string) ([]byte, error) {
+	dir, err := c.clone(repoURL, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{utils.PipelineFileYml, utils.PipelineFileYaml} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		return content, err
+	}
+	return nil, nil
+}
+
+func (c *client) SetPipelineFileInRepo(repoURL string, branch string, accessToken string, content []byte) error {
+	dir, err := c.clone(repoURL, branch)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := utils.PipelineFileYml
+	message := "Create .rancher-pipeline.yml file"
+	if _, err := os.Stat(filepath.Join(dir, utils.PipelineFileYml)); err == nil {
+		message = fmt.Sprintf("Update %s file", utils.PipelineFileYml)
+	} else if _, err := os.Stat(filepath.Join(dir, utils.PipelineFileYaml)); err == nil {
+		fileName = utils.PipelineFileYaml
+		message = fmt.Sprintf("Update %s file", utils.PipelineFileYaml)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, fileName), content, 0644); err != nil {
+		return err
+	}
+	if _, err := c.run(dir, "add", fileName); err != nil {
+		return err
+	}
+	if _, err := c.run(dir, "-c", "user.name="+commitUserName, "-c", "user.email="+commitUserEmail, "commit", "-q", "-m", message); err != nil {
+		return err
+	}
+	_, err = c.run(dir, "push", "-q", "origin", "HEAD:"+refsHeadsPrefix+branch)
+	return err
+}
+
+func (c *client) GetBranches(repoURL string, accessToken string) ([]string, error) {
+	refs, err := c.ListRefs(repoURL, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for ref := range refs {
+		if strings.HasPrefix(ref, refsHeadsPrefix) {
+			result = append(result, strings.TrimPrefix(ref, refsHeadsPrefix))
+		}
+	}
+	return result, nil
+}
+
+func (c *client) GetDefaultBranch(repoURL string, accessToken string) (string, error) {
+	if err := c.checkRepository(repoURL); err != nil {
+		return "", err
+	}
+	output, err := c.run("", "ls-remote", "--symref", "--", repoURL, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.HasPrefix(line, "ref: ") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "ref: "))
+		if len(fields) > 0 {
+			return strings.TrimPrefix(fields[0], refsHeadsPrefix), nil
+		}
+	}
+	return "", nil
+}
+
+func (c *client) GetHeadInfo(repoURL string, branch string, accessToken string) (*model.BuildInfo, error) {
+	refs, err := c.ListRefs(repoURL, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	commit, ok := refs[refsHeadsPrefix+branch]
+	if !ok {
+		return nil, errors.New("no commit found")
+	}
+	info := &model.BuildInfo{}
+	info.Commit = commit
+	info.Ref = refsHeadsPrefix + branch
+	info.Branch = branch
+	return info, nil
+}
+
+// SetCommitStatus does nothing, plain git servers have no API to report statuses to
+func (c *client) SetCommitStatus(repoURL string, commit string, status *model.CommitStatus, accessToken string) error {
+	return nil
+}
+
+func (c *client) ListRefs(repoURL string, accessToken string) (map[string]string, error) {
+	if err := c.checkRepository(repoURL); err != nil {
+		return nil, err
+	}
+	output, err := c.run("", "ls-remote", "--heads", "--tags", "--", repoURL)
+	if err != nil {
+		return nil, err
+	}
+	return parseRefs(string(output)), nil
+}
+
+// parseRefs parses the output of git ls-remote, annotated tags resolve to the commit they point to
+func parseRefs(output string) map[string]string {
+	refs := map[string]string{}
+	peeled := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if strings.HasSuffix(fields[1], peeledTagSuffix) {
+			peeled[strings.TrimSuffix(fields[1], peeledTagSuffix)] = fields[0]
+			continue
+		}
+		refs[fields[1]] = fields[0]
+	}
+	for ref, commit := range peeled {
+		refs[ref] = commit
+	}
+	return refs
+}
+
+func (c *client) clone(repoURL string, ref string) (string, error) {
+	if err := c.checkRepository(repoURL); err != nil {
+		return "", err
+	}
+	dir, err := ioutil.TempDir("", "pipeline-git-")
+	if err != nil {
+		return "", err
+	}
+	args := []string{"clone", "-q", "--depth=1", "--single-branch"}
+	if ref != "" {
+		args = append(args, "-b", strings.TrimPrefix(strings.TrimPrefix(ref, refsHeadsPrefix), refsTagsPrefix))
+	}
+	args = append(args, "--", repoURL, dir)
+	if _, err := c.run("", args...); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// run runs a git command authenticated by the deploy key, only trusting the servers with a known host key
+func (c *client) run(dir string, args ...string) ([]byte, error) {
+	keyFile, err := writeTempFile("pipeline-deploy-key-", c.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(keyFile)
+	knownHostsFile, err := writeTempFile("pipeline-known-hosts-", c.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(knownHostsFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes -o StrictHostKeyChecking=yes -o UserKnownHostsFile=%s", keyFile, knownHostsFile),
+	)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, stderr.String())
+	}
+	return output, nil
+}
+
+func writeTempFile(prefix, content string) (string, error) {
+	f, err := ioutil.TempFile("", prefix)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
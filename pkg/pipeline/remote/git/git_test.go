@@ -0,0 +1,60 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRefs(t *testing.T) {
+	output := "1111111111111111111111111111111111111111\trefs/heads/master\n" +
+		"2222222222222222222222222222222222222222\trefs/tags/v1.0\n" +
+		"3333333333333333333333333333333333333333\trefs/tags/v1.0^{}\n" +
+		"4444444444444444444444444444444444444444\trefs/tags/v0.9\n"
+
+	assert.Equal(t, map[string]string{
+		"refs/heads/master": "1111111111111111111111111111111111111111",
+		"refs/tags/v1.0":    "3333333333333333333333333333333333333333",
+		"refs/tags/v0.9":    "4444444444444444444444444444444444444444",
+	}, parseRefs(output))
+}
+
+func TestValidateRepositoryURL(t *testing.T) {
+	for _, repoURL := range []string{
+		"git@git.example.com:org/repo.git",
+		"git.example.com:/srv/git/repo.git",
+		"ssh://git@git.example.com:2222/org/repo.git",
+		"git+ssh://git.example.com/org/repo.git",
+	} {
+		assert.Nil(t, ValidateRepositoryURL(repoURL), repoURL)
+	}
+	for _, repoURL := range []string{
+		"--upload-pack=touch /tmp/pwned",
+		"-oProxyCommand=sh@host:repo",
+		"file:///var/lib/rancher",
+		"/var/lib/rancher",
+		"./repo",
+		"https://git.example.com/org/repo.git",
+		"ext::sh -c touch% /tmp/pwned",
+		"ssh://-oProxyCommand=sh/repo",
+		"ssh://-user@git.example.com/repo",
+	} {
+		assert.NotNil(t, ValidateRepositoryURL(repoURL), repoURL)
+	}
+}
+
+func TestCheckRepository(t *testing.T) {
+	c := &client{Repositories: []string{"git@git.example.com:org/repo.git"}}
+	assert.Nil(t, c.checkRepository("git@git.example.com:org/repo.git"))
+	assert.NotNil(t, c.checkRepository("git@git.example.com:org/other.git"))
+
+	_, err := c.ListRefs("--upload-pack=touch /tmp/pwned", "")
+	assert.NotNil(t, err)
+}
+
+func TestValidateKnownHosts(t *testing.T) {
+	assert.Nil(t, ValidateKnownHosts("git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\n"))
+	assert.NotNil(t, ValidateKnownHosts(""))
+	assert.NotNil(t, ValidateKnownHosts("# no keys\n"))
+	assert.NotNil(t, ValidateKnownHosts("git.example.com ssh-ed25519 invalid"))
+}
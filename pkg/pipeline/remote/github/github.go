@@ -479,6 +479,31 @@ func (c *client) GetHeadInfo(repoURL string, branch string, accessToken string)
 	return info, nil
 }
 
+func (c *client) SetCommitStatus(repoURL string, commit string, status *model.CommitStatus, accessToken string) error {
+	owner, repo, err := getUserRepoFromURL(repoURL)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/statuses/%s", c.API, owner, repo, commit)
+	repoStatus := &github.RepoStatus{
+		State:       &status.State,
+		Context:     &status.Context,
+		Description: &status.Description,
+		TargetURL:   &status.TargetURL,
+	}
+	b, err := json.Marshal(repoStatus)
+	if err != nil {
+		return err
+	}
+	resp, err := doRequestToGithub(http.MethodPost, url, accessToken, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
 func convertRepos(repos []github.Repository) []v3.SourceCodeRepository {
 	result := []v3.SourceCodeRepository{}
 	for _, repo := range repos {
@@ -392,6 +392,39 @@ func (c *client) GetHeadInfo(repoURL string, branch string, accessToken string)
 	return info, nil
 }
 
+func (c *client) SetCommitStatus(repoURL string, commit string, status *model.CommitStatus, accessToken string) error {
+	project, err := getProjectNameFromURL(repoURL)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/statuses/%s", c.API, project, commit)
+	option := &gitlab.SetCommitStatusOptions{
+		State:       convertCommitState(status.State),
+		Name:        &status.Context,
+		Description: &status.Description,
+		TargetURL:   &status.TargetURL,
+	}
+	resp, err := doRequestToGitlab(http.MethodPost, url, accessToken, option)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+func convertCommitState(state string) gitlab.BuildStateValue {
+	switch state {
+	case model.CommitStatePending:
+		return gitlab.Running
+	case model.CommitStateSuccess:
+		return gitlab.Success
+	case model.CommitStateFailure:
+		return gitlab.Failed
+	}
+	return gitlab.Canceled
+}
+
 func (c *client) GetAccount(accessToken string) (*v3.SourceCodeCredential, error) {
 	account, err := c.getGitlabUser(accessToken)
 	if err != nil {
@@ -16,4 +16,6 @@ type BuildInfo struct {
 	Author          string `json:"author,omitempty"`
 	AvatarURL       string `json:"avatarUrl,omitempty"`
 	Email           string `json:"email,omitempty"`
+
+	Env map[string]string `json:"env,omitempty"`
 }
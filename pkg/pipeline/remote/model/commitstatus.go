@@ -0,0 +1,28 @@
+package model
+
+const (
+	CommitStatePending = "pending"
+	CommitStateSuccess = "success"
+	CommitStateFailure = "failure"
+	CommitStateError   = "error"
+)
+
+// CommitStatus is the state of a pipeline stage reported on the commit it runs for. Context identifies the stage,
+// statuses reported with the same context replace each other.
+type CommitStatus struct {
+	State       string
+	Context     string
+	Description string
+	TargetURL   string
+}
+
+// BitbucketBuildState converts the state of a commit status to the state of a bitbucket cloud or server build status
+func BitbucketBuildState(state string) string {
+	switch state {
+	case CommitStatePending:
+		return "INPROGRESS"
+	case CommitStateSuccess:
+		return "SUCCESSFUL"
+	}
+	return "FAILED"
+}
@@ -0,0 +1,14 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitbucketBuildState(t *testing.T) {
+	assert.Equal(t, "INPROGRESS", BitbucketBuildState(CommitStatePending))
+	assert.Equal(t, "SUCCESSFUL", BitbucketBuildState(CommitStateSuccess))
+	assert.Equal(t, "FAILED", BitbucketBuildState(CommitStateFailure))
+	assert.Equal(t, "FAILED", BitbucketBuildState(CommitStateError))
+}
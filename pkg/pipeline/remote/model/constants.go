@@ -5,4 +5,6 @@ const (
 	GithubType          = "github"
 	BitbucketCloudType  = "bitbucketcloud"
 	BitbucketServerType = "bitbucketserver"
+	GiteaType           = "gitea"
+	GitType             = "git"
 )
@@ -23,8 +23,17 @@ type Remote interface {
 	GetBranches(repoURL string, accessToken string) ([]string, error)
 
 	GetHeadInfo(repoURL string, branch string, accessToken string) (*BuildInfo, error)
+
+	SetCommitStatus(repoURL string, commit string, status *CommitStatus, accessToken string) error
 }
 
 type Refresher interface {
 	Refresh(cred *v3.SourceCodeCredential) (bool, error)
 }
+
+// RefLister is implemented by remotes of servers that cannot send webhooks, their pipelines are triggered by polling
+// the refs of the repository
+type RefLister interface {
+	// ListRefs returns the commit of every branch and tag of a repository keyed by their full ref
+	ListRefs(repoURL string, accessToken string) (map[string]string, error)
+}
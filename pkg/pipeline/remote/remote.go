@@ -7,6 +7,8 @@ import (
 
 	"github.com/rancher/rancher/pkg/pipeline/remote/bitbucketcloud"
 	"github.com/rancher/rancher/pkg/pipeline/remote/bitbucketserver"
+	"github.com/rancher/rancher/pkg/pipeline/remote/git"
+	"github.com/rancher/rancher/pkg/pipeline/remote/gitea"
 	"github.com/rancher/rancher/pkg/pipeline/remote/github"
 	"github.com/rancher/rancher/pkg/pipeline/remote/gitlab"
 	"github.com/rancher/rancher/pkg/pipeline/remote/model"
@@ -25,6 +27,10 @@ func New(config interface{}) (model.Remote, error) {
 		return bitbucketcloud.New(config)
 	case *v32.BitbucketServerPipelineConfig:
 		return bitbucketserver.New(config)
+	case *v32.GiteaPipelineConfig:
+		return gitea.New(config)
+	case *v32.GitPipelineConfig:
+		return git.New(config)
 	}
 
 	return nil, errors.New("unsupported remote type")
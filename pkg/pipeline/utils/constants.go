@@ -38,6 +38,8 @@ const (
 	MinioName                      = "minio"
 	MinioBucketLocation            = "local"
 	MinioLogBucket                 = "pipeline-logs"
+	MinioCacheBucket               = "pipeline-cache"
+	MinioArtifactBucket            = "pipeline-artifacts"
 	NetWorkPolicyName              = "pipeline-np"
 	LabelKeyApp                    = "app"
 	LabelKeyJenkins                = "jenkins"
@@ -54,9 +56,11 @@ const (
 	WebhookEventPush        = "push"
 	WebhookEventPullRequest = "pull_request"
 	WebhookEventTag         = "tag"
+	EventCron               = "cron"
 
 	TriggerTypeUser    = "user"
 	TriggerTypeWebhook = "webhook"
+	TriggerTypeCron    = "cron"
 
 	StateWaiting  = "Waiting"
 	StateBuilding = "Building"
@@ -73,6 +77,10 @@ const (
 	PipelineFinishLabel    = "pipeline.project.cattle.io/finish"
 	LocalRegistryPortLabel = "pipeline.project.cattle.io/local-registry-port"
 	PipelineNamespaceLabel = "pipeline.project.cattle.io/pipeline-namespace"
+	PipelineEngineLabel    = "pipeline.project.cattle.io/engine"
+
+	EngineJenkins = "jenkins"
+	EngineTekton  = "tekton"
 
 	PipelineFileYml  = ".rancher-pipeline.yml"
 	PipelineFileYaml = ".rancher-pipeline.yaml"
@@ -107,6 +115,16 @@ const (
 	SettingExecutorCPURequestDefault    = "10m"
 	SettingExecutorCPULimit             = "executor-cpu-limit"
 	SettingExecutorCPULimitDefault      = "1"
+	SettingEngine                       = "engine"
+	SettingEngineDefault                = EngineJenkins
+	SettingCacheExpiry                  = "cache-expiry"
+	SettingCacheExpiryDefault           = "168h"
+	SettingCacheSizeLimit               = "cache-size-limit"
+	SettingCacheSizeLimitDefault        = "2Gi"
+	SettingArtifactExpiry               = "artifact-expiry"
+	SettingArtifactExpiryDefault        = "72h"
+	SettingArtifactSizeLimit            = "artifact-size-limit"
+	SettingArtifactSizeLimitDefault     = "1Gi"
 
 	PipelineToolsMemoryRequestDefault = "10Mi"
 	PipelineToolsMemoryLimitDefault   = "100Mi"
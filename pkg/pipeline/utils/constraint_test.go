@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchAllCronEvent(t *testing.T) {
+	execution := &v3.PipelineExecution{}
+	execution.Spec.Branch = "master"
+	execution.Spec.Event = EventCron
+	execution.Spec.TriggeredBy = TriggerTypeCron
+	execution.Spec.Env = map[string]string{
+		"SUITE":      "integration",
+		EnvEvent:     "push",
+		EnvGitBranch: "other",
+	}
+
+	m := GetEnvVarMap(execution)
+	assert.Equal(t, "integration", m["SUITE"])
+	assert.Equal(t, EventCron, m[EnvEvent])
+	assert.Equal(t, "master", m[EnvGitBranch])
+
+	assert.True(t, MatchAll(&v32.Constraints{Event: &v32.Constraint{Include: []string{EventCron}}}, execution))
+	assert.False(t, MatchAll(&v32.Constraints{Event: &v32.Constraint{Exclude: []string{EventCron}}}, execution))
+	assert.False(t, MatchAll(&v32.Constraints{Event: &v32.Constraint{Include: []string{WebhookEventPush}}}, execution))
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	v1 "github.com/rancher/rancher/pkg/generated/norman/core/v1"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/ref"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	MaskedValue = "****"
+	//values shorter than this are not masked, they would mask unrelated parts of the log
+	minMaskLength = 4
+)
+
+// MaskSecrets replaces the secret values in a step log. Each line of a multi-line value is masked on its own, as
+// scripts printing a key file print it line by line. Values and lines shorter than minMaskLength are left as is.
+func MaskSecrets(log string, values []string) string {
+	var masks []string
+	for _, value := range values {
+		masks = append(masks, value)
+		if strings.Contains(value, "\n") {
+			for _, line := range strings.Split(value, "\n") {
+				masks = append(masks, strings.TrimSpace(line))
+			}
+		}
+	}
+	//mask longer values first so that values containing others are fully masked
+	sort.Slice(masks, func(i, j int) bool {
+		return len(masks[i]) > len(masks[j])
+	})
+	for _, mask := range masks {
+		if len(strings.TrimSpace(mask)) < minMaskLength {
+			continue
+		}
+		log = strings.Replace(log, mask, MaskedValue, -1)
+	}
+	return log
+}
+
+// GetSecretValues returns the values of the secrets the steps of an execution have access to: the secrets of their
+// envFrom, the registry credentials of the publish image steps and the source code credential of the pipeline. They
+// are masked in the step logs. providerConfig returns the configuration of a source code provider in a project.
+func GetSecretValues(secretLister v1.SecretLister, pipelineLister v3.PipelineLister, sourceCodeCredentialLister v3.SourceCodeCredentialLister,
+	execution *v3.PipelineExecution, providerConfig func(sourceCodeType, projectID string) (interface{}, error)) ([]string, error) {
+	ns := GetPipelineCommonName(execution.Spec.ProjectName)
+	var values []string
+	addSecretKey := func(name, key string) error {
+		secret, err := secretLister.Get(ns, name)
+		if apierrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if key == "" {
+			for _, value := range secret.Data {
+				values = append(values, string(value))
+			}
+		} else if value, ok := secret.Data[key]; ok {
+			values = append(values, string(value))
+		}
+		return nil
+	}
+
+	reg := regexp.MustCompile("[^a-zA-Z0-9]+")
+	for _, stage := range execution.Spec.PipelineConfig.Stages {
+		for _, step := range stage.Steps {
+			for _, e := range step.EnvFrom {
+				if err := addSecretKey(e.SourceName, e.SourceKey); err != nil {
+					return nil, err
+				}
+			}
+			if step.PublishImageConfig == nil {
+				continue
+			}
+			if !step.PublishImageConfig.PushRemote {
+				if err := addSecretKey(PipelineSecretName, PipelineSecretTokenKey); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			registry := strings.ToLower(reg.ReplaceAllString(step.PublishImageConfig.Registry, ""))
+			if err := addSecretKey(fmt.Sprintf("%s-%s", execution.Namespace, registry), PublishSecretPwKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	credentialValues, err := getSourceCodeCredentialValues(pipelineLister, sourceCodeCredentialLister, execution, providerConfig)
+	if err != nil {
+		return nil, err
+	}
+	return append(values, credentialValues...), nil
+}
+
+func getSourceCodeCredentialValues(pipelineLister v3.PipelineLister, sourceCodeCredentialLister v3.SourceCodeCredentialLister,
+	execution *v3.PipelineExecution, providerConfig func(sourceCodeType, projectID string) (interface{}, error)) ([]string, error) {
+	pipeline, err := pipelineLister.Get(ref.Parse(execution.Spec.PipelineName))
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if pipeline.Spec.SourceCodeCredentialName == "" {
+		return nil, nil
+	}
+	credential, err := sourceCodeCredentialLister.Get(ref.Parse(pipeline.Spec.SourceCodeCredentialName))
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	values := []string{
+		credential.Spec.AccessToken,
+		credential.Spec.RefreshToken,
+		credential.Spec.GitCloneToken,
+	}
+
+	_, projID := ref.Parse(execution.Spec.ProjectName)
+	scpConfig, err := providerConfig(credential.Spec.SourceCodeType, projID)
+	if err != nil {
+		return nil, err
+	}
+	if gitConfig, ok := scpConfig.(*v32.GitPipelineConfig); ok {
+		values = append(values, gitConfig.PrivateKey)
+	}
+	return values, nil
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"sort"
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/generated/norman/core/v1/fakes"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
+	projectfakes "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3/fakes"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestMaskSecrets(t *testing.T) {
+	log := "+ env\nTOKEN=abc123\nTOKEN_SUFFIXED=abc123456\nEMPTY=\n-----BEGIN KEY-----\nAAAA\n-----END KEY-----\n"
+	values := []string{"abc123", "abc123456", "", "-----BEGIN KEY-----\nAAAA\n-----END KEY-----\n"}
+
+	assert.Equal(t, "+ env\nTOKEN=****\nTOKEN_SUFFIXED=****\nEMPTY=\n****", MaskSecrets(log, values))
+	assert.Equal(t, "key line ****", MaskSecrets("key line AAAA", values))
+	assert.Equal(t, log, MaskSecrets(log, nil))
+	assert.Equal(t, "a=1\n  {\n  ****\n  }\n", MaskSecrets("a=1\n  {\n  token\n  }\n", []string{"1", "a", "{\ntoken\n}"}))
+}
+
+type credentialLister map[string]*v3.SourceCodeCredential
+
+func (c credentialLister) List(namespace string, selector labels.Selector) ([]*v3.SourceCodeCredential, error) {
+	return nil, nil
+}
+
+func (c credentialLister) Get(namespace, name string) (*v3.SourceCodeCredential, error) {
+	if credential, ok := c[namespace+":"+name]; ok {
+		return credential, nil
+	}
+	return nil, apierrors.NewNotFound(v3.SourceCodeCredentialGroupVersionResource.GroupResource(), name)
+}
+
+func TestGetSecretValues(t *testing.T) {
+	secrets := map[string]*corev1.Secret{
+		"p-1-pipeline:env": {Data: map[string][]byte{"a": []byte("env-a"), "b": []byte("env-b")}},
+		"p-1-pipeline:" + PipelineSecretName: {Data: map[string][]byte{
+			PipelineSecretTokenKey: []byte("local-token"),
+			PipelineSecretUserKey:  []byte("admin"),
+		}},
+		"p-1-pipeline:p-1-indexdockerio": {Data: map[string][]byte{PublishSecretPwKey: []byte("registry-password")}},
+	}
+	secretLister := &fakes.SecretListerMock{
+		GetFunc: func(namespace string, name string) (*corev1.Secret, error) {
+			if secret, ok := secrets[namespace+":"+name]; ok {
+				return secret, nil
+			}
+			return nil, apierrors.NewNotFound(corev1.Resource("secrets"), name)
+		},
+	}
+	pipelineLister := &projectfakes.PipelineListerMock{
+		GetFunc: func(namespace string, name string) (*v3.Pipeline, error) {
+			return &v3.Pipeline{
+				Spec: v32.PipelineSpec{SourceCodeCredentialName: "u-1:credential"},
+			}, nil
+		},
+	}
+	credentials := credentialLister{
+		"u-1:credential": {Spec: v32.SourceCodeCredentialSpec{
+			SourceCodeType: "git",
+			AccessToken:    "access-token",
+			RefreshToken:   "refresh-token",
+			GitCloneToken:  "clone-token",
+		}},
+	}
+	providerConfig := func(sourceCodeType, projectID string) (interface{}, error) {
+		assert.Equal(t, "git", sourceCodeType)
+		assert.Equal(t, "p-1", projectID)
+		return &v32.GitPipelineConfig{PrivateKey: "private-key"}, nil
+	}
+
+	step := func(envFrom []v32.EnvFrom, publish *v32.PublishImageConfig) v32.Step {
+		return v32.Step{EnvFrom: envFrom, PublishImageConfig: publish}
+	}
+	tests := []struct {
+		name   string
+		steps  []v32.Step
+		values []string
+	}{
+		{
+			name: "env from a key",
+			steps: []v32.Step{
+				step([]v32.EnvFrom{{SourceName: "env", SourceKey: "a"}}, nil),
+			},
+			values: []string{"env-a"},
+		},
+		{
+			name: "env from a whole secret",
+			steps: []v32.Step{
+				step([]v32.EnvFrom{{SourceName: "env"}}, nil),
+			},
+			values: []string{"env-a", "env-b"},
+		},
+		{
+			name: "missing secret and key",
+			steps: []v32.Step{
+				step([]v32.EnvFrom{{SourceName: "missing"}, {SourceName: "env", SourceKey: "c"}}, nil),
+			},
+		},
+		{
+			name: "local publish",
+			steps: []v32.Step{
+				step(nil, &v32.PublishImageConfig{Tag: "image"}),
+			},
+			values: []string{"local-token"},
+		},
+		{
+			name: "remote publish",
+			steps: []v32.Step{
+				step(nil, &v32.PublishImageConfig{Tag: "image", PushRemote: true, Registry: "index.docker.io"}),
+			},
+			values: []string{"registry-password"},
+		},
+	}
+	for _, tt := range tests {
+		execution := &v3.PipelineExecution{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "p-1"},
+			Spec: v32.PipelineExecutionSpec{
+				ProjectName:  "c-1:p-1",
+				PipelineName: "p-1:pipeline-1",
+				PipelineConfig: v32.PipelineConfig{
+					Stages: []v32.Stage{{Steps: tt.steps}},
+				},
+			},
+		}
+		values, err := GetSecretValues(secretLister, pipelineLister, credentials, execution, providerConfig)
+		assert.Nil(t, err, tt.name)
+		expected := append(tt.values, "access-token", "refresh-token", "clone-token", "private-key")
+		sort.Strings(expected)
+		sort.Strings(values)
+		assert.Equal(t, expected, values, tt.name)
+	}
+
+	// the credential is skipped when it is gone
+	execution := &v3.PipelineExecution{
+		Spec: v32.PipelineExecutionSpec{ProjectName: "c-1:p-1", PipelineName: "p-1:pipeline-1"},
+	}
+	values, err := GetSecretValues(secretLister, pipelineLister, credentialLister{}, execution, providerConfig)
+	assert.Nil(t, err)
+	assert.Empty(t, values)
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+)
+
+// MaxMatrixInstances limits the number of instances a matrix step expands into
+const MaxMatrixInstances = 64
+
+var matrixKeyRegexp = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// ExpandMatrix replaces each matrix step of a pipeline config with an instance for every combination of its matrix
+// values. The values are set as environment variables of the instance and substituted in its image and tag, instances
+// not matching the matrix constraints of the step are left out. It returns the matrix values of every step of the
+// expanded config, nil for steps without a matrix.
+func ExpandMatrix(config *v32.PipelineConfig) ([][]map[string]string, error) {
+	var result [][]map[string]string
+	for i := range config.Stages {
+		stage := &config.Stages[i]
+		var (
+			steps  []v32.Step
+			values []map[string]string
+		)
+		for _, step := range stage.Steps {
+			if len(step.Matrix) == 0 {
+				steps = append(steps, step)
+				values = append(values, nil)
+				continue
+			}
+			if step.SourceCodeConfig != nil {
+				return nil, fmt.Errorf("invalid definition for pipeline: the source code step cannot have a matrix")
+			}
+			combinations, err := matrixCombinations(step.Matrix)
+			if err != nil {
+				return nil, err
+			}
+			var instances int
+			for _, combination := range combinations {
+				if step.When != nil && !matchMatrix(step.When.Matrix, combination) {
+					continue
+				}
+				steps = append(steps, matrixInstance(step, combination))
+				values = append(values, combination)
+				instances++
+			}
+			if instances == 0 {
+				return nil, fmt.Errorf("invalid definition for pipeline: matrix constraints of a step in stage %q exclude all combinations", stage.Name)
+			}
+		}
+		stage.Steps = steps
+		result = append(result, values)
+	}
+	return result, nil
+}
+
+func matrixCombinations(matrix map[string][]string) ([]map[string]string, error) {
+	var keys []string
+	for k, values := range matrix {
+		if !matrixKeyRegexp.MatchString(k) {
+			return nil, fmt.Errorf("invalid definition for pipeline: invalid matrix variable %q", k)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("invalid definition for pipeline: matrix variable %q has no values", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	combinations := []map[string]string{{}}
+	for _, k := range keys {
+		var expanded []map[string]string
+		for _, combination := range combinations {
+			for _, v := range matrix[k] {
+				c := make(map[string]string, len(combination)+1)
+				for ck, cv := range combination {
+					c[ck] = cv
+				}
+				c[k] = v
+				expanded = append(expanded, c)
+			}
+		}
+		if len(expanded) > MaxMatrixInstances {
+			return nil, fmt.Errorf("invalid definition for pipeline: matrix expands into more than %d steps", MaxMatrixInstances)
+		}
+		combinations = expanded
+	}
+	return combinations, nil
+}
+
+func matchMatrix(constraints map[string]v32.Constraint, values map[string]string) bool {
+	for k, c := range constraints {
+		c := c
+		if !Match(&c, values[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func matrixInstance(step v32.Step, values map[string]string) v32.Step {
+	instance := *step.DeepCopy()
+	instance.Matrix = nil
+	if instance.When != nil {
+		instance.When.Matrix = nil
+	}
+	if instance.Env == nil {
+		instance.Env = map[string]string{}
+	}
+	for k, v := range values {
+		instance.Env[k] = v
+	}
+	substitute := func(raw string) string {
+		for k, v := range values {
+			raw = strings.Replace(raw, "${"+k+"}", v, -1)
+		}
+		return raw
+	}
+	if instance.RunScriptConfig != nil {
+		instance.RunScriptConfig.Image = substitute(instance.RunScriptConfig.Image)
+	}
+	if instance.PublishImageConfig != nil {
+		instance.PublishImageConfig.Tag = substitute(instance.PublishImageConfig.Tag)
+	}
+	return instance
+}
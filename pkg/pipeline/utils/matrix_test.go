@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+
+	v32 "github.com/rancher/rancher/pkg/apis/project.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandMatrix(t *testing.T) {
+	config := &v32.PipelineConfig{
+		Stages: []v32.Stage{
+			{Name: "clone", Steps: []v32.Step{{SourceCodeConfig: &v32.SourceCodeConfig{}}}},
+			{Name: "test", Steps: []v32.Step{
+				{
+					RunScriptConfig: &v32.RunScriptConfig{Image: "golang:${GO}", ShellScript: "go test ./..."},
+					Matrix: map[string][]string{
+						"GO":     {"1.13", "1.14"},
+						"GOARCH": {"amd64", "arm64"},
+					},
+					When: &v32.Constraints{
+						Branch: &v32.Constraint{Include: []string{"master"}},
+						Matrix: map[string]v32.Constraint{"GO": {Exclude: []string{"1.13"}}},
+					},
+				},
+				{RunScriptConfig: &v32.RunScriptConfig{Image: "golang", ShellScript: "go vet ./..."}},
+			}},
+		},
+	}
+
+	values, err := ExpandMatrix(config)
+	assert.Nil(t, err)
+	steps := config.Stages[1].Steps
+	assert.Len(t, steps, 3)
+	assert.Equal(t, "golang:1.14", steps[0].RunScriptConfig.Image)
+	assert.Equal(t, map[string]string{"GO": "1.14", "GOARCH": "amd64"}, steps[0].Env)
+	assert.Equal(t, map[string]string{"GO": "1.14", "GOARCH": "arm64"}, steps[1].Env)
+	assert.Nil(t, steps[0].Matrix)
+	assert.Nil(t, steps[0].When.Matrix)
+	assert.Equal(t, []string{"master"}, steps[0].When.Branch.Include)
+	assert.Equal(t, [][]map[string]string{
+		{nil},
+		{{"GO": "1.14", "GOARCH": "amd64"}, {"GO": "1.14", "GOARCH": "arm64"}, nil},
+	}, values)
+
+	config.Stages[1].Steps = []v32.Step{{
+		RunScriptConfig: &v32.RunScriptConfig{},
+		Matrix:          map[string][]string{"GO": {"1.13"}},
+		When:            &v32.Constraints{Matrix: map[string]v32.Constraint{"GO": {Include: []string{"1.14"}}}},
+	}}
+	_, err = ExpandMatrix(config)
+	assert.NotNil(t, err)
+}
+
+func TestValidPipelineConfigMatrix(t *testing.T) {
+	newConfig := func(matrix map[string][]string) *v32.PipelineConfig {
+		return ConfigWithCloneStage(&v32.PipelineConfig{
+			Stages: []v32.Stage{
+				{Name: "test", Steps: []v32.Step{{RunScriptConfig: &v32.RunScriptConfig{}, Matrix: matrix}}},
+			},
+		})
+	}
+
+	config := newConfig(map[string][]string{"GO": {"1.13", "1.14"}})
+	assert.Nil(t, ValidPipelineConfig(*config))
+	assert.Len(t, config.Stages[1].Steps, 1, "the config is not expanded")
+
+	assert.NotNil(t, ValidPipelineConfig(*newConfig(map[string][]string{"GO-VERSION": {"1.14"}})))
+	assert.NotNil(t, ValidPipelineConfig(*newConfig(map[string][]string{"GO": {}})))
+
+	tooLarge := map[string][]string{}
+	for _, k := range []string{"A", "B", "C", "D", "E", "F", "G"} {
+		tooLarge[k] = []string{"1", "2"}
+	}
+	assert.NotNil(t, ValidPipelineConfig(*newConfig(tooLarge)))
+}
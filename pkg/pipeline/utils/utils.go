@@ -3,6 +3,7 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -12,17 +13,24 @@ import (
 	v3 "github.com/rancher/rancher/pkg/generated/norman/project.cattle.io/v3"
 	"github.com/rancher/rancher/pkg/pipeline/remote/model"
 	"github.com/rancher/rancher/pkg/ref"
+	"github.com/rancher/rancher/pkg/settings"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func initExecution(p *v3.Pipeline, config *v32.PipelineConfig) *v3.PipelineExecution {
+func initExecution(p *v3.Pipeline, config *v32.PipelineConfig) (*v3.PipelineExecution, error) {
 	//add Clone stage/step at the start
 
-	toRunConfig := configWithCloneStage(config)
+	toRunConfig := ConfigWithCloneStage(config)
+	matrixValues, err := ExpandMatrix(toRunConfig)
+	if err != nil {
+		return nil, err
+	}
 	execution := &v3.PipelineExecution{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      GetNextExecutionName(p),
@@ -49,12 +57,15 @@ func initExecution(p *v3.Pipeline, config *v32.PipelineConfig) *v3.PipelineExecu
 		for j := 0; j < stepsize; j++ {
 			step := &stage.Steps[j]
 			step.State = StateWaiting
+			step.Matrix = matrixValues[i][j]
 		}
 	}
-	return execution
+	return execution, nil
 }
 
-func configWithCloneStage(config *v32.PipelineConfig) *v32.PipelineConfig {
+// ConfigWithCloneStage returns a copy of a pipeline config starting with the clone stage, the pipeline file of a
+// repository doesn't have it
+func ConfigWithCloneStage(config *v32.PipelineConfig) *v32.PipelineConfig {
 	result := config.DeepCopy()
 	if len(config.Stages) > 0 && len(config.Stages[0].Steps) > 0 &&
 		config.Stages[0].Steps[0].SourceCodeConfig != nil {
@@ -103,7 +114,10 @@ func IsFinishState(state string) bool {
 func GenerateExecution(executions v3.PipelineExecutionInterface, pipeline *v3.Pipeline, pipelineConfig *v32.PipelineConfig, info *model.BuildInfo) (*v3.PipelineExecution, error) {
 
 	//Generate a new pipeline execution
-	execution := initExecution(pipeline, pipelineConfig)
+	execution, err := initExecution(pipeline, pipelineConfig)
+	if err != nil {
+		return nil, err
+	}
 	execution.Spec.TriggeredBy = info.TriggerType
 	execution.Spec.TriggerUserName = info.TriggerUserName
 	execution.Spec.Branch = info.Branch
@@ -116,6 +130,7 @@ func GenerateExecution(executions v3.PipelineExecutionInterface, pipeline *v3.Pi
 	execution.Spec.Ref = info.Ref
 	execution.Spec.Commit = info.Commit
 	execution.Spec.Event = info.Event
+	execution.Spec.Env = info.Env
 
 	if info.RepositoryURL != "" {
 		execution.Spec.RepositoryURL = info.RepositoryURL
@@ -126,7 +141,7 @@ func GenerateExecution(executions v3.PipelineExecutionInterface, pipeline *v3.Pi
 		return nil, nil
 	}
 
-	execution, err := executions.Create(execution)
+	execution, err = executions.Create(execution)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +174,39 @@ func ValidPipelineConfig(config v32.PipelineConfig) error {
 		config.Stages[0].Steps[0].SourceCodeConfig == nil {
 		return fmt.Errorf("invalid definition for pipeline: expect souce code step at the start")
 	}
+	if _, err := ExpandMatrix(config.DeepCopy()); err != nil {
+		return err
+	}
+	for _, stage := range config.Stages {
+		for _, step := range stage.Steps {
+			if step.Cache != nil {
+				if step.Cache.Key == "" {
+					return fmt.Errorf("invalid definition for pipeline: cache key is required")
+				}
+				if err := validStoragePaths(step.Cache.Paths); err != nil {
+					return err
+				}
+			}
+			if step.Artifacts != nil {
+				if err := validStoragePaths(step.Artifacts.Paths); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validStoragePaths checks the cache and artifact paths of a step are inside the workspace
+func validStoragePaths(paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("invalid definition for pipeline: cache and artifacts require paths")
+	}
+	for _, p := range paths {
+		if p == "" || path.IsAbs(p) || strings.ContainsAny(p, "'\\") || path.Clean(p) == ".." || strings.HasPrefix(path.Clean(p), "../") {
+			return fmt.Errorf("invalid definition for pipeline: path %q must be relative to the workspace", p)
+		}
+	}
 	return nil
 }
 
@@ -167,6 +215,99 @@ func GetPipelineCommonName(projectName string) string {
 	return p + PipelineNamespaceSuffix
 }
 
+// GetEngine returns the pipeline engine configured for a project, jenkins if it is not configured
+func GetEngine(pipelineSettingLister v3.PipelineSettingLister, projectID string) (string, error) {
+	setting, err := pipelineSettingLister.Get(projectID, SettingEngine)
+	if apierrors.IsNotFound(err) {
+		return SettingEngineDefault, nil
+	} else if err != nil {
+		return "", err
+	}
+	switch setting.Value {
+	case "":
+		return SettingEngineDefault, nil
+	case EngineJenkins, EngineTekton:
+		return setting.Value, nil
+	}
+	return "", fmt.Errorf("invalid pipeline engine %q", setting.Value)
+}
+
+// StepStorageSettings holds the limits the step caches and artifacts of a project are kept within
+type StepStorageSettings struct {
+	CacheExpiry       time.Duration
+	CacheSizeLimit    int64
+	ArtifactExpiry    time.Duration
+	ArtifactSizeLimit int64
+}
+
+// GetStepStorageSettings returns the expiry and size limits of the step caches and artifacts of a project,
+// falling back to the defaults for missing settings
+func GetStepStorageSettings(pipelineSettingLister v3.PipelineSettingLister, projectID string) (*StepStorageSettings, error) {
+	result := &StepStorageSettings{}
+	durations := map[string]*time.Duration{
+		SettingCacheExpiry:    &result.CacheExpiry,
+		SettingArtifactExpiry: &result.ArtifactExpiry,
+	}
+	for name, d := range durations {
+		value, err := getPipelineSettingOrDefault(pipelineSettingLister, projectID, name)
+		if err != nil {
+			return nil, err
+		}
+		if *d, err = time.ParseDuration(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid pipeline setting %s", name)
+		}
+	}
+	sizes := map[string]*int64{
+		SettingCacheSizeLimit:    &result.CacheSizeLimit,
+		SettingArtifactSizeLimit: &result.ArtifactSizeLimit,
+	}
+	for name, size := range sizes {
+		value, err := getPipelineSettingOrDefault(pipelineSettingLister, projectID, name)
+		if err != nil {
+			return nil, err
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pipeline setting %s", name)
+		}
+		*size = q.Value()
+	}
+	return result, nil
+}
+
+var storageSettingDefaults = map[string]string{
+	SettingCacheExpiry:       SettingCacheExpiryDefault,
+	SettingCacheSizeLimit:    SettingCacheSizeLimitDefault,
+	SettingArtifactExpiry:    SettingArtifactExpiryDefault,
+	SettingArtifactSizeLimit: SettingArtifactSizeLimitDefault,
+}
+
+func getPipelineSettingOrDefault(pipelineSettingLister v3.PipelineSettingLister, projectID string, name string) (string, error) {
+	setting, err := pipelineSettingLister.Get(projectID, name)
+	if apierrors.IsNotFound(err) {
+		return storageSettingDefaults[name], nil
+	} else if err != nil {
+		return "", err
+	}
+	if setting.Value != "" {
+		return setting.Value, nil
+	}
+	if setting.Default != "" {
+		return setting.Default, nil
+	}
+	return storageSettingDefaults[name], nil
+}
+
+// GetExecutionURL returns the link to the page showing the stages and logs of an execution
+func GetExecutionURL(execution *v3.PipelineExecution) string {
+	return fmt.Sprintf("%s/p/%s/pipeline/pipelines/%s/run/%d",
+		settings.ServerURL.Get(),
+		execution.Spec.ProjectName,
+		execution.Spec.PipelineName,
+		execution.Spec.Run,
+	)
+}
+
 func GetEnvVarMap(execution *v3.PipelineExecution) map[string]string {
 
 	m := map[string]string{}
@@ -191,6 +332,10 @@ func GetEnvVarMap(execution *v3.PipelineExecution) map[string]string {
 		localRegistry = "127.0.0.1:" + execution.Annotations[LocalRegistryPortLabel]
 	}
 
+	//extra variables of the execution do not override the built-in ones
+	for k, v := range execution.Spec.Env {
+		m[k] = v
+	}
 	m[EnvGitCommit] = commit
 	m[EnvGitRepoName] = repoName
 	m[EnvGitRef] = execution.Spec.Ref
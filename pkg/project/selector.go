@@ -0,0 +1,81 @@
+package project
+
+import (
+	"fmt"
+
+	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var defaultProjectLabels = labels.Set(map[string]string{"authz.management.cattle.io/default-project": "true"})
+
+// SelectTargets returns the projects matched by the target selectors of a multi cluster app mapped to the first selector
+// matching them, as well as the project names in the order they were matched.
+func SelectTargets(clusterLister v3.ClusterLister, projectLister v3.ProjectLister, selectors []v32.TargetSelector) (map[string]*v32.TargetSelector, []string, error) {
+	var (
+		result = map[string]*v32.TargetSelector{}
+		order  []string
+	)
+	if len(selectors) == 0 {
+		return result, order, nil
+	}
+
+	clusters, err := clusterLister.List("", labels.Everything())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range selectors {
+		selector := &selectors[i]
+		clusterSelector, projectSelector, err := TargetSelectorLabels(selector)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, cluster := range clusters {
+			if cluster.DeletionTimestamp != nil || !clusterSelector.Matches(labels.Set(cluster.Labels)) {
+				continue
+			}
+			projects, err := projectLister.List(cluster.Name, projectSelector)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, project := range projects {
+				if project.DeletionTimestamp != nil {
+					continue
+				}
+				projectName := fmt.Sprintf("%s:%s", cluster.Name, project.Name)
+				if _, ok := result[projectName]; ok {
+					continue
+				}
+				result[projectName] = selector
+				order = append(order, projectName)
+			}
+		}
+	}
+
+	return result, order, nil
+}
+
+// TargetSelectorLabels returns the cluster and project label selectors of a target selector. All clusters are selected
+// if the cluster selector is empty, and the Default project of each cluster if the project selector is empty.
+func TargetSelectorLabels(selector *v32.TargetSelector) (labels.Selector, labels.Selector, error) {
+	clusterSelector, err := toSelector(selector.ClusterSelector, labels.Everything())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid cluster selector %s: %v", selector.Name, err)
+	}
+	projectSelector, err := toSelector(selector.ProjectSelector, defaultProjectLabels.AsSelector())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid project selector %s: %v", selector.Name, err)
+	}
+	return clusterSelector, projectSelector, nil
+}
+
+func toSelector(selector *metav1.LabelSelector, defaultSelector labels.Selector) (labels.Selector, error) {
+	if selector == nil {
+		return defaultSelector, nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
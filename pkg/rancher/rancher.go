@@ -18,6 +18,7 @@ import (
 	crds "github.com/rancher/rancher/pkg/crds/dashboard"
 	dashboarddata "github.com/rancher/rancher/pkg/data/dashboard"
 	"github.com/rancher/rancher/pkg/features"
+	"github.com/rancher/rancher/pkg/maintenance"
 	"github.com/rancher/rancher/pkg/multiclustermanager"
 	"github.com/rancher/rancher/pkg/tls"
 	"github.com/rancher/rancher/pkg/ui"
@@ -134,6 +135,8 @@ func New(ctx context.Context, clientConfg clientcmd.ClientConfig, opts *Options)
 		wranglerContext.MultiClusterManager,
 		wranglerContext.Mgmt.Cluster().Cache(),
 		localClusterEnabled(opts),
+		!opts.Agent,
+		wranglerContext.Core.Secret().Cache(),
 		steve,
 	)
 	if err != nil {
@@ -155,6 +158,7 @@ func New(ctx context.Context, clientConfg clientcmd.ClientConfig, opts *Options)
 			responsewriter.ContentTypeOptions,
 			websocket.NewWebsocketHandler,
 			proxy.RewriteLocalCluster,
+			maintenance.NewMiddleware,
 			clusterProxy,
 			wranglerContext.MultiClusterManager.Middleware,
 			authServer.Management,
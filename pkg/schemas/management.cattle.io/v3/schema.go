@@ -235,6 +235,7 @@ func clusterTypes(schemas *types.Schemas) *types.Schemas {
 		).
 		MustImport(&Version, v3.Cluster{}).
 		MustImport(&Version, v3.ClusterRegistrationToken{}).
+		MustImport(&Version, v3.GenerateKubeConfigInput{}).
 		MustImport(&Version, v3.GenerateKubeConfigOutput{}).
 		MustImport(&Version, v3.ImportClusterYamlInput{}).
 		MustImport(&Version, v3.RotateCertificateInput{}).
@@ -268,8 +269,15 @@ func clusterTypes(schemas *types.Schemas) *types.Schemas {
 				return field
 			})
 			schema.ResourceActions[v3.ClusterActionGenerateKubeconfig] = types.Action{
+				Input:  "generateKubeConfigInput",
 				Output: "generateKubeConfigOutput",
 			}
+			schema.CollectionActions = map[string]types.Action{
+				v3.ClusterActionGenerateKubeconfig: {
+					Input:  "generateKubeConfigInput",
+					Output: "generateKubeConfigOutput",
+				},
+			}
 			schema.ResourceActions[v3.ClusterActionImportYaml] = types.Action{
 				Input:  "importClusterYamlInput",
 				Output: "importYamlOutput",
@@ -291,6 +299,7 @@ func clusterTypes(schemas *types.Schemas) *types.Schemas {
 			schema.ResourceActions[v3.ClusterActionRestoreFromEtcdBackup] = types.Action{
 				Input: "restoreFromEtcdBackupInput",
 			}
+			schema.ResourceActions[v3.ClusterActionResume] = types.Action{}
 			schema.ResourceActions[v3.ClusterActionRotateCertificates] = types.Action{
 				Input:  "rotateCertificateInput",
 				Output: "rotateCertificateOutput",
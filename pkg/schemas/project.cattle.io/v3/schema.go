@@ -861,6 +861,8 @@ func pipelineTypes(schema *types.Schemas) *types.Schemas {
 		MustImport(&Version, v3.BitbucketServerApplyInput{}).
 		MustImport(&Version, v3.BitbucketServerRequestLoginInput{}).
 		MustImport(&Version, v3.BitbucketServerRequestLoginOutput{}).
+		MustImport(&Version, v3.GiteaApplyInput{}).
+		MustImport(&Version, v3.GitApplyInput{}).
 		MustImportAndCustomize(&Version, v3.SourceCodeProvider{}, func(schema *types.Schema) {
 			schema.CollectionMethods = []string{http.MethodGet}
 		}).
@@ -881,6 +883,17 @@ func pipelineTypes(schema *types.Schemas) *types.Schemas {
 			schema.CollectionMethods = []string{}
 			schema.ResourceMethods = []string{http.MethodGet}
 		}).
+		MustImportAndCustomize(&Version, v3.GiteaProvider{}, baseProviderCustomizeFunc).
+		MustImportAndCustomize(&Version, v3.GitProvider{}, func(schema *types.Schema) {
+			schema.BaseType = "sourceCodeProvider"
+			schema.ResourceActions = map[string]types.Action{
+				"login": {
+					Output: "sourceCodeCredential",
+				},
+			}
+			schema.CollectionMethods = []string{}
+			schema.ResourceMethods = []string{http.MethodGet}
+		}).
 		//Github Integration Config
 		MustImportAndCustomize(&Version, v3.SourceCodeProviderConfig{}, func(schema *types.Schema) {
 			schema.CollectionMethods = []string{http.MethodGet}
@@ -933,6 +946,29 @@ func pipelineTypes(schema *types.Schemas) *types.Schemas {
 		schema.CollectionMethods = []string{}
 		schema.ResourceMethods = []string{http.MethodGet, http.MethodPut}
 	}).
+		MustImportAndCustomize(&Version, v3.GiteaPipelineConfig{}, func(schema *types.Schema) {
+			schema.BaseType = "sourceCodeProviderConfig"
+			schema.ResourceActions = map[string]types.Action{
+				"disable": {},
+				"testAndApply": {
+					Input: "giteaApplyInput",
+				},
+			}
+			schema.CollectionMethods = []string{}
+			schema.ResourceMethods = []string{http.MethodGet, http.MethodPut}
+		}).
+		MustImportAndCustomize(&Version, v3.GitPipelineConfig{}, func(schema *types.Schema) {
+			schema.BaseType = "sourceCodeProviderConfig"
+			schema.ResourceActions = map[string]types.Action{
+				"disable":      {},
+				"generateKeys": {},
+				"testAndApply": {
+					Input: "gitApplyInput",
+				},
+			}
+			schema.CollectionMethods = []string{}
+			schema.ResourceMethods = []string{http.MethodGet, http.MethodPut}
+		}).
 		MustImportAndCustomize(&Version, v3.Pipeline{}, func(schema *types.Schema) {
 			schema.ResourceActions = map[string]types.Action{
 				"activate":   {},
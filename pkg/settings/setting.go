@@ -20,6 +20,8 @@ var (
 	InjectDefaults string
 
 	AgentImage                        = NewSetting("agent-image", "rancher/rancher-agent:master-head")
+	AgentTunnelCredentialRequired     = NewSetting("agent-tunnel-credential-required", "false")   // enable once all the agents are upgraded to exchange their registration token, until then a registration token opens a tunnel
+	AgentTunnelCredentialTTLMinutes   = NewSetting("agent-tunnel-credential-ttl-minutes", "1440") // 24 hours
 	AuthImage                         = NewSetting("auth-image", v32.ToolsSystemImages.AuthSystemImages.KubeAPIAuth)
 	AuthTokenMaxTTLMinutes            = NewSetting("auth-token-max-ttl-minutes", "0") // never expire
 	AuthorizationCacheTTLSeconds      = NewSetting("authorization-cache-ttl-seconds", "10")
@@ -29,6 +31,7 @@ var (
 	CLIURLDarwin                      = NewSetting("cli-url-darwin", "https://releases.rancher.com/cli/v1.0.0-alpha8/rancher-darwin-amd64-v1.0.0-alpha8.tar.gz")
 	CLIURLLinux                       = NewSetting("cli-url-linux", "https://releases.rancher.com/cli/v1.0.0-alpha8/rancher-linux-amd64-v1.0.0-alpha8.tar.gz")
 	CLIURLWindows                     = NewSetting("cli-url-windows", "https://releases.rancher.com/cli/v1.0.0-alpha8/rancher-windows-386-v1.0.0-alpha8.zip")
+	ClusterConnectProxy               = NewSetting("cluster-connect-proxy", "")
 	ClusterControllerStartCount       = NewSetting("cluster-controller-start-count", "50")
 	EngineInstallURL                  = NewSetting("engine-install-url", "https://releases.rancher.com/install-docker/20.10.sh")
 	EngineISOURL                      = NewSetting("engine-iso-url", "https://releases.rancher.com/os/latest/rancheros-vmware.iso")
@@ -42,14 +45,23 @@ var (
 	IngressIPDomain                   = NewSetting("ingress-ip-domain", "xip.io")
 	InstallUUID                       = NewSetting("install-uuid", "")
 	JailerTimeout                     = NewSetting("jailer-timeout", "60")
+	KubeconfigClusterTokenTTLMinutes  = NewSetting("kubeconfig-cluster-token-ttl-minutes", "") // per cluster kubeconfig token ttls, JSON
 	KubeconfigGenerateToken           = NewSetting("kubeconfig-generate-token", "true")
 	KubeconfigTokenTTLMinutes         = NewSetting("kubeconfig-token-ttl-minutes", "960") // 16 hours
+	K8sProxyClusterBurst              = NewSetting("k8s-proxy-cluster-burst", "200")
+	K8sProxyClusterQPS                = NewSetting("k8s-proxy-cluster-qps", "0") // no limit
+	K8sProxyRateLimits                = NewSetting("k8s-proxy-rate-limits", "")  // per lane and per cluster overrides, JSON
+	K8sProxyUserBurst                 = NewSetting("k8s-proxy-user-burst", "50")
+	K8sProxyUserQPS                   = NewSetting("k8s-proxy-user-qps", "0") // no limit
 	KubernetesVersion                 = NewSetting("k8s-version", "")
 	KubernetesVersionToServiceOptions = NewSetting("k8s-version-to-service-options", "")
 	KubernetesVersionToSystemImages   = NewSetting("k8s-version-to-images", "")
 	KubernetesVersionsCurrent         = NewSetting("k8s-versions-current", "")
 	KubernetesVersionsDeprecated      = NewSetting("k8s-versions-deprecated", "")
 	MachineVersion                    = NewSetting("machine-version", "dev")
+	MaintenanceMode                   = NewSetting("maintenance-mode", "false")
+	MaintenanceModeAllowedGroups      = NewSetting("maintenance-mode-allowed-groups", "") // comma separated groups whose members can make changes in maintenance mode
+	MaintenanceModeMessage            = NewSetting("maintenance-mode-message", "Rancher is in maintenance mode, changes are not allowed")
 	Namespace                         = NewSetting("namespace", os.Getenv("CATTLE_NAMESPACE"))
 	PeerServices                      = NewSetting("peer-service", os.Getenv("CATTLE_PEER_SERVICE"))
 	RDNSServerBaseURL                 = NewSetting("rdns-base-url", "https://api.lb.rancher.cloud/v1")
@@ -58,6 +70,13 @@ var (
 	ServerImage                       = NewSetting("server-image", "rancher/rancher")
 	ServerURL                         = NewSetting("server-url", "")
 	ServerVersion                     = NewSetting("server-version", "dev")
+	ShellRecordingClusters            = NewSetting("shell-recording-clusters", "")                              // comma separated clusters whose shell sessions are always recorded, * for all clusters
+	ShellRecordingPath                = NewSetting("shell-recording-path", "management-state/shell-recordings") // must be a volume shared by the rancher replicas when running more than one
+	ShellRecordingS3Bucket            = NewSetting("shell-recording-s3-bucket", "")
+	ShellRecordingS3Credentials       = NewSetting("shell-recording-s3-credentials", "") // secret in cattle-system with the accessKey and secretKey, IAM roles are used when empty
+	ShellRecordingS3Endpoint          = NewSetting("shell-recording-s3-endpoint", "")
+	ShellRecordingS3Region            = NewSetting("shell-recording-s3-region", "")
+	ShellRecordingStore               = NewSetting("shell-recording-store", "file") // file or s3
 	SystemDefaultRegistry             = NewSetting("system-default-registry", "")
 	SystemNamespaces                  = NewSetting("system-namespaces", "kube-system,kube-public,cattle-system,cattle-alerting,cattle-logging,cattle-pipeline,cattle-prometheus,ingress-nginx,cattle-global-data,cattle-istio,kube-node-lease,cert-manager,cattle-global-nt,security-scan,fleet-system")
 	TelemetryOpt                      = NewSetting("telemetry-opt", "")
@@ -35,6 +35,9 @@ const (
 	rancherCertFile    = "/etc/rancher/ssl/cert.pem"
 	rancherKeyFile     = "/etc/rancher/ssl/key.pem"
 	rancherCACertsFile = "/etc/rancher/ssl/cacerts.pem"
+
+	// InternalCAName is the secret of the CA signing the certificate of the internal listener
+	InternalCAName = "tls-rancher-internal-ca"
 )
 
 func ListenAndServe(ctx context.Context, restConfig *rest.Config, handler http.Handler, bindHost string, httpsPort, httpPort int, acmeDomains []string, noCACerts bool) error {
@@ -81,15 +84,12 @@ func ListenAndServe(ctx context.Context, restConfig *rest.Config, handler http.H
 		return errors.Wrap(err, "failed to ListenAndServe")
 	}
 
-	internalPort := 0
-	if httpsPort != 0 {
-		internalPort = httpsPort + 1
-	}
+	internalPort := InternalPort(httpsPort)
 
 	serverOptions := &server.ListenOpts{
 		Storage:       opts.Storage,
 		Secrets:       opts.Secrets,
-		CAName:        "tls-rancher-internal-ca",
+		CAName:        InternalCAName,
 		CANamespace:   "cattle-system",
 		CertNamespace: "cattle-system",
 		CertName:      "tls-rancher-internal",
@@ -117,6 +117,14 @@ func ListenAndServe(ctx context.Context, restConfig *rest.Config, handler http.H
 
 }
 
+// InternalPort returns the port of the internal listener, its certificate is signed by the internal CA
+func InternalPort(httpsPort int) int {
+	if httpsPort == 0 {
+		return 0
+	}
+	return httpsPort + 1
+}
+
 func migrateConfig(ctx context.Context, restConfig *rest.Config, opts *server.ListenOpts) {
 	c, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
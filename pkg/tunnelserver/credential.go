@@ -0,0 +1,288 @@
+package tunnelserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/namespace"
+	"github.com/rancher/rancher/pkg/settings"
+	"github.com/rancher/rancher/pkg/types/config"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Agents exchange the cluster registration token for a short-lived credential bound to their cluster, and to their
+// node for node agents, and renew it with the credential itself before it expires. Credentials are signed with a key
+// shared by the rancher servers and can be revoked by setting CredentialsRevokedAnnotation on the cluster, which also
+// closes the tunnels opened before that time. The registration token of an agent can only be exchanged once, the time
+// of the exchange is recorded on its node or cluster; it can be exchanged again once the credentials are revoked. The
+// agents keep their credential in a secret of their cluster across restarts.
+//
+// The registration token still opens a tunnel until agent-tunnel-credential-required is enabled. It should be enabled
+// once all the agents of the clusters are upgraded, the agents which did not exchange their registration token are
+// rejected from then on.
+
+const (
+	// CredentialsRevokedAnnotation rejects the agent credentials of a cluster issued before its RFC3339 time
+	CredentialsRevokedAnnotation = "tunnelserver.cattle.io/credentials-revoked-before"
+	// CredentialExchangedAnnotation is the RFC3339 time the registration token of the agent of a node or cluster was
+	// exchanged for a credential
+	CredentialExchangedAnnotation = "tunnelserver.cattle.io/credential-exchanged"
+
+	credentialPrefix  = "tc1."
+	signingKeySecret  = "tunnel-credential-signing-key"
+	signingKeyDataKey = "key"
+)
+
+var (
+	errInvalidCredential   = errors.New("invalid agent credential")
+	errCredentialExchanged = errors.New("registration token was already exchanged for a credential")
+)
+
+type credential struct {
+	Cluster   string `json:"cluster"`
+	Node      string `json:"node,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (c *credential) issuedAt() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	return time.Unix(c.IssuedAt, 0)
+}
+
+// CredentialResponse is the credential issued to an agent
+type CredentialResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func isCredential(token string) bool {
+	return strings.HasPrefix(token, credentialPrefix)
+}
+
+// issueCredential signs a credential for the agent of a cluster, or of a node of the cluster
+func (t *Authorizer) issueCredential(clusterName, nodeName string) (*CredentialResponse, error) {
+	key, err := t.getSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(settings.AgentTunnelCredentialTTLMinutes.GetInt()) * time.Minute)
+	payload, err := json.Marshal(credential{
+		Cluster:   clusterName,
+		Node:      nodeName,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return &CredentialResponse{
+		Token:     credentialPrefix + encoded + "." + sign(key, encoded),
+		ExpiresAt: expiresAt.UTC(),
+	}, nil
+}
+
+// verifyCredential returns the cluster of a credential that is correctly signed, not expired and not revoked
+func (t *Authorizer) verifyCredential(token string) (*v3.Cluster, *credential, error) {
+	parts := strings.Split(strings.TrimPrefix(token, credentialPrefix), ".")
+	if len(parts) != 2 {
+		return nil, nil, errInvalidCredential
+	}
+
+	key, err := t.getSigningKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !hmac.Equal([]byte(sign(key, parts[0])), []byte(parts[1])) {
+		return nil, nil, errInvalidCredential
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, errInvalidCredential
+	}
+	cred := &credential{}
+	if err := json.Unmarshal(payload, cred); err != nil {
+		return nil, nil, errInvalidCredential
+	}
+	if time.Now().Unix() >= cred.ExpiresAt {
+		return nil, nil, errors.New("agent credential expired")
+	}
+
+	cluster, err := t.clusterLister.Get("", cred.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	if revokedBefore, ok := credentialsRevokedBefore(cluster); ok && cred.IssuedAt < revokedBefore.Unix() {
+		return nil, nil, errors.New("agent credential revoked")
+	}
+
+	return cluster, cred, nil
+}
+
+// credentialsRevokedBefore returns the time the agent credentials of a cluster were revoked
+func credentialsRevokedBefore(cluster *v3.Cluster) (time.Time, bool) {
+	revoked, ok := cluster.Annotations[CredentialsRevokedAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	revokedBefore, err := time.Parse(time.RFC3339, revoked)
+	if err != nil {
+		logrus.Warnf("Invalid %s annotation on cluster %s: %v", CredentialsRevokedAnnotation, cluster.Name, err)
+		return time.Time{}, false
+	}
+	return revokedBefore, true
+}
+
+// recordExchange records the exchange of the registration token of a client, it fails if the token was already
+// exchanged since the credentials of the cluster were last revoked
+func (t *Authorizer) recordExchange(client *Client) error {
+	revokedBefore, _ := credentialsRevokedBefore(client.Cluster)
+	exchanged := func(annotations map[string]string) bool {
+		at, err := time.Parse(time.RFC3339, annotations[CredentialExchangedAnnotation])
+		return err == nil && !at.Before(revokedBefore)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if client.Node != nil {
+		if exchanged(client.Node.Annotations) {
+			return errCredentialExchanged
+		}
+		node := client.Node.DeepCopy()
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[CredentialExchangedAnnotation] = now
+		_, err := t.machines.Update(node)
+		return err
+	}
+
+	if exchanged(client.Cluster.Annotations) {
+		return errCredentialExchanged
+	}
+	cluster := client.Cluster.DeepCopy()
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[CredentialExchangedAnnotation] = now
+	_, err := t.clusters.Update(cluster)
+	return err
+}
+
+// RegisterCredentialRevocation closes the tunnels of a cluster opened before its credentials were revoked
+func RegisterCredentialRevocation(ctx context.Context, context *config.ScaledContext) {
+	context.Management.Clusters("").AddHandler(ctx, "tunnel-credential-revocation", func(key string, cluster *v3.Cluster) (runtime.Object, error) {
+		if cluster == nil {
+			return nil, nil
+		}
+		if revokedBefore, ok := credentialsRevokedBefore(cluster); ok {
+			Sessions.closeRevoked(cluster.Name, revokedBefore)
+		}
+		return cluster, nil
+	})
+}
+
+func sign(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// getSigningKey returns the key signing the agent credentials, it is created by the first server that needs it
+func (t *Authorizer) getSigningKey() ([]byte, error) {
+	secret, err := t.secretLister.Get(namespace.System, signingKeySecret)
+	if apierrors.IsNotFound(err) {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		secret, err = t.secrets.Create(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      signingKeySecret,
+				Namespace: namespace.System,
+			},
+			Data: map[string][]byte{
+				signingKeyDataKey: key,
+			},
+		})
+		if apierrors.IsAlreadyExists(err) {
+			secret, err = t.secrets.GetNamespaced(namespace.System, signingKeySecret, metav1.GetOptions{})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := secret.Data[signingKeyDataKey]
+	if len(key) == 0 {
+		return nil, errors.New("agent credential signing key is empty")
+	}
+	return key, nil
+}
+
+// NewCredentialHandler exchanges the registration token, once, or the current credential of an agent for a new
+// credential
+func NewCredentialHandler(auth *Authorizer) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		client, ok, err := auth.Authorize(req)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte(err.Error()))
+			return
+		}
+		if !ok || client == nil || client.Cluster == nil {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if !isCredential(client.Token) {
+			if err := auth.recordExchange(client); err == errCredentialExchanged {
+				rw.WriteHeader(http.StatusForbidden)
+				rw.Write([]byte(err.Error()))
+				return
+			} else if err != nil {
+				logrus.Errorf("Failed to record the agent credential exchange for cluster %s: %v", client.Cluster.Name, err)
+				rw.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		nodeName := ""
+		if client.Node != nil {
+			nodeName = client.Node.Name
+		}
+		cred, err := auth.issueCredential(client.Cluster.Name, nodeName)
+		if err != nil {
+			logrus.Errorf("Failed to issue agent credential for cluster %s: %v", client.Cluster.Name, err)
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(cred)
+	})
+}
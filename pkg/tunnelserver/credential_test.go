@@ -0,0 +1,85 @@
+package tunnelserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher/rancher/pkg/generated/norman/core/v1/fakes"
+	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	mgmtfakes "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3/fakes"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newTestAuthorizer(cluster *v3.Cluster) *Authorizer {
+	var secret *corev1.Secret
+	return &Authorizer{
+		clusterLister: &mgmtfakes.ClusterListerMock{
+			GetFunc: func(namespace, name string) (*v3.Cluster, error) {
+				if name != cluster.Name {
+					return nil, apierrors.NewNotFound(schema.GroupResource{}, name)
+				}
+				return cluster, nil
+			},
+		},
+		secretLister: &fakes.SecretListerMock{
+			GetFunc: func(namespace, name string) (*corev1.Secret, error) {
+				if secret == nil {
+					return nil, apierrors.NewNotFound(schema.GroupResource{}, name)
+				}
+				return secret, nil
+			},
+		},
+		secrets: &fakes.SecretInterfaceMock{
+			CreateFunc: func(in *corev1.Secret) (*corev1.Secret, error) {
+				secret = in
+				return in, nil
+			},
+		},
+	}
+}
+
+func TestCredential(t *testing.T) {
+	cluster := &v3.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c-1"}}
+	auth := newTestAuthorizer(cluster)
+
+	cred, err := auth.issueCredential("c-1", "m-1")
+	assert.Nil(t, err)
+	assert.True(t, isCredential(cred.Token))
+	assert.True(t, cred.ExpiresAt.After(time.Now()))
+
+	verified, c, err := auth.verifyCredential(cred.Token)
+	assert.Nil(t, err)
+	assert.Equal(t, cluster, verified)
+	assert.Equal(t, "m-1", c.Node)
+
+	_, _, err = auth.verifyCredential(cred.Token[:len(cred.Token)-2] + "xx")
+	assert.Equal(t, errInvalidCredential, err)
+
+	cluster.Annotations = map[string]string{
+		CredentialsRevokedAnnotation: time.Now().Add(time.Minute).UTC().Format(time.RFC3339),
+	}
+	_, _, err = auth.verifyCredential(cred.Token)
+	assert.EqualError(t, err, "agent credential revoked")
+}
+
+func TestRecordExchange(t *testing.T) {
+	cluster := &v3.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c-1"}}
+	auth := newTestAuthorizer(cluster)
+	auth.clusters = &mgmtfakes.ClusterInterfaceMock{
+		UpdateFunc: func(in *v3.Cluster) (*v3.Cluster, error) {
+			*cluster = *in
+			return in, nil
+		},
+	}
+
+	assert.Nil(t, auth.recordExchange(&Client{Cluster: cluster}))
+	assert.NotEmpty(t, cluster.Annotations[CredentialExchangedAnnotation])
+	assert.Equal(t, errCredentialExchanged, auth.recordExchange(&Client{Cluster: cluster}))
+
+	cluster.Annotations[CredentialsRevokedAnnotation] = time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	assert.Nil(t, auth.recordExchange(&Client{Cluster: cluster}))
+}
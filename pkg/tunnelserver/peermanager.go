@@ -130,6 +130,7 @@ func (p *peerManager) notify() {
 	for id := range p.peers {
 		peers.IDs = append(peers.IDs, id)
 	}
+	Sessions.setPeers(peers.IDs)
 
 	for c := range p.listeners {
 		c <- peers
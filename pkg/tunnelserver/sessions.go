@@ -0,0 +1,538 @@
+package tunnelserver
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rancher/rancher/pkg/auth/util"
+	v1 "github.com/rancher/rancher/pkg/generated/norman/core/v1"
+	"github.com/rancher/rancher/pkg/namespace"
+	rancherTLS "github.com/rancher/rancher/pkg/tls"
+	"github.com/rancher/remotedialer"
+	"github.com/sirupsen/logrus"
+	authV1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The agent tunnels served by this server are tracked from their authorization until the remotedialer server stops
+// serving them. Their traffic is counted on the hijacked connection of the websocket. The reconnects and dial errors
+// of a client are kept for statsRetention after its last session is removed. The sessions API aggregates the sessions
+// of all the replicas, the peers are asked for their own sessions with the peer token of the tunnel server on their
+// internal listener, whose certificate is verified against the internal CA before the token is sent.
+
+const (
+	statsRetention     = time.Hour
+	peerSessionTimeout = 5 * time.Second
+)
+
+var (
+	sessionMetrics = false
+
+	activeSessions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "tunnel_server",
+			Name:      "sessions",
+			Help:      "Number of agent tunnel sessions connected to this server",
+		},
+		[]string{"cluster"},
+	)
+
+	sessionConnects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "tunnel_server",
+			Name:      "session_connects_total",
+			Help:      "Number of agent tunnel sessions connected to this server",
+		},
+		[]string{"cluster"},
+	)
+
+	receivedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "tunnel_server",
+			Name:      "received_bytes_total",
+			Help:      "Bytes received from the agent tunnel sessions",
+		},
+		[]string{"cluster"},
+	)
+
+	transmittedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "tunnel_server",
+			Name:      "transmitted_bytes_total",
+			Help:      "Bytes transmitted to the agent tunnel sessions",
+		},
+		[]string{"cluster"},
+	)
+
+	dialErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "tunnel_server",
+			Name:      "dial_errors_total",
+			Help:      "Number of failed dials to a cluster",
+		},
+		[]string{"cluster"},
+	)
+
+	// Sessions tracks the agent tunnel sessions of this server
+	Sessions = newSessionTracker()
+)
+
+// RegisterMetrics registers the metrics of the agent tunnel sessions
+func RegisterMetrics() {
+	sessionMetrics = true
+
+	prometheus.MustRegister(activeSessions)
+	prometheus.MustRegister(sessionConnects)
+	prometheus.MustRegister(receivedBytes)
+	prometheus.MustRegister(transmittedBytes)
+	prometheus.MustRegister(dialErrors)
+}
+
+// Session is an agent tunnel connected to this server
+type Session struct {
+	ClientKey        string    `json:"clientKey"`
+	Cluster          string    `json:"cluster"`
+	Node             string    `json:"node,omitempty"`
+	Replica          string    `json:"replica,omitempty"`
+	RemoteAddr       string    `json:"remoteAddr"`
+	Connected        time.Time `json:"connected"`
+	ReceivedBytes    int64     `json:"receivedBytes"`
+	TransmittedBytes int64     `json:"transmittedBytes"`
+	Reconnects       int64     `json:"reconnects"`
+	DialErrors       int64     `json:"dialErrors"`
+
+	received    int64
+	transmitted int64
+	issuedAt    time.Time
+	conn        net.Conn
+}
+
+// SessionList is the response of the sessions API
+type SessionList struct {
+	Replica          string    `json:"replica,omitempty"`
+	Peers            []string  `json:"peers,omitempty"`
+	UnreachablePeers []string  `json:"unreachablePeers,omitempty"`
+	Sessions         []Session `json:"sessions"`
+}
+
+type counter struct {
+	value   int64
+	updated time.Time
+}
+
+type sessionTracker struct {
+	sync.Mutex
+	sessions   map[*http.Request]*Session
+	connects   map[string]*counter
+	dialErrors map[string]*counter
+	peers      []string
+}
+
+func newSessionTracker() *sessionTracker {
+	return &sessionTracker{
+		sessions:   map[*http.Request]*Session{},
+		connects:   map[string]*counter{},
+		dialErrors: map[string]*counter{},
+	}
+}
+
+func increment(counters map[string]*counter, key string) {
+	c, ok := counters[key]
+	if !ok {
+		c = &counter{}
+		counters[key] = c
+	}
+	c.value++
+	c.updated = time.Now()
+}
+
+func value(counters map[string]*counter, key string) int64 {
+	if c, ok := counters[key]; ok {
+		return c.value
+	}
+	return 0
+}
+
+// authorized starts tracking the session of a request whose tunnel was authorized with a credential issued at a time,
+// zero for registration tokens
+func (t *sessionTracker) authorized(req *http.Request, clientKey string, issuedAt time.Time) {
+	cluster, node := clientKey, ""
+	if i := strings.Index(clientKey, ":"); i >= 0 {
+		cluster, node = clientKey[:i], clientKey[i+1:]
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	increment(t.connects, clientKey)
+	t.sessions[req] = &Session{
+		ClientKey:  clientKey,
+		Cluster:    cluster,
+		Node:       node,
+		RemoteAddr: req.RemoteAddr,
+		Connected:  time.Now().UTC(),
+		issuedAt:   issuedAt,
+	}
+	if sessionMetrics {
+		activeSessions.WithLabelValues(cluster).Inc()
+		sessionConnects.WithLabelValues(cluster).Inc()
+	}
+}
+
+// hijacked counts the traffic of the connection of a session, it is closed when the session is revoked
+func (t *sessionTracker) hijacked(req *http.Request, conn net.Conn) net.Conn {
+	t.Lock()
+	defer t.Unlock()
+
+	session, ok := t.sessions[req]
+	if !ok {
+		return conn
+	}
+	session.conn = &countingConn{Conn: conn, session: session}
+	return session.conn
+}
+
+// closeRevoked closes the sessions of a cluster authorized with a credential issued before a time or with a
+// registration token
+func (t *sessionTracker) closeRevoked(cluster string, revokedBefore time.Time) {
+	var conns []net.Conn
+	t.Lock()
+	for _, session := range t.sessions {
+		if session.Cluster == cluster && session.conn != nil && session.issuedAt.Before(revokedBefore) {
+			conns = append(conns, session.conn)
+		}
+	}
+	t.Unlock()
+
+	for _, conn := range conns {
+		logrus.Infof("Closing tunnel session of cluster %s, its credentials were revoked", cluster)
+		conn.Close()
+	}
+}
+
+func (t *sessionTracker) remove(req *http.Request) {
+	t.Lock()
+	defer t.Unlock()
+
+	session, ok := t.sessions[req]
+	if !ok {
+		return
+	}
+	delete(t.sessions, req)
+	if sessionMetrics {
+		activeSessions.WithLabelValues(session.Cluster).Dec()
+	}
+	t.prune(time.Now().Add(-statsRetention))
+}
+
+// prune drops the counters of the clients and clusters without sessions that were not updated since a time
+func (t *sessionTracker) prune(since time.Time) {
+	clientKeys := map[string]bool{}
+	clusters := map[string]bool{}
+	for _, session := range t.sessions {
+		clientKeys[session.ClientKey] = true
+		clusters[session.Cluster] = true
+	}
+	for clientKey, c := range t.connects {
+		if !clientKeys[clientKey] && c.updated.Before(since) {
+			delete(t.connects, clientKey)
+		}
+	}
+	for cluster, c := range t.dialErrors {
+		if !clusters[cluster] && c.updated.Before(since) {
+			delete(t.dialErrors, cluster)
+		}
+	}
+}
+
+// DialError records a failed dial to a cluster
+func (t *sessionTracker) DialError(cluster string) {
+	t.Lock()
+	defer t.Unlock()
+
+	increment(t.dialErrors, cluster)
+	if sessionMetrics {
+		dialErrors.WithLabelValues(cluster).Inc()
+	}
+}
+
+func (t *sessionTracker) setPeers(peers []string) {
+	t.Lock()
+	defer t.Unlock()
+	t.peers = peers
+}
+
+func (t *sessionTracker) getPeers() []string {
+	t.Lock()
+	defer t.Unlock()
+	return append([]string(nil), t.peers...)
+}
+
+func (t *sessionTracker) isPeer(id string) bool {
+	for _, peer := range t.getPeers() {
+		if peer == id {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the sessions of this server, all of them when cluster is empty
+func (t *sessionTracker) List(replica, cluster string) SessionList {
+	t.Lock()
+	defer t.Unlock()
+
+	list := SessionList{
+		Replica:  replica,
+		Peers:    append([]string(nil), t.peers...),
+		Sessions: []Session{},
+	}
+	for _, session := range t.sessions {
+		if cluster != "" && session.Cluster != cluster {
+			continue
+		}
+		s := *session
+		s.Replica = replica
+		s.ReceivedBytes = atomic.LoadInt64(&session.received)
+		s.TransmittedBytes = atomic.LoadInt64(&session.transmitted)
+		s.Reconnects = value(t.connects, session.ClientKey) - 1
+		s.DialErrors = value(t.dialErrors, session.Cluster)
+		list.Sessions = append(list.Sessions, s)
+	}
+	sortSessions(list.Sessions)
+	return list
+}
+
+func sortSessions(sessions []Session) {
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].ClientKey != sessions[j].ClientKey {
+			return sessions[i].ClientKey < sessions[j].ClientKey
+		}
+		return sessions[i].Replica < sessions[j].Replica
+	})
+}
+
+// NewSessionHandler serves the tunnel server, tracking the sessions it serves
+func NewSessionHandler(server *remotedialer.Server) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		defer Sessions.remove(req)
+		server.ServeHTTP(&countingResponseWriter{ResponseWriter: rw, req: req}, req)
+	})
+}
+
+type countingResponseWriter struct {
+	http.ResponseWriter
+	req *http.Request
+}
+
+func (w *countingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	return Sessions.hijacked(w.req, conn), brw, nil
+}
+
+type countingConn struct {
+	net.Conn
+	session *Session
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddInt64(&c.session.received, int64(n))
+	if sessionMetrics && n > 0 {
+		receivedBytes.WithLabelValues(c.session.Cluster).Add(float64(n))
+	}
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddInt64(&c.session.transmitted, int64(n))
+	if sessionMetrics && n > 0 {
+		transmittedBytes.WithLabelValues(c.session.Cluster).Add(float64(n))
+	}
+	return n, err
+}
+
+type sessionsHandler struct {
+	server     *remotedialer.Server
+	k8sClient  kubernetes.Interface
+	secrets    v1.SecretLister
+	peerPort   int
+	peerClient *http.Client
+}
+
+// NewSessionsAPIHandler serves the read-only list of the agent tunnel sessions of all the replicas, the peers are
+// reached on the internal port of the listener serving httpsPort
+func NewSessionsAPIHandler(server *remotedialer.Server, k8sClient kubernetes.Interface, secrets v1.SecretLister, httpsPort int) http.Handler {
+	h := &sessionsHandler{
+		server:    server,
+		k8sClient: k8sClient,
+		secrets:   secrets,
+		peerPort:  rancherTLS.InternalPort(httpsPort),
+	}
+	h.peerClient = &http.Client{
+		Timeout: peerSessionTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				// the peers are addressed by IP, which their certificate does not name, the certificate is verified
+				// against the internal CA by verifyPeer instead
+				InsecureSkipVerify:    true,
+				VerifyPeerCertificate: h.verifyPeer,
+			},
+		},
+	}
+	return h
+}
+
+// verifyPeer verifies that the certificate of a peer is signed by the internal CA, only the rancher servers have it
+func (h *sessionsHandler) verifyPeer(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return errors.New("peer did not present a certificate")
+	}
+	secret, err := h.secrets.Get(namespace.System, rancherTLS.InternalCAName)
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(secret.Data[corev1.TLSCertKey]) {
+		return errors.New("invalid internal CA")
+	}
+
+	var certs []*x509.Certificate
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+func (h *sessionsHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var reqGroup []string
+	if g, ok := req.Header["Impersonate-Group"]; ok {
+		reqGroup = g
+	}
+
+	review := authV1.SubjectAccessReview{
+		Spec: authV1.SubjectAccessReviewSpec{
+			User:   req.Header.Get("Impersonate-User"),
+			Groups: reqGroup,
+			ResourceAttributes: &authV1.ResourceAttributes{
+				Verb:     "get",
+				Resource: "tunnelsessions",
+				Group:    "management.cattle.io",
+			},
+		},
+	}
+
+	result, err := h.k8sClient.AuthorizationV1().SubjectAccessReviews().Create(req.Context(), &review, metav1.CreateOptions{})
+	if err != nil {
+		util.ReturnHTTPError(rw, req, 500, err.Error())
+		return
+	}
+
+	if !result.Status.Allowed {
+		util.ReturnHTTPError(rw, req, 403, "Forbidden")
+		return
+	}
+
+	cluster := req.URL.Query().Get("cluster")
+	list := Sessions.List(h.server.PeerID, cluster)
+	for _, peer := range list.Peers {
+		peerList, err := h.peerSessions(req, peer, cluster)
+		if err != nil {
+			logrus.Debugf("failed to list the tunnel sessions of peer %s: %v", peer, err)
+			list.UnreachablePeers = append(list.UnreachablePeers, peer)
+			continue
+		}
+		list.Sessions = append(list.Sessions, peerList.Sessions...)
+	}
+	sortSessions(list.Sessions)
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(list)
+}
+
+// peerSessions lists the sessions of a peer, authenticated with the peer token
+func (h *sessionsHandler) peerSessions(req *http.Request, peer, cluster string) (*SessionList, error) {
+	if h.peerPort == 0 {
+		return nil, errors.New("internal listener is disabled")
+	}
+	peerURL := url.URL{
+		Scheme:   "https",
+		Host:     net.JoinHostPort(peer, strconv.Itoa(h.peerPort)),
+		Path:     req.URL.Path,
+		RawQuery: url.Values{"cluster": []string{cluster}}.Encode(),
+	}
+	peerReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, peerURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	peerReq.Header.Set(remotedialer.ID, h.server.PeerID)
+	peerReq.Header.Set(remotedialer.Token, h.server.PeerToken)
+
+	resp, err := h.peerClient.Do(peerReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	list := &SessionList{}
+	return list, json.NewDecoder(resp.Body).Decode(list)
+}
+
+// IsPeerRequest matches the requests made by the peers of this server to list its sessions
+func IsPeerRequest(req *http.Request, _ *mux.RouteMatch) bool {
+	return req.Header.Get(remotedialer.ID) != ""
+}
+
+// NewPeerSessionsHandler serves the sessions of this server to its peers
+func NewPeerSessionsHandler(server *remotedialer.Server) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		token := req.Header.Get(remotedialer.Token)
+		if server.PeerToken == "" || !Sessions.isPeer(req.Header.Get(remotedialer.ID)) ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(server.PeerToken)) != 1 {
+			util.ReturnHTTPError(rw, req, 403, "Forbidden")
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(Sessions.List(server.PeerID, req.URL.Query().Get("cluster")))
+	})
+}
@@ -0,0 +1,187 @@
+package tunnelserver
+
+import (
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher/dynamiclistener/factory"
+	"github.com/rancher/rancher/pkg/generated/norman/core/v1/fakes"
+	"github.com/rancher/remotedialer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSessionTracker(t *testing.T) {
+	tracker := newSessionTracker()
+	first := httptest.NewRequest("GET", "/v3/connect", nil)
+	second := httptest.NewRequest("GET", "/v3/connect", nil)
+	node := httptest.NewRequest("GET", "/v3/connect", nil)
+
+	tracker.authorized(first, "c-1", time.Time{})
+	tracker.remove(first)
+	tracker.authorized(second, "c-1", time.Time{})
+	tracker.authorized(node, "c-2:m-1", time.Time{})
+	tracker.DialError("c-1")
+	tracker.setPeers([]string{"10.0.0.2"})
+
+	list := tracker.List("10.0.0.1", "")
+	assert.Equal(t, "10.0.0.1", list.Replica)
+	assert.Equal(t, []string{"10.0.0.2"}, list.Peers)
+	if assert.Len(t, list.Sessions, 2) {
+		assert.Equal(t, "c-1", list.Sessions[0].Cluster)
+		assert.Equal(t, int64(1), list.Sessions[0].Reconnects)
+		assert.Equal(t, int64(1), list.Sessions[0].DialErrors)
+		assert.Equal(t, "c-2", list.Sessions[1].Cluster)
+		assert.Equal(t, "m-1", list.Sessions[1].Node)
+		assert.Equal(t, int64(0), list.Sessions[1].Reconnects)
+	}
+
+	list = tracker.List("10.0.0.1", "c-2")
+	if assert.Len(t, list.Sessions, 1) {
+		assert.Equal(t, "c-2:m-1", list.Sessions[0].ClientKey)
+	}
+}
+
+func TestSessionTrackerPrune(t *testing.T) {
+	tracker := newSessionTracker()
+	active := httptest.NewRequest("GET", "/v3/connect", nil)
+	gone := httptest.NewRequest("GET", "/v3/connect", nil)
+
+	tracker.authorized(active, "c-1", time.Time{})
+	tracker.authorized(gone, "c-2", time.Time{})
+	tracker.DialError("c-1")
+	tracker.DialError("c-2")
+	tracker.remove(gone)
+	assert.Len(t, tracker.connects, 2)
+	assert.Len(t, tracker.dialErrors, 2)
+
+	tracker.prune(time.Now().Add(time.Minute))
+	assert.Equal(t, []string{"c-1"}, keys(tracker.connects))
+	assert.Equal(t, []string{"c-1"}, keys(tracker.dialErrors))
+}
+
+func keys(counters map[string]*counter) []string {
+	var result []string
+	for key := range counters {
+		result = append(result, key)
+	}
+	return result
+}
+
+func TestPeerSessionsHandler(t *testing.T) {
+	server := &remotedialer.Server{PeerID: "10.0.0.1", PeerToken: "token"}
+	Sessions.setPeers([]string{"10.0.0.2"})
+	defer Sessions.setPeers(nil)
+	handler := NewPeerSessionsHandler(server)
+
+	for _, test := range []struct {
+		peer, token string
+		code        int
+	}{
+		{peer: "10.0.0.2", token: "token", code: 200},
+		{peer: "10.0.0.2", token: "wrong", code: 403},
+		{peer: "10.0.0.3", token: "token", code: 403},
+	} {
+		req := httptest.NewRequest("GET", "/meta/tunnelsessions", nil)
+		req.Header.Set(remotedialer.ID, test.peer)
+		req.Header.Set(remotedialer.Token, test.token)
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		assert.Equal(t, test.code, rw.Code, "peer %s with token %s", test.peer, test.token)
+	}
+}
+
+func TestPeerSessionsVerifyPeer(t *testing.T) {
+	var tokens []string
+	peer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		tokens = append(tokens, req.Header.Get(remotedialer.Token))
+		rw.Write([]byte("{}"))
+	}))
+	defer peer.Close()
+	host, port, err := net.SplitHostPort(peer.Listener.Addr().String())
+	assert.Nil(t, err)
+
+	peerCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: peer.Certificate().Raw})
+	otherCA, _, err := factory.GenCA()
+	assert.Nil(t, err)
+	ca := peerCA
+	secrets := &fakes.SecretListerMock{
+		GetFunc: func(namespace, name string) (*corev1.Secret, error) {
+			return &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: ca}}, nil
+		},
+	}
+
+	server := &remotedialer.Server{PeerID: "10.0.0.1", PeerToken: "token"}
+	h := NewSessionsAPIHandler(server, nil, secrets, 443).(*sessionsHandler)
+	h.peerPort, err = strconv.Atoi(port)
+	assert.Nil(t, err)
+	req := httptest.NewRequest("GET", "/meta/tunnelsessions", nil)
+
+	_, err = h.peerSessions(req, host, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"token"}, tokens)
+
+	// the token is not sent to a peer whose certificate is not signed by the internal CA
+	ca = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherCA.Raw})
+	h.peerClient.CloseIdleConnections()
+	_, err = h.peerSessions(req, host, "")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"token"}, tokens)
+}
+
+func TestCloseRevoked(t *testing.T) {
+	tracker := newSessionTracker()
+	revokedBefore := time.Now()
+	conns := map[string]net.Conn{}
+	for _, test := range []struct {
+		clientKey string
+		issuedAt  time.Time
+	}{
+		{clientKey: "c-1", issuedAt: revokedBefore.Add(-time.Minute)},
+		{clientKey: "c-1:m-1", issuedAt: revokedBefore.Add(time.Minute)},
+		{clientKey: "c-2", issuedAt: revokedBefore.Add(-time.Minute)},
+	} {
+		req := httptest.NewRequest("GET", "/v3/connect", nil)
+		tracker.authorized(req, test.clientKey, test.issuedAt)
+		client, server := net.Pipe()
+		defer client.Close()
+		tracker.hijacked(req, server)
+		conns[test.clientKey] = client
+	}
+
+	tracker.closeRevoked("c-1", revokedBefore)
+	for clientKey, closed := range map[string]bool{"c-1": true, "c-1:m-1": false, "c-2": false} {
+		conns[clientKey].SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+		_, err := conns[clientKey].Read(make([]byte, 1))
+		assert.Equal(t, closed, err == io.EOF, clientKey)
+	}
+}
+
+func TestCountingConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	session := &Session{Cluster: "c-1"}
+	conn := &countingConn{Conn: server, session: session}
+
+	go client.Write([]byte("hello"))
+	buf := make([]byte, 5)
+	n, err := conn.Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, n)
+
+	go func() {
+		buf := make([]byte, 3)
+		client.Read(buf)
+	}()
+	_, err = conn.Write([]byte("abc"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, int64(5), session.received)
+	assert.Equal(t, int64(3), session.transmitted)
+}
@@ -10,13 +10,16 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	v32 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
 
 	"github.com/rancher/norman/types/convert"
 	client "github.com/rancher/rancher/pkg/client/generated/management/v3"
 	provisioner "github.com/rancher/rancher/pkg/controllers/management/clusterprovisioner"
+	corev1 "github.com/rancher/rancher/pkg/generated/norman/core/v1"
 	v3 "github.com/rancher/rancher/pkg/generated/norman/management.cattle.io/v3"
+	"github.com/rancher/rancher/pkg/settings"
 	"github.com/rancher/rancher/pkg/taints"
 	"github.com/rancher/rancher/pkg/types/config"
 	"github.com/rancher/remotedialer"
@@ -64,6 +67,8 @@ func NewAuthorizer(context *config.ScaledContext) *Authorizer {
 		machines:              context.Management.Nodes(""),
 		clusters:              context.Management.Clusters(""),
 		KontainerDriverLister: context.Management.KontainerDrivers("").Controller().Lister(),
+		secretLister:          context.Core.Secrets("").Controller().Lister(),
+		secrets:               context.Core.Secrets(""),
 	}
 	context.Management.ClusterRegistrationTokens("").Controller().Informer().AddIndexers(map[string]cache.IndexFunc{
 		crtKeyIndex: auth.crtIndex,
@@ -82,6 +87,8 @@ type Authorizer struct {
 	machines              v3.NodeInterface
 	clusters              v3.ClusterInterface
 	KontainerDriverLister v3.KontainerDriverLister
+	secretLister          corev1.SecretLister
+	secrets               corev1.SecretInterface
 }
 
 type Client struct {
@@ -90,17 +97,26 @@ type Client struct {
 	Token       string
 	Server      string
 	NodeVersion int
+	// IssuedAt is the time the credential the client authenticated with was issued, zero for registration tokens
+	IssuedAt time.Time
 }
 
+// authorizeTunnel authorizes the tunnel of an agent and starts tracking its session
 func (t *Authorizer) authorizeTunnel(req *http.Request) (string, bool, error) {
 	client, ok, err := t.Authorize(req)
+	var clientKey string
 	if client != nil && client.Node != nil {
-		return client.Cluster.Name + ":" + client.Node.Name, ok, err
+		clientKey = client.Cluster.Name + ":" + client.Node.Name
 	} else if client != nil && client.Cluster != nil {
-		return client.Cluster.Name, ok, err
+		clientKey = client.Cluster.Name
+	} else {
+		return "", false, err
 	}
 
-	return "", false, err
+	if ok && err == nil {
+		Sessions.authorized(req, clientKey, client.IssuedAt)
+	}
+	return clientKey, ok, err
 }
 
 func (t *Authorizer) Authorize(req *http.Request) (*Client, bool, error) {
@@ -110,7 +126,19 @@ func (t *Authorizer) Authorize(req *http.Request) (*Client, bool, error) {
 		return nil, false, nil
 	}
 
-	cluster, err := t.getClusterByToken(token)
+	var (
+		cluster *v3.Cluster
+		cred    *credential
+		err     error
+	)
+	if isCredential(token) {
+		cluster, cred, err = t.verifyCredential(token)
+	} else if settings.AgentTunnelCredentialRequired.Get() == "true" && !strings.HasSuffix(req.URL.Path, "/credential") {
+		logrus.Debugf("Authorize: registration token rejected, agents must connect with a credential")
+		return nil, false, nil
+	} else {
+		cluster, err = t.getClusterByToken(token)
+	}
 	if err != nil || cluster == nil {
 		return nil, false, err
 	}
@@ -119,6 +147,9 @@ func (t *Authorizer) Authorize(req *http.Request) (*Client, bool, error) {
 	if err != nil {
 		return nil, false, err
 	}
+	if cred != nil && (input.Node == nil) != (cred.Node == "") {
+		return nil, false, errInvalidCredential
+	}
 
 	if input.Node != nil {
 		register := strings.HasSuffix(req.URL.Path, "/register")
@@ -127,6 +158,9 @@ func (t *Authorizer) Authorize(req *http.Request) (*Client, bool, error) {
 		if err != nil {
 			return nil, false, err
 		}
+		if cred != nil && cred.Node != node.Name {
+			return nil, false, errInvalidCredential
+		}
 		if register && node.Status.NodeConfig != nil && input.Node.CustomConfig != nil {
 			node = node.DeepCopy()
 			node.Status.NodeConfig.Address = input.Node.CustomConfig.Address
@@ -139,15 +173,17 @@ func (t *Authorizer) Authorize(req *http.Request) (*Client, bool, error) {
 			Token:       token,
 			Server:      req.Host,
 			NodeVersion: input.NodeVersion,
+			IssuedAt:    cred.issuedAt(),
 		}, ok, err
 	}
 
 	if input.Cluster != nil {
 		cluster, ok, err := t.authorizeCluster(cluster, input.Cluster, req)
 		return &Client{
-			Cluster: cluster,
-			Token:   token,
-			Server:  req.Host,
+			Cluster:  cluster,
+			Token:    token,
+			Server:   req.Host,
+			IssuedAt: cred.issuedAt(),
 		}, ok, err
 	}
 
@@ -198,6 +198,7 @@ type UserContext struct {
 	ControllerFactory controller.SharedControllerFactory
 	UnversionedClient rest.Interface
 	APIExtClient      clientset.Interface
+	DynamicClient     k8dynamic.Interface
 	K8sClient         kubernetes.Interface
 	runContext        context.Context
 
@@ -367,6 +368,11 @@ func NewUserContext(scaledContext *ScaledContext, config rest.Config, clusterNam
 		return nil, err
 	}
 
+	context.DynamicClient, err = k8dynamic.NewForConfig(&config)
+	if err != nil {
+		return nil, err
+	}
+
 	context.Apps, err = appsv1.NewFromControllerFactory(controllerFactory)
 	if err != nil {
 		return nil, err
@@ -11,6 +11,7 @@ type Manager interface {
 	GetUser(apiContext *types.APIContext) string
 	EnsureToken(tokenName, description, kind, userName string) (string, error)
 	EnsureClusterToken(clusterName, tokenName, description, kind, userName string) (string, error)
+	EnsureClustersToken(clusterNames []string, tokenName, description, kind, userName string) (string, error)
 	EnsureUser(principalName, displayName string) (*v3.User, error)
 	CheckAccess(accessMode string, allowedPrincipalIDs []string, userPrincipalID string, groups []v3.Principal) (bool, error)
 	SetPrincipalOnCurrentUserByUserID(userID string, principal v3.Principal) (*v3.User, error)